@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
@@ -13,21 +16,39 @@ import (
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 
+	"jainfood/internal/admin"
+	"jainfood/internal/analytics"
 	"jainfood/internal/auth"
+	"jainfood/internal/campaigns"
 	"jainfood/internal/chat"
 	"jainfood/internal/db"
+	"jainfood/internal/delivery"
+	"jainfood/internal/devices"
+	"jainfood/internal/dietary"
 	"jainfood/internal/events"
+	"jainfood/internal/hours"
+	"jainfood/internal/jaincalendar"
+	"jainfood/internal/jainrules"
 	"jainfood/internal/media"
 	"jainfood/internal/menus"
 	"jainfood/internal/middleware"
+	"jainfood/internal/models"
 	"jainfood/internal/monitoring"
 	"jainfood/internal/notify"
+	"jainfood/internal/onboarding"
 	"jainfood/internal/orders"
 	"jainfood/internal/payment"
+	"jainfood/internal/pii"
+	"jainfood/internal/places"
+	"jainfood/internal/promotions"
 	"jainfood/internal/providers"
+	"jainfood/internal/push"
+	"jainfood/internal/queue"
 	"jainfood/internal/redisclient"
 	"jainfood/internal/reviews"
 	"jainfood/internal/search"
+	"jainfood/internal/topics"
+	"jainfood/internal/travel"
 	"jainfood/internal/users"
 	"jainfood/internal/util"
 )
@@ -76,6 +97,19 @@ func main() {
 		}
 	}
 
+	// Blocked users' tokens are refused through a Redis denylist; reload it
+	// from the database in case Redis lost it
+	if blocked, err := users.ListActiveBlocks(ctx); err != nil {
+		logger.Warn("failed to load blocked users", zap.Error(err))
+	} else {
+		for _, u := range blocked {
+			if err := middleware.DenyUser(ctx, u.ID, u.BlockedUntil); err != nil {
+				logger.Warn("failed to restore token denylist", zap.Error(err))
+				break
+			}
+		}
+	}
+
 	// Initialize media client (optional - for object storage)
 	var mediaClient *media.Client
 	if cfg.S3Endpoint != "" {
@@ -92,6 +126,34 @@ func main() {
 		}
 	}
 
+	// Aadhaar numbers and address street lines are stored envelope-encrypted;
+	// without keys Aadhaar numbers are refused, and addresses (outside
+	// production only) are stored in the clear until keys are configured
+	var piiKeys *pii.Keyring
+	if cfg.PIIKeys != "" {
+		piiKeys, err = pii.NewKeyring(cfg.PIIKeys, cfg.PIIActiveKeyID, cfg.PIIHashKey)
+		if err != nil {
+			logger.Fatal("invalid PII key configuration", zap.Error(err))
+		}
+		go func() {
+			saved, err := users.EncryptStoredAddresses(ctx, piiKeys)
+			if err != nil {
+				logger.Error("failed to encrypt saved addresses", zap.Error(err))
+			}
+			ordered, err := orders.EncryptStoredAddresses(ctx, piiKeys)
+			if err != nil {
+				logger.Error("failed to encrypt order addresses", zap.Error(err))
+			}
+			if saved+ordered > 0 {
+				logger.Info("addresses encrypted", zap.Int("saved", saved), zap.Int("orders", ordered))
+			}
+		}()
+	} else if cfg.IsProduction() {
+		logger.Fatal("PII_KEYS is required in production")
+	} else {
+		logger.Warn("PII_KEYS not set; Aadhaar numbers will be rejected and addresses stored in the clear")
+	}
+
 	// Initialize payment service (optional - for Razorpay)
 	var paymentService payment.PaymentService
 	razorpayKeyID := os.Getenv("RAZORPAY_KEY_ID")
@@ -104,8 +166,244 @@ func main() {
 		logger.Info("payment service initialized", zap.String("provider", "mock"))
 	}
 
+	// Notifications go over SMS or WhatsApp depending on the user's preferred channel
+	whatsappNotifier := notify.NewWhatsAppNotifierFromEnv()
+	if whatsappNotifier != nil {
+		whatsappNotifier.OnSent = func(messageID, recipient, template string) {
+			if err := notify.RecordDelivery(ctx, models.ChannelWhatsApp, messageID, recipient, template); err != nil {
+				logger.Warn("failed to record whatsapp delivery", zap.Error(err))
+			}
+		}
+		logger.Info("whatsapp notifications enabled")
+	}
+	notifier := notify.NewChannelRouter(notify.NewNotifier(), whatsappNotifier, users.GetPreferredChannelByPhone)
+
+	// Initialize notification queue; OTP and other notifications are sent by
+	// background workers so slow SMS APIs don't block requests
+	notifyQueue := queue.New("notify", cfg.QueueVisibility)
+	if cfg.QueueWorkers > 0 {
+		notifyWorker := queue.NewWorker(notifyQueue, cfg.QueueWorkers, logger)
+		notify.RegisterHandlers(notifyWorker, notifier)
+		go notifyWorker.Run(ctx)
+	} else {
+		// Nothing here consumes the queue; OTPs wait for cmd/worker
+		logger.Warn("in-process queue workers disabled; run cmd/worker to send notifications",
+			zap.String("queue", notifyQueue.Name))
+	}
+
+	// Push notifications are delivered to every registered device of the recipient
+	pushService := push.NewPushService(devices.Store{})
+	pushHelper := push.NewNotificationHelper(pushService, devices.Store{})
+
+	// Topic broadcasts fan out to subscribers' devices so quiet hours and
+	// per-user frequency caps can be enforced
+	topicFanout := &push.TopicFanout{Helper: pushHelper, Topics: topics.Store{}}
+	campaignRules := campaigns.Rules{
+		QuietStart:          cfg.CampaignQuietStart,
+		QuietEnd:            cfg.CampaignQuietEnd,
+		Location:            hours.LoadLocation(cfg.CampaignTimezone),
+		DailyCap:            cfg.CampaignDailyCap,
+		ProviderWeeklyLimit: cfg.CampaignProviderWeeklyLimit,
+	}
+	go campaigns.NewDispatcher(topicFanout, campaignRules, logger).Run(ctx)
+
+	// Replace search keys the SQL migration could only lowercase with the
+	// transliterated ones
+	go func() {
+		res, err := search.BackfillKeys(ctx)
+		if err != nil {
+			logger.Warn("search key backfill failed; run POST /v1/admin/search/reindex", zap.Error(err))
+		} else if res.Providers+res.MenuItems > 0 {
+			logger.Info("search keys backfilled", zap.Int("providers", res.Providers), zap.Int("menu_items", res.MenuItems))
+		}
+	}()
+
+	// Provider analytics are served from daily rollups kept current in the background
+	analyticsLoc := hours.LoadLocation(cfg.AnalyticsTimezone)
+	if cfg.AnalyticsRollupInterval > 0 {
+		go analytics.NewRoller(analyticsLoc, time.Duration(cfg.AnalyticsRollupInterval)*time.Second, logger).Run(ctx)
+	} else {
+		logger.Warn("in-process analytics rollups disabled; run cmd/worker with -rollup-interval or provider analytics stop updating")
+	}
+
+	// Promotions switch off when they end or go unpaid; impressions and
+	// daily caps are counted on analytics days
+	if cfg.PromotionExpiryCheck > 0 {
+		go promotions.NewExpirer(time.Duration(cfg.PromotionExpiryCheck)*time.Second, logger).Run(ctx)
+	} else {
+		logger.Info("in-process promotion expiry disabled")
+	}
+	// servePromotions counts an impression for each picked promotion still
+	// under its daily cap and returns those, the only ones to show
+	servePromotions := func(ids []string) map[string]bool {
+		served, err := promotions.RecordImpressions(ctx, promotions.Today(time.Now(), analyticsLoc), ids)
+		if err != nil {
+			logger.Warn("failed to record promotion impressions", zap.Error(err))
+		}
+		return served
+	}
+
+	// Observance days and chovihar windows are computed at the request's
+	// location, or the configured reference location
+	calendarPlace := jaincalendar.Place{
+		Lat:      cfg.JainCalendarLat,
+		Lng:      cfg.JainCalendarLng,
+		Location: hours.LoadLocation(cfg.JainCalendarTimezone),
+	}
+	placeFor := func(lat, lng float64) jaincalendar.Place {
+		if lat == 0 && lng == 0 {
+			return calendarPlace
+		}
+		return jaincalendar.Place{Lat: lat, Lng: lng, Location: calendarPlace.Location}
+	}
+	choviharRules := jaincalendar.ChoviharRules{
+		DeliveryBuffer: time.Duration(cfg.ChoviharDeliveryBuffer) * time.Minute,
+		OrderLead:      time.Duration(cfg.ChoviharOrderLeadMinutes) * time.Minute,
+	}
+	rankWeights := search.RankWeights{
+		Relevance:        cfg.SearchWeightRelevance,
+		Rating:           cfg.SearchWeightRating,
+		Distance:         cfg.SearchWeightDistance,
+		Popularity:       cfg.SearchWeightPopularity,
+		DistanceHalfLife: cfg.SearchDistanceHalfLifeM,
+		RatingPrior:      cfg.SearchRatingPrior,
+		RatingMinVotes:   cfg.SearchRatingMinVotes,
+		PopularityCap:    search.DefaultRankWeights.PopularityCap,
+	}
+	// searchLimit reads the page size for cursor-paged search results
+	searchLimit := func(c *gin.Context) int {
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if limit <= 0 || limit > 100 {
+			return 20
+		}
+		return limit
+	}
+	// searchLocation reads where to search: lat/lng, or a pin code (pin=) or
+	// city (city=) resolved from the offline dataset. The error is for a 400.
+	searchLocation := func(c *gin.Context) (float64, float64, error) {
+		lat, _ := strconv.ParseFloat(c.Query("lat"), 64)
+		lng, _ := strconv.ParseFloat(c.Query("lng"), 64)
+		switch {
+		case lat != 0 || lng != 0:
+			return lat, lng, nil
+		case c.Query("pin") != "":
+			pc, err := places.LookupPin(ctx, c.Query("pin"))
+			if err != nil {
+				return 0, 0, fmt.Errorf("unknown pin code")
+			}
+			return pc.Lat, pc.Lng, nil
+		case c.Query("city") != "":
+			lat, lng, err := places.CityCentre(ctx, c.Query("city"))
+			if err != nil {
+				return 0, 0, fmt.Errorf("unknown city")
+			}
+			return lat, lng, nil
+		}
+		return 0, 0, fmt.Errorf("lat and lng, pin or city is required")
+	}
+	// deliverAt reads the opening-hours filter: deliver_at (RFC 3339) or
+	// open_now=true. Zero means no filter; false means a malformed time.
+	deliverAt := func(c *gin.Context) (time.Time, bool) {
+		if v := c.Query("deliver_at"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			return t, err == nil
+		}
+		if open, _ := strconv.ParseBool(c.Query("open_now")); open {
+			return time.Now(), true
+		}
+		return time.Time{}, true
+	}
+	validItemTags := func(tags []string) bool {
+		for _, t := range tags {
+			if t != models.ItemTagTithiSafe && t != models.ItemTagAyambil {
+				return false
+			}
+		}
+		return true
+	}
+
+	// applicationError writes the response for a failed onboarding
+	// application change
+	applicationError := func(c *gin.Context, err error) {
+		var incomplete *onboarding.IncompleteError
+		var transition *onboarding.TransitionError
+		switch {
+		case err == db.ErrNotFound:
+			c.JSON(404, gin.H{"error": "application not found"})
+		case err == onboarding.ErrNotEditable, err == onboarding.ErrTooManyDocuments,
+			err == onboarding.ErrDuplicateAadhaar, errors.As(err, &transition):
+			c.JSON(409, gin.H{"error": err.Error()})
+		case err == pii.ErrNotConfigured:
+			c.JSON(503, gin.H{"error": "Aadhaar numbers can't be stored right now; choose another ID proof"})
+		case errors.As(err, &incomplete):
+			c.JSON(422, gin.H{"error": "application is incomplete", "missing": incomplete.Missing})
+		default:
+			logger.Error("provider application update failed", zap.Error(err))
+			c.JSON(500, gin.H{"error": "application update failed"})
+		}
+	}
+
+	// notifyOrderStatus tells the buyer about an order status change via
+	// WhatsApp (if preferred) and push notifications
+	notifyOrderStatus := func(orderID, status string) {
+		order, err := orders.GetOrderByID(ctx, piiKeys, orderID)
+		if err != nil {
+			logger.Warn("order update: order lookup failed", zap.Error(err), zap.String("order_id", orderID))
+			return
+		}
+		if buyer, err := users.GetUserByID(ctx, order.BuyerID); err == nil && buyer.Phone != "" {
+			if err := notify.EnqueueOrderUpdate(ctx, notifyQueue, buyer.Phone, order.OrderCode, status); err != nil {
+				logger.Warn("order update: enqueue failed", zap.Error(err), zap.String("order_id", orderID))
+			}
+		}
+
+		providerName := ""
+		if provider, err := providers.GetProvider(ctx, order.ProviderID); err == nil {
+			providerName = provider.BusinessName
+		}
+		go func() {
+			var err error
+			switch status {
+			case models.OrderStatusConfirmed:
+				err = pushHelper.NotifyOrderConfirmed(ctx, order.BuyerID, orderID, providerName)
+			case models.OrderStatusReady:
+				err = pushHelper.NotifyOrderReady(ctx, order.BuyerID, orderID, providerName)
+			}
+			if err != nil {
+				logger.Warn("order update: push failed", zap.Error(err), zap.String("order_id", orderID))
+			}
+		}()
+	}
+
 	// Initialize chat hub
 	chatHub := chat.NewHub(logger)
+	chatHub.OnMessage = func(msg *chat.Message) {
+		room, err := chat.GetChat(ctx, msg.ChatID)
+		if err != nil {
+			return
+		}
+		senderName := "Someone"
+		if sender, err := users.GetUserByID(ctx, msg.SenderID); err == nil && sender.Name != "" {
+			senderName = sender.Name
+		}
+		// Only push to participants who aren't looking at the chat right now
+		for _, participant := range room.Participants {
+			if participant == msg.SenderID || chatHub.IsConnected(msg.ChatID, participant) {
+				continue
+			}
+			if err := pushHelper.NotifyNewMessage(ctx, participant, senderName, msg.Content, msg.ChatID); err != nil {
+				logger.Warn("chat push failed", zap.Error(err), zap.String("chat_id", msg.ChatID))
+			}
+		}
+	}
+	// Blocked users can't chat, and chats about a blocked provider's orders close
+	chatHub.CanChat = func(ctx context.Context, chatID, userID string) bool {
+		if middleware.IsDenied(ctx, userID) {
+			return false
+		}
+		blocked, err := chat.ProviderBlocked(ctx, chatID)
+		return err == nil && !blocked
+	}
 	go chatHub.Run()
 
 	// Setup Gin router
@@ -185,6 +483,7 @@ func main() {
 				var body struct {
 					Phone   string `json:"phone" binding:"required,min=4"`
 					Purpose string `json:"purpose"` // "login" or "register"
+					Channel string `json:"channel"` // Optional: "sms" or "whatsapp"
 				}
 				if err := c.ShouldBindJSON(&body); err != nil {
 					logger.Error("send-otp: invalid request body", zap.Error(err))
@@ -192,6 +491,11 @@ func main() {
 					return
 				}
 
+				if body.Channel != "" && body.Channel != models.ChannelSMS && body.Channel != models.ChannelWhatsApp {
+					c.JSON(400, gin.H{"error": "invalid_channel", "message": "Channel must be sms or whatsapp."})
+					return
+				}
+
 				logger.Info("send-otp: request received",
 					zap.String("phone", util.MaskPhone(body.Phone)),
 					zap.String("purpose", body.Purpose),
@@ -240,19 +544,24 @@ func main() {
 					return
 				}
 
-				// Send OTP via SMS based on environment configuration
-				smsSent := false
+				// Queue OTP SMS based on environment configuration; fall back to
+				// sending inline if the queue is unavailable
+				smsQueued := false
 				smsError := ""
 				if cfg.ShouldSendSMS() {
-					notifier := notify.NewNotifier()
-					if err := notifier.SendOTP(body.Phone, otp); err != nil {
-						logger.Warn("send-otp: SMS sending failed",
+					if err := notify.EnqueueOTP(ctx, notifyQueue, body.Phone, otp, body.Purpose, body.Channel, 10*time.Minute); err != nil {
+						logger.Warn("send-otp: enqueue failed, sending inline",
 							zap.Error(err),
 							zap.String("service", cfg.NotifyService))
-						smsError = err.Error()
+						if err := notifier.SendOTPVia(c.Request.Context(), body.Channel, body.Phone, otp); err != nil {
+							logger.Warn("send-otp: SMS sending failed",
+								zap.Error(err),
+								zap.String("service", cfg.NotifyService))
+							smsError = err.Error()
+						}
 					} else {
-						smsSent = true
-						logger.Info("send-otp: SMS sent successfully",
+						smsQueued = true
+						logger.Info("send-otp: SMS queued",
 							zap.String("phone", util.MaskPhone(body.Phone)),
 							zap.String("service", cfg.NotifyService))
 					}
@@ -278,7 +587,7 @@ func main() {
 
 				// Include SMS status in dev mode for debugging
 				if cfg.IsDevelopment() {
-					response["sms_sent"] = smsSent
+					response["sms_queued"] = smsQueued
 					if smsError != "" {
 						response["sms_error"] = smsError
 					}
@@ -472,6 +781,16 @@ func main() {
 					c.JSON(500, gin.H{"error": "user creation failed"})
 					return
 				}
+				blocked, err := users.IsBlocked(ctx, user.ID)
+				if err != nil {
+					logger.Error("block check failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "user lookup failed"})
+					return
+				}
+				if blocked {
+					c.JSON(403, gin.H{"error": "user_blocked", "message": "Your account has been blocked. Please contact support."})
+					return
+				}
 
 				// Generate JWT
 				token, err := middleware.GenerateJWT(cfg.JwtSecret, user.ID, user.Phone, user.Role)
@@ -515,19 +834,30 @@ func main() {
 			userGroup.PUT("/me", func(c *gin.Context) {
 				userID, _ := middleware.GetUserIDFromContext(c)
 				var body struct {
-					Name        string                 `json:"name"`
-					Email       string                 `json:"email"`
-					Preferences map[string]interface{} `json:"preferences"`
+					Name             string                 `json:"name"`
+					Email            string                 `json:"email"`
+					Preferences      map[string]interface{} `json:"preferences"`
+					PreferredChannel string                 `json:"preferred_channel"` // "sms" or "whatsapp"
 				}
 				if err := c.ShouldBindJSON(&body); err != nil {
 					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
+				if body.PreferredChannel != "" && body.PreferredChannel != models.ChannelSMS && body.PreferredChannel != models.ChannelWhatsApp {
+					c.JSON(400, gin.H{"error": "preferred_channel must be sms or whatsapp"})
+					return
+				}
 
 				if err := users.UpdateUser(ctx, userID, body.Name, body.Email, body.Preferences); err != nil {
 					c.JSON(500, gin.H{"error": "update failed"})
 					return
 				}
+				if body.PreferredChannel != "" {
+					if err := users.SetPreferredChannel(ctx, userID, body.PreferredChannel); err != nil {
+						c.JSON(500, gin.H{"error": "update failed"})
+						return
+					}
+				}
 				c.JSON(200, gin.H{"message": "updated"})
 			})
 
@@ -541,813 +871,3583 @@ func main() {
 				c.JSON(200, gin.H{"message": "deleted"})
 			})
 
-			// Admin: List all users
-			userGroup.GET("", middleware.RoleMiddleware("admin"), func(c *gin.Context) {
-				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
-				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+			// Register a device for push notifications
+			userGroup.POST("/me/devices", func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				var body struct {
+					Token    string `json:"token" binding:"required"`
+					Platform string `json:"platform" binding:"required"` // android, ios, web
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				if !devices.ValidPlatform(body.Platform) {
+					c.JSON(400, gin.H{"error": "platform must be android, ios or web"})
+					return
+				}
 
-				list, err := users.ListUsers(ctx, limit, offset)
+				device, err := devices.RegisterDevice(ctx, userID, body.Token, body.Platform)
 				if err != nil {
-					c.JSON(500, gin.H{"error": "failed to list users"})
+					logger.Error("device registration failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "device registration failed"})
 					return
 				}
-				c.JSON(200, list)
+				c.JSON(201, device)
 			})
 
-		}
+			// Register a browser Web Push subscription (PushSubscription.toJSON())
+			userGroup.POST("/me/webpush-subscriptions", func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				var body push.WebPushSubscription
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				if body.Endpoint == "" || body.Keys.P256dh == "" || body.Keys.Auth == "" {
+					c.JSON(400, gin.H{"error": "endpoint, keys.p256dh and keys.auth are required"})
+					return
+				}
 
-		// ==================== PROVIDER ROUTES ====================
-		providerGroup := v1.Group("/providers")
-		{
-			// Public: Get provider by ID
-			providerGroup.GET("/:id", func(c *gin.Context) {
-				id := c.Param("id")
-				provider, err := providers.GetProvider(ctx, id)
+				device, err := devices.RegisterWebPush(ctx, userID, &body)
+				if errors.Is(err, devices.ErrInvalidEndpoint) {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
 				if err != nil {
-					c.JSON(404, gin.H{"error": "provider not found"})
+					logger.Error("web push subscription failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "web push subscription failed"})
 					return
 				}
-				c.JSON(200, provider)
+				c.JSON(201, device)
 			})
 
-			// Public: List providers
-			providerGroup.GET("", func(c *gin.Context) {
-				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-				list, err := providers.ListProviders(ctx, limit, offset)
+			// My broadcast topic subscriptions
+			userGroup.GET("/me/topics", func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				list, err := topics.GetSubscriptions(ctx, userID)
 				if err != nil {
-					c.JSON(500, gin.H{"error": "failed to list providers"})
+					c.JSON(500, gin.H{"error": "failed to list topics"})
 					return
 				}
-				c.JSON(200, list)
+				c.JSON(200, gin.H{"topics": list})
 			})
 
-			// Protected: Create provider
-			providerGroup.POST("", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+			// Replace my topic subscriptions with those derived from my interests
+			userGroup.PUT("/me/topics", func(c *gin.Context) {
 				userID, _ := middleware.GetUserIDFromContext(c)
-				var body struct {
-					BusinessName string   `json:"business_name" binding:"required"`
-					Address      string   `json:"address" binding:"required"`
-					Lat          float64  `json:"lat" binding:"required"`
-					Lng          float64  `json:"lng" binding:"required"`
-					Tags         []string `json:"tags"`
-				}
+				var body topics.Interests
 				if err := c.ShouldBindJSON(&body); err != nil {
 					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
 
-				provider, err := providers.CreateProvider(ctx, userID, body.BusinessName, body.Address, body.Lat, body.Lng, body.Tags)
+				list, err := topics.FromInterests(body)
 				if err != nil {
-					logger.Error("provider creation failed", zap.Error(err))
-					c.JSON(500, gin.H{"error": "provider creation failed"})
+					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
-
-				_ = events.LogEvent(ctx, "provider", provider.ID, events.EventProviderCreated, map[string]interface{}{
-					"user_id":       userID,
-					"business_name": body.BusinessName,
-				})
-
-				c.JSON(201, provider)
+				if err := topics.SetSubscriptions(ctx, userID, list); err != nil {
+					c.JSON(500, gin.H{"error": "failed to update topics"})
+					return
+				}
+				if list == nil {
+					list = []string{}
+				}
+				c.JSON(200, gin.H{"topics": list})
 			})
 
-			// Protected: Update provider
-			providerGroup.PUT("/:id", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
-				providerID := c.Param("id")
-				var body struct {
-					BusinessName string   `json:"business_name"`
-					Address      string   `json:"address"`
-					Lat          float64  `json:"lat"`
-					Lng          float64  `json:"lng"`
-					Tags         []string `json:"tags"`
+			// My dietary profile (defaults to standard if never saved)
+			userGroup.GET("/me/dietary-profile", func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				profile, err := users.GetDietaryProfile(ctx, userID)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to get dietary profile"})
+					return
 				}
+				c.JSON(200, profile)
+			})
+
+			// Replace my dietary profile
+			userGroup.PUT("/me/dietary-profile", func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				var body models.DietaryProfile
 				if err := c.ShouldBindJSON(&body); err != nil {
 					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
+				body.UserID = userID
+				if body.Strictness == "" {
+					body.Strictness = models.StrictnessStandard
+				}
+				if err := dietary.Validate(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
 
-				if err := providers.UpdateProvider(ctx, providerID, body.BusinessName, body.Address, body.Lat, body.Lng, body.Tags); err != nil {
-					c.JSON(500, gin.H{"error": "update failed"})
+				if err := users.SetDietaryProfile(ctx, &body); err != nil {
+					c.JSON(500, gin.H{"error": "failed to save dietary profile"})
 					return
 				}
-				c.JSON(200, gin.H{"message": "updated"})
+				c.JSON(200, body)
 			})
 
-			// Admin: Verify provider
-			providerGroup.POST("/:id/verify", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("admin"), func(c *gin.Context) {
-				providerID := c.Param("id")
-				var body struct {
-					Verified bool `json:"verified"`
+			// My saved addresses, default first
+			userGroup.GET("/me/addresses", func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				list, err := users.ListAddresses(ctx, piiKeys, userID)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to list addresses"})
+					return
 				}
+				c.JSON(200, list)
+			})
+
+			// saveAddress handles create (no :id) and update of an address
+			saveAddress := func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				var body models.Address
 				if err := c.ShouldBindJSON(&body); err != nil {
 					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
-
-				if err := providers.VerifyProvider(ctx, providerID, body.Verified); err != nil {
-					c.JSON(500, gin.H{"error": "verification update failed"})
-					return
+				body.ID = c.Param("id")
+				body.Label = strings.TrimSpace(body.Label)
+				if body.Label == "" {
+					body.Label = "home"
 				}
-
-				_ = events.LogEvent(ctx, "provider", providerID, events.EventProviderVerified, map[string]interface{}{
-					"verified": body.Verified,
-				})
-
-				c.JSON(200, gin.H{"message": "verification status updated"})
-			})
-
-			// Admin: Block provider
-			providerGroup.POST("/:id/block", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("admin"), func(c *gin.Context) {
-				providerID := c.Param("id")
-				var body struct {
-					Reason string `json:"reason" binding:"required"`
+				if strings.TrimSpace(body.Line1) == "" {
+					c.JSON(400, gin.H{"error": "line1 is required"})
+					return
 				}
-				if err := c.ShouldBindJSON(&body); err != nil {
+				if err := places.FillAddress(ctx, &body); err != nil {
 					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
 
-				if err := providers.BlockProvider(ctx, providerID, body.Reason); err != nil {
-					c.JSON(500, gin.H{"error": "block failed"})
+				if err := users.SaveAddress(ctx, piiKeys, userID, &body); err != nil {
+					if err == db.ErrNotFound {
+						c.JSON(404, gin.H{"error": "address not found"})
+						return
+					}
+					logger.Error("failed to save address", zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to save address"})
 					return
 				}
-				c.JSON(200, gin.H{"message": "provider blocked"})
-			})
-
-			// Admin: Unblock provider
-			providerGroup.POST("/:id/unblock", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("admin"), func(c *gin.Context) {
-				providerID := c.Param("id")
-
-				if err := providers.UnblockProvider(ctx, providerID); err != nil {
-					c.JSON(500, gin.H{"error": "unblock failed"})
+				if body.ID == c.Param("id") {
+					c.JSON(200, body)
 					return
 				}
-				c.JSON(200, gin.H{"message": "provider unblocked"})
-			})
-		}
+				c.JSON(201, body)
+			}
 
-		// ==================== MENU ROUTES ====================
-		menuGroup := v1.Group("/menus")
-		{
-			// Public: Get menu by ID
-			menuGroup.GET("/:id", func(c *gin.Context) {
-				id := c.Param("id")
-				menu, err := menus.GetMenu(ctx, id)
-				if err != nil {
-					c.JSON(404, gin.H{"error": "menu not found"})
+			// Add an address (the first one becomes the default)
+			userGroup.POST("/me/addresses", saveAddress)
+
+			// Replace an address; is_default=true makes it the default
+			userGroup.PUT("/me/addresses/:id", saveAddress)
+
+			// Delete an address
+			userGroup.DELETE("/me/addresses/:id", func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				if err := users.DeleteAddress(ctx, userID, c.Param("id")); err != nil {
+					if err == db.ErrNotFound {
+						c.JSON(404, gin.H{"error": "address not found"})
+						return
+					}
+					c.JSON(500, gin.H{"error": "failed to delete address"})
 					return
 				}
-				c.JSON(200, menu)
+				c.JSON(200, gin.H{"message": "address deleted"})
 			})
 
-			// Public: Get menus by provider
-			menuGroup.GET("/provider/:provider_id", func(c *gin.Context) {
-				providerID := c.Param("provider_id")
-				list, err := menus.GetMenusByProvider(ctx, providerID)
+			// List my registered devices
+			userGroup.GET("/me/devices", func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				list, err := devices.GetDevicesByUser(ctx, userID)
 				if err != nil {
-					c.JSON(500, gin.H{"error": "failed to get menus"})
+					c.JSON(500, gin.H{"error": "failed to list devices"})
 					return
 				}
 				c.JSON(200, list)
 			})
 
-			// Protected: Create menu
-			menuGroup.POST("", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
+			// Unregister a device (e.g. on logout)
+			userGroup.DELETE("/me/devices", func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
 				var body struct {
-					ProviderID  string `json:"provider_id" binding:"required"`
-					Name        string `json:"name" binding:"required"`
-					Description string `json:"description"`
+					Token string `json:"token" binding:"required"`
 				}
 				if err := c.ShouldBindJSON(&body); err != nil {
 					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
 
-				menu, err := menus.CreateMenu(ctx, body.ProviderID, body.Name, body.Description)
-				if err != nil {
-					c.JSON(500, gin.H{"error": "menu creation failed"})
+				if err := devices.UnregisterDevice(ctx, userID, body.Token); err != nil {
+					if err == db.ErrNotFound {
+						c.JSON(404, gin.H{"error": "device not found"})
+						return
+					}
+					c.JSON(500, gin.H{"error": "device removal failed"})
+					return
+				}
+				c.JSON(200, gin.H{"message": "device removed"})
+			})
+
+			// Admin: List all users
+			userGroup.GET("", middleware.RoleMiddleware("admin"), func(c *gin.Context) {
+				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+				list, err := users.ListUsers(ctx, limit, offset)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to list users"})
+					return
+				}
+				c.JSON(200, list)
+			})
+
+			// Admin: Block a user; their tokens stop working at once and they can't
+			// log in until the block expires (no until: indefinitely)
+			userGroup.POST("/:id/block", middleware.RoleMiddleware("admin"), middleware.AuditMiddleware(), func(c *gin.Context) {
+				userID := c.Param("id")
+				adminID, _ := middleware.GetUserIDFromContext(c)
+				var body struct {
+					Reason string     `json:"reason" binding:"required"`
+					Until  *time.Time `json:"until"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				if userID == adminID {
+					c.JSON(400, gin.H{"error": "you can't block yourself"})
+					return
+				}
+				if body.Until != nil && !body.Until.After(time.Now()) {
+					c.JSON(400, gin.H{"error": "until must be in the future"})
+					return
+				}
+
+				err := users.BlockUser(ctx, userID, body.Reason, body.Until)
+				if err == db.ErrNotFound {
+					c.JSON(404, gin.H{"error": "user not found"})
+					return
+				}
+				if err != nil {
+					logger.Error("user block failed", zap.String("user_id", userID), zap.Error(err))
+					c.JSON(500, gin.H{"error": "block failed"})
+					return
+				}
+				if err := middleware.DenyUser(ctx, userID, body.Until); err != nil {
+					logger.Error("token denylist update failed", zap.String("user_id", userID), zap.Error(err))
+				}
+
+				_ = events.LogEvent(ctx, "user", userID, events.EventUserBlocked, map[string]interface{}{
+					"admin_id": adminID,
+					"reason":   body.Reason,
+					"until":    body.Until,
+				})
+
+				c.JSON(200, gin.H{"message": "user blocked", "until": body.Until})
+			})
+
+			// Admin: Unblock a user
+			userGroup.POST("/:id/unblock", middleware.RoleMiddleware("admin"), middleware.AuditMiddleware(), func(c *gin.Context) {
+				userID := c.Param("id")
+				err := users.UnblockUser(ctx, userID)
+				if err == db.ErrNotFound {
+					c.JSON(404, gin.H{"error": "user not found"})
+					return
+				}
+				if err != nil {
+					c.JSON(500, gin.H{"error": "unblock failed"})
+					return
+				}
+				if err := middleware.AllowUser(ctx, userID); err != nil {
+					logger.Error("token denylist update failed", zap.String("user_id", userID), zap.Error(err))
+				}
+
+				adminID, _ := middleware.GetUserIDFromContext(c)
+				_ = events.LogEvent(ctx, "user", userID, events.EventUserUnblocked, map[string]interface{}{
+					"admin_id": adminID,
+				})
+
+				c.JSON(200, gin.H{"message": "user unblocked"})
+			})
+
+		}
+
+		// promotionBody books a promoted slot
+		type promotionBody struct {
+			City             string    `json:"city" binding:"required"`
+			ProviderCategory string    `json:"provider_category"` // Empty for every category
+			StartsAt         time.Time `json:"starts_at" binding:"required"`
+			EndsAt           time.Time `json:"ends_at" binding:"required"`
+			DailyCap         int       `json:"daily_cap" binding:"required"`
+		}
+
+		// bookPromotion validates and books a promotion for a provider in its
+		// city, writing the error response and returning nil on failure
+		bookPromotion := func(c *gin.Context, r promotions.Request, source string, amountPaise int64) *models.Promotion {
+			if err := r.Validate(time.Now(), cfg.PromotionMaxDays); err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return nil
+			}
+			in, err := places.ProviderInCity(ctx, r.ProviderID, r.CitySlug)
+			if err == db.ErrNotFound {
+				c.JSON(404, gin.H{"error": "provider or city not found"})
+				return nil
+			}
+			if err != nil {
+				logger.Error("promotion city check failed", zap.Error(err))
+				c.JSON(500, gin.H{"error": "failed to book promotion"})
+				return nil
+			}
+			if !in {
+				c.JSON(400, gin.H{"error": "provider is not in this city"})
+				return nil
+			}
+
+			userID, _ := middleware.GetUserIDFromContext(c)
+			pr, err := promotions.Create(ctx, r, source, amountPaise, userID, cfg.PromotionSlots)
+			if err == promotions.ErrNoSlot || err == promotions.ErrOverlap {
+				c.JSON(409, gin.H{"error": err.Error()})
+				return nil
+			}
+			if err != nil {
+				logger.Error("promotion booking failed", zap.Error(err))
+				c.JSON(500, gin.H{"error": "failed to book promotion"})
+				return nil
+			}
+			_ = events.LogEvent(ctx, "promotion", pr.ID, events.EventPromotionCreated, map[string]interface{}{
+				"provider_id": pr.ProviderID, "city": pr.CitySlug, "provider_category": pr.ProviderCategory,
+				"source": source, "amount_paise": amountPaise, "created_by": userID,
+			})
+			return pr
+		}
+
+		// callerOutlet resolves the outlet the caller is acting for: ?outlet_id=
+		// if their role there passes allowed, or the only outlet where it does.
+		// missing is the error when there is none.
+		callerOutlet := func(c *gin.Context, allowed func(string) bool, missing string) (string, bool) {
+			userID, _ := middleware.GetUserIDFromContext(c)
+			providerID, err := providers.ResolveOutlet(ctx, userID, c.Query("outlet_id"), allowed)
+			switch err {
+			case nil:
+				return providerID, true
+			case db.ErrNotFound:
+				c.JSON(404, gin.H{"error": missing})
+			case providers.ErrOutletRequired:
+				c.JSON(400, gin.H{"error": err.Error()})
+			case providers.ErrOutletForbidden:
+				c.JSON(403, gin.H{"error": err.Error()})
+			default:
+				c.JSON(500, gin.H{"error": "failed to load outlets"})
+			}
+			return "", false
+		}
+
+		// ==================== PROVIDER ROUTES ====================
+		// Admins edit providers, menus, orders and campaigns through the same
+		// routes as their owners; those changes are audited like /admin ones
+		providerGroup := v1.Group("/providers")
+		providerGroup.Use(middleware.AuditAdminMiddleware())
+		{
+			// canManageProvider allows admins and the outlet's owner and managers
+			canManageProvider := func(c *gin.Context, providerID string) bool {
+				if role, _ := middleware.GetRoleFromContext(c); role == models.RoleAdmin {
+					return true
+				}
+				userID, _ := middleware.GetUserIDFromContext(c)
+				role, err := providers.OutletRole(ctx, userID, providerID)
+				if err != nil || !providers.CanManageOutlet(role) {
+					c.JSON(403, gin.H{"error": "not your provider profile"})
+					return false
+				}
+				return true
+			}
+
+			// Public: Get provider by ID, with opening hours and when it next opens
+			providerGroup.GET("/:id", func(c *gin.Context) {
+				id := c.Param("id")
+				provider, err := providers.GetProvider(ctx, id)
+				if err != nil {
+					c.JSON(404, gin.H{"error": "provider not found"})
+					return
+				}
+				schedule, err := providers.GetSchedule(ctx, id)
+				if err != nil {
+					logger.Error("failed to load provider hours", zap.String("provider_id", id), zap.Error(err))
+					c.JSON(200, provider)
+					return
+				}
+				now := time.Now()
+				resp := struct {
+					*models.Provider
+					Hours       *models.ProviderSchedule `json:"hours"`
+					OpenNow     bool                     `json:"open_now"`
+					NextOpening *time.Time               `json:"next_opening,omitempty"`
+				}{Provider: provider, Hours: schedule, OpenNow: hours.CanDeliver(schedule, now, now)}
+				if next, ok := hours.NextOpening(schedule, now); ok {
+					resp.NextOpening = &next
+				}
+				c.JSON(200, resp)
+			})
+
+			// Protected: Order, revenue and rating analytics (owner/manager, admin)
+			providerGroup.GET("/:id/analytics", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID := c.Param("id")
+				if !canManageProvider(c, providerID) {
+					return
+				}
+				q, err := analytics.ParseQuery(c.Query("from"), c.Query("to"), c.Query("granularity"), time.Now().In(analyticsLoc))
+				if err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				report, err := analytics.Report(ctx, providerID, q, analyticsLoc)
+				if err != nil {
+					logger.Error("analytics report failed", zap.String("provider_id", providerID), zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to load analytics"})
+					return
+				}
+				c.JSON(200, report)
+			})
+
+			// Protected: The provider's promotions with their impressions and clicks (owner/manager, admin)
+			providerGroup.GET("/:id/promotions", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID := c.Param("id")
+				if !canManageProvider(c, providerID) {
+					return
+				}
+				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+				list, total, err := promotions.List(ctx, promotions.Filter{
+					ProviderID: providerID, Status: c.Query("status"), Limit: limit, Offset: offset,
+				})
+				if err != nil {
+					logger.Error("failed to list promotions", zap.String("provider_id", providerID), zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to list promotions"})
+					return
+				}
+				c.JSON(200, gin.H{"promotions": list, "total": total})
+			})
+
+			// Protected: Book a promoted slot; it starts once the returned
+			// Razorpay order is paid (owner/manager, admin)
+			providerGroup.POST("/:id/promotions", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID := c.Param("id")
+				if !canManageProvider(c, providerID) {
+					return
+				}
+				var body promotionBody
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				r := promotions.Request{
+					ProviderID: providerID, CitySlug: body.City, ProviderCategory: body.ProviderCategory,
+					StartsAt: body.StartsAt, EndsAt: body.EndsAt, DailyCap: body.DailyCap,
+				}
+				pr := bookPromotion(c, r, models.PromotionSourcePayment, r.Price(cfg.PromotionCPMPaise))
+				if pr == nil {
+					return
+				}
+
+				paymentOrder, err := paymentService.CreateOrder(pr.AmountPaise, "INR", "promotion_"+pr.ID, map[string]string{
+					"promotion_id": pr.ID,
+					"provider_id":  providerID,
+				})
+				if err == nil {
+					err = promotions.SetPaymentOrder(ctx, pr.ID, paymentOrder.ID)
+				}
+				if err != nil {
+					logger.Error("promotion payment order failed", zap.String("promotion_id", pr.ID), zap.Error(err))
+					_, _ = promotions.Cancel(ctx, pr.ID)
+					c.JSON(500, gin.H{"error": "failed to create payment order"})
+					return
+				}
+				pr.PaymentOrderID = paymentOrder.ID
+
+				c.JSON(201, gin.H{
+					"promotion":         pr,
+					"razorpay_order_id": paymentOrder.ID,
+					"amount":            paymentOrder.Amount,
+					"currency":          paymentOrder.Currency,
+					"key_id":            os.Getenv("RAZORPAY_KEY_ID"),
+				})
+			})
+
+			// Protected: Verify a promotion's payment and start it (owner/manager, admin)
+			providerGroup.POST("/:id/promotions/:promotionId/pay", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID := c.Param("id")
+				if !canManageProvider(c, providerID) {
+					return
+				}
+				var body struct {
+					RazorpayPaymentID string `json:"razorpay_payment_id" binding:"required"`
+					RazorpaySignature string `json:"razorpay_signature" binding:"required"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				pr, err := promotions.Get(ctx, c.Param("promotionId"))
+				if err != nil || pr.ProviderID != providerID {
+					c.JSON(404, gin.H{"error": "promotion not found"})
+					return
+				}
+				if !paymentService.VerifyPayment(pr.PaymentOrderID, body.RazorpayPaymentID, body.RazorpaySignature) {
+					c.JSON(400, gin.H{"error": "payment verification failed"})
+					return
+				}
+
+				promotionID, amount := pr.ID, pr.AmountPaise
+				pr, err = promotions.Activate(ctx, promotionID, pr.PaymentOrderID, body.RazorpayPaymentID, cfg.PromotionSlots)
+				if err == promotions.ErrNotPending || err == promotions.ErrNoSlot {
+					// A retried request for a promotion this payment already started
+					if cur, gerr := promotions.Get(ctx, promotionID); gerr == nil &&
+						cur.Status == models.PromotionActive && cur.PaymentID == body.RazorpayPaymentID {
+						c.JSON(200, cur)
+						return
+					}
+
+					// Paid after the slot was released: release it for good and refund
+					logger.Warn("payment for a promotion no longer awaiting it",
+						zap.String("promotion_id", promotionID), zap.String("payment_id", body.RazorpayPaymentID), zap.Error(err))
+					_, _ = promotions.Cancel(ctx, promotionID)
+					refund, rerr := paymentService.RefundPayment(body.RazorpayPaymentID, amount, map[string]string{
+						"promotion_id": promotionID,
+						"reason":       "promotion slot no longer available",
+					})
+					if rerr != nil {
+						logger.Error("promotion refund failed",
+							zap.String("promotion_id", promotionID), zap.String("payment_id", body.RazorpayPaymentID), zap.Error(rerr))
+						c.JSON(409, gin.H{"error": "promotion is no longer awaiting payment; contact support for a refund"})
+						return
+					}
+					_ = events.LogEvent(ctx, "promotion", promotionID, events.EventPromotionRefunded, map[string]interface{}{
+						"provider_id": providerID, "payment_id": body.RazorpayPaymentID, "refund_id": refund.ID, "amount_paise": amount,
+					})
+					c.JSON(409, gin.H{"error": "promotion is no longer awaiting payment; your payment has been refunded", "refund_id": refund.ID})
+					return
+				}
+				if err != nil {
+					logger.Error("promotion activation failed", zap.String("promotion_id", promotionID), zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to start promotion"})
+					return
+				}
+				_ = events.LogEvent(ctx, "promotion", pr.ID, events.EventPromotionActivated, map[string]interface{}{
+					"provider_id": providerID, "payment_id": body.RazorpayPaymentID, "amount_paise": pr.AmountPaise,
+				})
+				c.JSON(200, pr)
+			})
+
+			// Protected: A promotion's impressions and clicks per day, for billing (owner/manager, admin)
+			providerGroup.GET("/:id/promotions/:promotionId/stats", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID := c.Param("id")
+				if !canManageProvider(c, providerID) {
+					return
+				}
+				stats, err := promotions.Stats(ctx, c.Param("promotionId"))
+				if err == db.ErrNotFound || (err == nil && stats.Promotion.ProviderID != providerID) {
+					c.JSON(404, gin.H{"error": "promotion not found"})
+					return
+				}
+				if err != nil {
+					logger.Error("promotion stats failed", zap.String("promotion_id", c.Param("promotionId")), zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to load promotion stats"})
+					return
+				}
+				c.JSON(200, stats)
+			})
+
+			// Public: Opening hours and upcoming holidays
+			providerGroup.GET("/:id/hours", func(c *gin.Context) {
+				schedule, err := providers.GetSchedule(ctx, c.Param("id"))
+				if err != nil {
+					c.JSON(404, gin.H{"error": "provider not found"})
+					return
+				}
+				c.JSON(200, schedule)
+			})
+
+			// Protected: Replace weekly hours (owner or admin)
+			providerGroup.PUT("/:id/hours", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID := c.Param("id")
+				if !canManageProvider(c, providerID) {
+					return
+				}
+				var body struct {
+					Timezone string               `json:"timezone"`
+					Slots    []models.OpeningSlot `json:"slots"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				if body.Timezone == "" {
+					body.Timezone = hours.DefaultTimezone
+				}
+				if err := hours.Validate(&models.ProviderSchedule{Timezone: body.Timezone, Slots: body.Slots}); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				if err := providers.SetHours(ctx, providerID, body.Timezone, body.Slots); err != nil {
+					logger.Error("failed to set provider hours", zap.String("provider_id", providerID), zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to update hours"})
+					return
+				}
+				schedule, err := providers.GetSchedule(ctx, providerID)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to load hours"})
+					return
+				}
+				c.JSON(200, schedule)
+			})
+
+			// Protected: Add a holiday closure (owner or admin)
+			providerGroup.POST("/:id/holidays", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID := c.Param("id")
+				if !canManageProvider(c, providerID) {
+					return
+				}
+				var holiday models.Holiday
+				if err := c.ShouldBindJSON(&holiday); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				if holiday.To == "" {
+					holiday.To = holiday.From
+				}
+				if err := hours.Validate(&models.ProviderSchedule{Holidays: []models.Holiday{holiday}}); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				if err := providers.AddHoliday(ctx, providerID, &holiday); err != nil {
+					logger.Error("failed to add holiday", zap.String("provider_id", providerID), zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to add holiday"})
+					return
+				}
+				c.JSON(201, holiday)
+			})
+
+			// Protected: Remove a holiday closure (owner or admin)
+			providerGroup.DELETE("/:id/holidays/:holidayId", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID := c.Param("id")
+				if !canManageProvider(c, providerID) {
+					return
+				}
+				if err := providers.DeleteHoliday(ctx, providerID, c.Param("holidayId")); err != nil {
+					c.JSON(404, gin.H{"error": "holiday not found"})
+					return
+				}
+				c.JSON(200, gin.H{"message": "holiday removed"})
+			})
+
+			// Public: Delivery zone and fee slabs (null when the provider has none)
+			providerGroup.GET("/:id/delivery-zone", func(c *gin.Context) {
+				zone, err := providers.GetDeliveryZone(ctx, c.Param("id"))
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to load delivery zone"})
+					return
+				}
+				c.JSON(200, zone)
+			})
+
+			// Protected: Set the delivery zone (owner or admin)
+			providerGroup.PUT("/:id/delivery-zone", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID := c.Param("id")
+				if !canManageProvider(c, providerID) {
+					return
+				}
+				var zone models.DeliveryZone
+				if err := c.ShouldBindJSON(&zone); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				zone.ProviderID = providerID
+				if err := delivery.Validate(&zone); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				if err := providers.SetDeliveryZone(ctx, &zone); err != nil {
+					logger.Error("failed to set delivery zone", zap.String("provider_id", providerID), zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to update delivery zone"})
+					return
+				}
+				c.JSON(200, zone)
+			})
+
+			// Protected: Remove the delivery zone (owner or admin)
+			providerGroup.DELETE("/:id/delivery-zone", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID := c.Param("id")
+				if !canManageProvider(c, providerID) {
+					return
+				}
+				if err := providers.DeleteDeliveryZone(ctx, providerID); err != nil {
+					c.JSON(404, gin.H{"error": "delivery zone not found"})
+					return
+				}
+				c.JSON(200, gin.H{"message": "delivery zone removed"})
+			})
+
+			// Public: Delivery fee to lat/lng for a cart subtotal
+			providerGroup.GET("/:id/delivery-quote", func(c *gin.Context) {
+				lat, err1 := strconv.ParseFloat(c.Query("lat"), 64)
+				lng, err2 := strconv.ParseFloat(c.Query("lng"), 64)
+				if err1 != nil || err2 != nil {
+					c.JSON(400, gin.H{"error": "lat and lng are required"})
+					return
+				}
+				subtotal, _ := strconv.ParseFloat(c.DefaultQuery("subtotal", "0"), 64)
+				quote, err := providers.QuoteDelivery(ctx, c.Param("id"), lat, lng, subtotal)
+				if err == db.ErrNotFound {
+					c.JSON(404, gin.H{"error": "provider not found"})
+					return
+				}
+				if err == providers.ErrInvalidLocation {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				if err != nil {
+					logger.Error("delivery quote failed", zap.String("provider_id", c.Param("id")), zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to quote delivery"})
+					return
+				}
+				c.JSON(200, quote)
+			})
+
+			// Public: List providers
+			providerGroup.GET("", func(c *gin.Context) {
+				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+				list, err := providers.ListProviders(ctx, limit, offset)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to list providers"})
+					return
+				}
+				c.JSON(200, list)
+			})
+
+			// Protected: Create provider
+			providerGroup.POST("", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				var body struct {
+					BusinessName string   `json:"business_name" binding:"required"`
+					Address      string   `json:"address" binding:"required"`
+					Lat          float64  `json:"lat" binding:"required"`
+					Lng          float64  `json:"lng" binding:"required"`
+					Tags         []string `json:"tags"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				provider, err := providers.CreateProvider(ctx, userID, body.BusinessName, body.Address, body.Lat, body.Lng, body.Tags)
+				if err != nil {
+					logger.Error("provider creation failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "provider creation failed"})
+					return
+				}
+
+				_ = events.LogEvent(ctx, "provider", provider.ID, events.EventProviderCreated, map[string]interface{}{
+					"user_id":       userID,
+					"business_name": body.BusinessName,
+				})
+
+				c.JSON(201, provider)
+			})
+
+			// Protected: Update provider
+			providerGroup.PUT("/:id", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID := c.Param("id")
+				if !canManageProvider(c, providerID) {
+					return
+				}
+				var body struct {
+					BusinessName string   `json:"business_name"`
+					Address      string   `json:"address"`
+					Lat          float64  `json:"lat"`
+					Lng          float64  `json:"lng"`
+					Tags         []string `json:"tags"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				if err := providers.UpdateProvider(ctx, providerID, body.BusinessName, body.Address, body.Lat, body.Lng, body.Tags); err != nil {
+					c.JSON(500, gin.H{"error": "update failed"})
+					return
+				}
+				c.JSON(200, gin.H{"message": "updated"})
+			})
+
+			// patchProvider applies a JSON merge patch to a provider profile
+			patchProvider := func(c *gin.Context, providerID string) {
+				raw, err := io.ReadAll(io.LimitReader(c.Request.Body, 64<<10))
+				if err != nil {
+					c.JSON(400, gin.H{"error": "failed to read body"})
+					return
+				}
+				patch, err := providers.ParseProfilePatch(raw)
+				if err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				provider, err := providers.PatchProvider(ctx, providerID, patch)
+				if err == db.ErrNotFound {
+					c.JSON(404, gin.H{"error": "provider not found"})
+					return
+				}
+				if err != nil {
+					logger.Error("provider update failed", zap.String("provider_id", providerID), zap.Error(err))
+					c.JSON(500, gin.H{"error": "update failed"})
+					return
+				}
+
+				if !patch.Empty() {
+					userID, _ := middleware.GetUserIDFromContext(c)
+					_ = events.LogEvent(ctx, "provider", providerID, events.EventProviderUpdated, map[string]interface{}{
+						"user_id": userID,
+						"fields":  patch.Fields(),
+					})
+				}
+				c.JSON(200, provider)
+			}
+
+			// Protected: Partially update a provider profile (JSON merge patch;
+			// null resets a field to its default)
+			providerGroup.PATCH("/:id", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				if !canManageProvider(c, c.Param("id")) {
+					return
+				}
+				patchProvider(c, c.Param("id"))
+			})
+
+			// Admin: Verify provider
+			providerGroup.POST("/:id/verify", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("admin"), middleware.AuditMiddleware(), func(c *gin.Context) {
+				providerID := c.Param("id")
+				var body struct {
+					Verified bool `json:"verified"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				if err := providers.VerifyProvider(ctx, providerID, body.Verified); err != nil {
+					c.JSON(500, gin.H{"error": "verification update failed"})
+					return
+				}
+
+				adminID, _ := middleware.GetUserIDFromContext(c)
+				_ = events.LogEvent(ctx, "provider", providerID, events.EventProviderVerified, map[string]interface{}{
+					"admin_id": adminID,
+					"verified": body.Verified,
+				})
+
+				c.JSON(200, gin.H{"message": "verification status updated"})
+			})
+
+			// Admin: Block provider
+			providerGroup.POST("/:id/block", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("admin"), middleware.AuditMiddleware(), func(c *gin.Context) {
+				providerID := c.Param("id")
+				var body struct {
+					Reason string `json:"reason" binding:"required"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				if err := providers.BlockProvider(ctx, providerID, body.Reason); err != nil {
+					c.JSON(500, gin.H{"error": "block failed"})
+					return
+				}
+
+				adminID, _ := middleware.GetUserIDFromContext(c)
+				_ = events.LogEvent(ctx, "provider", providerID, events.EventProviderBlocked, map[string]interface{}{
+					"admin_id": adminID,
+					"reason":   body.Reason,
+				})
+
+				c.JSON(200, gin.H{"message": "provider blocked"})
+			})
+
+			// Admin: Unblock provider
+			providerGroup.POST("/:id/unblock", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("admin"), middleware.AuditMiddleware(), func(c *gin.Context) {
+				providerID := c.Param("id")
+
+				if err := providers.UnblockProvider(ctx, providerID); err != nil {
+					c.JSON(500, gin.H{"error": "unblock failed"})
+					return
+				}
+
+				adminID, _ := middleware.GetUserIDFromContext(c)
+				_ = events.LogEvent(ctx, "provider", providerID, events.EventProviderUnblocked, map[string]interface{}{
+					"admin_id": adminID,
+				})
+
+				c.JSON(200, gin.H{"message": "provider unblocked"})
+			})
+
+			// myProviderID resolves the outlet (?outlet_id=, required for
+			// staff of several outlets) the caller owns or manages
+			myProviderID := func(c *gin.Context) (string, bool) {
+				return callerOutlet(c, providers.CanManageOutlet, "create your provider profile first")
+			}
+
+			// Protected: The caller's own provider profile
+			providerGroup.GET("/me", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID, ok := callerOutlet(c, providers.CanHandleOrders, "create your provider profile first")
+				if !ok {
+					return
+				}
+				provider, err := providers.GetProvider(ctx, providerID)
+				if err != nil {
+					c.JSON(404, gin.H{"error": "create your provider profile first"})
+					return
+				}
+				c.JSON(200, provider)
+			})
+
+			// Protected: Partially update the caller's own provider profile
+			providerGroup.PATCH("/me", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID, ok := myProviderID(c)
+				if !ok {
+					return
+				}
+				patchProvider(c, providerID)
+			})
+
+			// Protected: The caller's verification application, with documents and reviewer comments
+			providerGroup.GET("/me/application", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID, ok := myProviderID(c)
+				if !ok {
+					return
+				}
+				app, err := onboarding.GetApplication(ctx, providerID)
+				if err == db.ErrNotFound {
+					c.JSON(404, gin.H{"error": "no application yet; save your business details to start one"})
+					return
+				}
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to get application"})
+					return
+				}
+				c.JSON(200, app)
+			})
+
+			// Protected: Save business details, starting a draft application
+			// if there isn't one. Only drafts and applications sent back for
+			// changes can be edited.
+			providerGroup.PUT("/me/application", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID, ok := myProviderID(c)
+				if !ok {
+					return
+				}
+				var body struct {
+					LegalName      string `json:"legal_name"`
+					BusinessType   string `json:"business_type"`
+					GSTIN          string `json:"gstin"`
+					FSSAINumber    string `json:"fssai_number"`
+					FSSAIExpiresOn string `json:"fssai_expires_on"`
+					IDProofType    string `json:"id_proof_type"`
+					AadharNumber   string `json:"aadhar_number"` // Stored encrypted; responses show it masked
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				details := &models.ProviderApplication{
+					LegalName:      strings.TrimSpace(body.LegalName),
+					BusinessType:   body.BusinessType,
+					GSTIN:          strings.ToUpper(strings.TrimSpace(body.GSTIN)),
+					FSSAINumber:    strings.TrimSpace(body.FSSAINumber),
+					FSSAIExpiresOn: body.FSSAIExpiresOn,
+					IDProofType:    body.IDProofType,
+				}
+				if err := onboarding.ValidateDetails(details); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				aadhaar := onboarding.NormaliseAadhaar(body.AadharNumber)
+				if aadhaar != "" && !onboarding.ValidAadhaar(aadhaar) {
+					c.JSON(400, gin.H{"error": "aadhar_number is not a valid Aadhaar number"})
+					return
+				}
+
+				app, err := onboarding.SaveDetails(ctx, piiKeys, providerID, details, aadhaar)
+				if err != nil {
+					applicationError(c, err)
+					return
+				}
+				c.JSON(200, app)
+			})
+
+			// Protected: Attach a document uploaded via POST /v1/media/upload-url
+			// with folder "provider-docs". Only the uploader may attach it.
+			providerGroup.POST("/me/application/documents", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID, ok := myProviderID(c)
+				if !ok {
+					return
+				}
+				userID, _ := middleware.GetUserIDFromContext(c)
+				var body struct {
+					Kind      string `json:"kind" binding:"required"` // fssai_licence, id_proof, kitchen_photo
+					ObjectKey string `json:"object_key" binding:"required"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				if onboarding.MaxDocuments(body.Kind) == 0 {
+					c.JSON(400, gin.H{"error": "kind must be fssai_licence, id_proof or kitchen_photo"})
+					return
+				}
+				if !onboarding.ValidObjectKey(body.ObjectKey, userID) {
+					c.JSON(400, gin.H{"error": "object_key must be uploaded by you to the " + onboarding.DocumentFolder + " folder"})
+					return
+				}
+
+				doc, err := onboarding.AddDocument(ctx, providerID, body.Kind, body.ObjectKey)
+				if err != nil {
+					applicationError(c, err)
+					return
+				}
+				c.JSON(201, doc)
+			})
+
+			// Protected: Remove a document from the application
+			providerGroup.DELETE("/me/application/documents/:docId", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID, ok := myProviderID(c)
+				if !ok {
+					return
+				}
+				if err := onboarding.DeleteDocument(ctx, providerID, c.Param("docId")); err != nil {
+					applicationError(c, err)
+					return
+				}
+				c.JSON(200, gin.H{"message": "document deleted"})
+			})
+
+			// Protected: Submit the application for review. Returns 422 with
+			// what is missing if it isn't complete.
+			providerGroup.POST("/me/application/submit", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				providerID, ok := myProviderID(c)
+				if !ok {
+					return
+				}
+				userID, _ := middleware.GetUserIDFromContext(c)
+				var body struct {
+					Comment string `json:"comment"` // e.g. what was changed since the last review
+				}
+				_ = c.ShouldBindJSON(&body)
+
+				app, err := onboarding.Submit(ctx, providerID, userID, strings.TrimSpace(body.Comment), time.Now())
+				if err != nil {
+					applicationError(c, err)
+					return
+				}
+
+				_ = events.LogEvent(ctx, "provider", providerID, events.EventApplicationSubmitted, map[string]interface{}{
+					"application_id": app.ID,
+				})
+
+				c.JSON(200, app)
+			})
+		}
+
+		// ==================== ORGANISATION ROUTES ====================
+		orgGroup := v1.Group("/organisations")
+		orgGroup.Use(middleware.AuthMiddleware(cfg.JwtSecret))
+		{
+			// myOrganisation resolves the organisation the caller works for
+			myOrganisation := func(c *gin.Context) (*models.Organisation, bool) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				org, err := providers.GetOrganisationForUser(ctx, userID)
+				if err == db.ErrNotFound {
+					c.JSON(404, gin.H{"error": "you don't belong to an organisation"})
+					return nil, false
+				}
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to load organisation"})
+					return nil, false
+				}
+				return org, true
+			}
+
+			// staffRole is the caller's role for an outlet of org, or
+			// organisation-wide when outletID is ""
+			staffRole := func(c *gin.Context, org *models.Organisation, outletID string) string {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				var role string
+				if outletID == "" {
+					role, _ = providers.OrganisationRole(ctx, userID, org.ID)
+				} else {
+					role, _ = providers.OutletRole(ctx, userID, outletID)
+				}
+				return role
+			}
+
+			// Create an organisation; the caller's existing outlets join it
+			orgGroup.POST("", middleware.RoleMiddleware("provider"), func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				var body struct {
+					Name string `json:"name" binding:"required"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				body.Name = strings.TrimSpace(body.Name)
+				if body.Name == "" || len(body.Name) > 200 {
+					c.JSON(400, gin.H{"error": "name must be 1-200 characters"})
+					return
+				}
+
+				org, err := providers.CreateOrganisation(ctx, userID, body.Name)
+				if err == providers.ErrOrganisationExists {
+					c.JSON(409, gin.H{"error": err.Error()})
+					return
+				}
+				if err != nil {
+					logger.Error("organisation creation failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "organisation creation failed"})
+					return
+				}
+
+				_ = events.LogEvent(ctx, "organisation", org.ID, events.EventOrganisationCreated, map[string]interface{}{
+					"owner_id": userID,
+					"name":     org.Name,
+					"outlets":  len(org.Outlets),
+				})
+
+				c.JSON(201, org)
+			})
+
+			// The caller's organisation, their role and the outlets they work at
+			orgGroup.GET("/me", func(c *gin.Context) {
+				org, ok := myOrganisation(c)
+				if !ok {
+					return
+				}
+				userID, _ := middleware.GetUserIDFromContext(c)
+				access, err := providers.AccessibleOutlets(ctx, userID)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to load outlets"})
+					return
+				}
+				c.JSON(200, gin.H{
+					"organisation": org,
+					"role":         staffRole(c, org, ""),
+					"access":       access,
+				})
+			})
+
+			// Owner: Add an outlet with its own location
+			orgGroup.POST("/me/outlets", func(c *gin.Context) {
+				org, ok := myOrganisation(c)
+				if !ok {
+					return
+				}
+				if staffRole(c, org, "") != models.StaffRoleOwner {
+					c.JSON(403, gin.H{"error": "only the owner can add outlets"})
+					return
+				}
+				var body struct {
+					BusinessName string   `json:"business_name" binding:"required"`
+					Address      string   `json:"address" binding:"required"`
+					Lat          float64  `json:"lat" binding:"required"`
+					Lng          float64  `json:"lng" binding:"required"`
+					Tags         []string `json:"tags"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				outlet, err := providers.AddOutlet(ctx, org, body.BusinessName, body.Address, body.Lat, body.Lng, body.Tags)
+				if err != nil {
+					logger.Error("outlet creation failed", zap.String("organisation_id", org.ID), zap.Error(err))
+					c.JSON(500, gin.H{"error": "outlet creation failed"})
+					return
+				}
+
+				userID, _ := middleware.GetUserIDFromContext(c)
+				_ = events.LogEvent(ctx, "provider", outlet.ID, events.EventOutletCreated, map[string]interface{}{
+					"organisation_id": org.ID,
+					"user_id":         userID,
+					"business_name":   body.BusinessName,
+				})
+
+				c.JSON(201, outlet)
+			})
+
+			// Owner/manager: List staff
+			orgGroup.GET("/me/staff", func(c *gin.Context) {
+				org, ok := myOrganisation(c)
+				if !ok {
+					return
+				}
+				userID, _ := middleware.GetUserIDFromContext(c)
+				access, err := providers.AccessibleOutlets(ctx, userID)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to load outlets"})
+					return
+				}
+				manages := providers.CanManageOutlet(staffRole(c, org, ""))
+				for _, a := range access {
+					if a.OrganisationID == org.ID && providers.CanManageOutlet(a.Role) {
+						manages = true
+					}
+				}
+				if !manages {
+					c.JSON(403, gin.H{"error": "only owners and managers can see staff"})
+					return
+				}
+
+				staff, err := providers.ListStaff(ctx, org.ID)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to list staff"})
+					return
+				}
+				c.JSON(200, gin.H{"staff": staff})
+			})
+
+			// Owner/manager: Give a registered user a role at one outlet, or
+			// at all of them when outlet_id is left out
+			orgGroup.POST("/me/staff", func(c *gin.Context) {
+				org, ok := myOrganisation(c)
+				if !ok {
+					return
+				}
+				var body struct {
+					Phone    string `json:"phone" binding:"required"`
+					Role     string `json:"role" binding:"required"`
+					OutletID string `json:"outlet_id"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				if !providers.CanAssign(models.StaffRoleOwner, body.Role) {
+					c.JSON(400, gin.H{"error": "role must be manager or kitchen_staff"})
+					return
+				}
+				if !providers.CanAssign(staffRole(c, org, body.OutletID), body.Role) {
+					c.JSON(403, gin.H{"error": "you can't assign this role here"})
+					return
+				}
+
+				member, promoted, err := providers.AddStaff(ctx, org.ID, strings.TrimSpace(body.Phone), body.OutletID, body.Role)
+				switch err {
+				case nil:
+				case providers.ErrNotRegistered:
+					c.JSON(404, gin.H{"error": err.Error()})
+					return
+				case providers.ErrNotOutlet:
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				case providers.ErrStaffExists:
+					c.JSON(409, gin.H{"error": err.Error()})
+					return
+				default:
+					logger.Error("adding staff failed", zap.String("organisation_id", org.ID), zap.Error(err))
+					c.JSON(500, gin.H{"error": "adding staff failed"})
+					return
+				}
+				// Let the new provider into the provider app without signing in again
+				if promoted {
+					if err := middleware.SetRole(ctx, member.UserID, models.RoleProvider); err != nil {
+						logger.Warn("failed to refresh staff role", zap.String("user_id", member.UserID), zap.Error(err))
+					}
+				}
+
+				userID, _ := middleware.GetUserIDFromContext(c)
+				_ = events.LogEvent(ctx, "organisation", org.ID, events.EventStaffAdded, map[string]interface{}{
+					"staff_id":  member.ID,
+					"user_id":   member.UserID,
+					"role":      member.Role,
+					"outlet_id": member.ProviderID,
+					"added_by":  userID,
+				})
+
+				c.JSON(201, member)
+			})
+
+			// Owner/manager: Remove a staff member's role
+			orgGroup.DELETE("/me/staff/:staffId", func(c *gin.Context) {
+				org, ok := myOrganisation(c)
+				if !ok {
+					return
+				}
+				member, err := providers.GetStaff(ctx, org.ID, c.Param("staffId"))
+				if err == db.ErrNotFound {
+					c.JSON(404, gin.H{"error": "staff member not found"})
+					return
+				}
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to load staff member"})
+					return
+				}
+				if !providers.CanAssign(staffRole(c, org, member.ProviderID), member.Role) {
+					c.JSON(403, gin.H{"error": "you can't remove this staff member"})
+					return
+				}
+
+				if err := providers.RemoveStaff(ctx, org.ID, member.ID); err != nil {
+					c.JSON(500, gin.H{"error": "removing staff failed"})
+					return
+				}
+
+				userID, _ := middleware.GetUserIDFromContext(c)
+				_ = events.LogEvent(ctx, "organisation", org.ID, events.EventStaffRemoved, map[string]interface{}{
+					"staff_id":   member.ID,
+					"user_id":    member.UserID,
+					"role":       member.Role,
+					"outlet_id":  member.ProviderID,
+					"removed_by": userID,
+				})
+
+				c.JSON(200, gin.H{"message": "staff member removed"})
+			})
+		}
+
+		// ==================== MENU ROUTES ====================
+		menuGroup := v1.Group("/menus")
+		menuGroup.Use(middleware.AuditAdminMiddleware())
+		{
+			// Public: Get menu by ID
+			menuGroup.GET("/:id", func(c *gin.Context) {
+				id := c.Param("id")
+				menu, err := menus.GetMenu(ctx, id)
+				if err != nil {
+					c.JSON(404, gin.H{"error": "menu not found"})
+					return
+				}
+				c.JSON(200, menu)
+			})
+
+			// Public: Get menus by provider
+			menuGroup.GET("/provider/:provider_id", func(c *gin.Context) {
+				providerID := c.Param("provider_id")
+				list, err := menus.GetMenusByProvider(ctx, providerID)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to get menus"})
+					return
+				}
+				c.JSON(200, list)
+			})
+
+			// Protected: Create menu
+			menuGroup.POST("", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
+				var body struct {
+					ProviderID  string `json:"provider_id" binding:"required"`
+					Name        string `json:"name" binding:"required"`
+					Description string `json:"description"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				menu, err := menus.CreateMenu(ctx, body.ProviderID, body.Name, body.Description)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "menu creation failed"})
+					return
+				}
+
+				_ = events.LogEvent(ctx, "menu", menu.ID, events.EventMenuCreated, map[string]interface{}{
+					"provider_id": body.ProviderID,
+					"name":        body.Name,
+				})
+
+				c.JSON(201, menu)
+			})
+
+			// Protected: Update menu
+			menuGroup.PUT("/:id", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
+				menuID := c.Param("id")
+				var body struct {
+					Name        string `json:"name"`
+					Description string `json:"description"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				if err := menus.UpdateMenu(ctx, menuID, body.Name, body.Description); err != nil {
+					c.JSON(500, gin.H{"error": "update failed"})
+					return
+				}
+				c.JSON(200, gin.H{"message": "updated"})
+			})
+
+			// Protected: Delete menu
+			menuGroup.DELETE("/:id", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
+				menuID := c.Param("id")
+				if err := menus.DeleteMenu(ctx, menuID); err != nil {
+					c.JSON(500, gin.H{"error": "deletion failed"})
+					return
+				}
+				c.JSON(200, gin.H{"message": "deleted"})
+			})
+		}
+
+		// ==================== MENU ITEM ROUTES ====================
+		itemGroup := v1.Group("/menu-items")
+		itemGroup.Use(middleware.AuditAdminMiddleware())
+		{
+			// Public: Get item by ID
+			itemGroup.GET("/:id", func(c *gin.Context) {
+				id := c.Param("id")
+				item, err := menus.GetMenuItem(ctx, id)
+				if err != nil {
+					c.JSON(404, gin.H{"error": "item not found"})
+					return
+				}
+				c.JSON(200, item)
+			})
+
+			// Public: Get items by menu
+			itemGroup.GET("/menu/:menu_id", func(c *gin.Context) {
+				menuID := c.Param("menu_id")
+				var tags []string
+				if t := c.Query("tags"); t != "" {
+					tags = strings.Split(t, ",")
+				}
+				list, err := menus.GetMenuItems(ctx, menuID, tags...)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to get items"})
+					return
+				}
+				c.JSON(200, list)
+			})
+
+			// Protected: Create item
+			itemGroup.POST("", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
+				var body struct {
+					MenuID       string   `json:"menu_id" binding:"required"`
+					Name         string   `json:"name" binding:"required"`
+					Price        float64  `json:"price" binding:"required"`
+					Ingredients  []string `json:"ingredients"`
+					IsJain       bool     `json:"is_jain"`
+					Availability bool     `json:"availability"`
+					ImageURL     string   `json:"image_url"`
+					DietaryTags  []string `json:"dietary_tags"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				if !validItemTags(body.DietaryTags) {
+					c.JSON(400, gin.H{"error": "dietary_tags must be tithi-safe or ayambil"})
+					return
+				}
+
+				verdict := jainrules.Check(body.Name, body.Ingredients)
+				if body.IsJain && !verdict.Compliant && cfg.JainRulesMode != "flag" {
+					c.JSON(422, gin.H{"error": "item is marked Jain but contains forbidden ingredients", "verdict": verdict})
+					return
+				}
+
+				item, err := menus.CreateMenuItem(ctx, body.MenuID, body.Name, body.Price, body.Ingredients, body.IsJain, body.Availability, body.ImageURL, verdict, body.DietaryTags)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "item creation failed"})
+					return
+				}
+
+				_ = events.LogEvent(ctx, "menu_item", item.ID, events.EventItemCreated, map[string]interface{}{
+					"menu_id":     body.MenuID,
+					"name":        body.Name,
+					"is_jain":     body.IsJain,
+					"jain_status": verdict.Status,
+				})
+				if body.IsJain && !verdict.Compliant {
+					_ = events.LogEvent(ctx, "menu_item", item.ID, events.EventItemJainFlagged, map[string]interface{}{
+						"reasons": verdict.Reasons(),
+						"version": verdict.Version,
+					})
+				}
+
+				c.JSON(201, item)
+			})
+
+			// Protected: Update item
+			itemGroup.PUT("/:id", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
+				itemID := c.Param("id")
+				var body struct {
+					Name         string   `json:"name"`
+					Price        float64  `json:"price"`
+					Ingredients  []string `json:"ingredients"`
+					IsJain       bool     `json:"is_jain"`
+					Availability bool     `json:"availability"`
+					ImageURL     string   `json:"image_url"`
+					DietaryTags  []string `json:"dietary_tags"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				if !validItemTags(body.DietaryTags) {
+					c.JSON(400, gin.H{"error": "dietary_tags must be tithi-safe or ayambil"})
+					return
+				}
+
+				verdict := jainrules.Check(body.Name, body.Ingredients)
+				if body.IsJain && !verdict.Compliant && cfg.JainRulesMode != "flag" {
+					c.JSON(422, gin.H{"error": "item is marked Jain but contains forbidden ingredients", "verdict": verdict})
+					return
+				}
+
+				if err := menus.UpdateMenuItem(ctx, itemID, body.Name, body.Price, body.Ingredients, body.IsJain, body.Availability, body.ImageURL, verdict, body.DietaryTags); err != nil {
+					c.JSON(500, gin.H{"error": "update failed"})
+					return
+				}
+				if body.IsJain && !verdict.Compliant {
+					_ = events.LogEvent(ctx, "menu_item", itemID, events.EventItemJainFlagged, map[string]interface{}{
+						"reasons": verdict.Reasons(),
+						"version": verdict.Version,
+					})
+				}
+				c.JSON(200, gin.H{"message": "updated", "jain_verdict": verdict})
+			})
+
+			// Protected: Toggle availability (real-time)
+			itemGroup.PATCH("/:id/availability", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
+				itemID := c.Param("id")
+				var body struct {
+					Available bool `json:"available"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				if err := menus.ToggleAvailability(ctx, itemID, body.Available); err != nil {
+					c.JSON(500, gin.H{"error": "toggle failed"})
+					return
+				}
+				c.JSON(200, gin.H{"message": "availability updated"})
+			})
+
+			// Protected: Tag an item as tithi-safe / ayambil
+			itemGroup.PUT("/:id/dietary-tags", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
+				itemID := c.Param("id")
+				var body struct {
+					Tags []string `json:"tags"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				if !validItemTags(body.Tags) {
+					c.JSON(400, gin.H{"error": "tags must be tithi-safe or ayambil"})
+					return
+				}
+
+				if err := menus.SetDietaryTags(ctx, itemID, body.Tags); err != nil {
+					c.JSON(404, gin.H{"error": "item not found"})
+					return
+				}
+				c.JSON(200, gin.H{"message": "dietary tags updated"})
+			})
+
+			// Protected: Delete item
+			itemGroup.DELETE("/:id", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
+				itemID := c.Param("id")
+				if err := menus.DeleteMenuItem(ctx, itemID); err != nil {
+					c.JSON(500, gin.H{"error": "deletion failed"})
+					return
+				}
+				c.JSON(200, gin.H{"message": "deleted"})
+			})
+		}
+
+		// ==================== JAIN RULES ROUTES ====================
+		jainGroup := v1.Group("/jain-rules")
+		{
+			// Public: Ingredient taxonomy used for compliance checks
+			jainGroup.GET("", func(c *gin.Context) {
+				c.JSON(200, gin.H{
+					"version":     jainrules.TaxonomyVersion,
+					"ingredients": jainrules.Taxonomy(),
+				})
+			})
+
+			// Public: Check a dish before saving it
+			jainGroup.POST("/check", func(c *gin.Context) {
+				var body struct {
+					Name        string   `json:"name"`
+					Ingredients []string `json:"ingredients"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				c.JSON(200, jainrules.Check(body.Name, body.Ingredients))
+			})
+		}
+
+		// ==================== JAIN CALENDAR ROUTES ====================
+		calendarGroup := v1.Group("/jain-calendar")
+		{
+			// Public: Observance calendar for a year at a location
+			calendarGroup.GET("", func(c *gin.Context) {
+				lat, _ := strconv.ParseFloat(c.Query("lat"), 64)
+				lng, _ := strconv.ParseFloat(c.Query("lng"), 64)
+				place := placeFor(lat, lng)
+				year, err := strconv.Atoi(c.DefaultQuery("year", strconv.Itoa(time.Now().In(place.Location).Year())))
+				if err != nil || year < 1900 || year > 2100 {
+					c.JSON(400, gin.H{"error": "invalid year"})
+					return
+				}
+
+				days, err := jaincalendar.Year(year, place)
+				if err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				// Optionally only days carrying an observance
+				if observance := c.Query("observance"); observance != "" {
+					filtered := []jaincalendar.Day{}
+					for _, d := range days {
+						if d.Has(observance) {
+							filtered = append(filtered, d)
+						}
+					}
+					days = filtered
+				}
+				c.JSON(200, gin.H{"year": year, "days": days})
+			})
+
+			// Public: Today's tithi, observances and chovihar window
+			calendarGroup.GET("/today", func(c *gin.Context) {
+				lat, _ := strconv.ParseFloat(c.Query("lat"), 64)
+				lng, _ := strconv.ParseFloat(c.Query("lng"), 64)
+				place := placeFor(lat, lng)
+				now := time.Now()
+
+				day, err := jaincalendar.On(now, place)
+				if err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				window, err := jaincalendar.NextChovihar(now, place, choviharRules)
+				if err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				itemTags := []string{}
+				if day.AvoidsGreenVegetables() {
+					itemTags = append(itemTags, models.ItemTagTithiSafe)
+				}
+				if day.Has(jaincalendar.ObservanceAyambilOli) {
+					itemTags = append(itemTags, models.ItemTagAyambil)
+				}
+				c.JSON(200, gin.H{"day": day, "chovihar": window, "suggested_item_tags": itemTags})
+			})
+
+			// Public: Chovihar ordering window for a delivery location
+			calendarGroup.GET("/chovihar", func(c *gin.Context) {
+				lat, _ := strconv.ParseFloat(c.Query("lat"), 64)
+				lng, _ := strconv.ParseFloat(c.Query("lng"), 64)
+				window, err := jaincalendar.NextChovihar(time.Now(), placeFor(lat, lng), choviharRules)
+				if err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				c.JSON(200, window)
+			})
+		}
+
+		// ==================== PIN CODE ROUTES ====================
+		// Public: City, district, state and centroid for a pin code (address autofill)
+		v1.GET("/pincodes/:pin", func(c *gin.Context) {
+			pin := c.Param("pin")
+			if !places.ValidPin(pin) {
+				c.JSON(400, gin.H{"error": "pin must be 6 digits"})
+				return
+			}
+			pc, err := places.LookupPin(ctx, pin)
+			if err != nil {
+				c.JSON(404, gin.H{"error": "pin code not found"})
+				return
+			}
+			c.JSON(200, pc)
+		})
+
+		// ==================== CITY ROUTES ====================
+		cityGroup := v1.Group("/cities")
+		{
+			// Public: City directory with provider counts by category (?state=)
+			cityGroup.GET("", func(c *gin.Context) {
+				list, err := places.ListCities(ctx, c.Query("state"))
+				if err != nil {
+					logger.Error("failed to list cities", zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to list cities"})
+					return
+				}
+				c.JSON(200, list)
+			})
+
+			// Public: A city's verified providers grouped by district, with its
+			// promoted slots (?provider_category=)
+			cityGroup.GET("/:slug/providers", func(c *gin.Context) {
+				dir, err := places.CityProviders(ctx, c.Param("slug"), c.Query("provider_category"))
+				if err == db.ErrNotFound {
+					c.JSON(404, gin.H{"error": "city not found"})
+					return
+				}
+				if err == nil && cfg.PromotionFeedSlots > 0 {
+					dir.Promoted, err = places.PromotedInCity(ctx, c.Param("slug"), c.Query("provider_category"),
+						promotions.Today(time.Now(), analyticsLoc), cfg.PromotionFeedSlots)
+				}
+				if err != nil {
+					logger.Error("failed to list city providers", zap.String("city", c.Param("slug")), zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to list providers"})
+					return
+				}
+				var picked []string
+				for _, cp := range dir.Promoted {
+					picked = append(picked, cp.PromotionID)
+				}
+				served := servePromotions(picked)
+				shown := dir.Promoted[:0]
+				for _, cp := range dir.Promoted {
+					if served[cp.PromotionID] {
+						shown = append(shown, cp)
+					}
+				}
+				dir.Promoted = shown
+				c.JSON(200, dir)
+			})
+		}
+
+		// ==================== PROMOTION ROUTES ====================
+		promotionGroup := v1.Group("/promotions")
+		{
+			// Public: Count a click on a promoted slot
+			promotionGroup.POST("/:id/click", middleware.EndpointRateLimiter(30, time.Minute), func(c *gin.Context) {
+				err := promotions.RecordClick(ctx, promotions.Today(time.Now(), analyticsLoc), c.Param("id"))
+				if err == db.ErrNotFound {
+					c.JSON(404, gin.H{"error": "promotion not running"})
+					return
+				}
+				if err != nil {
+					logger.Error("failed to record promotion click", zap.String("promotion_id", c.Param("id")), zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to record click"})
+					return
+				}
+				c.Status(204)
+			})
+		}
+
+		// ==================== TRIP ROUTES ====================
+		// Public: Plan a multi-stop trip; returns providers open at each stop
+		// and along the way on the travel dates
+		v1.POST("/trips/plan", func(c *gin.Context) {
+			var body struct {
+				Stops            []travel.Stop `json:"stops" binding:"required"`
+				ProviderCategory string        `json:"provider_category"`
+			}
+			if err := c.ShouldBindJSON(&body); err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			now := time.Now()
+			if err := travel.Validate(body.Stops, now.In(hours.LoadLocation(hours.DefaultTimezone)).Format(time.DateOnly)); err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+
+			plan, err := travel.PlanTrip(ctx, body.Stops, body.ProviderCategory, now)
+			if err == db.ErrNotFound {
+				c.JSON(400, gin.H{"error": "unknown city; use a slug from /v1/cities"})
+				return
+			}
+			if err != nil {
+				logger.Error("trip planning failed", zap.Error(err))
+				c.JSON(500, gin.H{"error": "trip planning failed"})
+				return
+			}
+			c.JSON(200, plan)
+		})
+
+		// ==================== SEARCH ROUTES ====================
+		searchGroup := v1.Group("/search")
+		{
+			// Search nearby providers, optionally by name or category (q)
+			searchGroup.GET("/providers", func(c *gin.Context) {
+				lat, lng, err := searchLocation(c)
+				if err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				radius, _ := strconv.ParseFloat(c.DefaultQuery("radius", "5000"), 64) // Default 5km
+				query := c.Query("q")
+				minRating, _ := strconv.ParseFloat(c.DefaultQuery("min_rating", "0"), 64)
+				limit := searchLimit(c)
+
+				tags := []string{}
+				if t := c.Query("tags"); t != "" {
+					tags = strings.Split(t, ",")
+				}
+
+				sort := c.DefaultQuery("sort", search.SortRelevance)
+				if !search.ValidSort(sort, false) {
+					c.JSON(400, gin.H{"error": "sort must be relevance, distance or rating"})
+					return
+				}
+				explainRank, _ := strconv.ParseBool(c.Query("explain_rank"))
+				at, ok := deliverAt(c)
+				if !ok {
+					c.JSON(400, gin.H{"error": "deliver_at must be an RFC 3339 time, e.g. 2025-03-07T19:30:00+05:30"})
+					return
+				}
+
+				filters := search.SearchFilters{
+					Lat:              lat,
+					Lng:              lng,
+					RadiusMeters:     radius,
+					Query:            query,
+					Tags:             tags,
+					MinRating:        minRating,
+					ProviderCategory: c.Query("provider_category"),
+					FoodCategory:     c.Query("food_category"),
+					DeliverAt:        at,
+					Sort:             sort,
+					Weights:          &rankWeights,
+					ExplainRank:      explainRank,
+					PromotedSlots:    cfg.PromotionFeedSlots,
+					PromotionDay:     promotions.Today(time.Now(), analyticsLoc),
+				}
+
+				results, err := search.SearchNearbyProviders(ctx, filters, limit, c.Query("cursor"))
+				if err == search.ErrInvalidCursor {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				if err != nil {
+					logger.Error("provider search failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "search failed"})
+					return
+				}
+				var picked []string
+				for _, r := range results.Promoted {
+					picked = append(picked, r.PromotionID)
+				}
+				served := servePromotions(picked)
+				shown := results.Promoted[:0]
+				for _, r := range results.Promoted {
+					if served[r.PromotionID] {
+						shown = append(shown, r)
+					}
+				}
+				results.Promoted = shown
+				c.JSON(200, results)
+			})
+
+			// Search menu items. Signed-in users get their dietary profile
+			// applied unless profile=off; explain=true lists excluded items.
+			searchGroup.GET("/items", middleware.OptionalAuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				lat, lng, err := searchLocation(c)
+				if err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				radius, _ := strconv.ParseFloat(c.DefaultQuery("radius", "5000"), 64)
+				query := c.Query("q")
+				jainOnly, _ := strconv.ParseBool(c.DefaultQuery("jain_only", "true"))
+				availableOnly, _ := strconv.ParseBool(c.DefaultQuery("available_only", "true"))
+				minRating, _ := strconv.ParseFloat(c.DefaultQuery("min_rating", "0"), 64)
+				priceMin, _ := strconv.ParseFloat(c.DefaultQuery("price_min", "0"), 64)
+				priceMax, _ := strconv.ParseFloat(c.DefaultQuery("price_max", "0"), 64)
+				limit := searchLimit(c)
+
+				tags := []string{}
+				if t := c.Query("tags"); t != "" {
+					tags = strings.Split(t, ",")
+				}
+
+				sort := c.DefaultQuery("sort", search.SortRelevance)
+				if !search.ValidSort(sort, true) {
+					c.JSON(400, gin.H{"error": "sort must be relevance, distance, rating or price"})
+					return
+				}
+				explainRank, _ := strconv.ParseBool(c.Query("explain_rank"))
+				at, ok := deliverAt(c)
+				if !ok {
+					c.JSON(400, gin.H{"error": "deliver_at must be an RFC 3339 time, e.g. 2025-03-07T19:30:00+05:30"})
+					return
+				}
+
+				// Observance filters: explicit, or observance=auto to apply
+				// today's tithi at the search location
+				itemTags := []string{}
+				if v, _ := strconv.ParseBool(c.Query("tithi_safe")); v {
+					itemTags = append(itemTags, models.ItemTagTithiSafe)
+				}
+				if v, _ := strconv.ParseBool(c.Query("ayambil")); v {
+					itemTags = append(itemTags, models.ItemTagAyambil)
+				}
+				// Dietary profile: strictness and exclusions, overridable per request
+				strictness := ""
+				var exclusions []dietary.Exclusion
+				observance := c.Query("observance")
+				if userID, ok := middleware.GetUserIDFromContext(c); ok && c.Query("profile") != "off" {
+					if profile, err := users.GetDietaryProfile(ctx, userID); err == nil {
+						strictness = profile.Strictness
+						exclusions = dietary.Exclusions(profile)
+						if profile.ObserveTithi && observance == "" {
+							observance = "auto"
+						}
+					}
+				}
+				if s := c.Query("strictness"); s != "" {
+					if s != models.StrictnessRelaxed && s != models.StrictnessStandard && s != models.StrictnessStrict {
+						c.JSON(400, gin.H{"error": "strictness must be relaxed, standard or strict"})
+						return
+					}
+					strictness = s
+				}
+				if e := c.Query("exclude"); e != "" {
+					for _, entry := range strings.Split(e, ",") {
+						exclusions = append(exclusions, dietary.Expand(entry)...)
+					}
+				}
+
+				if observance == "auto" {
+					day, err := jaincalendar.On(time.Now(), placeFor(lat, lng))
+					if err == nil && len(day.Observances) > 0 {
+						c.Header("X-Jain-Observances", strings.Join(day.Observances, ","))
+						if day.AvoidsGreenVegetables() && len(itemTags) == 0 {
+							itemTags = append(itemTags, models.ItemTagTithiSafe)
+						}
+					}
+				}
+
+				filters := search.SearchFilters{
+					Lat:              lat,
+					Lng:              lng,
+					RadiusMeters:     radius,
+					Query:            query,
+					ItemTags:         itemTags,
+					JainOnly:         jainOnly,
+					AvailableOnly:    availableOnly,
+					Tags:             tags,
+					MinRating:        minRating,
+					PriceMin:         priceMin,
+					PriceMax:         priceMax,
+					ProviderCategory: c.Query("provider_category"),
+					FoodCategory:     c.Query("food_category"),
+					DeliverAt:        at,
+					Strictness:       strictness,
+					Exclusions:       exclusions,
+					Sort:             sort,
+					Weights:          &rankWeights,
+					ExplainRank:      explainRank,
+				}
+
+				results, err := search.SearchMenuItems(ctx, filters, limit, c.Query("cursor"))
+				if err == search.ErrInvalidCursor {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				if err != nil {
+					logger.Error("item search failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "search failed"})
+					return
+				}
+
+				if explain, _ := strconv.ParseBool(c.Query("explain")); explain {
+					excluded, err := search.SearchExcludedItems(ctx, filters, limit)
+					if err != nil {
+						logger.Error("excluded item search failed", zap.Error(err))
+						c.JSON(500, gin.H{"error": "search failed"})
+						return
+					}
+					c.JSON(200, gin.H{
+						"results":     results.Results,
+						"total":       results.Total,
+						"facets":      results.Facets,
+						"next_cursor": results.NextCursor,
+						"excluded":    excluded,
+						"strictness":  strictness,
+						"exclusions":  exclusions,
+					})
+					return
+				}
+				c.JSON(200, results)
+			})
+
+			// Search items excluding ingredients, e.g. exclude=onion,garlic,dairy
+			searchGroup.GET("/by-ingredients", func(c *gin.Context) {
+				lat, lng, err := searchLocation(c)
+				if err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				radius, _ := strconv.ParseFloat(c.DefaultQuery("radius", "5000"), 64)
+				limit := searchLimit(c)
+
+				exclude := []string{}
+				if e := c.Query("exclude"); e != "" {
+					exclude = strings.Split(e, ",")
+				}
+				at, ok := deliverAt(c)
+				if !ok {
+					c.JSON(400, gin.H{"error": "deliver_at must be an RFC 3339 time, e.g. 2025-03-07T19:30:00+05:30"})
+					return
+				}
+
+				filters := search.SearchFilters{
+					Lat:          lat,
+					Lng:          lng,
+					RadiusMeters: radius,
+					Query:        c.Query("q"),
+					DeliverAt:    at,
+					Weights:      &rankWeights,
+				}
+				results, err := search.SearchByIngredients(ctx, filters, exclude, limit, c.Query("cursor"))
+				if err == search.ErrInvalidCursor {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				if err != nil {
+					logger.Error("ingredient search failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "search failed"})
+					return
+				}
+				c.JSON(200, results)
+			})
+
+			// Typeahead suggestions while the user types. Kept on a tight
+			// deadline: a slow lookup returns nothing rather than stalling input.
+			searchGroup.GET("/suggest", func(c *gin.Context) {
+				q := strings.TrimSpace(c.Query("q"))
+				lat, _ := strconv.ParseFloat(c.Query("lat"), 64)
+				lng, _ := strconv.ParseFloat(c.Query("lng"), 64)
+				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "8"))
+				if limit <= 0 || limit > 20 {
+					limit = 8
+				}
+
+				suggestCtx, cancel := context.WithTimeout(c.Request.Context(), 200*time.Millisecond)
+				defer cancel()
+				results, hit, err := search.CachedSuggest(suggestCtx, q, lat, lng, limit, time.Duration(cfg.SearchSuggestCacheTTL)*time.Second)
+				if err != nil {
+					logger.Warn("suggest failed", zap.String("q", q), zap.Error(err))
+					monitoring.GetMetrics().IncrCustom("search_suggest_errors")
+					results = []*search.Suggestion{}
+				}
+				if hit {
+					c.Header("X-Cache", "HIT")
+					monitoring.GetMetrics().IncrCustom("search_suggest_cache_hits")
+				} else {
+					c.Header("X-Cache", "MISS")
+				}
+				c.JSON(200, gin.H{"query": q, "suggestions": results})
+			})
+		}
+
+		// ==================== ORDER ROUTES ====================
+		orderGroup := v1.Group("/orders")
+		orderGroup.Use(middleware.AuthMiddleware(cfg.JwtSecret), middleware.AuditAdminMiddleware())
+		{
+			// Create order
+			orderGroup.POST("", func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				var body struct {
+					ProviderID string      `json:"provider_id" binding:"required"`
+					Items      interface{} `json:"items" binding:"required"`
+					Total      float64     `json:"total" binding:"required"`
+					Chovihar   bool        `json:"chovihar"`   // Must arrive before sunset at the delivery location
+					DeliverAt  *time.Time  `json:"deliver_at"` // Requested delivery time, defaults to now
+					AddressID  string      `json:"address_id"` // Saved delivery address
+					Lat        float64     `json:"lat"`        // Or a raw delivery location; with neither the order is a pickup
+					Lng        float64     `json:"lng"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				provider, err := providers.GetProvider(ctx, body.ProviderID)
+				if err != nil {
+					c.JSON(404, gin.H{"error": "provider not found"})
+					return
+				}
+				if provider.Blocked {
+					c.JSON(403, gin.H{"error": "provider_blocked", "message": "This provider is not accepting orders."})
+					return
+				}
+
+				// The address is snapshotted onto the order
+				var address *models.Address
+				if body.AddressID != "" {
+					a, err := users.GetAddress(ctx, piiKeys, userID, body.AddressID)
+					if err != nil {
+						c.JSON(404, gin.H{"error": "address not found"})
+						return
+					}
+					address = a
+					body.Lat, body.Lng = a.Lat, a.Lng
+				} else if body.Lat != 0 || body.Lng != 0 {
+					address = &models.Address{Lat: body.Lat, Lng: body.Lng}
+				}
+
+				// Chovihar orders must be placed before the cutoff and
+				// delivered between navkarsi and sunset
+				var choviharWindow *jaincalendar.ChoviharWindow
+				if body.Chovihar {
+					lat, lng := body.Lat, body.Lng
+					if lat == 0 && lng == 0 {
+						lat, lng = provider.Lat, provider.Lng
+					}
+					now := time.Now()
+					deliverAt := now
+					if body.DeliverAt != nil {
+						deliverAt = *body.DeliverAt
+					}
+					window, err := jaincalendar.Chovihar(deliverAt, placeFor(lat, lng), choviharRules)
+					if err != nil {
+						c.JSON(400, gin.H{"error": err.Error()})
+						return
+					}
+					if !window.Open(now) || (body.DeliverAt != nil && !window.Allows(deliverAt)) {
+						next, _ := jaincalendar.NextChovihar(now, placeFor(lat, lng), choviharRules)
+						c.JSON(422, gin.H{"error": "outside the chovihar ordering window", "chovihar": next})
+						return
+					}
+					choviharWindow = &window
+				}
+
+				// Delivery fee from the provider's zone, slabs and free-delivery rules
+				var quote *models.DeliveryQuote
+				if body.Lat != 0 || body.Lng != 0 {
+					q, err := providers.QuoteDelivery(ctx, body.ProviderID, body.Lat, body.Lng, body.Total)
+					if err == db.ErrNotFound {
+						c.JSON(404, gin.H{"error": "provider not found"})
+						return
+					}
+					if err == providers.ErrInvalidLocation {
+						c.JSON(422, gin.H{"error": err.Error()})
+						return
+					}
+					if err != nil {
+						logger.Error("delivery quote failed", zap.String("provider_id", body.ProviderID), zap.Error(err))
+						c.JSON(500, gin.H{"error": "failed to quote delivery"})
+						return
+					}
+					if !q.Deliverable {
+						c.JSON(422, gin.H{"error": "provider does not deliver to this location", "delivery": q})
+						return
+					}
+					quote = q
+				}
+
+				orderID, orderCode, err := orders.CreateOrder(ctx, piiKeys, userID, body.ProviderID, body.Items, body.Total, quote, address)
+				if err != nil {
+					logger.Error("order creation failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "order creation failed"})
+					return
+				}
+
+				// Generate order confirmation OTP
+				otp, err := auth.GenerateOTP()
+				if err != nil {
+					c.JSON(500, gin.H{"error": "otp generation failed"})
+					return
+				}
+				hash := auth.HashOTP(cfg.OtpSecret, otp)
+				otpKey := "order-otp:" + orderID
+				if err := auth.StoreOTP(ctx, otpKey, hash, 30*time.Minute); err != nil {
+					c.JSON(500, gin.H{"error": "failed to store otp"})
+					return
+				}
+
+				// Log event
+				orderEvent := map[string]interface{}{
+					"buyer_id":    userID,
+					"provider_id": body.ProviderID,
+					"total":       body.Total,
+					"order_code":  orderCode,
+				}
+				if quote != nil {
+					orderEvent["delivery_fee"] = quote.Fee
+				}
+				if choviharWindow != nil {
+					orderEvent["chovihar_last_delivery"] = choviharWindow.LastDelivery
+				}
+				_ = events.LogEvent(ctx, "order", orderID, events.EventOrderCreated, orderEvent)
+
+				// Send OTP notification for order confirmation to the buyer's phone number
+				buyer, err := users.GetUserByID(ctx, userID)
+				if err != nil {
+					logger.Warn("failed to load buyer for order OTP notification",
+						zap.Error(err),
+						zap.String("order_id", orderID),
+						zap.String("buyer_id", userID))
+				} else if buyer.Phone == "" {
+					logger.Warn("buyer phone missing for order OTP notification",
+						zap.String("order_id", orderID),
+						zap.String("buyer_id", userID))
+				} else if err := notify.EnqueueOTP(ctx, notifyQueue, buyer.Phone, otp, "order", "", 30*time.Minute); err != nil {
+					// Fall back to sending inline, as login OTPs do
+					logger.Warn("failed to queue order OTP notification, sending inline",
+						zap.Error(err),
+						zap.String("order_id", orderID),
+						zap.String("buyer_id", userID))
+					if err := notifier.SendOTP(c.Request.Context(), buyer.Phone, otp); err != nil {
+						logger.Warn("order OTP notification failed",
+							zap.Error(err),
+							zap.String("order_id", orderID),
+							zap.String("buyer_id", userID))
+					}
+				}
+
+				// Push the new order to the provider's devices
+				go func() {
+					provider, err := providers.GetProvider(ctx, body.ProviderID)
+					if err != nil {
+						return
+					}
+					buyerName := "A customer"
+					if buyer != nil && buyer.Name != "" {
+						buyerName = buyer.Name
+					}
+					if err := pushHelper.NotifyNewOrder(ctx, provider.UserID, orderID, buyerName, body.Total); err != nil {
+						logger.Warn("new order push failed", zap.Error(err), zap.String("order_id", orderID))
+					}
+				}()
+
+				resp := gin.H{
+					"order_id":     orderID,
+					"order_code":   orderCode,
+					"delivery_fee": 0.0,
+					"total":        body.Total,
+				}
+				if quote != nil {
+					resp["delivery_fee"] = quote.Fee
+					resp["total"] = body.Total + quote.Fee
+					resp["free_delivery"] = quote.FreeDelivery
+				}
+
+				// In development/local environments, optionally return OTP in response
+				if cfg.ShouldReturnOTPInResponse() {
+					resp["otp"] = otp
+				}
+
+				c.JSON(201, resp)
+			})
+
+			// Order inbox for the outlets the caller works at, optionally one
+			// outlet (?outlet_id=) and one status (?status=)
+			orderGroup.GET("/inbox", func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				access, err := providers.AccessibleOutlets(ctx, userID)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to load outlets"})
+					return
+				}
+
+				outletID := c.Query("outlet_id")
+				var outletIDs []string
+				for _, a := range access {
+					if providers.CanHandleOrders(a.Role) && (outletID == "" || a.ProviderID == outletID) {
+						outletIDs = append(outletIDs, a.ProviderID)
+					}
+				}
+				if outletID != "" && len(outletIDs) == 0 {
+					c.JSON(403, gin.H{"error": "you don't work at this outlet"})
+					return
+				}
+				if len(outletIDs) == 0 {
+					c.JSON(200, gin.H{"orders": []*models.Order{}, "outlets": access})
+					return
+				}
+
+				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+				if limit <= 0 || limit > 100 {
+					limit = 50
+				}
+				if offset < 0 {
+					offset = 0
+				}
+
+				list, err := orders.GetOrdersByProviders(ctx, piiKeys, outletIDs, strings.ToUpper(c.Query("status")), limit, offset)
+				if err != nil {
+					logger.Error("order inbox failed", zap.String("user_id", userID), zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to load orders"})
+					return
+				}
+				c.JSON(200, gin.H{"orders": list, "outlets": access})
+			})
+
+			// Get order by code
+			orderGroup.GET("/code/:code", func(c *gin.Context) {
+				code := c.Param("code")
+				orderID, err := orders.GetOrderByCode(ctx, code)
+				if err != nil {
+					c.JSON(404, gin.H{"error": "order not found"})
+					return
+				}
+				c.JSON(200, gin.H{"order_id": orderID, "order_code": code})
+			})
+
+			// Confirm order with OTP
+			orderGroup.POST("/:id/confirm-otp", func(c *gin.Context) {
+				orderID := c.Param("id")
+				var body struct {
+					OTP string `json:"otp" binding:"required"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				key := "order-otp:" + orderID
+				stored, err := auth.GetOTP(ctx, key)
+				if err != nil {
+					c.JSON(400, gin.H{"error": "otp expired or not found"})
+					return
+				}
+
+				if stored != auth.HashOTP(cfg.OtpSecret, body.OTP) {
+					c.JSON(401, gin.H{"error": "invalid otp"})
+					return
+				}
+
+				if err := orders.ConfirmOrder(ctx, orderID); err != nil {
+					c.JSON(500, gin.H{"error": "order confirmation failed"})
+					return
+				}
+
+				_ = auth.DeleteOTP(ctx, key)
+
+				// Log event
+				_ = events.LogEvent(ctx, "order", orderID, events.EventOrderConfirmed, map[string]interface{}{})
+				notifyOrderStatus(orderID, models.OrderStatusConfirmed)
+
+				c.JSON(200, gin.H{"message": "order confirmed"})
+			})
+
+			// canUpdateOrder allows admins, staff who handle the order's
+			// outlet's orders and, when buyerMay, the order's buyer
+			canUpdateOrder := func(c *gin.Context, orderID string, buyerMay bool) bool {
+				if role, _ := middleware.GetRoleFromContext(c); role == models.RoleAdmin {
+					return true
+				}
+				order, err := orders.GetOrderByID(ctx, piiKeys, orderID)
+				if err != nil {
+					c.JSON(404, gin.H{"error": "order not found"})
+					return false
+				}
+				userID, _ := middleware.GetUserIDFromContext(c)
+				if buyerMay && order.BuyerID == userID {
+					return true
+				}
+				if staff, err := providers.OutletRole(ctx, userID, order.ProviderID); err != nil || !providers.CanHandleOrders(staff) {
+					c.JSON(403, gin.H{"error": "not an order for your outlet"})
+					return false
+				}
+				return true
+			}
+
+			// Cancel order (its buyer or outlet staff)
+			orderGroup.POST("/:id/cancel", func(c *gin.Context) {
+				orderID := c.Param("id")
+				if !canUpdateOrder(c, orderID, true) {
+					return
+				}
+				if err := orders.CancelOrder(ctx, orderID); err != nil {
+					c.JSON(500, gin.H{"error": "cancellation failed"})
+					return
+				}
+
+				_ = events.LogEvent(ctx, "order", orderID, events.EventOrderCancelled, map[string]interface{}{})
+				notifyOrderStatus(orderID, models.OrderStatusCancelled)
+
+				c.JSON(200, gin.H{"message": "order cancelled"})
+			})
+
+			// Mark order ready for pickup/delivery (outlet staff)
+			orderGroup.POST("/:id/ready", middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
+				orderID := c.Param("id")
+				if !canUpdateOrder(c, orderID, false) {
+					return
+				}
+				if err := orders.UpdateStatus(ctx, orderID, models.OrderStatusReady); err != nil {
+					c.JSON(500, gin.H{"error": "status update failed"})
+					return
+				}
+
+				_ = events.LogEvent(ctx, "order", orderID, events.EventOrderReady, map[string]interface{}{})
+				notifyOrderStatus(orderID, models.OrderStatusReady)
+
+				c.JSON(200, gin.H{"message": "order ready"})
+			})
+
+			// Complete order (outlet staff)
+			orderGroup.POST("/:id/complete", func(c *gin.Context) {
+				orderID := c.Param("id")
+				if !canUpdateOrder(c, orderID, false) {
+					return
+				}
+				if err := orders.CompleteOrder(ctx, orderID); err != nil {
+					c.JSON(500, gin.H{"error": "completion failed"})
+					return
+				}
+
+				_ = events.LogEvent(ctx, "order", orderID, events.EventOrderCompleted, map[string]interface{}{})
+				notifyOrderStatus(orderID, models.OrderStatusCompleted)
+
+				c.JSON(200, gin.H{"message": "order completed"})
+			})
+		}
+
+		// ==================== PAYMENT ROUTES ====================
+		paymentGroup := v1.Group("/payments")
+		paymentGroup.Use(middleware.AuthMiddleware(cfg.JwtSecret))
+		{
+			// Create payment order (Razorpay)
+			paymentGroup.POST("/create-order", func(c *gin.Context) {
+				var body struct {
+					OrderID  string `json:"order_id" binding:"required"`
+					Amount   int64  `json:"amount" binding:"required"` // Amount in paise
+					Currency string `json:"currency"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				userID, _ := middleware.GetUserIDFromContext(c)
+				receipt := "order_" + body.OrderID
+
+				paymentOrder, err := paymentService.CreateOrder(
+					body.Amount,
+					body.Currency,
+					receipt,
+					map[string]string{
+						"order_id": body.OrderID,
+						"user_id":  userID,
+					},
+				)
+				if err != nil {
+					logger.Error("payment order creation failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to create payment order"})
 					return
 				}
 
-				_ = events.LogEvent(ctx, "menu", menu.ID, events.EventMenuCreated, map[string]interface{}{
+				c.JSON(200, gin.H{
+					"razorpay_order_id": paymentOrder.ID,
+					"amount":            paymentOrder.Amount,
+					"currency":          paymentOrder.Currency,
+					"key_id":            os.Getenv("RAZORPAY_KEY_ID"),
+				})
+			})
+
+			// Verify payment
+			paymentGroup.POST("/verify", func(c *gin.Context) {
+				var body struct {
+					RazorpayOrderID   string `json:"razorpay_order_id" binding:"required"`
+					RazorpayPaymentID string `json:"razorpay_payment_id" binding:"required"`
+					RazorpaySignature string `json:"razorpay_signature" binding:"required"`
+					OrderID           string `json:"order_id" binding:"required"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				// Verify signature
+				if !paymentService.VerifyPayment(body.RazorpayOrderID, body.RazorpayPaymentID, body.RazorpaySignature) {
+					c.JSON(400, gin.H{"error": "payment verification failed"})
+					return
+				}
+
+				// Update order status to paid
+				if err := orders.UpdatePaymentStatus(ctx, body.OrderID, "PAID", body.RazorpayPaymentID); err != nil {
+					logger.Error("order payment update failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to update order"})
+					return
+				}
+
+				_ = events.LogEvent(ctx, "payment", body.OrderID, "payment_verified", map[string]interface{}{
+					"payment_id": body.RazorpayPaymentID,
+				})
+
+				c.JSON(200, gin.H{"message": "payment verified", "payment_id": body.RazorpayPaymentID})
+			})
+
+			// Get payment details
+			paymentGroup.GET("/:payment_id", func(c *gin.Context) {
+				paymentID := c.Param("payment_id")
+				details, err := paymentService.GetPaymentDetails(paymentID)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to get payment details"})
+					return
+				}
+				c.JSON(200, details)
+			})
+		}
+
+		// ==================== REVIEW ROUTES ====================
+		// rerollRatings rebuilds the analytics day a review counted towards
+		// once it is hidden, restored or deleted, without waiting for the
+		// roller. The roller also picks up moderated reviews, but a deleted
+		// review leaves nothing for it to find
+		rerollRatings := func(r *models.Review) {
+			if err := analytics.RollupProviderDay(ctx, r.ProviderID, r.CreatedAt.In(analyticsLoc), analyticsLoc); err != nil {
+				logger.Warn("analytics rollup after review change failed", zap.String("review_id", r.ID), zap.Error(err))
+			}
+		}
+
+		reviewGroup := v1.Group("/reviews")
+		{
+			// Public: Get reviews by provider
+			reviewGroup.GET("/provider/:provider_id", func(c *gin.Context) {
+				providerID := c.Param("provider_id")
+				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+				list, err := reviews.GetReviewsByProvider(ctx, providerID, limit, offset)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to get reviews"})
+					return
+				}
+				c.JSON(200, list)
+			})
+
+			// Public: Get review stats for provider
+			reviewGroup.GET("/provider/:provider_id/stats", func(c *gin.Context) {
+				providerID := c.Param("provider_id")
+				stats, err := reviews.GetProviderReviewStats(ctx, providerID)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to get review stats"})
+					return
+				}
+				c.JSON(200, stats)
+			})
+
+			// Protected: Create review
+			reviewGroup.POST("", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				var body struct {
+					ProviderID string   `json:"provider_id" binding:"required"`
+					OrderID    string   `json:"order_id"`
+					Rating     int      `json:"rating" binding:"required,min=1,max=5"`
+					Comment    string   `json:"comment"`
+					PhotoURLs  []string `json:"photo_urls"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				provider, err := providers.GetProvider(ctx, body.ProviderID)
+				if err != nil {
+					c.JSON(404, gin.H{"error": "provider not found"})
+					return
+				}
+				if provider.Blocked {
+					c.JSON(403, gin.H{"error": "provider_blocked", "message": "This provider can't be reviewed."})
+					return
+				}
+
+				review, err := reviews.CreateReview(ctx, body.ProviderID, userID, body.OrderID, body.Rating, body.Comment, body.PhotoURLs)
+				if err != nil {
+					logger.Error("review creation failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "review creation failed"})
+					return
+				}
+
+				_ = events.LogEvent(ctx, "review", review.ID, events.EventReviewCreated, map[string]interface{}{
 					"provider_id": body.ProviderID,
-					"name":        body.Name,
+					"user_id":     userID,
+					"rating":      body.Rating,
 				})
 
-				c.JSON(201, menu)
+				c.JSON(201, review)
 			})
 
-			// Protected: Update menu
-			menuGroup.PUT("/:id", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
-				menuID := c.Param("id")
+			// Protected: Get my reviews
+			reviewGroup.GET("/my", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+				list, err := reviews.GetReviewsByUser(ctx, userID, limit, offset)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to get reviews"})
+					return
+				}
+				c.JSON(200, list)
+			})
+
+			// Protected: Report a review for moderation
+			reviewGroup.POST("/:id/report", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				reviewID := c.Param("id")
 				var body struct {
-					Name        string `json:"name"`
-					Description string `json:"description"`
+					Reason string `json:"reason" binding:"required,max=500"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				review, err := reviews.GetReview(ctx, reviewID)
+				if err != nil {
+					c.JSON(404, gin.H{"error": "review not found"})
+					return
+				}
+				if review.UserID == userID {
+					c.JSON(400, gin.H{"error": "you can't report your own review"})
+					return
+				}
+
+				err = reviews.ReportReview(ctx, reviewID, userID, body.Reason)
+				if err == db.ErrNotFound {
+					c.JSON(404, gin.H{"error": "review not found"})
+					return
+				}
+				if err != nil {
+					logger.Error("review report failed", zap.String("review_id", reviewID), zap.Error(err))
+					c.JSON(500, gin.H{"error": "report failed"})
+					return
+				}
+
+				_ = events.LogEvent(ctx, "review", reviewID, events.EventReviewReported, map[string]interface{}{
+					"user_id": userID,
+					"reason":  body.Reason,
+				})
+
+				c.JSON(200, gin.H{"message": "reported"})
+			})
+
+			// Protected: Delete my review
+			reviewGroup.DELETE("/:id", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				reviewID := c.Param("id")
+
+				review, err := reviews.GetReview(ctx, reviewID)
+				if err != nil || review.UserID != userID {
+					c.JSON(404, gin.H{"error": "review not found"})
+					return
+				}
+				if err := reviews.DeleteReview(ctx, reviewID, userID); err != nil {
+					c.JSON(500, gin.H{"error": "deletion failed"})
+					return
+				}
+				rerollRatings(review)
+				c.JSON(200, gin.H{"message": "deleted"})
+			})
+
+			// Admin: Delete any review
+			reviewGroup.DELETE("/:id/admin", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("admin"), middleware.AuditMiddleware(), func(c *gin.Context) {
+				reviewID := c.Param("id")
+
+				review, err := reviews.GetReview(ctx, reviewID)
+				if err != nil {
+					c.JSON(404, gin.H{"error": "review not found"})
+					return
+				}
+				if err := reviews.DeleteReviewAdmin(ctx, reviewID); err != nil {
+					c.JSON(500, gin.H{"error": "deletion failed"})
+					return
+				}
+				rerollRatings(review)
+
+				adminID, _ := middleware.GetUserIDFromContext(c)
+				_ = events.LogEvent(ctx, "review", reviewID, events.EventReviewDeleted, map[string]interface{}{
+					"admin_id":    adminID,
+					"provider_id": review.ProviderID,
+				})
+
+				c.JSON(200, gin.H{"message": "deleted"})
+			})
+		}
+
+		// ==================== CHAT ROUTES ====================
+		chatGroup := v1.Group("/chat")
+		{
+			// WebSocket endpoint for chat
+			chatGroup.GET("/ws", chatHub.HandleWebSocket)
+
+			// Protected: Create chat for order
+			chatGroup.POST("", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				var body struct {
+					OrderID      string   `json:"order_id" binding:"required"`
+					Participants []string `json:"participants" binding:"required"`
 				}
 				if err := c.ShouldBindJSON(&body); err != nil {
 					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
 
-				if err := menus.UpdateMenu(ctx, menuID, body.Name, body.Description); err != nil {
-					c.JSON(500, gin.H{"error": "update failed"})
+				order, err := orders.GetOrderByID(ctx, piiKeys, body.OrderID)
+				if err != nil {
+					c.JSON(404, gin.H{"error": "order not found"})
+					return
+				}
+				if provider, err := providers.GetProvider(ctx, order.ProviderID); err == nil && provider.Blocked {
+					c.JSON(403, gin.H{"error": "provider_blocked", "message": "Chat is unavailable for this provider."})
+					return
+				}
+
+				chatRoom, err := chat.CreateChat(ctx, body.OrderID, body.Participants)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "chat creation failed"})
+					return
+				}
+
+				_ = events.LogEvent(ctx, "chat", chatRoom.ID, events.EventChatCreated, map[string]interface{}{
+					"order_id":     body.OrderID,
+					"participants": body.Participants,
+				})
+
+				c.JSON(201, chatRoom)
+			})
+
+			// Get chat by order
+			chatGroup.GET("/order/:order_id", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				orderID := c.Param("order_id")
+				chatRoom, err := chat.GetChatByOrder(ctx, orderID)
+				if err != nil {
+					c.JSON(404, gin.H{"error": "chat not found"})
+					return
+				}
+				c.JSON(200, chatRoom)
+			})
+
+			// Get messages
+			chatGroup.GET("/:id/messages", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+				chatID := c.Param("id")
+				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+				messages, err := chat.GetMessages(ctx, chatID, limit, offset)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to get messages"})
+					return
+				}
+				c.JSON(200, messages)
+			})
+		}
+
+		// ==================== WEBHOOK ROUTES ====================
+		webhookGroup := v1.Group("/webhooks")
+		{
+			// WhatsApp webhook subscription handshake
+			webhookGroup.GET("/whatsapp", func(c *gin.Context) {
+				if cfg.WhatsAppVerifyToken == "" ||
+					c.Query("hub.mode") != "subscribe" ||
+					c.Query("hub.verify_token") != cfg.WhatsAppVerifyToken {
+					c.JSON(403, gin.H{"error": "verification failed"})
+					return
+				}
+				c.String(200, c.Query("hub.challenge"))
+			})
+
+			// WhatsApp delivery status callbacks
+			webhookGroup.POST("/whatsapp", func(c *gin.Context) {
+				raw, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+				if err != nil {
+					c.JSON(400, gin.H{"error": "invalid body"})
+					return
+				}
+				if !notify.VerifyWhatsAppSignature(cfg.WhatsAppAppSecret, raw, c.GetHeader("X-Hub-Signature-256")) {
+					c.JSON(401, gin.H{"error": "invalid signature"})
 					return
 				}
-				c.JSON(200, gin.H{"message": "updated"})
-			})
 
-			// Protected: Delete menu
-			menuGroup.DELETE("/:id", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
-				menuID := c.Param("id")
-				if err := menus.DeleteMenu(ctx, menuID); err != nil {
-					c.JSON(500, gin.H{"error": "deletion failed"})
+				statuses, err := notify.ParseWhatsAppWebhook(raw)
+				if err != nil {
+					c.JSON(400, gin.H{"error": "invalid payload"})
 					return
 				}
-				c.JSON(200, gin.H{"message": "deleted"})
+				for _, st := range statuses {
+					if err := notify.UpdateDeliveryStatus(ctx, models.ChannelWhatsApp, st); err != nil {
+						logger.Warn("whatsapp webhook: status update failed", zap.Error(err), zap.String("message_id", st.MessageID))
+					}
+					if st.Status == "failed" {
+						logger.Warn("whatsapp delivery failed",
+							zap.String("message_id", st.MessageID),
+							zap.String("recipient", util.MaskPhone(st.RecipientID)),
+							zap.Int("error_code", st.ErrorCode),
+							zap.String("error_title", st.ErrorTitle))
+					}
+				}
+				c.JSON(200, gin.H{"received": len(statuses)})
 			})
 		}
 
-		// ==================== MENU ITEM ROUTES ====================
-		itemGroup := v1.Group("/menu-items")
+		// ==================== CAMPAIGN ROUTES ====================
+		campaignGroup := v1.Group("/campaigns")
+		campaignGroup.Use(middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("admin", "provider"), middleware.AuditAdminMiddleware())
 		{
-			// Public: Get item by ID
-			itemGroup.GET("/:id", func(c *gin.Context) {
-				id := c.Param("id")
-				item, err := menus.GetMenuItem(ctx, id)
+			// campaignProviderID returns the outlet (?outlet_id=) the caller
+			// owns or manages, or "" for admins
+			campaignProviderID := func(c *gin.Context) (string, bool) {
+				role, _ := middleware.GetRoleFromContext(c)
+				if role == models.RoleAdmin {
+					return "", true
+				}
+				return callerOutlet(c, providers.CanManageOutlet, "provider profile required")
+			}
+
+			// Topics the caller may target (providers are limited to their own profile)
+			campaignGroup.GET("/topics", func(c *gin.Context) {
+				providerID, ok := campaignProviderID(c)
+				if !ok {
+					return
+				}
+				if providerID == "" {
+					c.JSON(200, gin.H{"topics": "any", "prefixes": []string{
+						topics.PrefixCity, topics.PrefixPin, topics.PrefixCategory, topics.PrefixFood,
+					}})
+					return
+				}
+				list, err := topics.GetProviderTopics(ctx, providerID)
 				if err != nil {
-					c.JSON(404, gin.H{"error": "item not found"})
+					c.JSON(500, gin.H{"error": "failed to load provider topics"})
 					return
 				}
-				c.JSON(200, item)
+				c.JSON(200, gin.H{"topics": list})
 			})
 
-			// Public: Get items by menu
-			itemGroup.GET("/menu/:menu_id", func(c *gin.Context) {
-				menuID := c.Param("menu_id")
-				list, err := menus.GetMenuItems(ctx, menuID)
-				if err != nil {
-					c.JSON(500, gin.H{"error": "failed to get items"})
+			// Create (schedule) a campaign
+			campaignGroup.POST("", func(c *gin.Context) {
+				userID, _ := middleware.GetUserIDFromContext(c)
+				providerID, ok := campaignProviderID(c)
+				if !ok {
 					return
 				}
-				c.JSON(200, list)
-			})
 
-			// Protected: Create item
-			itemGroup.POST("", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
 				var body struct {
-					MenuID       string   `json:"menu_id" binding:"required"`
-					Name         string   `json:"name" binding:"required"`
-					Price        float64  `json:"price" binding:"required"`
-					Ingredients  []string `json:"ingredients"`
-					IsJain       bool     `json:"is_jain"`
-					Availability bool     `json:"availability"`
-					ImageURL     string   `json:"image_url"`
+					Topic  string            `json:"topic" binding:"required"`
+					Title  string            `json:"title" binding:"required"`
+					Body   string            `json:"body" binding:"required"`
+					Data   map[string]string `json:"data"`
+					SendAt *time.Time        `json:"send_at"` // Omit to send as soon as allowed
 				}
 				if err := c.ShouldBindJSON(&body); err != nil {
 					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
+				if !topics.Valid(body.Topic) {
+					c.JSON(400, gin.H{"error": "invalid topic"})
+					return
+				}
+				if err := campaigns.Validate(body.Title, body.Body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				if providerID != "" {
+					allowed, err := topics.GetProviderTopics(ctx, providerID)
+					if err != nil {
+						c.JSON(500, gin.H{"error": "failed to load provider topics"})
+						return
+					}
+					permitted := false
+					for _, t := range allowed {
+						if t == body.Topic {
+							permitted = true
+							break
+						}
+					}
+					if !permitted {
+						c.JSON(403, gin.H{"error": "providers can only target topics matching their own profile"})
+						return
+					}
+
+					if body.Data == nil {
+						body.Data = map[string]string{}
+					}
+					body.Data["provider_id"] = providerID
+				}
+
+				sendAt := time.Now()
+				if body.SendAt != nil && body.SendAt.After(sendAt) {
+					sendAt = *body.SendAt
+				}
+				// Don't schedule into quiet hours; move to when they end
+				sendAt = campaignRules.NextAllowed(sendAt)
 
-				item, err := menus.CreateMenuItem(ctx, body.MenuID, body.Name, body.Price, body.Ingredients, body.IsJain, body.Availability, body.ImageURL)
+				campaign, err := campaigns.CreateCampaign(ctx, userID, providerID, body.Topic, body.Title, body.Body, body.Data, sendAt, campaignRules.ProviderWeeklyLimit)
+				if err == campaigns.ErrWeeklyLimit {
+					c.JSON(429, gin.H{"error": fmt.Sprintf("providers may create at most %d campaigns per week", campaignRules.ProviderWeeklyLimit)})
+					return
+				}
 				if err != nil {
-					c.JSON(500, gin.H{"error": "item creation failed"})
+					logger.Error("campaign creation failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "campaign creation failed"})
 					return
 				}
 
-				_ = events.LogEvent(ctx, "menu_item", item.ID, events.EventItemCreated, map[string]interface{}{
-					"menu_id": body.MenuID,
-					"name":    body.Name,
-					"is_jain": body.IsJain,
+				_ = events.LogEvent(ctx, "campaign", campaign.ID, events.EventCampaignCreated, map[string]interface{}{
+					"topic":       campaign.Topic,
+					"provider_id": providerID,
+					"send_at":     campaign.SendAt,
 				})
-
-				c.JSON(201, item)
+				c.JSON(201, campaign)
 			})
 
-			// Protected: Update item
-			itemGroup.PUT("/:id", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
-				itemID := c.Param("id")
-				var body struct {
-					Name         string   `json:"name"`
-					Price        float64  `json:"price"`
-					Ingredients  []string `json:"ingredients"`
-					IsJain       bool     `json:"is_jain"`
-					Availability bool     `json:"availability"`
-					ImageURL     string   `json:"image_url"`
+			// List campaigns (admins see all, providers their own)
+			campaignGroup.GET("", func(c *gin.Context) {
+				providerID, ok := campaignProviderID(c)
+				if !ok {
+					return
 				}
-				if err := c.ShouldBindJSON(&body); err != nil {
-					c.JSON(400, gin.H{"error": err.Error()})
+				list, err := campaigns.ListCampaigns(ctx, providerID, 50, 0)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to list campaigns"})
 					return
 				}
+				c.JSON(200, list)
+			})
 
-				if err := menus.UpdateMenuItem(ctx, itemID, body.Name, body.Price, body.Ingredients, body.IsJain, body.Availability, body.ImageURL); err != nil {
-					c.JSON(500, gin.H{"error": "update failed"})
+			// Get campaign with delivery counts
+			campaignGroup.GET("/:id", func(c *gin.Context) {
+				providerID, ok := campaignProviderID(c)
+				if !ok {
 					return
 				}
-				c.JSON(200, gin.H{"message": "updated"})
+				campaign, err := campaigns.GetCampaign(ctx, c.Param("id"))
+				if err != nil || (providerID != "" && campaign.ProviderID != providerID) {
+					c.JSON(404, gin.H{"error": "campaign not found"})
+					return
+				}
+				c.JSON(200, campaign)
 			})
 
-			// Protected: Toggle availability (real-time)
-			itemGroup.PATCH("/:id/availability", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
-				itemID := c.Param("id")
-				var body struct {
-					Available bool `json:"available"`
+			// Cancel a scheduled campaign
+			campaignGroup.DELETE("/:id", func(c *gin.Context) {
+				providerID, ok := campaignProviderID(c)
+				if !ok {
+					return
 				}
-				if err := c.ShouldBindJSON(&body); err != nil {
-					c.JSON(400, gin.H{"error": err.Error()})
+				campaign, err := campaigns.GetCampaign(ctx, c.Param("id"))
+				if err != nil || (providerID != "" && campaign.ProviderID != providerID) {
+					c.JSON(404, gin.H{"error": "campaign not found"})
 					return
 				}
-
-				if err := menus.ToggleAvailability(ctx, itemID, body.Available); err != nil {
-					c.JSON(500, gin.H{"error": "toggle failed"})
+				if err := campaigns.CancelCampaign(ctx, campaign.ID); err != nil {
+					c.JSON(409, gin.H{"error": err.Error()})
 					return
 				}
-				c.JSON(200, gin.H{"message": "availability updated"})
+				c.JSON(200, gin.H{"message": "campaign cancelled"})
 			})
+		}
 
-			// Protected: Delete item
-			itemGroup.DELETE("/:id", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("provider", "admin"), func(c *gin.Context) {
-				itemID := c.Param("id")
-				if err := menus.DeleteMenuItem(ctx, itemID); err != nil {
-					c.JSON(500, gin.H{"error": "deletion failed"})
+		// ==================== PUSH ROUTES ====================
+		pushGroup := v1.Group("/push")
+		{
+			// VAPID application server key for pushManager.subscribe()
+			pushGroup.GET("/vapid-public-key", func(c *gin.Context) {
+				if cfg.VAPIDPublicKey == "" {
+					c.JSON(404, gin.H{"error": "web push not configured"})
 					return
 				}
-				c.JSON(200, gin.H{"message": "deleted"})
+				c.JSON(200, gin.H{"public_key": cfg.VAPIDPublicKey})
 			})
 		}
 
-		// ==================== SEARCH ROUTES ====================
-		searchGroup := v1.Group("/search")
+		// ==================== ADMIN ROUTES ====================
+		adminGroup := v1.Group("/admin")
+		adminGroup.Use(middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("admin"), middleware.AuditMiddleware())
 		{
-			// Search nearby providers
-			searchGroup.GET("/providers", func(c *gin.Context) {
-				lat, _ := strconv.ParseFloat(c.Query("lat"), 64)
-				lng, _ := strconv.ParseFloat(c.Query("lng"), 64)
-				radius, _ := strconv.ParseFloat(c.DefaultQuery("radius", "5000"), 64) // Default 5km
-				minRating, _ := strconv.ParseFloat(c.DefaultQuery("min_rating", "0"), 64)
-				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+			// Promotions, optionally by status, city and provider
+			adminGroup.GET("/promotions", func(c *gin.Context) {
+				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-
-				tags := []string{}
-				if t := c.Query("tags"); t != "" {
-					tags = strings.Split(t, ",")
-				}
-
-				filters := search.SearchFilters{
-					Lat:          lat,
-					Lng:          lng,
-					RadiusMeters: radius,
-					Tags:         tags,
-					MinRating:    minRating,
-				}
-
-				results, err := search.SearchNearbyProviders(ctx, filters, limit, offset)
+				list, total, err := promotions.List(ctx, promotions.Filter{
+					ProviderID: c.Query("provider_id"), Status: c.Query("status"), CitySlug: c.Query("city"),
+					Limit: limit, Offset: offset,
+				})
 				if err != nil {
-					logger.Error("provider search failed", zap.Error(err))
-					c.JSON(500, gin.H{"error": "search failed"})
+					logger.Error("failed to list promotions", zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to list promotions"})
 					return
 				}
-				c.JSON(200, results)
+				c.JSON(200, gin.H{"promotions": list, "total": total})
 			})
 
-			// Search menu items
-			searchGroup.GET("/items", func(c *gin.Context) {
-				lat, _ := strconv.ParseFloat(c.Query("lat"), 64)
-				lng, _ := strconv.ParseFloat(c.Query("lng"), 64)
-				radius, _ := strconv.ParseFloat(c.DefaultQuery("radius", "5000"), 64)
-				query := c.Query("q")
-				jainOnly, _ := strconv.ParseBool(c.DefaultQuery("jain_only", "true"))
-				availableOnly, _ := strconv.ParseBool(c.DefaultQuery("available_only", "true"))
-				minRating, _ := strconv.ParseFloat(c.DefaultQuery("min_rating", "0"), 64)
-				priceMax, _ := strconv.ParseFloat(c.DefaultQuery("price_max", "0"), 64)
-				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-
-				tags := []string{}
-				if t := c.Query("tags"); t != "" {
-					tags = strings.Split(t, ",")
+			// Book a promoted slot for a provider, active without payment.
+			// amount_paise records what was billed outside the app.
+			adminGroup.POST("/promotions", func(c *gin.Context) {
+				var body struct {
+					promotionBody
+					ProviderID  string `json:"provider_id" binding:"required"`
+					AmountPaise int64  `json:"amount_paise"`
 				}
-
-				filters := search.SearchFilters{
-					Lat:           lat,
-					Lng:           lng,
-					RadiusMeters:  radius,
-					Query:         query,
-					JainOnly:      jainOnly,
-					AvailableOnly: availableOnly,
-					Tags:          tags,
-					MinRating:     minRating,
-					PriceMax:      priceMax,
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
 				}
-
-				results, err := search.SearchMenuItems(ctx, filters, limit, offset)
-				if err != nil {
-					logger.Error("item search failed", zap.Error(err))
-					c.JSON(500, gin.H{"error": "search failed"})
+				if body.AmountPaise < 0 {
+					c.JSON(400, gin.H{"error": "amount_paise must not be negative"})
 					return
 				}
-				c.JSON(200, results)
+				pr := bookPromotion(c, promotions.Request{
+					ProviderID: body.ProviderID, CitySlug: body.City, ProviderCategory: body.ProviderCategory,
+					StartsAt: body.StartsAt, EndsAt: body.EndsAt, DailyCap: body.DailyCap,
+				}, models.PromotionSourceAdmin, body.AmountPaise)
+				if pr == nil {
+					return
+				}
+				c.JSON(201, pr)
 			})
-		}
 
-		// ==================== ORDER ROUTES ====================
-		orderGroup := v1.Group("/orders")
-		orderGroup.Use(middleware.AuthMiddleware(cfg.JwtSecret))
-		{
-			// Create order
-			orderGroup.POST("", func(c *gin.Context) {
-				userID, _ := middleware.GetUserIDFromContext(c)
-				var body struct {
-					ProviderID string      `json:"provider_id" binding:"required"`
-					Items      interface{} `json:"items" binding:"required"`
-					Total      float64     `json:"total" binding:"required"`
+			// Switch a running or unpaid promotion off
+			adminGroup.POST("/promotions/:id/cancel", func(c *gin.Context) {
+				pr, err := promotions.Cancel(ctx, c.Param("id"))
+				if err == db.ErrNotFound {
+					c.JSON(404, gin.H{"error": "promotion not found"})
+					return
 				}
-				if err := c.ShouldBindJSON(&body); err != nil {
-					c.JSON(400, gin.H{"error": err.Error()})
+				if err == promotions.ErrEnded {
+					c.JSON(409, gin.H{"error": err.Error()})
 					return
 				}
-
-				orderID, orderCode, err := orders.CreateOrder(ctx, userID, body.ProviderID, body.Items, body.Total)
 				if err != nil {
-					logger.Error("order creation failed", zap.Error(err))
-					c.JSON(500, gin.H{"error": "order creation failed"})
+					logger.Error("failed to cancel promotion", zap.String("promotion_id", c.Param("id")), zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to cancel promotion"})
 					return
 				}
+				adminID, _ := middleware.GetUserIDFromContext(c)
+				_ = events.LogEvent(ctx, "promotion", pr.ID, events.EventPromotionCancelled, map[string]interface{}{
+					"admin_id": adminID, "provider_id": pr.ProviderID,
+				})
+				c.JSON(200, pr)
+			})
 
-				// Generate order confirmation OTP
-				otp, err := auth.GenerateOTP()
+			// Notification queue depth and recent dead letters
+			adminGroup.GET("/queues/notify", func(c *gin.Context) {
+				stats, err := notifyQueue.Stats(ctx)
 				if err != nil {
-					c.JSON(500, gin.H{"error": "otp generation failed"})
+					c.JSON(500, gin.H{"error": "failed to get queue stats"})
 					return
 				}
-				hash := auth.HashOTP(cfg.OtpSecret, otp)
-				otpKey := "order-otp:" + orderID
-				if err := auth.StoreOTP(ctx, otpKey, hash, 30*time.Minute); err != nil {
-					c.JSON(500, gin.H{"error": "failed to store otp"})
+				dead, err := notifyQueue.DeadLetters(ctx, 20)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to get dead letters"})
 					return
 				}
 
-				// Log event
-				_ = events.LogEvent(ctx, "order", orderID, events.EventOrderCreated, map[string]interface{}{
-					"buyer_id":    userID,
-					"provider_id": body.ProviderID,
-					"total":       body.Total,
-					"order_code":  orderCode,
-				})
+				// Payloads are omitted since OTP jobs carry the plaintext code
+				deadLetters := make([]gin.H, 0, len(dead))
+				for _, job := range dead {
+					deadLetters = append(deadLetters, gin.H{
+						"id":          job.ID,
+						"type":        job.Type,
+						"attempts":    job.Attempts,
+						"last_error":  job.LastError,
+						"enqueued_at": job.EnqueuedAt,
+					})
+				}
+				c.JSON(200, gin.H{"stats": stats, "dead_letters": deadLetters})
+			})
 
-				// Send OTP notification for order confirmation to the buyer's phone number
-				buyer, err := users.GetUserByID(ctx, userID)
+			// Items claiming is_jain that the ingredient checker marked non-Jain
+			adminGroup.GET("/jain-rules/flagged", func(c *gin.Context) {
+				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+				items, err := menus.GetFlaggedJainItems(ctx, limit, offset)
 				if err != nil {
-					logger.Warn("failed to load buyer for order OTP notification",
-						zap.Error(err),
-						zap.String("order_id", orderID),
-						zap.String("buyer_id", userID))
-				} else if buyer.Phone == "" {
-					logger.Warn("buyer phone missing for order OTP notification",
-						zap.String("order_id", orderID),
-						zap.String("buyer_id", userID))
-				} else {
-					notifier := notify.NewNotifier()
-					if err := notifier.SendOTP(buyer.Phone, otp); err != nil {
-						logger.Warn("failed to send order OTP notification",
-							zap.Error(err),
-							zap.String("order_id", orderID),
-							zap.String("buyer_id", userID))
-					}
+					c.JSON(500, gin.H{"error": "failed to get flagged items"})
+					return
 				}
+				c.JSON(200, items)
+			})
 
-				// In development/local environments, optionally return OTP in response
-				if cfg.ShouldReturnOTPInResponse() {
-					c.JSON(201, gin.H{
-						"order_id":   orderID,
-						"order_code": orderCode,
-						"otp":        otp,
-					})
+			// Recompute verdicts computed with an older taxonomy version
+			adminGroup.POST("/jain-rules/recheck", func(c *gin.Context) {
+				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "500"))
+				items, err := menus.GetItemsNeedingJainCheck(ctx, jainrules.TaxonomyVersion, limit)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to load items"})
 					return
 				}
+				flagged := 0
+				for _, item := range items {
+					verdict := jainrules.Check(item.Name, item.Ingredients)
+					if err := menus.SetJainVerdict(ctx, item.ID, verdict.Status, verdict.Reasons(), verdict.Version); err != nil {
+						logger.Warn("failed to store jain verdict", zap.String("item_id", item.ID), zap.Error(err))
+						continue
+					}
+					if item.IsJain && !verdict.Compliant {
+						flagged++
+					}
+				}
+				c.JSON(200, gin.H{"version": jainrules.TaxonomyVersion, "checked": len(items), "flagged": flagged})
+			})
 
-				c.JSON(201, gin.H{
-					"order_id":   orderID,
-					"order_code": orderCode,
-				})
+			// Provider applications awaiting review, oldest submission first.
+			// state= filters by a comma-separated list of states instead.
+			adminGroup.GET("/applications", func(c *gin.Context) {
+				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+				var states []string
+				if v := c.Query("state"); v != "" {
+					states = strings.Split(v, ",")
+				}
+				list, err := onboarding.ListQueue(ctx, states, limit, offset)
+				if err != nil {
+					c.JSON(500, gin.H{"error": "failed to list applications"})
+					return
+				}
+				c.JSON(200, list)
 			})
 
-			// Get order by code
-			orderGroup.GET("/code/:code", func(c *gin.Context) {
-				code := c.Param("code")
-				orderID, err := orders.GetOrderByCode(ctx, code)
+			// An application with its documents and history
+			adminGroup.GET("/applications/:id", func(c *gin.Context) {
+				app, err := onboarding.GetApplicationByID(ctx, c.Param("id"))
 				if err != nil {
-					c.JSON(404, gin.H{"error": "order not found"})
+					applicationError(c, err)
 					return
 				}
-				c.JSON(200, gin.H{"order_id": orderID, "order_code": code})
+				c.JSON(200, app)
 			})
 
-			// Confirm order with OTP
-			orderGroup.POST("/:id/confirm-otp", func(c *gin.Context) {
-				orderID := c.Param("id")
+			// Decrypt an application's Aadhaar number to check it against the
+			// ID proof. Every reveal is logged.
+			adminGroup.GET("/applications/:id/aadhaar", func(c *gin.Context) {
+				adminID, _ := middleware.GetUserIDFromContext(c)
+				number, err := onboarding.RevealAadhaar(ctx, piiKeys, c.Param("id"))
+				if err == db.ErrNotFound {
+					c.JSON(404, gin.H{"error": "application has no Aadhaar number"})
+					return
+				}
+				if err != nil {
+					logger.Error("aadhaar reveal failed", zap.String("application_id", c.Param("id")), zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to decrypt Aadhaar number"})
+					return
+				}
+
+				_ = events.LogEvent(ctx, "provider_application", c.Param("id"), events.EventAadhaarRevealed, map[string]interface{}{
+					"admin_id": adminID,
+				})
+
+				c.Header("Cache-Control", "no-store")
+				c.JSON(200, gin.H{"aadhar_number": number})
+			})
+
+			// Review an application: start_review, request_changes, approve or
+			// reject. Requesting changes and rejecting need a comment.
+			adminGroup.POST("/applications/:id/review", func(c *gin.Context) {
+				reviewerID, _ := middleware.GetUserIDFromContext(c)
 				var body struct {
-					OTP string `json:"otp" binding:"required"`
+					Action  string `json:"action" binding:"required"`
+					Comment string `json:"comment"`
 				}
 				if err := c.ShouldBindJSON(&body); err != nil {
 					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
 
-				key := "order-otp:" + orderID
-				stored, err := auth.GetOTP(ctx, key)
-				if err != nil {
-					c.JSON(400, gin.H{"error": "otp expired or not found"})
+				if err := onboarding.ValidateReview(body.Action, body.Comment); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
 
-				if stored != auth.HashOTP(cfg.OtpSecret, body.OTP) {
-					c.JSON(401, gin.H{"error": "invalid otp"})
+				app, err := onboarding.Review(ctx, c.Param("id"), reviewerID, body.Action, strings.TrimSpace(body.Comment))
+				if err != nil {
+					applicationError(c, err)
 					return
 				}
 
-				if err := orders.ConfirmOrder(ctx, orderID); err != nil {
-					c.JSON(500, gin.H{"error": "order confirmation failed"})
-					return
+				_ = events.LogEvent(ctx, "provider", app.ProviderID, events.EventApplicationReviewed, map[string]interface{}{
+					"application_id": app.ID,
+					"action":         body.Action,
+					"state":          app.State,
+					"reviewer_id":    reviewerID,
+				})
+				if app.State == models.ApplicationApproved {
+					_ = events.LogEvent(ctx, "provider", app.ProviderID, events.EventProviderVerified, map[string]interface{}{
+						"verified":       true,
+						"application_id": app.ID,
+					})
 				}
 
-				_ = auth.DeleteOTP(ctx, key)
-
-				// Log event
-				_ = events.LogEvent(ctx, "order", orderID, events.EventOrderConfirmed, map[string]interface{}{})
-
-				c.JSON(200, gin.H{"message": "order confirmed"})
+				c.JSON(200, app)
 			})
 
-			// Cancel order
-			orderGroup.POST("/:id/cancel", func(c *gin.Context) {
-				orderID := c.Param("id")
-				if err := orders.CancelOrder(ctx, orderID); err != nil {
-					c.JSON(500, gin.H{"error": "cancellation failed"})
+			// Recompute transliterated search keys for providers and items
+			adminGroup.POST("/search/reindex", func(c *gin.Context) {
+				result, err := search.Reindex(ctx)
+				if err != nil {
+					logger.Error("search reindex failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "reindex failed"})
 					return
 				}
-
-				_ = events.LogEvent(ctx, "order", orderID, events.EventOrderCancelled, map[string]interface{}{})
-
-				c.JSON(200, gin.H{"message": "order cancelled"})
+				c.JSON(200, result)
 			})
 
-			// Complete order
-			orderGroup.POST("/:id/complete", func(c *gin.Context) {
-				orderID := c.Param("id")
-				if err := orders.CompleteOrder(ctx, orderID); err != nil {
-					c.JSON(500, gin.H{"error": "completion failed"})
+			// Platform KPIs for the dashboard over from..to (local days,
+			// default the last 30)
+			adminGroup.GET("/kpis", func(c *gin.Context) {
+				q, err := analytics.ParseQuery(c.Query("from"), c.Query("to"), "", time.Now().In(analyticsLoc))
+				if err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
-
-				_ = events.LogEvent(ctx, "order", orderID, events.EventOrderCompleted, map[string]interface{}{})
-
-				c.JSON(200, gin.H{"message": "order completed"})
+				kpis, err := admin.KPIs(ctx, q, analyticsLoc)
+				if err != nil {
+					logger.Error("admin kpis failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "failed to compute kpis"})
+					return
+				}
+				c.JSON(200, kpis)
 			})
-		}
 
-		// ==================== PAYMENT ROUTES ====================
-		paymentGroup := v1.Group("/payments")
-		paymentGroup.Use(middleware.AuthMiddleware(cfg.JwtSecret))
-		{
-			// Create payment order (Razorpay)
-			paymentGroup.POST("/create-order", func(c *gin.Context) {
-				var body struct {
-					OrderID  string `json:"order_id" binding:"required"`
-					Amount   int64  `json:"amount" binding:"required"` // Amount in paise
-					Currency string `json:"currency"`
+			// queryBool reads an optional true/false filter
+			queryBool := func(c *gin.Context, key string) (*bool, bool) {
+				v := c.Query(key)
+				if v == "" {
+					return nil, true
 				}
-				if err := c.ShouldBindJSON(&body); err != nil {
-					c.JSON(400, gin.H{"error": err.Error()})
-					return
+				b, err := strconv.ParseBool(v)
+				if err != nil {
+					c.JSON(400, gin.H{"error": key + " must be true or false"})
+					return nil, false
 				}
+				return &b, true
+			}
 
-				userID, _ := middleware.GetUserIDFromContext(c)
-				receipt := "order_" + body.OrderID
+			// Search providers by name or owner phone, filtered by
+			// verified=, blocked=, category= and city=
+			adminGroup.GET("/providers", func(c *gin.Context) {
+				f := providers.Filter{
+					Query:    strings.TrimSpace(c.Query("q")),
+					Category: c.Query("category"),
+					City:     c.Query("city"),
+				}
+				var ok bool
+				if f.Verified, ok = queryBool(c, "verified"); !ok {
+					return
+				}
+				if f.Blocked, ok = queryBool(c, "blocked"); !ok {
+					return
+				}
+				f.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+				f.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-				paymentOrder, err := paymentService.CreateOrder(
-					body.Amount,
-					body.Currency,
-					receipt,
-					map[string]string{
-						"order_id": body.OrderID,
-						"user_id":  userID,
-					},
-				)
+				list, total, err := providers.SearchProviders(ctx, f)
 				if err != nil {
-					logger.Error("payment order creation failed", zap.Error(err))
-					c.JSON(500, gin.H{"error": "failed to create payment order"})
+					logger.Error("admin provider search failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "search failed"})
 					return
 				}
-
-				c.JSON(200, gin.H{
-					"razorpay_order_id": paymentOrder.ID,
-					"amount":            paymentOrder.Amount,
-					"currency":          paymentOrder.Currency,
-					"key_id":            os.Getenv("RAZORPAY_KEY_ID"),
-				})
+				c.JSON(200, gin.H{"providers": list, "total": total})
 			})
 
-			// Verify payment
-			paymentGroup.POST("/verify", func(c *gin.Context) {
+			// Bulk verify, unverify, block or unblock providers. Blocking
+			// needs a reason. Failures on some ids don't stop the rest.
+			adminGroup.POST("/providers/bulk", func(c *gin.Context) {
+				adminID, _ := middleware.GetUserIDFromContext(c)
 				var body struct {
-					RazorpayOrderID   string `json:"razorpay_order_id" binding:"required"`
-					RazorpayPaymentID string `json:"razorpay_payment_id" binding:"required"`
-					RazorpaySignature string `json:"razorpay_signature" binding:"required"`
-					OrderID           string `json:"order_id" binding:"required"`
+					IDs    []string `json:"ids" binding:"required"`
+					Action string   `json:"action" binding:"required,oneof=verify unverify block unblock"`
+					Reason string   `json:"reason"`
 				}
 				if err := c.ShouldBindJSON(&body); err != nil {
 					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
-
-				// Verify signature
-				if !paymentService.VerifyPayment(body.RazorpayOrderID, body.RazorpayPaymentID, body.RazorpaySignature) {
-					c.JSON(400, gin.H{"error": "payment verification failed"})
+				ids, err := admin.ParseBulkIDs(body.IDs)
+				if err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
-
-				// Update order status to paid
-				if err := orders.UpdatePaymentStatus(ctx, body.OrderID, "PAID", body.RazorpayPaymentID); err != nil {
-					logger.Error("order payment update failed", zap.Error(err))
-					c.JSON(500, gin.H{"error": "failed to update order"})
+				if body.Action == "block" && strings.TrimSpace(body.Reason) == "" {
+					c.JSON(400, gin.H{"error": "reason is required to block"})
 					return
 				}
 
-				_ = events.LogEvent(ctx, "payment", body.OrderID, "payment_verified", map[string]interface{}{
-					"payment_id": body.RazorpayPaymentID,
+				result := admin.RunBulk(ids, func(id string) error {
+					switch body.Action {
+					case "verify", "unverify":
+						verified := body.Action == "verify"
+						if err := providers.VerifyProvider(ctx, id, verified); err != nil {
+							return err
+						}
+						return events.LogEvent(ctx, "provider", id, events.EventProviderVerified, map[string]interface{}{
+							"admin_id": adminID, "verified": verified, "bulk": true,
+						})
+					case "block":
+						if err := providers.BlockProvider(ctx, id, body.Reason); err != nil {
+							return err
+						}
+						return events.LogEvent(ctx, "provider", id, events.EventProviderBlocked, map[string]interface{}{
+							"admin_id": adminID, "reason": body.Reason, "bulk": true,
+						})
+					default:
+						if err := providers.UnblockProvider(ctx, id); err != nil {
+							return err
+						}
+						return events.LogEvent(ctx, "provider", id, events.EventProviderUnblocked, map[string]interface{}{
+							"admin_id": adminID, "bulk": true,
+						})
+					}
 				})
 
-				c.JSON(200, gin.H{"message": "payment verified", "payment_id": body.RazorpayPaymentID})
+				middleware.AuditDetail(c, "action", body.Action)
+				middleware.AuditDetail(c, "reason", body.Reason)
+				middleware.AuditDetail(c, "result", result)
+				c.JSON(200, result)
 			})
 
-			// Get payment details
-			paymentGroup.GET("/:payment_id", func(c *gin.Context) {
-				paymentID := c.Param("payment_id")
-				details, err := paymentService.GetPaymentDetails(paymentID)
-				if err != nil {
-					c.JSON(500, gin.H{"error": "failed to get payment details"})
-					return
+			// Search users by part of their name or phone, filtered by role=
+			// and blocked=
+			adminGroup.GET("/users", func(c *gin.Context) {
+				f := users.Filter{
+					Query: strings.TrimSpace(c.Query("q")),
+					Role:  c.Query("role"),
 				}
-				c.JSON(200, details)
-			})
-		}
-
-		// ==================== REVIEW ROUTES ====================
-		reviewGroup := v1.Group("/reviews")
-		{
-			// Public: Get reviews by provider
-			reviewGroup.GET("/provider/:provider_id", func(c *gin.Context) {
-				providerID := c.Param("provider_id")
-				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-
-				list, err := reviews.GetReviewsByProvider(ctx, providerID, limit, offset)
-				if err != nil {
-					c.JSON(500, gin.H{"error": "failed to get reviews"})
+				var ok bool
+				if f.Blocked, ok = queryBool(c, "blocked"); !ok {
 					return
 				}
-				c.JSON(200, list)
-			})
+				f.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+				f.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-			// Public: Get review stats for provider
-			reviewGroup.GET("/provider/:provider_id/stats", func(c *gin.Context) {
-				providerID := c.Param("provider_id")
-				stats, err := reviews.GetProviderReviewStats(ctx, providerID)
+				list, total, err := users.SearchUsers(ctx, f)
 				if err != nil {
-					c.JSON(500, gin.H{"error": "failed to get review stats"})
+					logger.Error("admin user search failed", zap.Error(err))
+					c.JSON(500, gin.H{"error": "search failed"})
 					return
 				}
-				c.JSON(200, stats)
+				c.JSON(200, gin.H{"users": list, "total": total})
 			})
 
-			// Protected: Create review
-			reviewGroup.POST("", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
-				userID, _ := middleware.GetUserIDFromContext(c)
+			// Bulk block or unblock users. Blocking needs a reason and takes
+			// an optional until; admins can't block themselves.
+			adminGroup.POST("/users/bulk", func(c *gin.Context) {
+				adminID, _ := middleware.GetUserIDFromContext(c)
 				var body struct {
-					ProviderID string   `json:"provider_id" binding:"required"`
-					OrderID    string   `json:"order_id"`
-					Rating     int      `json:"rating" binding:"required,min=1,max=5"`
-					Comment    string   `json:"comment"`
-					PhotoURLs  []string `json:"photo_urls"`
+					IDs    []string   `json:"ids" binding:"required"`
+					Action string     `json:"action" binding:"required,oneof=block unblock"`
+					Reason string     `json:"reason"`
+					Until  *time.Time `json:"until"`
 				}
 				if err := c.ShouldBindJSON(&body); err != nil {
 					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
-
-				review, err := reviews.CreateReview(ctx, body.ProviderID, userID, body.OrderID, body.Rating, body.Comment, body.PhotoURLs)
+				ids, err := admin.ParseBulkIDs(body.IDs)
 				if err != nil {
-					logger.Error("review creation failed", zap.Error(err))
-					c.JSON(500, gin.H{"error": "review creation failed"})
+					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
+				if body.Action == "block" {
+					if strings.TrimSpace(body.Reason) == "" {
+						c.JSON(400, gin.H{"error": "reason is required to block"})
+						return
+					}
+					if body.Until != nil && !body.Until.After(time.Now()) {
+						c.JSON(400, gin.H{"error": "until must be in the future"})
+						return
+					}
+				}
 
-				_ = events.LogEvent(ctx, "review", review.ID, events.EventReviewCreated, map[string]interface{}{
-					"provider_id": body.ProviderID,
-					"user_id":     userID,
-					"rating":      body.Rating,
+				result := admin.RunBulk(ids, func(id string) error {
+					if body.Action == "unblock" {
+						if err := users.UnblockUser(ctx, id); err != nil {
+							return err
+						}
+						if err := middleware.AllowUser(ctx, id); err != nil {
+							logger.Error("token denylist update failed", zap.String("user_id", id), zap.Error(err))
+						}
+						return events.LogEvent(ctx, "user", id, events.EventUserUnblocked, map[string]interface{}{
+							"admin_id": adminID, "bulk": true,
+						})
+					}
+
+					if id == adminID {
+						return fmt.Errorf("you can't block yourself")
+					}
+					if err := users.BlockUser(ctx, id, body.Reason, body.Until); err != nil {
+						return err
+					}
+					if err := middleware.DenyUser(ctx, id, body.Until); err != nil {
+						logger.Error("token denylist update failed", zap.String("user_id", id), zap.Error(err))
+					}
+					return events.LogEvent(ctx, "user", id, events.EventUserBlocked, map[string]interface{}{
+						"admin_id": adminID, "reason": body.Reason, "until": body.Until, "bulk": true,
+					})
 				})
 
-				c.JSON(201, review)
+				middleware.AuditDetail(c, "action", body.Action)
+				middleware.AuditDetail(c, "reason", body.Reason)
+				middleware.AuditDetail(c, "result", result)
+				c.JSON(200, result)
 			})
 
-			// Protected: Get my reviews
-			reviewGroup.GET("/my", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
-				userID, _ := middleware.GetUserIDFromContext(c)
-				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+			// Review moderation queue: flagged reviews by default, most
+			// reported first; state= lists approved or hidden ones instead
+			adminGroup.GET("/reviews", func(c *gin.Context) {
+				state := c.DefaultQuery("state", models.ReviewFlagged)
+				switch state {
+				case models.ReviewPublished, models.ReviewFlagged, models.ReviewApproved, models.ReviewHidden:
+				default:
+					c.JSON(400, gin.H{"error": "unknown state"})
+					return
+				}
+				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-				list, err := reviews.GetReviewsByUser(ctx, userID, limit, offset)
+				list, total, err := reviews.ModerationQueue(ctx, state, limit, offset)
 				if err != nil {
+					logger.Error("moderation queue failed", zap.Error(err))
 					c.JSON(500, gin.H{"error": "failed to get reviews"})
 					return
 				}
-				c.JSON(200, list)
+				c.JSON(200, gin.H{"reviews": list, "total": total})
 			})
 
-			// Protected: Delete my review
-			reviewGroup.DELETE("/:id", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
-				userID, _ := middleware.GetUserIDFromContext(c)
-				reviewID := c.Param("id")
+			// moderateReview approves, hides or deletes a review and logs it
+			moderateReview := func(reviewID, adminID, action, note string, bulk bool) error {
+				if action == "delete" {
+					review, err := reviews.GetReview(ctx, reviewID)
+					if err != nil {
+						return db.ErrNotFound
+					}
+					if err := reviews.DeleteReviewAdmin(ctx, reviewID); err != nil {
+						return err
+					}
+					rerollRatings(review)
+					return events.LogEvent(ctx, "review", reviewID, events.EventReviewDeleted, map[string]interface{}{
+						"admin_id": adminID, "provider_id": review.ProviderID, "note": note, "bulk": bulk,
+					})
+				}
 
-				if err := reviews.DeleteReview(ctx, reviewID, userID); err != nil {
-					c.JSON(500, gin.H{"error": "deletion failed"})
-					return
+				review, err := reviews.ModerateReview(ctx, reviewID, adminID, action, note)
+				if err != nil {
+					return err
 				}
-				c.JSON(200, gin.H{"message": "deleted"})
-			})
+				rerollRatings(review)
+				return events.LogEvent(ctx, "review", reviewID, events.EventReviewModerated, map[string]interface{}{
+					"admin_id": adminID, "action": action, "state": review.ModerationState, "note": note, "bulk": bulk,
+				})
+			}
 
-			// Admin: Delete any review
-			reviewGroup.DELETE("/:id/admin", middleware.AuthMiddleware(cfg.JwtSecret), middleware.RoleMiddleware("admin"), func(c *gin.Context) {
-				reviewID := c.Param("id")
+			// Approve (keep) or hide a review, or delete it outright. Hidden
+			// reviews drop out of listings and ratings; approving restores one.
+			adminGroup.POST("/reviews/:id/moderate", func(c *gin.Context) {
+				adminID, _ := middleware.GetUserIDFromContext(c)
+				var body struct {
+					Action string `json:"action" binding:"required,oneof=approve hide delete"`
+					Note   string `json:"note"`
+				}
+				if err := c.ShouldBindJSON(&body); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				middleware.AuditDetail(c, "action", body.Action)
 
-				if err := reviews.DeleteReviewAdmin(ctx, reviewID); err != nil {
-					c.JSON(500, gin.H{"error": "deletion failed"})
+				err := moderateReview(c.Param("id"), adminID, body.Action, strings.TrimSpace(body.Note), false)
+				if err == db.ErrNotFound {
+					c.JSON(404, gin.H{"error": "review not found"})
 					return
 				}
-				c.JSON(200, gin.H{"message": "deleted"})
+				if err != nil {
+					logger.Error("review moderation failed", zap.String("review_id", c.Param("id")), zap.Error(err))
+					c.JSON(500, gin.H{"error": "moderation failed"})
+					return
+				}
+				c.JSON(200, gin.H{"message": "review moderated", "action": body.Action})
 			})
-		}
-
-		// ==================== CHAT ROUTES ====================
-		chatGroup := v1.Group("/chat")
-		{
-			// WebSocket endpoint for chat
-			chatGroup.GET("/ws", chatHub.HandleWebSocket)
 
-			// Protected: Create chat for order
-			chatGroup.POST("", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
+			// Bulk approve, hide or delete reviews
+			adminGroup.POST("/reviews/bulk", func(c *gin.Context) {
+				adminID, _ := middleware.GetUserIDFromContext(c)
 				var body struct {
-					OrderID      string   `json:"order_id" binding:"required"`
-					Participants []string `json:"participants" binding:"required"`
+					IDs    []string `json:"ids" binding:"required"`
+					Action string   `json:"action" binding:"required,oneof=approve hide delete"`
+					Note   string   `json:"note"`
 				}
 				if err := c.ShouldBindJSON(&body); err != nil {
 					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
-
-				chatRoom, err := chat.CreateChat(ctx, body.OrderID, body.Participants)
+				ids, err := admin.ParseBulkIDs(body.IDs)
 				if err != nil {
-					c.JSON(500, gin.H{"error": "chat creation failed"})
+					c.JSON(400, gin.H{"error": err.Error()})
 					return
 				}
 
-				_ = events.LogEvent(ctx, "chat", chatRoom.ID, events.EventChatCreated, map[string]interface{}{
-					"order_id":     body.OrderID,
-					"participants": body.Participants,
+				note := strings.TrimSpace(body.Note)
+				result := admin.RunBulk(ids, func(id string) error {
+					err := moderateReview(id, adminID, body.Action, note, true)
+					if err == db.ErrNotFound {
+						return fmt.Errorf("review not found")
+					}
+					return err
 				})
 
-				c.JSON(201, chatRoom)
-			})
-
-			// Get chat by order
-			chatGroup.GET("/order/:order_id", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
-				orderID := c.Param("order_id")
-				chatRoom, err := chat.GetChatByOrder(ctx, orderID)
-				if err != nil {
-					c.JSON(404, gin.H{"error": "chat not found"})
-					return
-				}
-				c.JSON(200, chatRoom)
+				middleware.AuditDetail(c, "action", body.Action)
+				middleware.AuditDetail(c, "result", result)
+				c.JSON(200, result)
 			})
 
-			// Get messages
-			chatGroup.GET("/:id/messages", middleware.AuthMiddleware(cfg.JwtSecret), func(c *gin.Context) {
-				chatID := c.Param("id")
+			// The audit trail of admin actions, newest first; admin_id=
+			// narrows it to one admin
+			adminGroup.GET("/audit", func(c *gin.Context) {
 				limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 				offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-				messages, err := chat.GetMessages(ctx, chatID, limit, offset)
+				var list []*events.Event
+				var err error
+				if adminID := c.Query("admin_id"); adminID != "" {
+					list, err = events.GetEventsByEntity(ctx, "admin", adminID, limit, offset)
+				} else {
+					list, err = events.GetEventsByType(ctx, events.EventAdminAction, limit, offset)
+				}
 				if err != nil {
-					c.JSON(500, gin.H{"error": "failed to get messages"})
+					c.JSON(500, gin.H{"error": "failed to get audit trail"})
 					return
 				}
-				c.JSON(200, messages)
+				c.JSON(200, list)
 			})
 		}
 
 		// ==================== MEDIA ROUTES ====================
+		// canReadDocument allows admins, the uploader and the owner and
+		// managers of the provider whose application the document is on
+		canReadDocument := func(c *gin.Context, objectKey string) bool {
+			if role, _ := middleware.GetRoleFromContext(c); role == models.RoleAdmin {
+				return true
+			}
+			userID, _ := middleware.GetUserIDFromContext(c)
+			if onboarding.ValidObjectKey(objectKey, userID) {
+				return true
+			}
+			providerID, err := onboarding.DocumentProvider(ctx, objectKey)
+			if err != nil {
+				return false
+			}
+			role, err := providers.OutletRole(ctx, userID, providerID)
+			return err == nil && providers.CanManageOutlet(role)
+		}
+
 		if mediaClient != nil {
 			mediaGroup := v1.Group("/media")
 			mediaGroup.Use(middleware.AuthMiddleware(cfg.JwtSecret))
@@ -1363,9 +4463,15 @@ func main() {
 						c.JSON(400, gin.H{"error": err.Error()})
 						return
 					}
+					// Application documents go under the uploader's own prefix
+					folder := body.Folder
+					if onboarding.IsDocumentKey(folder) {
+						userID, _ := middleware.GetUserIDFromContext(c)
+						folder = onboarding.UserDocumentFolder(userID)
+					}
 
 					resp, err := mediaClient.GenerateUploadURL(ctx, media.UploadURLRequest{
-						Folder:      body.Folder,
+						Folder:      folder,
 						ContentType: body.ContentType,
 						FileName:    body.FileName,
 					})
@@ -1385,6 +4491,10 @@ func main() {
 						c.JSON(400, gin.H{"error": err.Error()})
 						return
 					}
+					if onboarding.IsDocumentKey(body.ObjectKey) && !canReadDocument(c, body.ObjectKey) {
+						c.JSON(403, gin.H{"error": "not your document"})
+						return
+					}
 
 					resp, err := mediaClient.GenerateGetURL(ctx, media.GetURLRequest{
 						ObjectKey: body.ObjectKey,
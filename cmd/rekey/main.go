@@ -0,0 +1,163 @@
+// Command rekey rotates the keys protecting encrypted PII columns.
+//
+// To rotate the master key, generate a new one, add it to PII_KEYS, point
+// PII_ACTIVE_KEY_ID at it, deploy, and re-wrap the stored values:
+//
+//	go run ./cmd/rekey -generate
+//	go run ./cmd/rekey
+//
+// Once it reports nothing left to re-wrap, the old key can be removed from
+// PII_KEYS. After changing PII_HASH_KEY (keeping the old master keys), run
+// with -rehash to recompute the duplicate-detection hashes.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"jainfood/internal/db"
+	"jainfood/internal/pii"
+	"jainfood/internal/util"
+)
+
+func main() {
+	generate := flag.Bool("generate", false, "print a new random key and exit")
+	rehash := flag.Bool("rehash", false, "also recompute keyed hashes")
+	dryRun := flag.Bool("dry-run", false, "count the values that would change without writing")
+	batch := flag.Int("batch", 500, "rows per batch")
+	flag.Parse()
+
+	if *generate {
+		key, err := pii.GenerateKey()
+		if err != nil {
+			fail("failed to generate key", err)
+		}
+		fmt.Println(key)
+		return
+	}
+
+	_ = godotenv.Load()
+	cfg := util.Load()
+	keys, err := pii.NewKeyring(cfg.PIIKeys, cfg.PIIActiveKeyID, cfg.PIIHashKey)
+	if err != nil {
+		fail("invalid PII key configuration", err)
+	}
+
+	ctx := context.Background()
+	if err := db.Connect(ctx, cfg.DatabaseURL); err != nil {
+		fail("failed to connect to database", err)
+	}
+	defer db.Close()
+
+	for _, col := range pii.Columns {
+		n, err := rekeyColumn(ctx, keys, col, *rehash, *dryRun, *batch)
+		if err != nil {
+			fail(fmt.Sprintf("%s.%s", col.Table, col.Cipher), err)
+		}
+		verb := "updated"
+		if *dryRun {
+			verb = "to update"
+		}
+		fmt.Printf("%s.%s: %d %s\n", col.Table, col.Cipher, n, verb)
+	}
+}
+
+// rekeyColumn re-wraps (and optionally rehashes) every value in col, in
+// batches ordered by id, and returns how many rows changed. Rows are paged
+// and updated by their primary key (id, plus the partition column on
+// partitioned tables) so each batch is an index scan.
+func rekeyColumn(ctx context.Context, keys *pii.Keyring, col pii.Column, rehash, dryRun bool, batch int) (int, error) {
+	hashCol := col.Hash
+	if hashCol == "" {
+		hashCol = "NULL"
+	}
+	partCol := col.Partition
+	if partCol == "" {
+		partCol = "NULL::timestamptz"
+	}
+	changed, after := 0, uuid.Nil.String()
+	for {
+		rows, err := db.Pool.Query(ctx, fmt.Sprintf(`
+			SELECT id::text, %[4]s, %[2]s, COALESCE(%[3]s::text, '') FROM %[1]s
+			WHERE %[2]s IS NOT NULL AND id > $1
+			ORDER BY id LIMIT $2
+		`, col.Table, col.Cipher, hashCol, partCol), after, batch)
+		if err != nil {
+			return changed, err
+		}
+		type update struct {
+			id     string
+			part   *time.Time
+			cipher string
+			hash   string
+		}
+		var updates []update
+		n := 0
+		for rows.Next() {
+			var id, cipher, hash string
+			var part *time.Time
+			if err := rows.Scan(&id, &part, &cipher, &hash); err != nil {
+				rows.Close()
+				return changed, err
+			}
+			n++
+			after = id
+
+			out, rewrapped, err := keys.Rewrap(cipher)
+			if err != nil {
+				rows.Close()
+				return changed, fmt.Errorf("row %s: %w", id, err)
+			}
+			newHash := hash
+			if rehash && col.Hash != "" {
+				plain, err := keys.Decrypt(cipher)
+				if err != nil {
+					rows.Close()
+					return changed, fmt.Errorf("row %s: %w", id, err)
+				}
+				newHash = keys.Hash(plain)
+			}
+			if rewrapped || newHash != hash {
+				updates = append(updates, update{id, part, out, newHash})
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return changed, err
+		}
+
+		if !dryRun {
+			for _, u := range updates {
+				set := col.Cipher + " = $2"
+				args := []interface{}{u.id, u.cipher}
+				if col.Hash != "" {
+					args = append(args, u.hash)
+					set += fmt.Sprintf(", %s = NULLIF($%d, '')", col.Hash, len(args))
+				}
+				where := "id = $1"
+				if col.Partition != "" {
+					args = append(args, u.part)
+					where += fmt.Sprintf(" AND %s = $%d", col.Partition, len(args))
+				}
+				sql := fmt.Sprintf(`UPDATE %s SET %s WHERE %s`, col.Table, set, where)
+				if _, err := db.Pool.Exec(ctx, sql, args...); err != nil {
+					return changed, fmt.Errorf("row %s: %w", u.id, err)
+				}
+			}
+		}
+		changed += len(updates)
+		if n < batch {
+			return changed, nil
+		}
+	}
+}
+
+func fail(msg string, err error) {
+	fmt.Fprintln(os.Stderr, msg+":", err)
+	os.Exit(1)
+}
@@ -0,0 +1,21 @@
+// Command vapidkeys prints a new VAPID key pair for Web Push.
+//
+//	go run ./cmd/vapidkeys
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"jainfood/internal/push"
+)
+
+func main() {
+	publicKey, privateKey, err := push.GenerateVAPIDKeys()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to generate VAPID keys:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("VAPID_PUBLIC_KEY=%s\n", publicKey)
+	fmt.Printf("VAPID_PRIVATE_KEY=%s\n", privateKey)
+}
@@ -0,0 +1,102 @@
+// Command worker runs the API's background work in a process of its own: it
+// consumes the notification queue and keeps the analytics rollups current.
+//
+// Run it when the API is started with QUEUE_WORKERS=0 or
+// ANALYTICS_ROLLUP_INTERVAL_SECONDS=0, so OTPs and order updates are still
+// sent and provider analytics don't freeze:
+//
+//	go run ./cmd/worker -workers 4 -rollup-interval 5m
+//
+// -rollup-interval 0 leaves rollups to the API. The worker reads the same
+// environment as the API and stops cleanly on SIGINT or SIGTERM, leaving
+// unfinished jobs to be reclaimed.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+
+	"jainfood/internal/analytics"
+	"jainfood/internal/db"
+	"jainfood/internal/hours"
+	"jainfood/internal/models"
+	"jainfood/internal/notify"
+	"jainfood/internal/queue"
+	"jainfood/internal/redisclient"
+	"jainfood/internal/users"
+	"jainfood/internal/util"
+)
+
+func main() {
+	workers := flag.Int("workers", 4, "concurrent notification workers")
+	rollupInterval := flag.Duration("rollup-interval", 5*time.Minute, "time between analytics rollups (0 leaves them to the API)")
+	flag.Parse()
+
+	_ = godotenv.Load()
+	cfg := util.Load()
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("failed to create logger: %v", err)
+	}
+	defer func() { _ = logger.Sync() }()
+
+	if *workers < 1 {
+		logger.Fatal("-workers must be at least 1")
+	}
+	if *rollupInterval < 0 {
+		logger.Fatal("-rollup-interval must not be negative")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Preferred channels and WhatsApp deliveries live in the database
+	if err := db.Connect(ctx, cfg.DatabaseURL); err != nil {
+		logger.Fatal("db connect failed", zap.Error(err))
+	}
+	defer db.Close()
+
+	redisConnectCtx, cancelRedisConnect := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelRedisConnect()
+	if cfg.RedisURL != "" {
+		err = redisclient.ConnectWithURL(redisConnectCtx, cfg.RedisURL)
+	} else {
+		err = redisclient.Connect(redisConnectCtx, cfg.RedisAddr)
+	}
+	if err != nil {
+		logger.Fatal("redis connect failed", zap.Error(err))
+	}
+
+	// Send exactly as the API's in-process workers do
+	whatsappNotifier := notify.NewWhatsAppNotifierFromEnv()
+	if whatsappNotifier != nil {
+		whatsappNotifier.OnSent = func(messageID, recipient, template string) {
+			if err := notify.RecordDelivery(ctx, models.ChannelWhatsApp, messageID, recipient, template); err != nil {
+				logger.Warn("failed to record whatsapp delivery", zap.Error(err))
+			}
+		}
+		logger.Info("whatsapp notifications enabled")
+	}
+	notifier := notify.NewChannelRouter(notify.NewNotifier(), whatsappNotifier, users.GetPreferredChannelByPhone)
+
+	if *rollupInterval > 0 {
+		go analytics.NewRoller(hours.LoadLocation(cfg.AnalyticsTimezone), *rollupInterval, logger).Run(ctx)
+		logger.Info("analytics rollups started", zap.Duration("interval", *rollupInterval))
+	}
+
+	notifyQueue := queue.New("notify", cfg.QueueVisibility)
+	notifyWorker := queue.NewWorker(notifyQueue, *workers, logger)
+	notify.RegisterHandlers(notifyWorker, notifier)
+
+	logger.Info("notification worker started", zap.String("queue", notifyQueue.Name), zap.Int("workers", *workers))
+	notifyWorker.Run(ctx)
+	logger.Info("notification worker stopped")
+}
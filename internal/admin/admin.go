@@ -0,0 +1,62 @@
+// Package admin backs the admin console: platform KPIs and the bulk
+// actions admins apply to providers, users and reviews.
+package admin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxBulkIDs caps how many records one bulk action may touch.
+const MaxBulkIDs = 100
+
+// ParseBulkIDs trims and de-duplicates the ids of a bulk action, keeping
+// their order. It rejects an empty list, blank ids and lists over MaxBulkIDs.
+func ParseBulkIDs(ids []string) ([]string, error) {
+	seen := map[string]bool{}
+	var out []string
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			return nil, fmt.Errorf("ids must not be blank")
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("ids is required")
+	}
+	if len(out) > MaxBulkIDs {
+		return nil, fmt.Errorf("at most %d ids per request", MaxBulkIDs)
+	}
+	return out, nil
+}
+
+// BulkResult reports which records a bulk action succeeded or failed on.
+type BulkResult struct {
+	Succeeded []string      `json:"succeeded"`
+	Failed    []BulkFailure `json:"failed"`
+}
+
+// BulkFailure is a record a bulk action failed on, and why.
+type BulkFailure struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// RunBulk applies fn to each id in turn. A failure doesn't stop the rest,
+// so the result may be partial.
+func RunBulk(ids []string, fn func(id string) error) *BulkResult {
+	r := &BulkResult{Succeeded: []string{}, Failed: []BulkFailure{}}
+	for _, id := range ids {
+		if err := fn(id); err != nil {
+			r.Failed = append(r.Failed, BulkFailure{ID: id, Error: err.Error()})
+			continue
+		}
+		r.Succeeded = append(r.Succeeded, id)
+	}
+	return r
+}
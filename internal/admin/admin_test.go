@@ -0,0 +1,45 @@
+package admin
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestParseBulkIDs(t *testing.T) {
+	ids, err := ParseBulkIDs([]string{" a", "b", "a ", "c"})
+	if err != nil || !reflect.DeepEqual(ids, []string{"a", "b", "c"}) {
+		t.Errorf("ParseBulkIDs = %v, %v", ids, err)
+	}
+
+	tooMany := make([]string, MaxBulkIDs+1)
+	for i := range tooMany {
+		tooMany[i] = strconv.Itoa(i)
+	}
+	for _, bad := range [][]string{nil, {}, {"a", " "}, tooMany} {
+		if _, err := ParseBulkIDs(bad); err == nil {
+			t.Errorf("ParseBulkIDs(%d ids) succeeded", len(bad))
+		}
+	}
+}
+
+func TestRunBulk(t *testing.T) {
+	var seen []string
+	r := RunBulk([]string{"a", "b", "c"}, func(id string) error {
+		seen = append(seen, id)
+		if id == "b" {
+			return errors.New("provider not found")
+		}
+		return nil
+	})
+	if !reflect.DeepEqual(seen, []string{"a", "b", "c"}) {
+		t.Errorf("applied to %v", seen)
+	}
+	if !reflect.DeepEqual(r.Succeeded, []string{"a", "c"}) {
+		t.Errorf("Succeeded = %v", r.Succeeded)
+	}
+	if want := []BulkFailure{{ID: "b", Error: "provider not found"}}; !reflect.DeepEqual(r.Failed, want) {
+		t.Errorf("Failed = %v", r.Failed)
+	}
+}
@@ -0,0 +1,98 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"jainfood/internal/analytics"
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+	"jainfood/internal/users"
+)
+
+// KPIs computes the platform figures for the local days in q. Orders, GMV
+// and active providers come from the providers' daily rollups, so they are
+// as fresh as the last rollup; signups and the moderation counts are live.
+func KPIs(ctx context.Context, q analytics.Query, loc *time.Location) (*models.AdminKPIs, error) {
+	k := &models.AdminKPIs{
+		From:                  q.From.Format(time.DateOnly),
+		To:                    q.To.Format(time.DateOnly),
+		SignupsByRole:         map[string]int{},
+		ActiveProvidersByCity: []models.CityCount{},
+	}
+	start := time.Date(q.From.Year(), q.From.Month(), q.From.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(q.To.Year(), q.To.Month(), q.To.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT role, COUNT(*) FROM users
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY role
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var role string
+		var n int
+		if err := rows.Scan(&role, &n); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		k.SignupsByRole[role] = n
+		k.Signups += n
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(orders), 0)::int, COALESCE(SUM(revenue), 0)::float8,
+		       COUNT(DISTINCT provider_id) FILTER (WHERE billable_orders > 0)
+		FROM provider_daily_stats
+		WHERE day BETWEEN $1 AND $2
+	`, q.From, q.To).Scan(&k.Orders, &k.GMV, &k.ActiveProviders); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Pool.Query(ctx, `
+		SELECT COALESCE(pc.city, 'Unknown'), COUNT(DISTINCT s.provider_id)
+		FROM provider_daily_stats s
+		JOIN providers p ON p.id = s.provider_id
+		LEFT JOIN pin_codes pc ON pc.pin = p.pin_code
+		WHERE s.day BETWEEN $1 AND $2 AND s.billable_orders > 0
+		GROUP BY 1
+		ORDER BY 2 DESC, 1
+	`, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var c models.CityCount
+		if err := rows.Scan(&c.City, &c.Count); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		k.ActiveProvidersByCity = append(k.ActiveProvidersByCity, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM users),
+		       (SELECT COUNT(*) FROM users u WHERE `+users.BlockedSQL+`),
+		       (SELECT COUNT(*) FROM provider_applications WHERE state IN ($1, $2)),
+		       (SELECT COUNT(*) FROM reviews WHERE moderation_state = $3)
+	`, models.ApplicationSubmitted, models.ApplicationUnderReview, models.ReviewFlagged).Scan(
+		&k.Users, &k.BlockedUsers, &k.PendingApplications, &k.FlaggedReviews,
+	); err != nil {
+		return nil, err
+	}
+
+	if at, err := analytics.RolledUpTo(ctx); err == nil && !at.IsZero() {
+		k.UpdatedAt = &at
+	}
+	return k, nil
+}
@@ -0,0 +1,257 @@
+// Package analytics reports on providers' orders and ratings. Reports are
+// built from daily rollups, which a background Roller rebuilds whenever a
+// day's orders or reviews change, so they stay fast as order partitions grow.
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"jainfood/internal/models"
+)
+
+// Report granularities.
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week" // Weeks start on Monday
+	GranularityMonth = "month"
+)
+
+// Limits on reports.
+const (
+	DefaultRangeDays = 30
+	MaxRangeDays     = 366
+	TopItemsLimit    = 10
+)
+
+// Query is a validated report request. From and To are inclusive local days,
+// as UTC midnights.
+type Query struct {
+	From        time.Time
+	To          time.Time
+	Granularity string
+}
+
+// ParseQuery validates from and to (YYYY-MM-DD, inclusive) and granularity.
+// To defaults to today and from to DefaultRangeDays before it; granularity
+// defaults to days.
+func ParseQuery(from, to, granularity string, today time.Time) (Query, error) {
+	q := Query{To: date(today), Granularity: granularity}
+	if q.Granularity == "" {
+		q.Granularity = GranularityDay
+	}
+	if q.Granularity != GranularityDay && q.Granularity != GranularityWeek && q.Granularity != GranularityMonth {
+		return q, fmt.Errorf("granularity must be %s, %s or %s", GranularityDay, GranularityWeek, GranularityMonth)
+	}
+
+	var err error
+	if to != "" {
+		if q.To, err = time.Parse(time.DateOnly, to); err != nil {
+			return q, fmt.Errorf("to must be YYYY-MM-DD")
+		}
+	}
+	q.From = q.To.AddDate(0, 0, -(DefaultRangeDays - 1))
+	if from != "" {
+		if q.From, err = time.Parse(time.DateOnly, from); err != nil {
+			return q, fmt.Errorf("from must be YYYY-MM-DD")
+		}
+	}
+
+	if q.From.After(q.To) {
+		return q, fmt.Errorf("from must not be after to")
+	}
+	if days := int(q.To.Sub(q.From).Hours()/24) + 1; days > MaxRangeDays {
+		return q, fmt.Errorf("range can be at most %d days", MaxRangeDays)
+	}
+	return q, nil
+}
+
+// date returns t's calendar day (in t's location) as a UTC midnight.
+func date(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Day is a provider's rollup for one local day.
+type Day struct {
+	Date           time.Time // UTC midnight
+	Orders         int
+	OrdersByStatus map[string]int
+	BillableOrders int
+	Revenue        float64
+	HourlyOrders   [24]int
+	Ratings        int
+	RatingSum      int
+}
+
+// Billable reports whether an order in status counts towards revenue, top
+// items and customers: it was confirmed and not cancelled.
+func Billable(status string) bool {
+	switch status {
+	case models.OrderStatusConfirmed, models.OrderStatusReady, models.OrderStatusCompleted:
+		return true
+	}
+	return false
+}
+
+// OrderRow is an order as the roller reads it.
+type OrderRow struct {
+	BuyerID   string
+	Status    string
+	Total     float64
+	Items     []models.OrderItem
+	CreatedAt time.Time
+}
+
+// RollupDay aggregates a provider's orders placed on day (in loc) into the
+// day's rollup, the items sold and the number of orders per buyer. Ratings
+// are filled in by the caller.
+func RollupDay(day time.Time, orders []OrderRow, loc *time.Location) (*Day, map[string]*models.TopItem, map[string]int) {
+	d := &Day{Date: date(day), OrdersByStatus: map[string]int{}}
+	items := map[string]*models.TopItem{}
+	buyers := map[string]int{}
+
+	for _, o := range orders {
+		d.Orders++
+		d.OrdersByStatus[o.Status]++
+		d.HourlyOrders[o.CreatedAt.In(loc).Hour()]++
+		if !Billable(o.Status) {
+			continue
+		}
+		d.BillableOrders++
+		d.Revenue += o.Total
+		if o.BuyerID != "" {
+			buyers[o.BuyerID]++
+		}
+		for _, it := range o.Items {
+			if it.ItemID == "" || it.Quantity <= 0 {
+				continue
+			}
+			item, ok := items[it.ItemID]
+			if !ok {
+				item = &models.TopItem{ItemID: it.ItemID}
+				items[it.ItemID] = item
+			}
+			if it.Name != "" {
+				item.Name = it.Name
+			}
+			item.Quantity += it.Quantity
+			item.Revenue += float64(it.Quantity) * it.Price
+		}
+	}
+	return d, items, buyers
+}
+
+// PeriodStart returns the first day of the period containing day.
+func PeriodStart(day time.Time, granularity string) time.Time {
+	day = date(day)
+	switch granularity {
+	case GranularityWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case GranularityMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day
+}
+
+// nextPeriod returns the first day of the period after the one starting on start.
+func nextPeriod(start time.Time, granularity string) time.Time {
+	switch granularity {
+	case GranularityWeek:
+		return start.AddDate(0, 0, 7)
+	case GranularityMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// Summarise builds a report's totals, series and peak hours from the daily
+// rollups in q's range. Days without a rollup count as empty, and the series
+// has an entry for every period, with the first one starting at its period's
+// start even if that is before q.From.
+func Summarise(q Query, days []*Day) *models.ProviderAnalytics {
+	r := &models.ProviderAnalytics{
+		From:           q.From.Format(time.DateOnly),
+		To:             q.To.Format(time.DateOnly),
+		Granularity:    q.Granularity,
+		OrdersByStatus: map[string]int{},
+		Series:         []models.AnalyticsPeriod{},
+		TopItems:       []models.TopItem{},
+		PeakHours:      []models.HourlyOrders{},
+	}
+
+	type bucket struct {
+		orders, billable, ratings, ratingSum int
+		revenue                              float64
+	}
+	buckets := map[time.Time]*bucket{}
+	var hourly [24]int
+	var billable, ratingSum int
+
+	for _, d := range days {
+		if d.Date.Before(q.From) || d.Date.After(q.To) {
+			continue
+		}
+		start := PeriodStart(d.Date, q.Granularity)
+		b := buckets[start]
+		if b == nil {
+			b = &bucket{}
+			buckets[start] = b
+		}
+		b.orders += d.Orders
+		b.billable += d.BillableOrders
+		b.revenue += d.Revenue
+		b.ratings += d.Ratings
+		b.ratingSum += d.RatingSum
+
+		r.Orders += d.Orders
+		r.Revenue += d.Revenue
+		r.Ratings += d.Ratings
+		billable += d.BillableOrders
+		ratingSum += d.RatingSum
+		for status, n := range d.OrdersByStatus {
+			r.OrdersByStatus[status] += n
+		}
+		for h, n := range d.HourlyOrders {
+			hourly[h] += n
+		}
+	}
+
+	for start := PeriodStart(q.From, q.Granularity); !start.After(q.To); start = nextPeriod(start, q.Granularity) {
+		p := models.AnalyticsPeriod{Period: start.Format(time.DateOnly)}
+		if b := buckets[start]; b != nil {
+			p.Orders = b.orders
+			p.Revenue = round2(b.revenue)
+			p.AverageOrderValue = ratio(b.revenue, b.billable)
+			p.Ratings = b.ratings
+			p.AverageRating = ratio(float64(b.ratingSum), b.ratings)
+		}
+		r.Series = append(r.Series, p)
+	}
+
+	r.Revenue = round2(r.Revenue)
+	r.AverageOrderValue = ratio(r.Revenue, billable)
+	r.AverageRating = ratio(float64(ratingSum), r.Ratings)
+
+	for h, n := range hourly {
+		if n > 0 {
+			r.PeakHours = append(r.PeakHours, models.HourlyOrders{Hour: h, Orders: n})
+		}
+	}
+	sort.SliceStable(r.PeakHours, func(i, j int) bool { return r.PeakHours[i].Orders > r.PeakHours[j].Orders })
+	return r
+}
+
+// ratio returns n/d rounded to two places, or 0 when d is 0.
+func ratio(n float64, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return round2(n / float64(d))
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
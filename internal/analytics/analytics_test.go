@@ -0,0 +1,140 @@
+package analytics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"jainfood/internal/models"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseQuery(t *testing.T) {
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	// 23:00 UTC on 9 March is already 10 March in India
+	today := time.Date(2026, 3, 9, 23, 0, 0, 0, time.UTC).In(ist)
+
+	q, err := ParseQuery("", "", "", today)
+	if err != nil {
+		t.Fatalf("ParseQuery: %v", err)
+	}
+	if !q.To.Equal(day("2026-03-10")) || !q.From.Equal(day("2026-02-09")) || q.Granularity != GranularityDay {
+		t.Errorf("defaults = %v .. %v %s", q.From, q.To, q.Granularity)
+	}
+
+	q, err = ParseQuery("2026-01-01", "2026-01-31", GranularityWeek, today)
+	if err != nil || !q.From.Equal(day("2026-01-01")) || !q.To.Equal(day("2026-01-31")) {
+		t.Errorf("explicit range = %+v, %v", q, err)
+	}
+
+	for _, c := range [][3]string{
+		{"", "", "year"},
+		{"01-01-2026", "", ""},
+		{"", "2026-13-01", ""},
+		{"2026-02-01", "2026-01-01", ""},
+		{"2025-01-01", "2026-01-02", ""},
+	} {
+		if _, err := ParseQuery(c[0], c[1], c[2], today); err == nil {
+			t.Errorf("ParseQuery(%q, %q, %q) succeeded", c[0], c[1], c[2])
+		}
+	}
+}
+
+func TestRollupDay(t *testing.T) {
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	at := func(h int) time.Time { return time.Date(2026, 3, 10, h, 15, 0, 0, ist) }
+	thali := models.OrderItem{ItemID: "thali", Name: "Jain Thali", Quantity: 2, Price: 150}
+	orders := []OrderRow{
+		{BuyerID: "a", Status: models.OrderStatusCompleted, Total: 320, Items: []models.OrderItem{thali, {ItemID: "chaas", Name: "Chaas", Quantity: 1, Price: 20}}, CreatedAt: at(12)},
+		{BuyerID: "a", Status: models.OrderStatusConfirmed, Total: 300, Items: []models.OrderItem{thali}, CreatedAt: at(12)},
+		{BuyerID: "b", Status: models.OrderStatusCancelled, Total: 150, Items: []models.OrderItem{thali}, CreatedAt: at(19)},
+		{BuyerID: "c", Status: models.OrderStatusCreated, Total: 150, CreatedAt: at(19).UTC()},
+	}
+
+	d, items, buyers := RollupDay(day("2026-03-10"), orders, ist)
+	if d.Orders != 4 || d.BillableOrders != 2 || d.Revenue != 620 {
+		t.Errorf("day = %+v", d)
+	}
+	if want := map[string]int{"COMPLETED": 1, "CONFIRMED": 1, "CANCELLED": 1, "CREATED": 1}; !reflect.DeepEqual(d.OrdersByStatus, want) {
+		t.Errorf("OrdersByStatus = %v", d.OrdersByStatus)
+	}
+	if d.HourlyOrders[12] != 2 || d.HourlyOrders[19] != 2 {
+		t.Errorf("HourlyOrders = %v", d.HourlyOrders)
+	}
+	if got := items["thali"]; got == nil || got.Quantity != 4 || got.Revenue != 600 || got.Name != "Jain Thali" {
+		t.Errorf("thali = %+v", got)
+	}
+	if len(items) != 2 {
+		t.Errorf("items = %v", items)
+	}
+	if !reflect.DeepEqual(buyers, map[string]int{"a": 2}) {
+		t.Errorf("buyers = %v", buyers)
+	}
+}
+
+func TestPeriodStart(t *testing.T) {
+	cases := []struct{ day, granularity, want string }{
+		{"2026-03-11", GranularityDay, "2026-03-11"},
+		{"2026-03-11", GranularityWeek, "2026-03-09"}, // Wednesday -> Monday
+		{"2026-03-15", GranularityWeek, "2026-03-09"}, // Sunday
+		{"2026-03-09", GranularityWeek, "2026-03-09"},
+		{"2026-03-31", GranularityMonth, "2026-03-01"},
+	}
+	for _, c := range cases {
+		if got := PeriodStart(day(c.day), c.granularity).Format(time.DateOnly); got != c.want {
+			t.Errorf("PeriodStart(%s, %s) = %s, want %s", c.day, c.granularity, got, c.want)
+		}
+	}
+}
+
+func TestSummarise(t *testing.T) {
+	q := Query{From: day("2026-03-01"), To: day("2026-03-16"), Granularity: GranularityWeek}
+	d1 := &Day{Date: day("2026-03-02"), Orders: 3, BillableOrders: 2, Revenue: 500, Ratings: 2, RatingSum: 9,
+		OrdersByStatus: map[string]int{"COMPLETED": 2, "CANCELLED": 1}}
+	d1.HourlyOrders[12] = 2
+	d1.HourlyOrders[20] = 1
+	d2 := &Day{Date: day("2026-03-16"), Orders: 1, BillableOrders: 1, Revenue: 100.5,
+		OrdersByStatus: map[string]int{"CONFIRMED": 1}}
+	d2.HourlyOrders[20] = 1
+	outside := &Day{Date: day("2026-02-28"), Orders: 50, Revenue: 1e4}
+
+	r := Summarise(q, []*Day{outside, d1, d2})
+	if r.Orders != 4 || r.Revenue != 600.5 || r.AverageOrderValue != 200.17 || r.Ratings != 2 || r.AverageRating != 4.5 {
+		t.Errorf("totals = %+v", r)
+	}
+	if !reflect.DeepEqual(r.OrdersByStatus, map[string]int{"COMPLETED": 2, "CANCELLED": 1, "CONFIRMED": 1}) {
+		t.Errorf("OrdersByStatus = %v", r.OrdersByStatus)
+	}
+
+	// Weeks from Monday 23 Feb (containing 1 March) to Monday 16 March
+	var periods []string
+	for _, p := range r.Series {
+		periods = append(periods, p.Period)
+	}
+	if want := []string{"2026-02-23", "2026-03-02", "2026-03-09", "2026-03-16"}; !reflect.DeepEqual(periods, want) {
+		t.Errorf("periods = %v, want %v", periods, want)
+	}
+	if p := r.Series[1]; p.Orders != 3 || p.Revenue != 500 || p.AverageOrderValue != 250 || p.AverageRating != 4.5 {
+		t.Errorf("week of 2 March = %+v", p)
+	}
+	if p := r.Series[0]; p.Orders != 0 {
+		t.Errorf("week of 23 Feb = %+v", p)
+	}
+
+	want := []models.HourlyOrders{{Hour: 12, Orders: 2}, {Hour: 20, Orders: 2}}
+	if !reflect.DeepEqual(r.PeakHours, want) {
+		t.Errorf("PeakHours = %v, want %v", r.PeakHours, want)
+	}
+
+	empty := Summarise(Query{From: day("2026-03-01"), To: day("2026-03-01"), Granularity: GranularityDay}, nil)
+	if empty.AverageOrderValue != 0 || len(empty.Series) != 1 || empty.PeakHours == nil {
+		t.Errorf("empty report = %+v", empty)
+	}
+}
@@ -0,0 +1,91 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"jainfood/internal/monitoring"
+)
+
+// Roller keeps the daily rollups current. Each run rebuilds every provider
+// day with orders or reviews changed since the last run.
+type Roller struct {
+	Location *time.Location
+	Interval time.Duration
+	// Overlap re-scans the end of the previous run so changes committed
+	// while it ran aren't missed.
+	Overlap time.Duration
+	logger  *zap.Logger
+}
+
+// NewRoller creates a roller that counts days in loc and runs every interval.
+func NewRoller(loc *time.Location, interval time.Duration, logger *zap.Logger) *Roller {
+	return &Roller{
+		Location: loc,
+		Interval: interval,
+		Overlap:  time.Minute,
+		logger:   logger,
+	}
+}
+
+// Run rolls up changes straight away and then every Interval until ctx is
+// cancelled. The first run on a new database backfills every day.
+func (r *Roller) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.RollUp(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Error("analytics rollup failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RollUp rebuilds the provider days changed since the watermark and moves
+// it forward. A failed day leaves the watermark alone so the next run
+// retries it.
+func (r *Roller) RollUp(ctx context.Context) error {
+	since, err := watermark(ctx)
+	if err != nil {
+		return err
+	}
+	started := time.Now()
+
+	days, err := changedDays(ctx, since, r.Location)
+	if err != nil {
+		return err
+	}
+	var failed int
+	for _, pd := range days {
+		if err := RollupProviderDay(ctx, pd.ProviderID, pd.Day, r.Location); err != nil {
+			failed++
+			r.logger.Warn("analytics rollup: day failed",
+				zap.String("provider_id", pd.ProviderID),
+				zap.String("day", pd.Day.Format(time.DateOnly)),
+				zap.Error(err))
+		}
+	}
+
+	m := monitoring.GetMetrics()
+	m.IncrCustom("analytics_rollups")
+	if len(days) > 0 {
+		r.logger.Info("analytics rolled up",
+			zap.Int("days", len(days)),
+			zap.Int("failed", failed),
+			zap.Duration("took", time.Since(started)))
+	}
+	if failed > 0 {
+		return nil
+	}
+	next := started.Add(-r.Overlap)
+	if next.Before(since) {
+		return nil
+	}
+	return setWatermark(ctx, next)
+}
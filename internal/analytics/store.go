@@ -0,0 +1,246 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+)
+
+// rollupJob names the daily provider rollup in rollup_watermarks.
+const rollupJob = "provider_daily"
+
+// Report builds a provider's analytics report for q from the daily rollups.
+func Report(ctx context.Context, providerID string, q Query, loc *time.Location) (*models.ProviderAnalytics, error) {
+	days, err := loadDays(ctx, providerID, q)
+	if err != nil {
+		return nil, err
+	}
+	r := Summarise(q, days)
+	r.ProviderID = providerID
+	r.Timezone = loc.String()
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT item_id, MAX(name), SUM(quantity)::int, SUM(revenue)::float8
+		FROM provider_daily_items
+		WHERE provider_id = $1 AND day BETWEEN $2 AND $3
+		GROUP BY item_id
+		ORDER BY SUM(quantity) DESC, SUM(revenue) DESC, item_id
+		LIMIT $4
+	`, providerID, q.From, q.To, TopItemsLimit)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var it models.TopItem
+		if err := rows.Scan(&it.ItemID, &it.Name, &it.Quantity, &it.Revenue); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		it.Revenue = round2(it.Revenue)
+		r.TopItems = append(r.TopItems, it)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE orders > 1)
+		FROM (
+			SELECT buyer_id, SUM(orders) AS orders
+			FROM provider_daily_buyers
+			WHERE provider_id = $1 AND day BETWEEN $2 AND $3
+			GROUP BY buyer_id
+		) b
+	`, providerID, q.From, q.To).Scan(&r.Customers, &r.RepeatCustomers); err != nil {
+		return nil, err
+	}
+	r.RepeatCustomerRate = ratio(float64(r.RepeatCustomers), r.Customers)
+
+	if at, err := watermark(ctx); err == nil && !at.IsZero() {
+		r.UpdatedAt = &at
+	}
+	return r, nil
+}
+
+func loadDays(ctx context.Context, providerID string, q Query) ([]*Day, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT day, orders, orders_by_status, billable_orders, revenue::float8, hourly_orders, ratings, rating_sum
+		FROM provider_daily_stats
+		WHERE provider_id = $1 AND day BETWEEN $2 AND $3
+		ORDER BY day
+	`, providerID, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []*Day
+	for rows.Next() {
+		d := &Day{}
+		var hourly []int32
+		if err := rows.Scan(&d.Date, &d.Orders, &d.OrdersByStatus, &d.BillableOrders, &d.Revenue, &hourly, &d.Ratings, &d.RatingSum); err != nil {
+			return nil, err
+		}
+		for h := 0; h < len(hourly) && h < 24; h++ {
+			d.HourlyOrders[h] = int(hourly[h])
+		}
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
+
+// watermark returns the time up to which changes have been rolled up, or
+// the zero time before the first run.
+func watermark(ctx context.Context) (time.Time, error) {
+	var at time.Time
+	err := db.Pool.QueryRow(ctx, `SELECT rolled_up_to FROM rollup_watermarks WHERE job = $1`, rollupJob).Scan(&at)
+	if err == pgx.ErrNoRows {
+		return time.Time{}, nil
+	}
+	return at, err
+}
+
+// RolledUpTo returns the time up to which the rollups are current, or the
+// zero time before the first run.
+func RolledUpTo(ctx context.Context) (time.Time, error) {
+	return watermark(ctx)
+}
+
+func setWatermark(ctx context.Context, at time.Time) error {
+	_, err := db.Pool.Exec(ctx, `
+		INSERT INTO rollup_watermarks (job, rolled_up_to) VALUES ($1, $2)
+		ON CONFLICT (job) DO UPDATE SET rolled_up_to = EXCLUDED.rolled_up_to
+	`, rollupJob, at)
+	return err
+}
+
+// providerDay is a provider's local day whose rollup needs rebuilding.
+type providerDay struct {
+	ProviderID string
+	Day        time.Time
+}
+
+// changedDays lists the provider days with orders placed or updated, or
+// reviews written or moderated, after since. A moderated review changes the
+// ratings of the day it was written.
+func changedDays(ctx context.Context, since time.Time, loc *time.Location) ([]providerDay, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT DISTINCT provider_id::text, (created_at AT TIME ZONE $2)::date
+		FROM (
+			SELECT provider_id, created_at FROM orders
+			WHERE provider_id IS NOT NULL AND (created_at > $1 OR updated_at > $1)
+			UNION ALL
+			SELECT provider_id, created_at FROM reviews WHERE created_at > $1 OR moderated_at > $1
+		) changed
+	`, since, loc.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []providerDay
+	for rows.Next() {
+		var pd providerDay
+		if err := rows.Scan(&pd.ProviderID, &pd.Day); err != nil {
+			return nil, err
+		}
+		days = append(days, pd)
+	}
+	return days, rows.Err()
+}
+
+// RollupProviderDay rebuilds a provider's rollup for one local day from its
+// orders and reviews. It is idempotent, so concurrent or repeated runs are
+// harmless.
+func RollupProviderDay(ctx context.Context, providerID string, day time.Time, loc *time.Location) error {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	end := start.AddDate(0, 0, 1)
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT COALESCE(buyer_id::text, ''), status, COALESCE(total_estimate, 0)::float8, items, created_at
+		FROM orders
+		WHERE provider_id = $1 AND created_at >= $2 AND created_at < $3
+	`, providerID, start, end)
+	if err != nil {
+		return err
+	}
+	var orders []OrderRow
+	for rows.Next() {
+		var o OrderRow
+		var itemsJSON []byte
+		if err := rows.Scan(&o.BuyerID, &o.Status, &o.Total, &itemsJSON, &o.CreatedAt); err != nil {
+			rows.Close()
+			return err
+		}
+		_ = json.Unmarshal(itemsJSON, &o.Items)
+		orders = append(orders, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	d, items, buyers := RollupDay(day, orders, loc)
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(rating), 0)
+		FROM reviews
+		WHERE provider_id = $1 AND created_at >= $2 AND created_at < $3
+		  AND moderation_state <> 'hidden'
+	`, providerID, start, end).Scan(&d.Ratings, &d.RatingSum); err != nil {
+		return err
+	}
+
+	hourly := make([]int32, 24)
+	for h, n := range d.HourlyOrders {
+		hourly[h] = int32(n)
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO provider_daily_stats
+			(provider_id, day, orders, orders_by_status, billable_orders, revenue, hourly_orders, ratings, rating_sum, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, now())
+		ON CONFLICT (provider_id, day) DO UPDATE SET
+			orders = EXCLUDED.orders, orders_by_status = EXCLUDED.orders_by_status,
+			billable_orders = EXCLUDED.billable_orders, revenue = EXCLUDED.revenue,
+			hourly_orders = EXCLUDED.hourly_orders, ratings = EXCLUDED.ratings,
+			rating_sum = EXCLUDED.rating_sum, updated_at = now()
+	`, providerID, d.Date, d.Orders, d.OrdersByStatus, d.BillableOrders, d.Revenue, hourly, d.Ratings, d.RatingSum); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM provider_daily_items WHERE provider_id = $1 AND day = $2`, providerID, d.Date); err != nil {
+		return err
+	}
+	for _, it := range items {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO provider_daily_items (provider_id, day, item_id, name, quantity, revenue)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, providerID, d.Date, it.ItemID, it.Name, it.Quantity, it.Revenue); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM provider_daily_buyers WHERE provider_id = $1 AND day = $2`, providerID, d.Date); err != nil {
+		return err
+	}
+	for buyerID, n := range buyers {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO provider_daily_buyers (provider_id, day, buyer_id, orders) VALUES ($1, $2, $3, $4)
+		`, providerID, d.Date, buyerID, n); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
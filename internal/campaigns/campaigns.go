@@ -0,0 +1,248 @@
+package campaigns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+)
+
+// Limits on campaign text, matching what notification trays display.
+const (
+	MaxTitleLen = 65
+	MaxBodyLen  = 240
+)
+
+// sendingTimeout is how long a claimed campaign may go without a heartbeat
+// before another dispatch claims it again. The dispatcher refreshes its
+// claims every heartbeatInterval while it works through a batch.
+const (
+	sendingTimeout    = 10 * time.Minute
+	heartbeatInterval = sendingTimeout / 4
+)
+
+// ErrWeeklyLimit is returned when a provider has already created its
+// campaigns for the rolling week.
+var ErrWeeklyLimit = errors.New("provider campaign weekly limit reached")
+
+// Rules are the delivery policies applied to every campaign.
+type Rules struct {
+	QuietStart          int            // Hour (0-23) quiet hours begin
+	QuietEnd            int            // Hour (0-23) quiet hours end; equal to QuietStart disables them
+	Location            *time.Location // Timezone quiet hours and daily caps are evaluated in
+	DailyCap            int            // Max campaign notifications per user per day (0 = unlimited)
+	ProviderWeeklyLimit int            // Max campaigns a provider may create per rolling 7 days
+}
+
+func (r Rules) location() *time.Location {
+	if r.Location == nil {
+		return time.UTC
+	}
+	return r.Location
+}
+
+// InQuietHours reports whether t falls inside the quiet period. The period
+// may wrap past midnight (e.g. 22 to 8).
+func (r Rules) InQuietHours(t time.Time) bool {
+	if r.QuietStart == r.QuietEnd {
+		return false
+	}
+	h := t.In(r.location()).Hour()
+	if r.QuietStart < r.QuietEnd {
+		return h >= r.QuietStart && h < r.QuietEnd
+	}
+	return h >= r.QuietStart || h < r.QuietEnd
+}
+
+// NextAllowed returns t, or the end of the quiet period if t falls inside it.
+func (r Rules) NextAllowed(t time.Time) time.Time {
+	if !r.InQuietHours(t) {
+		return t
+	}
+	local := t.In(r.location())
+	end := time.Date(local.Year(), local.Month(), local.Day(), r.QuietEnd, 0, 0, 0, local.Location())
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// Validate checks campaign text lengths.
+func Validate(title, body string) error {
+	if title == "" || body == "" {
+		return fmt.Errorf("title and body are required")
+	}
+	if len([]rune(title)) > MaxTitleLen {
+		return fmt.Errorf("title must be at most %d characters", MaxTitleLen)
+	}
+	if len([]rune(body)) > MaxBodyLen {
+		return fmt.Errorf("body must be at most %d characters", MaxBodyLen)
+	}
+	return nil
+}
+
+const campaignColumns = `id, COALESCE(created_by::text, ''), COALESCE(provider_id::text, ''), topic, title, body,
+	COALESCE(data, '{}'), status, send_at, sent_at, COALESCE(recipients, 0), COALESCE(skipped, 0),
+	COALESCE(error, ''), created_at`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCampaign(row scanner) (*models.Campaign, error) {
+	c := &models.Campaign{}
+	err := row.Scan(&c.ID, &c.CreatedBy, &c.ProviderID, &c.Topic, &c.Title, &c.Body,
+		&c.Data, &c.Status, &c.SendAt, &c.SentAt, &c.Recipients, &c.Skipped,
+		&c.Error, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// CreateCampaign schedules a campaign. providerID is empty for admin
+// campaigns; provider campaigns are refused with ErrWeeklyLimit once the
+// provider has created weeklyLimit non-cancelled campaigns in 7 days.
+func CreateCampaign(ctx context.Context, createdBy, providerID, topic, title, body string, data map[string]string, sendAt time.Time, weeklyLimit int) (*models.Campaign, error) {
+	var providerIDPtr *string
+	if providerID != "" {
+		providerIDPtr = &providerID
+	}
+	if data == nil {
+		data = map[string]string{}
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if providerID != "" {
+		// Serialise the provider's campaign creation so concurrent requests
+		// can't both pass the count
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('campaign:' || $1::text))`, providerID); err != nil {
+			return nil, err
+		}
+		var recent int
+		if err := tx.QueryRow(ctx, `
+			SELECT COUNT(*) FROM campaigns
+			WHERE provider_id = $1 AND created_at >= $2 AND status <> $3
+		`, providerID, time.Now().AddDate(0, 0, -7), models.CampaignStatusCancelled).Scan(&recent); err != nil {
+			return nil, err
+		}
+		if recent >= weeklyLimit {
+			return nil, ErrWeeklyLimit
+		}
+	}
+
+	c, err := scanCampaign(tx.QueryRow(ctx, `
+		INSERT INTO campaigns (id, created_by, provider_id, topic, title, body, data, status, send_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING `+campaignColumns,
+		uuid.New().String(), createdBy, providerIDPtr, topic, title, body, data,
+		models.CampaignStatusScheduled, sendAt))
+	if err != nil {
+		return nil, err
+	}
+	return c, tx.Commit(ctx)
+}
+
+// GetCampaign retrieves a campaign by ID.
+func GetCampaign(ctx context.Context, id string) (*models.Campaign, error) {
+	return scanCampaign(db.Pool.QueryRow(ctx, `SELECT `+campaignColumns+` FROM campaigns WHERE id = $1`, id))
+}
+
+// ListCampaigns lists campaigns, newest first. An empty providerID lists all.
+func ListCampaigns(ctx context.Context, providerID string, limit, offset int) ([]*models.Campaign, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT `+campaignColumns+` FROM campaigns
+		WHERE ($1 = '' OR provider_id::text = $1)
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, providerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*models.Campaign{}
+	for rows.Next() {
+		c, err := scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// CancelCampaign cancels a campaign that has not been sent yet.
+func CancelCampaign(ctx context.Context, id string) error {
+	ct, err := db.Pool.Exec(ctx, `
+		UPDATE campaigns SET status = $2 WHERE id = $1 AND status = $3
+	`, id, models.CampaignStatusCancelled, models.CampaignStatusScheduled)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return fmt.Errorf("campaign not found or already sent")
+	}
+	return nil
+}
+
+// claimDue marks due campaigns as sending and returns them, along with
+// campaigns left sending for longer than sendingTimeout by a dispatcher
+// that died. SKIP LOCKED lets several API instances run the dispatcher
+// without double-sending.
+func claimDue(ctx context.Context, now time.Time, limit int) ([]*models.Campaign, error) {
+	rows, err := db.Pool.Query(ctx, `
+		UPDATE campaigns SET status = $1, claimed_at = $3
+		WHERE id IN (
+			SELECT id FROM campaigns
+			WHERE (status = $2 AND send_at <= $3)
+			   OR (status = $1 AND COALESCE(claimed_at, send_at) <= $5)
+			ORDER BY send_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+campaignColumns,
+		models.CampaignStatusSending, models.CampaignStatusScheduled, now, limit, now.Add(-sendingTimeout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.Campaign
+	for rows.Next() {
+		c, err := scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// touchClaims refreshes claimed_at on those of ids that are still sending.
+func touchClaims(ctx context.Context, ids []string, now time.Time) error {
+	_, err := db.Pool.Exec(ctx, `
+		UPDATE campaigns SET claimed_at = $2
+		WHERE id = ANY($1) AND status = $3
+	`, ids, now, models.CampaignStatusSending)
+	return err
+}
+
+// finishCampaign records the outcome of a dispatch.
+func finishCampaign(ctx context.Context, id, status string, recipients, skipped int, errText string) error {
+	_, err := db.Pool.Exec(ctx, `
+		UPDATE campaigns
+		SET status = $2, sent_at = now(), recipients = $3, skipped = $4, error = NULLIF($5, '')
+		WHERE id = $1
+	`, id, status, recipients, skipped, errText)
+	return err
+}
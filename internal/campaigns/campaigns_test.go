@@ -0,0 +1,78 @@
+package campaigns
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var ist = time.FixedZone("IST", 5*60*60+30*60)
+
+func at(hour, minute int) time.Time {
+	return time.Date(2026, 8, 20, hour, minute, 0, 0, ist)
+}
+
+func TestInQuietHoursWrapsMidnight(t *testing.T) {
+	r := Rules{QuietStart: 22, QuietEnd: 8, Location: ist}
+	quiet := []time.Time{at(22, 0), at(23, 59), at(0, 30), at(7, 59)}
+	open := []time.Time{at(8, 0), at(12, 0), at(21, 59)}
+
+	for _, tm := range quiet {
+		if !r.InQuietHours(tm) {
+			t.Errorf("%s should be quiet", tm.Format("15:04"))
+		}
+	}
+	for _, tm := range open {
+		if r.InQuietHours(tm) {
+			t.Errorf("%s should not be quiet", tm.Format("15:04"))
+		}
+	}
+}
+
+func TestInQuietHoursSameDayAndDisabled(t *testing.T) {
+	r := Rules{QuietStart: 13, QuietEnd: 15, Location: ist}
+	if !r.InQuietHours(at(14, 0)) || r.InQuietHours(at(15, 0)) {
+		t.Error("unexpected same-day quiet hours result")
+	}
+	if (Rules{QuietStart: 8, QuietEnd: 8, Location: ist}).InQuietHours(at(8, 0)) {
+		t.Error("equal start and end should disable quiet hours")
+	}
+}
+
+func TestInQuietHoursUsesLocation(t *testing.T) {
+	r := Rules{QuietStart: 22, QuietEnd: 8, Location: ist}
+	// 17:00 UTC is 22:30 IST
+	if !r.InQuietHours(time.Date(2026, 8, 20, 17, 0, 0, 0, time.UTC)) {
+		t.Error("expected quiet hours to be evaluated in IST")
+	}
+}
+
+func TestNextAllowed(t *testing.T) {
+	r := Rules{QuietStart: 22, QuietEnd: 8, Location: ist}
+
+	if got := r.NextAllowed(at(12, 0)); !got.Equal(at(12, 0)) {
+		t.Errorf("outside quiet hours should be unchanged, got %v", got)
+	}
+	if got, want := r.NextAllowed(at(23, 15)), at(8, 0).AddDate(0, 0, 1); !got.Equal(want) {
+		t.Errorf("late night: got %v, want %v", got, want)
+	}
+	if got := r.NextAllowed(at(3, 0)); !got.Equal(at(8, 0)) {
+		t.Errorf("early morning: got %v, want same-day 08:00", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate("Paryushan special thali", "Pre-order by Friday"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := Validate("", "body"); err == nil {
+		t.Error("expected error for empty title")
+	}
+	if err := Validate(strings.Repeat("a", MaxTitleLen+1), "body"); err == nil {
+		t.Error("expected error for long title")
+	}
+	// Length is counted in characters so Hindi text isn't penalised
+	if err := Validate(strings.Repeat("जै", MaxTitleLen/2), "body"); err != nil {
+		t.Errorf("unexpected error for Hindi title: %v", err)
+	}
+}
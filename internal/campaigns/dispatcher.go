@@ -0,0 +1,146 @@
+package campaigns
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"jainfood/internal/models"
+	"jainfood/internal/monitoring"
+	"jainfood/internal/push"
+	"jainfood/internal/redisclient"
+)
+
+// Dispatcher sends scheduled campaigns once they are due, outside quiet hours.
+type Dispatcher struct {
+	Fanout   *push.TopicFanout
+	Rules    Rules
+	Interval time.Duration
+	logger   *zap.Logger
+}
+
+// NewDispatcher creates a dispatcher that polls for due campaigns every 30 seconds.
+func NewDispatcher(fanout *push.TopicFanout, rules Rules, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		Fanout:   fanout,
+		Rules:    rules,
+		Interval: 30 * time.Second,
+		logger:   logger,
+	}
+}
+
+// Run dispatches due campaigns until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Campaigns that come due during quiet hours wait until they end
+		if d.Rules.InQuietHours(time.Now()) {
+			continue
+		}
+		d.dispatchDue(ctx)
+	}
+}
+
+func (d *Dispatcher) dispatchDue(ctx context.Context) {
+	due, err := claimDue(ctx, time.Now(), 10)
+	if err != nil {
+		d.logger.Error("campaign claim failed", zap.Error(err))
+		return
+	}
+	if len(due) == 0 {
+		return
+	}
+	// Broadcasts are sent serially, so keep the whole batch's claims fresh
+	// until the last one finishes; otherwise another instance re-sends them
+	stop := d.heartbeat(ctx, due)
+	defer stop()
+
+	m := monitoring.GetMetrics()
+	for _, c := range due {
+		data := map[string]string{}
+		for k, v := range c.Data {
+			data[k] = v
+		}
+		data["type"] = "campaign"
+		data["campaign_id"] = c.ID
+
+		result, err := d.Fanout.Broadcast(ctx, c.Topic, c.Title, c.Body, data, d.allowUser)
+		status, errText := models.CampaignStatusSent, ""
+		if err != nil {
+			status, errText = models.CampaignStatusFailed, err.Error()
+		}
+		if result == nil {
+			result = &push.BroadcastResult{}
+		}
+
+		if err := finishCampaign(ctx, c.ID, status, result.Recipients, result.Skipped, errText); err != nil {
+			d.logger.Error("campaign status update failed", zap.String("campaign_id", c.ID), zap.Error(err))
+		}
+		m.IncrCustom("campaigns_" + status)
+		d.logger.Info("campaign dispatched",
+			zap.String("campaign_id", c.ID),
+			zap.String("topic", c.Topic),
+			zap.String("status", status),
+			zap.Int("recipients", result.Recipients),
+			zap.Int("skipped", result.Skipped))
+	}
+}
+
+// heartbeat refreshes claimed_at on the batch every heartbeatInterval until
+// the returned stop func is called.
+func (d *Dispatcher) heartbeat(ctx context.Context, due []*models.Campaign) func() {
+	ids := make([]string, len(due))
+	for i, c := range due {
+		ids[i] = c.ID
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if err := touchClaims(ctx, ids, now); err != nil {
+					d.logger.Warn("campaign claim refresh failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// allowUser enforces the per-user daily cap with a Redis counter per local day.
+func (d *Dispatcher) allowUser(ctx context.Context, userID string) bool {
+	if d.Rules.DailyCap <= 0 || redisclient.Rdb == nil {
+		return true
+	}
+	day := time.Now().In(d.Rules.location()).Format("20060102")
+	key := fmt.Sprintf("campaign:cap:%s:%s", userID, day)
+
+	n, err := redisclient.Rdb.Incr(ctx, key).Result()
+	if err != nil {
+		// Fail open: a Redis outage shouldn't silently drop campaigns
+		return true
+	}
+	if n == 1 {
+		redisclient.Rdb.Expire(ctx, key, 48*time.Hour)
+	}
+	return n <= int64(d.Rules.DailyCap)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 	"jainfood/internal/db"
 	"jainfood/internal/models"
@@ -38,6 +39,11 @@ type Hub struct {
 	mu sync.RWMutex
 	// Logger
 	logger *zap.Logger
+	// OnMessage, if set, is called in its own goroutine for every persisted message
+	OnMessage func(msg *Message)
+	// CanChat, if set, is checked when a client connects and before each of
+	// its messages is relayed, so blocks take effect on open connections
+	CanChat func(ctx context.Context, chatID, userID string) bool
 }
 
 // Client represents a WebSocket client.
@@ -115,6 +121,18 @@ func (h *Hub) Run() {
 	}
 }
 
+// IsConnected reports whether a user currently has an open connection to a chat.
+func (h *Hub) IsConnected(chatID, userID string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.rooms[chatID] {
+		if client.userID == userID {
+			return true
+		}
+	}
+	return false
+}
+
 // HandleWebSocket handles WebSocket connection upgrades.
 func (h *Hub) HandleWebSocket(c *gin.Context) {
 	chatID := c.Query("chat_id")
@@ -124,6 +142,10 @@ func (h *Hub) HandleWebSocket(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "chat_id and user_id required"})
 		return
 	}
+	if h.CanChat != nil && !h.CanChat(c.Request.Context(), chatID, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "chat unavailable"})
+		return
+	}
 
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
@@ -178,13 +200,24 @@ func (c *Client) readPump() {
 		msg.SenderID = c.userID
 		msg.Timestamp = time.Now().Unix()
 
-		// Persist message to database
 		ctx := context.Background()
+		if c.hub.CanChat != nil && !c.hub.CanChat(ctx, c.chatID, c.userID) {
+			_ = c.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "chat unavailable"), time.Now().Add(time.Second))
+			break
+		}
+
+		// Persist message to database
 		if err := SaveMessage(ctx, msg.ChatID, msg.SenderID, msg.Content); err != nil {
 			c.hub.logger.Error("failed to save message", zap.Error(err))
 		}
 
 		c.hub.broadcast <- &msg
+
+		if c.hub.OnMessage != nil {
+			m := msg
+			go c.hub.OnMessage(&m)
+		}
 	}
 }
 
@@ -289,6 +322,22 @@ func GetChatByOrder(ctx context.Context, orderID string) (*models.Chat, error) {
 	return chat, nil
 }
 
+// ProviderBlocked reports whether the provider of a chat's order is blocked.
+func ProviderBlocked(ctx context.Context, chatID string) (bool, error) {
+	var blocked bool
+	err := db.Pool.QueryRow(ctx, `
+		SELECT COALESCE(p.blocked, FALSE)
+		FROM chats ch
+		JOIN orders o ON o.id = ch.order_id
+		JOIN providers p ON p.id = o.provider_id
+		WHERE ch.id = $1
+	`, chatID).Scan(&blocked)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	return blocked, err
+}
+
 // SaveMessage persists a chat message.
 func SaveMessage(ctx context.Context, chatID, senderID, content string) error {
 	id := uuid.New().String()
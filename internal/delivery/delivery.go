@@ -0,0 +1,114 @@
+// Package delivery decides whether a provider delivers to a location and what
+// it charges: a radius or polygon zone, distance fee slabs, and the
+// provider's free-delivery rules.
+package delivery
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"jainfood/internal/models"
+)
+
+// Validate checks that a zone has exactly one of a radius or a polygon, and
+// that fee slabs are non-negative and ordered by distance.
+func Validate(z *models.DeliveryZone) error {
+	switch {
+	case z.RadiusKm < 0:
+		return fmt.Errorf("radius_km must be positive")
+	case z.RadiusKm > 0 && len(z.Area) > 0:
+		return fmt.Errorf("set either radius_km or area, not both")
+	case z.RadiusKm == 0 && len(z.Area) == 0:
+		return fmt.Errorf("radius_km or area is required")
+	}
+	if len(z.Area) > 0 {
+		ring := Ring(z.Area)
+		if len(ring) < 4 {
+			return fmt.Errorf("area needs at least 3 points")
+		}
+		for _, pt := range ring {
+			if pt[0] < -180 || pt[0] > 180 || pt[1] < -90 || pt[1] > 90 {
+				return fmt.Errorf("area points must be [lng, lat]")
+			}
+		}
+	}
+	prev := 0.0
+	for _, s := range z.FeeSlabs {
+		if s.UpToKm <= prev {
+			return fmt.Errorf("fee slabs must be in increasing up_to_km order")
+		}
+		if s.Fee < 0 {
+			return fmt.Errorf("fee must not be negative")
+		}
+		prev = s.UpToKm
+	}
+	return nil
+}
+
+// Ring returns the polygon ring closed, as PostGIS requires.
+func Ring(area [][2]float64) [][2]float64 {
+	if len(area) == 0 || area[0] == area[len(area)-1] {
+		return area
+	}
+	return append(append([][2]float64{}, area...), area[0])
+}
+
+// GeoJSON encodes an area as a GeoJSON polygon for ST_GeomFromGeoJSON.
+func GeoJSON(area [][2]float64) string {
+	b, _ := json.Marshal(map[string]interface{}{
+		"type":        "Polygon",
+		"coordinates": [][][2]float64{Ring(area)},
+	})
+	return string(b)
+}
+
+// FreeDelivery applies the provider's free-delivery rules: the subtotal is
+// at least minPrice and the distance at most maxKm. A zero rule is not
+// checked; with both zero there is no free delivery.
+func FreeDelivery(p *models.Provider, distanceKm, subtotal float64) bool {
+	if p.FreeDeliveryMinPrice <= 0 && p.FreeDeliveryMaxKm <= 0 {
+		return false
+	}
+	if p.FreeDeliveryMinPrice > 0 && subtotal < p.FreeDeliveryMinPrice {
+		return false
+	}
+	return p.FreeDeliveryMaxKm <= 0 || distanceKm <= p.FreeDeliveryMaxKm
+}
+
+// SlabFee returns the fee of the first slab covering distanceKm, or the last
+// slab's fee beyond it. No slabs means no fee.
+func SlabFee(slabs []models.FeeSlab, distanceKm float64) float64 {
+	for _, s := range slabs {
+		if distanceKm <= s.UpToKm {
+			return s.Fee
+		}
+	}
+	if len(slabs) == 0 {
+		return 0
+	}
+	return slabs[len(slabs)-1].Fee
+}
+
+// Quote prices a delivery inside the zone. A nil zone means the provider
+// hasn't set one: they deliver anywhere, with no slab fee.
+func Quote(p *models.Provider, z *models.DeliveryZone, distanceKm, subtotal float64) *models.DeliveryQuote {
+	q := &models.DeliveryQuote{Deliverable: true, DistanceKm: distanceKm}
+	if FreeDelivery(p, distanceKm, subtotal) {
+		q.FreeDelivery = true
+		return q
+	}
+	if z != nil {
+		q.Fee = SlabFee(z.FeeSlabs, distanceKm)
+	}
+	return q
+}
+
+// DeliversToSQL is the SQL form of "the provider's zone covers point", for
+// search filters. p is the providers table reference including the dot
+// (e.g. "p." or "providers."); point is a geography expression. Providers
+// without a zone match.
+func DeliversToSQL(p, point string) string {
+	return fmt.Sprintf(`NOT EXISTS (SELECT 1 FROM provider_delivery_zones dz WHERE dz.provider_id = %[1]sid
+		AND NOT CASE WHEN dz.area IS NOT NULL THEN ST_Covers(dz.area, %[2]s)
+		             ELSE ST_DWithin(%[1]sgeo, %[2]s, dz.radius_km * 1000) END)`, p, point)
+}
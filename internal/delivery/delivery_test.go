@@ -0,0 +1,122 @@
+package delivery
+
+import (
+	"strings"
+	"testing"
+
+	"jainfood/internal/models"
+)
+
+var slabs = []models.FeeSlab{
+	{UpToKm: 2, Fee: 0},
+	{UpToKm: 5, Fee: 20},
+	{UpToKm: 8, Fee: 40},
+}
+
+func TestSlabFee(t *testing.T) {
+	cases := []struct {
+		km   float64
+		want float64
+	}{
+		{0.5, 0},
+		{2, 0},
+		{2.1, 20},
+		{5, 20},
+		{7.9, 40},
+		{12, 40}, // Beyond the last slab
+	}
+	for _, c := range cases {
+		if got := SlabFee(slabs, c.km); got != c.want {
+			t.Errorf("SlabFee(%v) = %v, want %v", c.km, got, c.want)
+		}
+	}
+	if got := SlabFee(nil, 3); got != 0 {
+		t.Errorf("SlabFee without slabs = %v, want 0", got)
+	}
+}
+
+func TestFreeDelivery(t *testing.T) {
+	cases := []struct {
+		name            string
+		minPrice, maxKm float64
+		km, subtotal    float64
+		want            bool
+	}{
+		{"no rules", 0, 0, 1, 1000, false},
+		{"above min price", 200, 0, 9, 250, true},
+		{"below min price", 200, 0, 1, 150, false},
+		{"within max km", 0, 3, 2.5, 50, true},
+		{"beyond max km", 0, 3, 3.5, 50, false},
+		{"both met", 200, 5, 4, 200, true},
+		{"price met, too far", 200, 5, 6, 500, false},
+	}
+	for _, c := range cases {
+		p := &models.Provider{FreeDeliveryMinPrice: c.minPrice, FreeDeliveryMaxKm: c.maxKm}
+		if got := FreeDelivery(p, c.km, c.subtotal); got != c.want {
+			t.Errorf("%s: FreeDelivery = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	p := &models.Provider{FreeDeliveryMinPrice: 300}
+	zone := &models.DeliveryZone{RadiusKm: 8, FeeSlabs: slabs}
+
+	q := Quote(p, zone, 4, 150)
+	if !q.Deliverable || q.Fee != 20 || q.FreeDelivery {
+		t.Errorf("Quote = %+v, want fee 20", q)
+	}
+	q = Quote(p, zone, 4, 300)
+	if q.Fee != 0 || !q.FreeDelivery {
+		t.Errorf("Quote = %+v, want free delivery", q)
+	}
+	q = Quote(&models.Provider{}, nil, 4, 150)
+	if !q.Deliverable || q.Fee != 0 {
+		t.Errorf("Quote without zone = %+v, want deliverable with no fee", q)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	square := [][2]float64{{72.5, 23.0}, {72.6, 23.0}, {72.6, 23.1}, {72.5, 23.1}}
+	valid := []*models.DeliveryZone{
+		{RadiusKm: 5, FeeSlabs: slabs},
+		{Area: square},
+	}
+	for _, z := range valid {
+		if err := Validate(z); err != nil {
+			t.Errorf("Validate(%+v) = %v", z, err)
+		}
+	}
+	invalid := []*models.DeliveryZone{
+		{},
+		{RadiusKm: 5, Area: square},
+		{Area: square[:2]},
+		{Area: [][2]float64{{23.0, 72.5}, {23.0, 200}, {23.1, 72.6}}},
+		{RadiusKm: 5, FeeSlabs: []models.FeeSlab{{UpToKm: 5, Fee: 10}, {UpToKm: 2, Fee: 5}}},
+		{RadiusKm: 5, FeeSlabs: []models.FeeSlab{{UpToKm: 5, Fee: -1}}},
+	}
+	for _, z := range invalid {
+		if err := Validate(z); err == nil {
+			t.Errorf("Validate(%+v) succeeded, want error", z)
+		}
+	}
+}
+
+func TestRingClosesPolygon(t *testing.T) {
+	ring := Ring([][2]float64{{1, 1}, {2, 1}, {2, 2}})
+	if len(ring) != 4 || ring[3] != ring[0] {
+		t.Errorf("Ring = %v, want closed ring", ring)
+	}
+	if !strings.Contains(GeoJSON(ring), `"coordinates":[[[1,1],[2,1],[2,2],[1,1]]]`) {
+		t.Errorf("GeoJSON = %s", GeoJSON(ring))
+	}
+}
+
+func TestDeliversToSQL(t *testing.T) {
+	sql := DeliversToSQL("p.", "$pt")
+	for _, part := range []string{"dz.provider_id = p.id", "ST_Covers(dz.area, $pt)", "ST_DWithin(p.geo, $pt, dz.radius_km * 1000)"} {
+		if !strings.Contains(sql, part) {
+			t.Errorf("missing %q in %s", part, sql)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package devices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+	"jainfood/internal/push"
+)
+
+// ErrInvalidEndpoint is returned by RegisterWebPush for endpoints that are
+// not on a known Web Push service.
+var ErrInvalidEndpoint = errors.New("endpoint must be an https URL on a known push service")
+
+// ValidPlatform reports whether platform is a supported device platform.
+func ValidPlatform(platform string) bool {
+	switch platform {
+	case models.PlatformAndroid, models.PlatformIOS, models.PlatformWeb:
+		return true
+	}
+	return false
+}
+
+// RegisterDevice stores a push token for a user. Re-registering an existing
+// token moves it to the given user (e.g. after logging in as someone else).
+func RegisterDevice(ctx context.Context, userID, token, platform string) (*models.DeviceToken, error) {
+	if !ValidPlatform(platform) {
+		return nil, fmt.Errorf("invalid platform %q", platform)
+	}
+
+	d := &models.DeviceToken{}
+	err := db.Pool.QueryRow(ctx, `
+		INSERT INTO device_tokens (id, user_id, token, platform)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (token) DO UPDATE
+		SET user_id = EXCLUDED.user_id, platform = EXCLUDED.platform, last_seen_at = now()
+		RETURNING id, user_id, token, platform, created_at, last_seen_at
+	`, uuid.New().String(), userID, token, platform).Scan(
+		&d.ID, &d.UserID, &d.Token, &d.Platform, &d.CreatedAt, &d.LastSeenAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// RegisterWebPush stores a browser Web Push subscription. The endpoint is
+// registered as a "web" device token and its encryption keys are kept alongside.
+func RegisterWebPush(ctx context.Context, userID string, sub *push.WebPushSubscription) (*models.DeviceToken, error) {
+	if !push.ValidWebPushEndpoint(sub.Endpoint) {
+		return nil, ErrInvalidEndpoint
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	d := &models.DeviceToken{}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO device_tokens (id, user_id, token, platform)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (token) DO UPDATE
+		SET user_id = EXCLUDED.user_id, platform = EXCLUDED.platform, last_seen_at = now()
+		RETURNING id, user_id, token, platform, created_at, last_seen_at
+	`, uuid.New().String(), userID, sub.Endpoint, models.PlatformWeb).Scan(
+		&d.ID, &d.UserID, &d.Token, &d.Platform, &d.CreatedAt, &d.LastSeenAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO webpush_subscriptions (endpoint, p256dh, auth)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (endpoint) DO UPDATE SET p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth
+	`, sub.Endpoint, sub.Keys.P256dh, sub.Keys.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// UnregisterDevice removes a user's push token.
+func UnregisterDevice(ctx context.Context, userID, token string) error {
+	ct, err := db.Pool.Exec(ctx, `DELETE FROM device_tokens WHERE user_id = $1 AND token = $2`, userID, token)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return db.ErrNotFound
+	}
+	return nil
+}
+
+// GetDevicesByUser lists a user's registered devices.
+func GetDevicesByUser(ctx context.Context, userID string) ([]*models.DeviceToken, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT id, user_id, token, platform, created_at, last_seen_at
+		FROM device_tokens WHERE user_id = $1
+		ORDER BY last_seen_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	devices := []*models.DeviceToken{}
+	for rows.Next() {
+		d := &models.DeviceToken{}
+		if err := rows.Scan(&d.ID, &d.UserID, &d.Token, &d.Platform, &d.CreatedAt, &d.LastSeenAt); err != nil {
+			return nil, err
+		}
+		devices = append(devices, d)
+	}
+	return devices, nil
+}
+
+// DeleteTokens removes tokens regardless of owner (used to prune tokens the
+// push provider reports as unregistered).
+func DeleteTokens(ctx context.Context, tokens []string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	_, err := db.Pool.Exec(ctx, `DELETE FROM device_tokens WHERE token = ANY($1)`, tokens)
+	return err
+}
+
+// Store adapts the device registry to push.TokenStore and push.SubscriptionStore.
+type Store struct{}
+
+// TokensForUser returns all push tokens registered by a user.
+func (Store) TokensForUser(ctx context.Context, userID string) ([]string, error) {
+	rows, err := db.Pool.Query(ctx, `SELECT token FROM device_tokens WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+// RemoveTokens prunes tokens reported as unregistered.
+func (Store) RemoveTokens(ctx context.Context, tokens []string) error {
+	return DeleteTokens(ctx, tokens)
+}
+
+// WebPushSubscription returns the stored subscription for an endpoint, or nil if unknown.
+func (Store) WebPushSubscription(ctx context.Context, endpoint string) (*push.WebPushSubscription, error) {
+	sub := &push.WebPushSubscription{Endpoint: endpoint}
+	err := db.Pool.QueryRow(ctx, `
+		SELECT p256dh, auth FROM webpush_subscriptions WHERE endpoint = $1
+	`, endpoint).Scan(&sub.Keys.P256dh, &sub.Keys.Auth)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
@@ -0,0 +1,155 @@
+// Package dietary turns a user's dietary profile into the ingredient
+// exclusions applied to search, and explains why an item was excluded.
+package dietary
+
+import (
+	"fmt"
+	"strings"
+
+	"jainfood/internal/jainrules"
+	"jainfood/internal/models"
+)
+
+// Exclusion is an ingredient term to keep out of results, with the reason
+// shown to the user.
+type Exclusion struct {
+	Term   string `json:"term"`
+	Reason string `json:"reason"`
+}
+
+// groups are the named exclusions a profile can list in place of individual
+// ingredients, e.g. "dairy" for vegan Jains.
+var groups = map[string][]string{
+	"dairy": {"milk", "doodh", "दूध", "paneer", "पनीर", "ghee", "घी", "butter", "makhan", "मक्खन",
+		"curd", "dahi", "दही", "yogurt", "cheese", "cream", "malai", "मलाई", "khoya", "khova", "mawa", "मावा",
+		"buttermilk", "chaas", "chhach", "छाछ", "condensed milk", "rabdi", "whey"},
+	"nuts": {"almond", "almonds", "badam", "बादाम", "cashew", "cashews", "kaju", "काजू", "pistachio",
+		"pista", "पिस्ता", "walnut", "walnuts", "akhrot", "अखरोट", "chironji"},
+	"peanut": {"peanut", "peanuts", "groundnut", "moongphali", "mungfali", "मूंगफली", "shengdana"},
+	"gluten": {"wheat", "gehu", "गेहूं", "atta", "आटा", "maida", "मैदा", "suji", "sooji", "rava", "rawa",
+		"सूजी", "semolina", "barley", "jau", "dalia", "bread", "noodles", "pasta"},
+	"soy":     {"soy", "soya", "soybean", "tofu", "सोया"},
+	"sesame":  {"sesame", "til", "तिल", "gingelly"},
+	"mustard": {"mustard", "rai", "sarson", "राई", "सरसों"},
+	"sugar":   {"sugar", "cheeni", "चीनी", "shakkar", "jaggery", "gud", "गुड़", "mishri"},
+}
+
+// Allergens that can be listed on a profile.
+var Allergens = []string{"dairy", "nuts", "peanut", "gluten", "soy", "sesame", "mustard"}
+
+// IsGroup reports whether name is a named exclusion group.
+func IsGroup(name string) bool {
+	_, ok := groups[name]
+	return ok
+}
+
+// Validate checks a profile's strictness, allergens and exclusions.
+func Validate(p *models.DietaryProfile) error {
+	switch p.Strictness {
+	case models.StrictnessRelaxed, models.StrictnessStandard, models.StrictnessStrict:
+	default:
+		return fmt.Errorf("strictness must be %s, %s or %s",
+			models.StrictnessRelaxed, models.StrictnessStandard, models.StrictnessStrict)
+	}
+	for _, a := range p.Allergens {
+		if !contains(Allergens, a) {
+			return fmt.Errorf("unknown allergen %q (expected one of %s)", a, strings.Join(Allergens, ", "))
+		}
+	}
+	for _, e := range p.ExcludeIngredients {
+		if strings.TrimSpace(e) == "" {
+			return fmt.Errorf("exclude_ingredients must not contain empty entries")
+		}
+	}
+	return nil
+}
+
+// Exclusions expands a profile's extra exclusions and allergens into terms.
+// Jain strictness is not expanded here: it is applied through the verdict
+// stored on each item (see jainrules), which understands "no onion".
+func Exclusions(p *models.DietaryProfile) []Exclusion {
+	if p == nil {
+		return nil
+	}
+	var out []Exclusion
+	for _, a := range p.Allergens {
+		for _, term := range groups[a] {
+			out = append(out, Exclusion{Term: term, Reason: fmt.Sprintf("contains %s (allergen: %s)", term, a)})
+		}
+	}
+	for _, e := range p.ExcludeIngredients {
+		out = append(out, Expand(e)...)
+	}
+	return dedupe(out)
+}
+
+// Expand resolves one exclusion entry: a group name ("dairy"), a Jain
+// taxonomy key or category ("onion", "root-vegetable"), or a literal term.
+func Expand(entry string) []Exclusion {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+	var out []Exclusion
+	if terms, ok := groups[entry]; ok {
+		for _, term := range terms {
+			out = append(out, Exclusion{Term: term, Reason: fmt.Sprintf("contains %s (excluded: %s)", term, entry)})
+		}
+		return out
+	}
+	if terms := jainrules.ExclusionTerms(entry); len(terms) > 0 {
+		for _, term := range terms {
+			out = append(out, Exclusion{Term: strings.ToLower(term), Reason: fmt.Sprintf("contains %s (excluded: %s)", term, entry)})
+		}
+		return out
+	}
+	return []Exclusion{{Term: entry, Reason: fmt.Sprintf("contains %s (excluded)", entry)}}
+}
+
+// Explain returns the reasons the item text (name and ingredients) matches
+// any of the exclusions.
+func Explain(exclusions []Exclusion, name string, ingredients []string) []string {
+	texts := make([]string, 0, len(ingredients)+1)
+	texts = append(texts, normalize(name))
+	for _, ing := range ingredients {
+		texts = append(texts, normalize(ing))
+	}
+
+	var reasons []string
+	for _, ex := range exclusions {
+		needle := " " + normalize(ex.Term) + " "
+		for _, t := range texts {
+			if strings.Contains(" "+t+" ", needle) {
+				reasons = append(reasons, ex.Reason)
+				break
+			}
+		}
+	}
+	return reasons
+}
+
+// normalize lowercases text and turns punctuation into spaces so terms
+// match on word boundaries.
+func normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '(' || r == ')' || r == '-' || r == '/' || r == '&' || r == '.'
+	}), " ")
+}
+
+func dedupe(in []Exclusion) []Exclusion {
+	seen := map[string]bool{}
+	out := in[:0]
+	for _, e := range in {
+		if !seen[e.Term] {
+			seen[e.Term] = true
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
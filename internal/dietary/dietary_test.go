@@ -0,0 +1,67 @@
+package dietary
+
+import (
+	"strings"
+	"testing"
+
+	"jainfood/internal/models"
+)
+
+func TestValidate(t *testing.T) {
+	ok := &models.DietaryProfile{Strictness: models.StrictnessStrict, Allergens: []string{"peanut"}, ExcludeIngredients: []string{"dairy"}}
+	if err := Validate(ok); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	bad := []*models.DietaryProfile{
+		{Strictness: "very"},
+		{Strictness: models.StrictnessStandard, Allergens: []string{"shellfish"}},
+		{Strictness: models.StrictnessStandard, ExcludeIngredients: []string{" "}},
+	}
+	for _, p := range bad {
+		if err := Validate(p); err == nil {
+			t.Errorf("expected error for %+v", p)
+		}
+	}
+}
+
+func TestExclusionsExpandGroupsAndTaxonomy(t *testing.T) {
+	p := &models.DietaryProfile{
+		Strictness:         models.StrictnessStandard,
+		Allergens:          []string{"peanut"},
+		ExcludeIngredients: []string{"dairy", "onion", "Kokum"},
+	}
+	terms := map[string]string{}
+	for _, ex := range Exclusions(p) {
+		if _, dup := terms[ex.Term]; dup {
+			t.Errorf("duplicate term %q", ex.Term)
+		}
+		terms[ex.Term] = ex.Reason
+	}
+
+	for _, want := range []string{"moongphali", "paneer", "ghee", "pyaz", "kokum"} {
+		if _, ok := terms[want]; !ok {
+			t.Errorf("expected %q in exclusions", want)
+		}
+	}
+	if !strings.Contains(terms["ghee"], "dairy") {
+		t.Errorf("reason should name the group: %q", terms["ghee"])
+	}
+}
+
+func TestExplain(t *testing.T) {
+	exclusions := Exclusions(&models.DietaryProfile{ExcludeIngredients: []string{"dairy"}, Allergens: []string{"sesame"}})
+
+	reasons := Explain(exclusions, "Paneer Tikka (Jain)", []string{"cottage cheese", "capsicum"})
+	if len(reasons) != 2 {
+		t.Errorf("expected paneer and cheese reasons, got %v", reasons)
+	}
+
+	// Whole words only: "til" must not match "tilak" or "lentil"
+	if reasons := Explain(exclusions, "Lentil Tilak Khichdi", []string{"moong dal", "rice"}); len(reasons) != 0 {
+		t.Errorf("unexpected reasons %v", reasons)
+	}
+	if reasons := Explain(exclusions, "Til Chikki", nil); len(reasons) != 1 {
+		t.Errorf("expected sesame reason, got %v", reasons)
+	}
+}
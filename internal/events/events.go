@@ -13,15 +13,48 @@ const (
 	EventUserCreated      = "USER_CREATED"
 	EventProviderCreated  = "PROVIDER_CREATED"
 	EventProviderVerified = "PROVIDER_VERIFIED"
+	EventProviderUpdated  = "PROVIDER_UPDATED"
 	EventMenuCreated      = "MENU_CREATED"
 	EventItemCreated      = "ITEM_CREATED"
+	EventItemJainFlagged  = "ITEM_JAIN_FLAGGED"
 	EventOrderCreated     = "ORDER_CREATED"
 	EventOrderConfirmed   = "ORDER_CONFIRMED"
+	EventOrderReady       = "ORDER_READY"
 	EventOrderCancelled   = "ORDER_CANCELLED"
 	EventOrderCompleted   = "ORDER_COMPLETED"
 	EventChatCreated      = "CHAT_CREATED"
 	EventMessageSent      = "MESSAGE_SENT"
 	EventReviewCreated    = "REVIEW_CREATED"
+	EventCampaignCreated  = "CAMPAIGN_CREATED"
+
+	// Provider onboarding applications
+	EventApplicationSubmitted = "PROVIDER_APPLICATION_SUBMITTED"
+	EventApplicationReviewed  = "PROVIDER_APPLICATION_REVIEWED"
+	EventAadhaarRevealed      = "AADHAAR_REVEALED"
+
+	// Admin blocks
+	EventUserBlocked       = "USER_BLOCKED"
+	EventUserUnblocked     = "USER_UNBLOCKED"
+	EventProviderBlocked   = "PROVIDER_BLOCKED"
+	EventProviderUnblocked = "PROVIDER_UNBLOCKED"
+
+	// Provider organisations
+	EventOrganisationCreated = "ORGANISATION_CREATED"
+	EventOutletCreated       = "OUTLET_CREATED"
+	EventStaffAdded          = "STAFF_ADDED"
+	EventStaffRemoved        = "STAFF_REMOVED"
+
+	// Review moderation and the admin audit trail
+	EventReviewReported  = "REVIEW_REPORTED"
+	EventReviewModerated = "REVIEW_MODERATED"
+	EventReviewDeleted   = "REVIEW_DELETED"
+	EventAdminAction     = "ADMIN_ACTION"
+
+	// Promotions
+	EventPromotionCreated   = "PROMOTION_CREATED"
+	EventPromotionActivated = "PROMOTION_ACTIVATED"
+	EventPromotionCancelled = "PROMOTION_CANCELLED"
+	EventPromotionRefunded  = "PROMOTION_REFUNDED"
 )
 
 // Event represents an audit/domain event.
@@ -0,0 +1,207 @@
+// Package hours evaluates provider opening hours: weekly slots that may run
+// past midnight, holiday closures and order cut-offs, all in the provider's
+// own timezone.
+package hours
+
+import (
+	"fmt"
+	"time"
+
+	"jainfood/internal/models"
+)
+
+// DefaultTimezone is used for providers that haven't set one.
+const DefaultTimezone = "Asia/Kolkata"
+
+// lookahead bounds the search for the next opening.
+const lookahead = 14
+
+// LoadLocation loads a timezone, falling back to IST when tzdata is missing.
+// An empty name means DefaultTimezone. Every package that evaluates local
+// days or clock times loads its zone through here.
+func LoadLocation(name string) *time.Location {
+	if name == "" {
+		name = DefaultTimezone
+	}
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc
+	}
+	return time.FixedZone("IST", 5*60*60+30*60)
+}
+
+// ParseClock parses HH:MM into minutes after midnight.
+func ParseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Validate checks a schedule's timezone, slots and holidays.
+func Validate(s *models.ProviderSchedule) error {
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("unknown timezone %q", s.Timezone)
+		}
+	}
+	for _, slot := range s.Slots {
+		if slot.Weekday < 0 || slot.Weekday > 6 {
+			return fmt.Errorf("weekday must be 0 (Sunday) to 6")
+		}
+		for _, clock := range []string{slot.Opens, slot.Closes} {
+			if _, err := ParseClock(clock); err != nil {
+				return err
+			}
+		}
+		if slot.OrderCutoff != "" {
+			if _, err := ParseClock(slot.OrderCutoff); err != nil {
+				return err
+			}
+		}
+	}
+	for _, h := range s.Holidays {
+		from, err1 := time.Parse(time.DateOnly, h.From)
+		to, err2 := time.Parse(time.DateOnly, h.To)
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("holiday dates must be YYYY-MM-DD")
+		}
+		if to.Before(from) {
+			return fmt.Errorf("holiday ends before it starts")
+		}
+	}
+	return nil
+}
+
+// window is a slot occurrence on a particular opening day.
+type window struct {
+	day    time.Time // Local midnight of the day the slot opens
+	start  time.Time
+	end    time.Time
+	cutoff time.Time // Zero when orders are accepted until close
+}
+
+func at(day time.Time, minutes int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minutes/60, minutes%60, 0, 0, day.Location())
+}
+
+// occurrence places a slot on day (which must fall on slot.Weekday).
+func occurrence(slot models.OpeningSlot, day time.Time) (window, bool) {
+	opens, err1 := ParseClock(slot.Opens)
+	closes, err2 := ParseClock(slot.Closes)
+	if err1 != nil || err2 != nil {
+		return window{}, false
+	}
+	w := window{day: day, start: at(day, opens), end: at(day, closes)}
+	overnight := closes <= opens
+	if overnight {
+		w.end = w.end.AddDate(0, 0, 1)
+	}
+	if cutoff, err := ParseClock(slot.OrderCutoff); err == nil {
+		w.cutoff = at(day, cutoff)
+		// An overnight slot's early-morning cutoff falls on the next day
+		if overnight && cutoff < opens && cutoff <= closes {
+			w.cutoff = w.cutoff.AddDate(0, 0, 1)
+		}
+	}
+	return w, true
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// closedOn reports whether the provider is closed for the whole day: a
+// holiday, or marked unavailable when day is today.
+func closedOn(s *models.ProviderSchedule, day, today time.Time) bool {
+	date := day.Format(time.DateOnly)
+	for _, h := range s.Holidays {
+		if date >= h.From && date <= h.To {
+			return true
+		}
+	}
+	return !s.AvailableToday && day.Equal(today)
+}
+
+// CanDeliver reports whether an order placed at now can be delivered at t:
+// t falls in an open slot on a day that isn't closed, and now is before that
+// slot's order cut-off. open_now is CanDeliver(s, now, now).
+func CanDeliver(s *models.ProviderSchedule, now, t time.Time) bool {
+	loc := LoadLocation(s.Timezone)
+	now, t = now.In(loc), t.In(loc)
+	today := midnight(now)
+
+	if len(s.Slots) == 0 {
+		return !closedOn(s, midnight(t), today)
+	}
+	// A slot covering t opened today or, running past midnight, yesterday
+	for _, day := range []time.Time{midnight(t), midnight(t).AddDate(0, 0, -1)} {
+		for _, slot := range s.Slots {
+			if time.Weekday(slot.Weekday) != day.Weekday() {
+				continue
+			}
+			w, ok := occurrence(slot, day)
+			if !ok || t.Before(w.start) || !t.Before(w.end) || closedOn(s, day, today) {
+				continue
+			}
+			if w.cutoff.IsZero() || !now.After(w.cutoff) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NextOpening returns when the provider next opens after t, or false when
+// it is open at t, has no slots, or has no opening within two weeks.
+func NextOpening(s *models.ProviderSchedule, t time.Time) (time.Time, bool) {
+	if len(s.Slots) == 0 || CanDeliver(s, t, t) {
+		return time.Time{}, false
+	}
+	loc := LoadLocation(s.Timezone)
+	t = t.In(loc)
+	today := midnight(t)
+
+	var next time.Time
+	for i := 0; i <= lookahead; i++ {
+		day := today.AddDate(0, 0, i)
+		if closedOn(s, day, today) {
+			continue
+		}
+		for _, slot := range s.Slots {
+			if time.Weekday(slot.Weekday) != day.Weekday() {
+				continue
+			}
+			if w, ok := occurrence(slot, day); ok && w.start.After(t) && (next.IsZero() || w.start.Before(next)) {
+				next = w.start
+			}
+		}
+		if !next.IsZero() {
+			return next, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// OpenOn reports whether the provider opens at some point on date (a
+// YYYY-MM-DD day in its timezone): it has a slot that weekday, or no slots
+// at all, and the day isn't closed.
+func OpenOn(s *models.ProviderSchedule, date string, now time.Time) bool {
+	loc := LoadLocation(s.Timezone)
+	day, err := time.ParseInLocation(time.DateOnly, date, loc)
+	if err != nil || closedOn(s, day, midnight(now.In(loc))) {
+		return false
+	}
+	return len(s.Slots) == 0 || len(SlotsOn(s, day.Weekday())) > 0
+}
+
+// SlotsOn returns the slots opening on a weekday.
+func SlotsOn(s *models.ProviderSchedule, weekday time.Weekday) []models.OpeningSlot {
+	slots := []models.OpeningSlot{}
+	for _, slot := range s.Slots {
+		if time.Weekday(slot.Weekday) == weekday {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
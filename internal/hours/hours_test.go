@@ -0,0 +1,167 @@
+package hours
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"jainfood/internal/models"
+)
+
+var ist = LoadLocation(DefaultTimezone)
+
+func istTime(day, clock string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", day+" "+clock, ist)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// 2025-03-07 is a Friday
+func schedule() *models.ProviderSchedule {
+	return &models.ProviderSchedule{
+		Timezone:       DefaultTimezone,
+		AvailableToday: true,
+		Slots: []models.OpeningSlot{
+			{Weekday: int(time.Friday), Opens: "12:00", Closes: "14:30", OrderCutoff: "10:00"}, // Tiffin lunch
+			{Weekday: int(time.Friday), Opens: "19:00", Closes: "01:00"},                       // Past midnight
+			{Weekday: int(time.Saturday), Opens: "19:00", Closes: "23:00"},
+		},
+	}
+}
+
+func TestCanDeliverAcrossMidnight(t *testing.T) {
+	s := schedule()
+	cases := []struct {
+		now, at string
+		want    bool
+	}{
+		{"2025-03-07 20:00", "2025-03-07 20:00", true},  // Friday evening
+		{"2025-03-08 00:30", "2025-03-08 00:30", true},  // Friday's slot, after midnight
+		{"2025-03-08 01:00", "2025-03-08 01:00", false}, // Closed at 01:00
+		{"2025-03-08 18:00", "2025-03-08 18:00", false}, // Saturday before opening
+		{"2025-03-08 23:30", "2025-03-08 23:30", false}, // Saturday doesn't run late
+	}
+	for _, c := range cases {
+		if got := CanDeliver(s, istTime(c.now[:10], c.now[11:]), istTime(c.at[:10], c.at[11:])); got != c.want {
+			t.Errorf("CanDeliver(now=%s, at=%s) = %v, want %v", c.now, c.at, got, c.want)
+		}
+	}
+}
+
+func TestOrderCutoff(t *testing.T) {
+	s := schedule()
+	lunch := istTime("2025-03-07", "13:00")
+	if !CanDeliver(s, istTime("2025-03-07", "09:30"), lunch) {
+		t.Error("ordering before the cutoff should be allowed")
+	}
+	if CanDeliver(s, istTime("2025-03-07", "10:30"), lunch) {
+		t.Error("ordering after the cutoff should be refused")
+	}
+	if CanDeliver(s, lunch, lunch) {
+		t.Error("open_now should be false once the slot's cutoff has passed")
+	}
+}
+
+func TestHolidaysAndAvailableToday(t *testing.T) {
+	s := schedule()
+	s.Holidays = []models.Holiday{{From: "2025-03-07", To: "2025-03-07", Reason: "Paryushan"}}
+	if CanDeliver(s, istTime("2025-03-07", "20:00"), istTime("2025-03-07", "20:00")) {
+		t.Error("open on a holiday")
+	}
+	// The holiday covers slots opening on the 7th, including after midnight
+	if CanDeliver(s, istTime("2025-03-08", "00:30"), istTime("2025-03-08", "00:30")) {
+		t.Error("overnight slot of a holiday should be closed")
+	}
+
+	s = schedule()
+	s.AvailableToday = false
+	now := istTime("2025-03-07", "20:00")
+	if CanDeliver(s, now, now) {
+		t.Error("open while marked unavailable today")
+	}
+	if !CanDeliver(s, now, istTime("2025-03-08", "20:00")) {
+		t.Error("available_today should not close tomorrow")
+	}
+
+	none := &models.ProviderSchedule{AvailableToday: true}
+	if !CanDeliver(none, now, now) {
+		t.Error("providers without hours should count as open")
+	}
+}
+
+func TestNextOpening(t *testing.T) {
+	s := schedule()
+	next, ok := NextOpening(s, istTime("2025-03-08", "02:00"))
+	if !ok || !next.Equal(istTime("2025-03-08", "19:00")) {
+		t.Errorf("next opening = %v, %v; want Saturday 19:00", next, ok)
+	}
+	// After Saturday's close, the next slot is the following Friday
+	next, ok = NextOpening(s, istTime("2025-03-08", "23:30"))
+	if !ok || !next.Equal(istTime("2025-03-14", "12:00")) {
+		t.Errorf("next opening = %v, %v; want Friday 12:00", next, ok)
+	}
+	if _, ok := NextOpening(s, istTime("2025-03-07", "20:00")); ok {
+		t.Error("no next opening while open")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(schedule()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	bad := []*models.ProviderSchedule{
+		{Timezone: "Mars/Olympus"},
+		{Slots: []models.OpeningSlot{{Weekday: 7, Opens: "09:00", Closes: "17:00"}}},
+		{Slots: []models.OpeningSlot{{Weekday: 1, Opens: "9am", Closes: "17:00"}}},
+		{Holidays: []models.Holiday{{From: "2025-03-09", To: "2025-03-08"}}},
+	}
+	for _, s := range bad {
+		if err := Validate(s); err == nil {
+			t.Errorf("expected error for %+v", s)
+		}
+	}
+}
+
+func TestDeliverableSQL(t *testing.T) {
+	sql := DeliverableSQL("p.", "$7::timestamptz")
+	for _, part := range []string{
+		"($7::timestamptz AT TIME ZONE p.timezone)",
+		"h.weekday = EXTRACT(DOW FROM d.day)",
+		"CASE WHEN h.closes <= h.opens THEN INTERVAL '1 day'",
+		"ph.provider_id = p.id AND d.day BETWEEN ph.starts_on AND ph.ends_on",
+		"COALESCE(p.available_today, TRUE) = FALSE",
+	} {
+		if !strings.Contains(sql, part) {
+			t.Errorf("missing %q in %s", part, sql)
+		}
+	}
+	if strings.Contains(sql, "{") {
+		t.Errorf("unreplaced placeholder in %s", sql)
+	}
+}
+
+func TestOpenOn(t *testing.T) {
+	s := schedule()
+	s.Holidays = []models.Holiday{{From: "2025-03-14", To: "2025-03-14"}}
+	now := istTime("2025-03-06", "12:00")
+	cases := map[string]bool{
+		"2025-03-07": true,  // Friday
+		"2025-03-08": true,  // Saturday
+		"2025-03-09": false, // No Sunday slots
+		"2025-03-14": false, // Holiday
+		"bad-date":   false,
+	}
+	for date, want := range cases {
+		if got := OpenOn(s, date, now); got != want {
+			t.Errorf("OpenOn(%s) = %v, want %v", date, got, want)
+		}
+	}
+	if got := len(SlotsOn(s, time.Friday)); got != 2 {
+		t.Errorf("SlotsOn(Friday) = %d slots, want 2", got)
+	}
+	if !OpenOn(&models.ProviderSchedule{AvailableToday: true}, "2025-03-09", now) {
+		t.Error("providers without hours should be open any day")
+	}
+}
@@ -0,0 +1,36 @@
+package hours
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DeliverableSQL is the SQL form of CanDeliver(schedule, now(), at) for use
+// in search filters. p is the providers table reference including the dot
+// (e.g. "p." or "providers."); at is a timestamptz expression.
+func DeliverableSQL(p, at string) string {
+	local := fmt.Sprintf("(%s AT TIME ZONE %stimezone)", at, p)
+	now := fmt.Sprintf("(now() AT TIME ZONE %stimezone)", p)
+	closed := func(day string) string {
+		return fmt.Sprintf(`(EXISTS (SELECT 1 FROM provider_holidays ph WHERE ph.provider_id = %[1]sid AND %[2]s BETWEEN ph.starts_on AND ph.ends_on)
+			OR (COALESCE(%[1]savailable_today, TRUE) = FALSE AND %[2]s = %[3]s::date))`, p, day, now)
+	}
+	overnight := "CASE WHEN h.closes <= h.opens THEN INTERVAL '1 day' ELSE INTERVAL '0' END"
+	cutoffNextDay := "CASE WHEN h.closes <= h.opens AND h.order_cutoff < h.opens AND h.order_cutoff <= h.closes THEN INTERVAL '1 day' ELSE INTERVAL '0' END"
+
+	sql := `(CASE WHEN EXISTS (SELECT 1 FROM provider_hours ph WHERE ph.provider_id = {p}id) THEN EXISTS (
+			SELECT 1 FROM provider_hours h
+			CROSS JOIN LATERAL (VALUES ({local}::date), ({local}::date - 1)) AS d(day)
+			WHERE h.provider_id = {p}id
+			  AND h.weekday = EXTRACT(DOW FROM d.day)
+			  AND {local} >= d.day + h.opens
+			  AND {local} < d.day + h.closes + {overnight}
+			  AND (h.order_cutoff IS NULL OR {now} <= d.day + h.order_cutoff + {cutoffNextDay})
+			  AND NOT {closedSlot})
+		ELSE NOT {closedDay} END)`
+	return strings.NewReplacer(
+		"{p}", p, "{local}", local, "{now}", now,
+		"{overnight}", overnight, "{cutoffNextDay}", cutoffNextDay,
+		"{closedSlot}", closed("d.day"), "{closedDay}", closed(local+"::date"),
+	).Replace(sql)
+}
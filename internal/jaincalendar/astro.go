@@ -0,0 +1,167 @@
+package jaincalendar
+
+import (
+	"math"
+	"time"
+)
+
+// Low-precision solar and lunar positions (Meeus, Astronomical Algorithms,
+// chapters 25 and 47). Good to a few arcminutes, which places tithi
+// boundaries within a few minutes and sunrise/sunset within about a minute.
+
+const (
+	j2000        = 2451545.0
+	synodicRate  = 12.190749 // Mean elongation gained per day, degrees
+	sunriseAngle = -0.833    // Refraction plus solar semi-diameter, degrees
+)
+
+func julianDay(t time.Time) float64 {
+	return float64(t.UnixNano())/86400e9 + 2440587.5
+}
+
+func fromJulianDay(jd float64) time.Time {
+	return time.Unix(0, int64((jd-2440587.5)*86400e9)).UTC()
+}
+
+func centuries(jd float64) float64 {
+	return (jd - j2000) / 36525
+}
+
+func rad(d float64) float64 { return d * math.Pi / 180 }
+
+func norm360(d float64) float64 {
+	d = math.Mod(d, 360)
+	if d < 0 {
+		d += 360
+	}
+	return d
+}
+
+// sunLongitude returns the Sun's apparent geocentric ecliptic longitude.
+func sunLongitude(jd float64) float64 {
+	t := centuries(jd)
+	l0 := 280.46646 + 36000.76983*t + 0.0003032*t*t
+	m := rad(357.52911 + 35999.05029*t - 0.0001537*t*t)
+	c := (1.914602-0.004817*t-0.000014*t*t)*math.Sin(m) +
+		(0.019993-0.000101*t)*math.Sin(2*m) +
+		0.000289*math.Sin(3*m)
+	omega := rad(125.04 - 1934.136*t)
+	return norm360(l0 + c - 0.00569 - 0.00478*math.Sin(omega))
+}
+
+// Periodic terms for the Moon's longitude: multiples of D, M, M', F and the
+// coefficient in millionths of a degree.
+var moonTerms = [][5]float64{
+	{0, 0, 1, 0, 6288774}, {2, 0, -1, 0, 1274027}, {2, 0, 0, 0, 658314},
+	{0, 0, 2, 0, 213618}, {0, 1, 0, 0, -185116}, {0, 0, 0, 2, -114332},
+	{2, 0, -2, 0, 58793}, {2, -1, -1, 0, 57066}, {2, 0, 1, 0, 53322},
+	{2, -1, 0, 0, 45758}, {0, 1, -1, 0, -40923}, {1, 0, 0, 0, -34720},
+	{0, 1, 1, 0, -30383}, {2, 0, 0, -2, 15327}, {0, 0, 1, 2, -12528},
+	{0, 0, 1, -2, 10980}, {4, 0, -1, 0, 10675}, {0, 0, 3, 0, 10034},
+	{4, 0, -2, 0, 8548}, {2, 1, -1, 0, -7888}, {2, 1, 0, 0, -6766},
+	{1, 0, -1, 0, -5163}, {1, 1, 0, 0, 4987}, {2, -1, 1, 0, 4036},
+	{2, 0, 2, 0, 3994}, {4, 0, 0, 0, 3861}, {2, 0, -3, 0, 3665},
+	{0, 1, -2, 0, -2689}, {2, 0, -1, 2, -2602}, {2, -1, -2, 0, 2390},
+	{1, 0, 1, 0, -2348}, {2, -2, 0, 0, 2236}, {0, 1, 2, 0, -2120},
+	{0, 2, 0, 0, -2069}, {2, -2, -1, 0, 2048}, {2, 0, 1, -2, -1773},
+	{2, 0, 0, 2, -1595}, {4, -1, -1, 0, 1215}, {0, 0, 2, 2, -1110},
+	{3, 0, -1, 0, -892}, {2, 1, 1, 0, -810}, {4, -1, -2, 0, 759},
+	{0, 2, -1, 0, -713}, {2, 2, -1, 0, -700}, {2, 1, -2, 0, 691},
+	{2, -1, 0, -2, 596}, {4, 0, 1, 0, 549}, {0, 0, 4, 0, 537},
+	{4, -1, 0, 0, 520}, {1, 0, -2, 0, -487}, {2, 1, 0, -2, -399},
+	{0, 0, 2, -2, -381}, {1, 1, 1, 0, 351}, {3, 0, -2, 0, -340},
+	{4, 0, -3, 0, 330}, {2, -1, 2, 0, 327}, {0, 2, 1, 0, -323},
+	{1, 1, -1, 0, 299}, {2, 0, 3, 0, 294},
+}
+
+// moonLongitude returns the Moon's apparent geocentric ecliptic longitude.
+func moonLongitude(jd float64) float64 {
+	t := centuries(jd)
+	t2, t3, t4 := t*t, t*t*t, t*t*t*t
+	lp := 218.3164477 + 481267.88123421*t - 0.0015786*t2 + t3/538841 - t4/65194000
+	d := 297.8501921 + 445267.1114034*t - 0.0018819*t2 + t3/545868 - t4/113065000
+	m := 357.5291092 + 35999.0502909*t - 0.0001536*t2 + t3/24490000
+	mp := 134.9633964 + 477198.8675055*t + 0.0087414*t2 + t3/69699 - t4/14712000
+	f := 93.2720950 + 483202.0175233*t - 0.0036539*t2 - t3/3526000 + t4/863310000
+	e := 1 - 0.002516*t - 0.0000074*t2
+
+	sum := 0.0
+	for _, term := range moonTerms {
+		arg := term[0]*d + term[1]*m + term[2]*mp + term[3]*f
+		coeff := term[4]
+		switch math.Abs(term[1]) {
+		case 1:
+			coeff *= e
+		case 2:
+			coeff *= e * e
+		}
+		sum += coeff * math.Sin(rad(arg))
+	}
+	a1 := 119.75 + 131.849*t
+	a2 := 53.09 + 479264.290*t
+	sum += 3958*math.Sin(rad(a1)) + 1962*math.Sin(rad(lp-f)) + 318*math.Sin(rad(a2))
+
+	nutation := -0.00478 * math.Sin(rad(125.04-1934.136*t))
+	return norm360(lp + sum/1e6 + nutation)
+}
+
+// elongation is the Moon's longitude minus the Sun's, 0 at new moon.
+func elongation(jd float64) float64 {
+	return norm360(moonLongitude(jd) - sunLongitude(jd))
+}
+
+// tithiAt returns the tithi (1-30) in effect at jd. 1-15 are the bright half
+// ending at purnima; 16-30 the dark half ending at amavasya.
+func tithiAt(jd float64) int {
+	return int(elongation(jd)/12) + 1
+}
+
+// newMoonBefore returns the Julian day of the last new moon at or before jd.
+func newMoonBefore(jd float64) float64 {
+	nm := jd - elongation(jd)/synodicRate
+	for i := 0; i < 5; i++ {
+		e := elongation(nm)
+		if e > 180 {
+			e -= 360
+		}
+		nm -= e / synodicRate
+	}
+	if nm > jd {
+		nm = newMoonBefore(nm - 1)
+	}
+	return nm
+}
+
+// ayanamsa approximates the Lahiri ayanamsa used to convert tropical to
+// sidereal longitude.
+func ayanamsa(jd float64) float64 {
+	return 23.853 + 1.3969*centuries(jd)
+}
+
+// siderealSign returns the sidereal zodiac sign (0 = Mesha ... 11 = Meena)
+// of the Sun at jd.
+func siderealSign(jd float64) int {
+	return int(norm360(sunLongitude(jd)-ayanamsa(jd)) / 30)
+}
+
+// sunTimes returns sunrise and sunset on the given civil date at lat/lng
+// (degrees, east positive). ok is false when the Sun doesn't rise or set.
+func sunTimes(year int, month time.Month, day int, lat, lng float64) (rise, set time.Time, ok bool) {
+	noon := time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+	n := math.Round(julianDay(noon) - j2000)
+	jstar := n - lng/360
+
+	m := norm360(357.5291 + 0.98560028*jstar)
+	c := 1.9148*math.Sin(rad(m)) + 0.0200*math.Sin(rad(2*m)) + 0.0003*math.Sin(rad(3*m))
+	lambda := norm360(m + c + 180 + 102.9372)
+	transit := j2000 + jstar + 0.0053*math.Sin(rad(m)) - 0.0069*math.Sin(rad(2*lambda))
+
+	sinDecl := math.Sin(rad(lambda)) * math.Sin(rad(23.4397))
+	cosDecl := math.Cos(math.Asin(sinDecl))
+	cosH := (math.Sin(rad(sunriseAngle)) - math.Sin(rad(lat))*sinDecl) / (math.Cos(rad(lat)) * cosDecl)
+	if cosH < -1 || cosH > 1 {
+		return time.Time{}, time.Time{}, false
+	}
+	h := math.Acos(cosH) * 180 / math.Pi
+	return fromJulianDay(transit - h/360), fromJulianDay(transit + h/360), true
+}
@@ -0,0 +1,64 @@
+package jaincalendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// Navkarsi is the wait after sunrise before the first meal.
+const Navkarsi = 48 * time.Minute
+
+// ChoviharRules control how far before sunset food must arrive.
+type ChoviharRules struct {
+	DeliveryBuffer time.Duration // Last delivery this long before sunset, leaving time to eat
+	OrderLead      time.Duration // Orders must be placed this long before the last delivery
+}
+
+// ChoviharWindow is when a chovihar observer can receive and eat food on a
+// day at a delivery location.
+type ChoviharWindow struct {
+	Date         string    `json:"date"`
+	Sunrise      time.Time `json:"sunrise"`
+	Sunset       time.Time `json:"sunset"`
+	EarliestMeal time.Time `json:"earliest_meal"` // Sunrise plus navkarsi
+	LastDelivery time.Time `json:"last_delivery"`
+	OrderCutoff  time.Time `json:"order_cutoff"`
+}
+
+// Open reports whether an order placed at t can still arrive in time.
+func (w ChoviharWindow) Open(t time.Time) bool {
+	return t.Before(w.OrderCutoff)
+}
+
+// Allows reports whether a delivery at t falls inside the window.
+func (w ChoviharWindow) Allows(t time.Time) bool {
+	return !t.Before(w.EarliestMeal) && !t.After(w.LastDelivery)
+}
+
+// Chovihar returns the window for the civil day containing t at the place.
+func Chovihar(t time.Time, place Place, rules ChoviharRules) (ChoviharWindow, error) {
+	local := t.In(place.location())
+	rise, set, ok := sunTimes(local.Year(), local.Month(), local.Day(), place.Lat, place.Lng)
+	if !ok {
+		return ChoviharWindow{}, fmt.Errorf("no sunset at %.2f,%.2f on %s", place.Lat, place.Lng, local.Format("2006-01-02"))
+	}
+	last := set.Add(-rules.DeliveryBuffer)
+	return ChoviharWindow{
+		Date:         local.Format("2006-01-02"),
+		Sunrise:      rise.In(local.Location()),
+		Sunset:       set.In(local.Location()),
+		EarliestMeal: rise.Add(Navkarsi).In(local.Location()),
+		LastDelivery: last.In(local.Location()),
+		OrderCutoff:  last.Add(-rules.OrderLead).In(local.Location()),
+	}, nil
+}
+
+// NextChovihar returns today's window if it is still open at t, otherwise
+// tomorrow's.
+func NextChovihar(t time.Time, place Place, rules ChoviharRules) (ChoviharWindow, error) {
+	w, err := Chovihar(t, place, rules)
+	if err != nil || w.Open(t) {
+		return w, err
+	}
+	return Chovihar(t.In(place.location()).AddDate(0, 0, 1), place, rules)
+}
@@ -0,0 +1,293 @@
+// Package jaincalendar computes the Jain observance calendar (tithis,
+// Paryushan, Ayambil Oli) and sunrise/sunset based chovihar windows for a
+// location, without calling any external panchang service.
+package jaincalendar
+
+import (
+	"container/list"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"jainfood/internal/hours"
+)
+
+// Observances that affect what an observant Jain eats on a day.
+const (
+	ObservanceAshtami     = "ashtami"      // 8th tithi of either fortnight: no green vegetables
+	ObservanceChaudas     = "chaudas"      // 14th tithi of either fortnight: no green vegetables
+	ObservanceParyushan   = "paryushan"    // Shwetambar 8 days ending on Samvatsari
+	ObservanceSamvatsari  = "samvatsari"   // Bhadrapada shukla chaturthi
+	ObservanceDasLakshana = "das-lakshana" // Digambar 10 days, Bhadrapada shukla 5-14
+	ObservanceAyambilOli  = "ayambil-oli"  // Chaitra and Ashvin shukla 7 to purnima
+)
+
+// Lunar months, amanta (new moon to new moon) reckoning.
+var monthNames = []string{
+	"chaitra", "vaishakha", "jyeshtha", "ashadha", "shravana", "bhadrapada",
+	"ashvin", "kartika", "margashirsha", "pausha", "magha", "phalguna",
+}
+
+const (
+	monthChaitra    = 0
+	monthBhadrapada = 5
+	monthAshvin     = 6
+)
+
+var tithiNames = []string{
+	"pratipada", "dwitiya", "tritiya", "chaturthi", "panchami", "shashthi", "saptami",
+	"ashtami", "navami", "dashami", "ekadashi", "dwadashi", "trayodashi", "chaturdashi",
+}
+
+// Place is where the calendar is computed; sunrise, and with it the tithi
+// of the day, depends on the location.
+type Place struct {
+	Lat      float64
+	Lng      float64
+	Location *time.Location
+}
+
+// DefaultPlace is Ahmedabad, the reference many Jain panchangs use.
+var DefaultPlace = Place{Lat: 23.0225, Lng: 72.5714, Location: hours.LoadLocation(hours.DefaultTimezone)}
+
+func (p Place) location() *time.Location {
+	if p.Location == nil {
+		return time.UTC
+	}
+	return p.Location
+}
+
+// Day is one civil day of the calendar. The tithi of the day is the one in
+// effect at sunrise (udaya tithi).
+type Day struct {
+	Date        string    `json:"date"` // YYYY-MM-DD in the place's timezone
+	Sunrise     time.Time `json:"sunrise"`
+	Sunset      time.Time `json:"sunset"`
+	Tithi       int       `json:"tithi"` // 1-30; 1-15 shukla, 16-30 krishna
+	TithiName   string    `json:"tithi_name"`
+	Paksha      string    `json:"paksha"` // shukla or krishna
+	Month       string    `json:"month"`
+	AdhikMaas   bool      `json:"adhik_maas,omitempty"`   // Intercalary month; festivals fall in the regular one
+	KshayaTithi int       `json:"kshaya_tithi,omitempty"` // Tithi that begins and ends between this and the next sunrise
+	Observances []string  `json:"observances"`
+
+	month int
+}
+
+// Has reports whether the day carries the given observance.
+func (d Day) Has(observance string) bool {
+	for _, o := range d.Observances {
+		if o == observance {
+			return true
+		}
+	}
+	return false
+}
+
+// AvoidsGreenVegetables reports whether observant Jains avoid green
+// vegetables on the day (ashtami, chaudas and the Paryushan period).
+func (d Day) AvoidsGreenVegetables() bool {
+	return d.Has(ObservanceAshtami) || d.Has(ObservanceChaudas) ||
+		d.Has(ObservanceParyushan) || d.Has(ObservanceDasLakshana)
+}
+
+// hasTithi matches the udaya tithi or a kshaya tithi falling within the
+// day, so a skipped ashtami is still observed.
+func (d Day) hasTithi(tithis ...int) bool {
+	for _, t := range tithis {
+		if d.Tithi == t || d.KshayaTithi == t {
+			return true
+		}
+	}
+	return false
+}
+
+func tithiName(t int) (name, paksha string) {
+	switch t {
+	case 15:
+		return "purnima", "shukla"
+	case 30:
+		return "amavasya", "krishna"
+	}
+	if t <= 15 {
+		return tithiNames[t-1], "shukla"
+	}
+	return tithiNames[t-16], "krishna"
+}
+
+const (
+	// gridDegrees is the resolution Year snaps places to. A tenth of a
+	// degree (about 11 km) moves sunrise by well under a minute, so nearby
+	// places share one computed year.
+	gridDegrees = 0.1
+	// maxCachedYears bounds the year cache; the least recently used year
+	// is dropped once it is full.
+	maxCachedYears = 256
+)
+
+// snapped returns the place moved to the nearest grid point.
+func (p Place) snapped() Place {
+	p.Lat = math.Round(p.Lat/gridDegrees) * gridDegrees
+	p.Lng = math.Round(p.Lng/gridDegrees) * gridDegrees
+	return p
+}
+
+// yearLRU is a fixed-size cache of computed years, safe for concurrent use.
+type yearLRU struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // Most recently used first
+	entries map[string]*list.Element
+}
+
+type cachedYear struct {
+	key  string
+	days []Day
+}
+
+func newYearLRU(max int) *yearLRU {
+	return &yearLRU{max: max, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (c *yearLRU) get(key string) ([]Day, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cachedYear).days, true
+}
+
+func (c *yearLRU) put(key string, days []Day) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*cachedYear).days = days
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cachedYear{key: key, days: days})
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedYear).key)
+	}
+}
+
+var yearCache = newYearLRU(maxCachedYears)
+
+// Year returns every day of the given Gregorian year at the place, computed
+// at the nearest point of a 0.1° grid.
+func Year(year int, place Place) ([]Day, error) {
+	place = place.snapped()
+	loc := place.location()
+	key := fmt.Sprintf("%d:%.1f:%.1f:%s", year, place.Lat, place.Lng, loc)
+	if days, ok := yearCache.get(key); ok {
+		return days, nil
+	}
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	var days []Day
+	for date := start; date.Year() == year; date = date.AddDate(0, 0, 1) {
+		day, err := computeDay(date, place)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	markObservances(days)
+
+	yearCache.put(key, days)
+	return days, nil
+}
+
+// On returns the calendar day containing t at the place.
+func On(t time.Time, place Place) (Day, error) {
+	local := t.In(place.location())
+	days, err := Year(local.Year(), place)
+	if err != nil {
+		return Day{}, err
+	}
+	return days[local.YearDay()-1], nil
+}
+
+func computeDay(date time.Time, place Place) (Day, error) {
+	loc := place.location()
+	rise, set, ok := sunTimes(date.Year(), date.Month(), date.Day(), place.Lat, place.Lng)
+	if !ok {
+		return Day{}, fmt.Errorf("no sunrise at %.2f,%.2f on %s", place.Lat, place.Lng, date.Format("2006-01-02"))
+	}
+	next := date.AddDate(0, 0, 1)
+	nextRise, _, ok := sunTimes(next.Year(), next.Month(), next.Day(), place.Lat, place.Lng)
+	if !ok {
+		return Day{}, fmt.Errorf("no sunrise at %.2f,%.2f on %s", place.Lat, place.Lng, next.Format("2006-01-02"))
+	}
+
+	jd := julianDay(rise)
+	tithi := tithiAt(jd)
+	name, paksha := tithiName(tithi)
+
+	// The month is named after the sidereal sign the Sun occupies at the new
+	// moon that starts it; two new moons in one sign make the first adhik.
+	newMoon := newMoonBefore(jd)
+	sign := siderealSign(newMoon)
+	nextNewMoon := newMoonBefore(newMoon + 31)
+	month := (sign + 1) % 12
+
+	day := Day{
+		Date:        date.Format("2006-01-02"),
+		Sunrise:     rise.In(loc),
+		Sunset:      set.In(loc),
+		Tithi:       tithi,
+		TithiName:   name,
+		Paksha:      paksha,
+		Month:       monthNames[month],
+		AdhikMaas:   siderealSign(nextNewMoon) == sign,
+		Observances: []string{},
+		month:       month,
+	}
+	if nextTithi := tithiAt(julianDay(nextRise)); (nextTithi-tithi+30)%30 == 2 {
+		day.KshayaTithi = tithi%30 + 1
+	}
+	return day, nil
+}
+
+func markObservances(days []Day) {
+	for i := range days {
+		d := &days[i]
+		if d.hasTithi(8, 23) {
+			d.Observances = append(d.Observances, ObservanceAshtami)
+		}
+		if d.hasTithi(14, 29) {
+			d.Observances = append(d.Observances, ObservanceChaudas)
+		}
+		if d.AdhikMaas {
+			continue
+		}
+		shukla := d.Tithi <= 15
+		switch {
+		case d.month == monthBhadrapada && shukla && d.Tithi >= 5 && d.Tithi <= 14:
+			d.Observances = append(d.Observances, ObservanceDasLakshana)
+		case (d.month == monthChaitra || d.month == monthAshvin) && shukla && d.Tithi >= 7:
+			d.Observances = append(d.Observances, ObservanceAyambilOli)
+		}
+	}
+
+	// Paryushan is the eight days ending on Samvatsari
+	for i := range days {
+		d := days[i]
+		if d.AdhikMaas || d.month != monthBhadrapada || !d.hasTithi(4) {
+			continue
+		}
+		days[i].Observances = append(days[i].Observances, ObservanceSamvatsari)
+		for j := i - 7; j <= i; j++ {
+			if j >= 0 {
+				days[j].Observances = append(days[j].Observances, ObservanceParyushan)
+			}
+		}
+		break
+	}
+}
@@ -0,0 +1,173 @@
+package jaincalendar
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var ist = time.FixedZone("IST", 5*60*60+30*60)
+
+func TestElongationAtKnownLunations(t *testing.T) {
+	newMoon := julianDay(time.Date(2024, 1, 11, 11, 57, 0, 0, time.UTC))
+	fullMoon := julianDay(time.Date(2024, 1, 25, 17, 54, 0, 0, time.UTC))
+
+	if e := elongation(newMoon); e > 0.2 && e < 359.8 {
+		t.Errorf("elongation at new moon = %.3f", e)
+	}
+	if e := elongation(fullMoon); e < 179.8 || e > 180.2 {
+		t.Errorf("elongation at full moon = %.3f", e)
+	}
+	if got := fromJulianDay(newMoonBefore(fullMoon)); got.Sub(time.Date(2024, 1, 11, 11, 57, 0, 0, time.UTC)).Abs() > 10*time.Minute {
+		t.Errorf("new moon before 25 Jan = %v", got)
+	}
+}
+
+func TestSunTimes(t *testing.T) {
+	cases := []struct {
+		name      string
+		lat, lng  float64
+		date      time.Time
+		rise, set string
+	}{
+		{"mumbai solstice", 19.076, 72.8777, time.Date(2024, 6, 21, 0, 0, 0, 0, ist), "06:02", "19:18"},
+		{"delhi winter", 28.61, 77.21, time.Date(2024, 12, 21, 0, 0, 0, 0, ist), "07:10", "17:29"},
+	}
+	for _, tc := range cases {
+		rise, set, ok := sunTimes(tc.date.Year(), tc.date.Month(), tc.date.Day(), tc.lat, tc.lng)
+		if !ok {
+			t.Fatalf("%s: no sunrise", tc.name)
+		}
+		for _, pair := range [][2]interface{}{{rise, tc.rise}, {set, tc.set}} {
+			got := pair[0].(time.Time).In(ist)
+			want, _ := time.ParseInLocation("2006-01-02 15:04", tc.date.Format("2006-01-02 ")+pair[1].(string), ist)
+			if got.Sub(want).Abs() > 3*time.Minute {
+				t.Errorf("%s: got %s, want %s", tc.name, got.Format("15:04"), pair[1])
+			}
+		}
+	}
+}
+
+func TestParyushanDates(t *testing.T) {
+	cases := map[int][2]string{
+		2024: {"2024-08-31", "2024-09-07"},
+		2025: {"2025-08-20", "2025-08-27"},
+	}
+	for year, want := range cases {
+		days, err := Year(year, DefaultPlace)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var first, samvatsari string
+		for _, d := range days {
+			if d.Has(ObservanceParyushan) && first == "" {
+				first = d.Date
+			}
+			if d.Has(ObservanceSamvatsari) {
+				samvatsari = d.Date
+			}
+		}
+		if first != want[0] || samvatsari != want[1] {
+			t.Errorf("%d: paryushan %s to %s, want %s to %s", year, first, samvatsari, want[0], want[1])
+		}
+	}
+}
+
+func TestTithiObservances(t *testing.T) {
+	days, err := Year(2024, DefaultPlace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ashtami, chaudas := 0, 0
+	for _, d := range days {
+		if d.Has(ObservanceAshtami) {
+			ashtami++
+			if !d.AvoidsGreenVegetables() {
+				t.Errorf("%s: ashtami should avoid green vegetables", d.Date)
+			}
+		}
+		if d.Has(ObservanceChaudas) {
+			chaudas++
+		}
+	}
+	// Two of each per lunar month, give or take a vruddhi/kshaya day
+	if ashtami < 23 || ashtami > 28 || chaudas < 23 || chaudas > 28 {
+		t.Errorf("unexpected counts: ashtami=%d chaudas=%d", ashtami, chaudas)
+	}
+
+	day, err := On(time.Date(2024, 4, 20, 12, 0, 0, 0, ist), DefaultPlace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !day.Has(ObservanceAyambilOli) || day.Month != "chaitra" {
+		t.Errorf("20 Apr 2024 should fall in the Chaitra Ayambil Oli: %+v", day)
+	}
+}
+
+func TestChoviharWindow(t *testing.T) {
+	rules := ChoviharRules{DeliveryBuffer: 30 * time.Minute, OrderLead: 45 * time.Minute}
+	place := Place{Lat: 19.076, Lng: 72.8777, Location: ist}
+
+	noon := time.Date(2024, 6, 21, 12, 0, 0, 0, ist)
+	w, err := Chovihar(noon, place, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Sunset.Sub(w.OrderCutoff); got != 75*time.Minute {
+		t.Errorf("order cutoff %v before sunset, want 75m", got)
+	}
+	if !w.Open(noon) || !w.Allows(noon) {
+		t.Error("window should be open at noon")
+	}
+	if w.Allows(w.Sunset) {
+		t.Error("delivery at sunset should not be allowed")
+	}
+
+	evening := time.Date(2024, 6, 21, 18, 30, 0, 0, ist)
+	next, err := NextChovihar(evening, place, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Date != "2024-06-22" {
+		t.Errorf("after cutoff the next window should be tomorrow, got %s", next.Date)
+	}
+}
+
+func TestYearSnapsToGrid(t *testing.T) {
+	a, err := Year(2026, Place{Lat: 19.076, Lng: 72.8777, Location: ist})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Year(2026, Place{Lat: 19.0812, Lng: 72.9049, Location: ist})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &a[0] != &b[0] {
+		t.Error("places in the same grid cell computed separate years")
+	}
+	if got := (Place{Lat: 19.076, Lng: 72.8777}).snapped(); math.Abs(got.Lat-19.1) > 1e-9 || math.Abs(got.Lng-72.9) > 1e-9 {
+		t.Errorf("snapped = %v,%v", got.Lat, got.Lng)
+	}
+}
+
+func TestYearLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newYearLRU(2)
+	c.put("a", []Day{{Date: "a"}})
+	c.put("b", []Day{{Date: "b"}})
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("a missing")
+	}
+	c.put("c", []Day{{Date: "c"}})
+
+	if _, ok := c.get("b"); ok {
+		t.Error("b should have been evicted as least recently used")
+	}
+	for _, k := range []string{"a", "c"} {
+		if days, ok := c.get(k); !ok || days[0].Date != k {
+			t.Errorf("get(%q) = %v, %v", k, days, ok)
+		}
+	}
+	if len(c.entries) != 2 || c.order.Len() != 2 {
+		t.Errorf("cache holds %d entries, %d in order", len(c.entries), c.order.Len())
+	}
+}
@@ -0,0 +1,199 @@
+package jainrules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Verdict statuses, from strictest to most lenient.
+const (
+	VerdictJain    = "jain"     // No forbidden or caution ingredients found
+	VerdictCaution = "caution"  // Only ingredients whose acceptance varies by practice
+	VerdictNonJain = "non-jain" // At least one forbidden ingredient
+)
+
+// Finding is a single taxonomy match in an item.
+type Finding struct {
+	Ingredient string `json:"ingredient"` // As written by the provider
+	Matched    string `json:"matched"`    // Taxonomy key
+	Category   string `json:"category"`
+	Status     Status `json:"status"`
+	Reason     string `json:"reason"`
+}
+
+// Verdict is the compliance result for a menu item.
+type Verdict struct {
+	Status    string    `json:"status"` // jain, caution, non-jain
+	Compliant bool      `json:"compliant"`
+	Findings  []Finding `json:"findings"`
+	Version   string    `json:"taxonomy_version"`
+}
+
+// Reasons returns one human-readable line per finding.
+func (v *Verdict) Reasons() []string {
+	reasons := make([]string, 0, len(v.Findings))
+	for _, f := range v.Findings {
+		reasons = append(reasons, fmt.Sprintf("%s: %s", f.Ingredient, f.Reason))
+	}
+	return reasons
+}
+
+// Forbidden returns the findings that make the item non-Jain.
+func (v *Verdict) Forbidden() []Finding {
+	var out []Finding
+	for _, f := range v.Findings {
+		if f.Status == StatusForbidden {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// negators make the following match not count ("no onion", "bina lehsun").
+var negators = map[string]bool{
+	"no": true, "without": true, "minus": true, "bina": true, "बिना": true, "nahi": true,
+}
+
+// Suffixes that negate the preceding match ("garlic free", "onion-free").
+var negatingSuffixes = map[string]bool{"free": true, "less": true}
+
+type phrase struct {
+	tokens     []string
+	ingredient *Ingredient
+}
+
+// index maps the first token of every synonym to its phrases, longest first,
+// so "dry ginger" wins over "ginger" and "patta gobhi" over "gobhi".
+var index = buildIndex(taxonomy)
+
+func buildIndex(entries []Ingredient) map[string][]phrase {
+	idx := map[string][]phrase{}
+	for i := range entries {
+		ing := &entries[i]
+		for _, syn := range ing.Synonyms {
+			toks := tokenize(syn)
+			if len(toks) == 0 {
+				continue
+			}
+			idx[toks[0]] = append(idx[toks[0]], phrase{tokens: toks, ingredient: ing})
+		}
+	}
+	for k := range idx {
+		sort.SliceStable(idx[k], func(a, b int) bool {
+			return len(idx[k][a].tokens) > len(idx[k][b].tokens)
+		})
+	}
+	return idx
+}
+
+// tokenize lowercases s and splits it into words. Combining marks are kept
+// so Devanagari words (आलू, लहसुन) stay intact.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+}
+
+// match returns the taxonomy entries mentioned in text, skipping negated and
+// explicitly allowed mentions.
+func match(text string) []*Ingredient {
+	toks := tokenize(text)
+	var found []*Ingredient
+	for i := 0; i < len(toks); {
+		var hit *phrase
+		for _, p := range index[toks[i]] {
+			if i+len(p.tokens) <= len(toks) && equalTokens(toks[i:i+len(p.tokens)], p.tokens) {
+				hit = &p
+				break
+			}
+		}
+		if hit == nil {
+			i++
+			continue
+		}
+
+		end := i + len(hit.tokens)
+		negated := (i > 0 && negators[toks[i-1]]) || (end < len(toks) && negatingSuffixes[toks[end]])
+		if !negated && hit.ingredient.Status != StatusAllowed {
+			found = append(found, hit.ingredient)
+		}
+		i = end
+	}
+	return found
+}
+
+func equalTokens(a, b []string) bool {
+	for i := range b {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Check evaluates an item's name and ingredients against the taxonomy.
+func Check(name string, ingredients []string) *Verdict {
+	v := &Verdict{Status: VerdictJain, Compliant: true, Findings: []Finding{}, Version: TaxonomyVersion}
+	seen := map[string]bool{}
+
+	add := func(source string) {
+		for _, ing := range match(source) {
+			if seen[ing.Key] {
+				continue
+			}
+			seen[ing.Key] = true
+			v.Findings = append(v.Findings, Finding{
+				Ingredient: strings.TrimSpace(source),
+				Matched:    ing.Key,
+				Category:   ing.Category,
+				Status:     ing.Status,
+				Reason:     ing.Reason,
+			})
+			switch ing.Status {
+			case StatusForbidden:
+				v.Status = VerdictNonJain
+				v.Compliant = false
+			case StatusCaution:
+				if v.Status == VerdictJain {
+					v.Status = VerdictCaution
+				}
+			}
+		}
+	}
+
+	for _, ing := range ingredients {
+		add(ing)
+	}
+	if name != "" {
+		add(name)
+	}
+	return v
+}
+
+// ExclusionTerms returns every synonym of the given taxonomy keys, for use
+// as an ingredient exclusion list in search.
+func ExclusionTerms(keys ...string) []string {
+	want := map[string]bool{}
+	for _, k := range keys {
+		want[k] = true
+	}
+	var terms []string
+	for _, ing := range taxonomy {
+		if want[ing.Key] || want[ing.Category] {
+			terms = append(terms, ing.Synonyms...)
+		}
+	}
+	return terms
+}
+
+// Lookup returns the taxonomy entry mentioned in text, if any.
+func Lookup(text string) (*Ingredient, bool) {
+	found := match(text)
+	if len(found) == 0 {
+		return nil, false
+	}
+	ing := *found[0]
+	return &ing, true
+}
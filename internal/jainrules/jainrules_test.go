@@ -0,0 +1,100 @@
+package jainrules
+
+import "testing"
+
+func TestCheckForbiddenSynonyms(t *testing.T) {
+	tests := []struct {
+		ingredient string
+		key        string
+	}{
+		{"Aloo", "potato"},
+		{"boiled potatoes", "potato"},
+		{"लहसुन", "garlic"},
+		{"lehsun paste", "garlic"},
+		{"Pyaaz", "onion"},
+		{"phool gobhi", "cauliflower"},
+		{"Anjeer", "fig"},
+		{"silver varq", "silver-varq"},
+		{"साबूदाना", "sabudana"},
+		{"adrak", "ginger"},
+	}
+	for _, tt := range tests {
+		v := Check("", []string{tt.ingredient})
+		if v.Status != VerdictNonJain || v.Compliant {
+			t.Errorf("%q: expected non-jain, got %s", tt.ingredient, v.Status)
+			continue
+		}
+		if v.Findings[0].Matched != tt.key {
+			t.Errorf("%q: matched %s, want %s", tt.ingredient, v.Findings[0].Matched, tt.key)
+		}
+	}
+}
+
+func TestCheckCompliantItem(t *testing.T) {
+	v := Check("Jain Dal Baati", []string{"toor dal", "wheat flour", "ghee", "haldi", "saunth", "hing"})
+	if v.Status != VerdictJain || !v.Compliant || len(v.Findings) != 0 {
+		t.Errorf("expected jain verdict, got %+v", v)
+	}
+	if v.Version != TaxonomyVersion {
+		t.Errorf("verdict version = %s", v.Version)
+	}
+}
+
+func TestCheckLongestMatchWins(t *testing.T) {
+	// "dry ginger" and "turmeric powder" are acceptable even though
+	// "ginger" and "kachi haldi" are not
+	if v := Check("", []string{"dry ginger", "turmeric powder"}); v.Status != VerdictJain {
+		t.Errorf("expected processed forms to be acceptable, got %+v", v.Findings)
+	}
+	if v := Check("", []string{"kachi haldi"}); v.Status != VerdictNonJain {
+		t.Errorf("expected raw turmeric to be forbidden, got %s", v.Status)
+	}
+	// Cabbage (patta gobhi) is only a caution, not cauliflower
+	v := Check("", []string{"patta gobhi"})
+	if v.Status != VerdictCaution || v.Findings[0].Matched != "cabbage" {
+		t.Errorf("expected cabbage caution, got %+v", v)
+	}
+}
+
+func TestCheckNegation(t *testing.T) {
+	v := Check("Pav Bhaji (no potato, without onion)", []string{"raw banana", "garlic-free masala", "बिना लहसुन"})
+	if v.Status != VerdictJain {
+		t.Errorf("negated mentions should not count, got %+v", v.Findings)
+	}
+}
+
+func TestCheckNameAndWholeWords(t *testing.T) {
+	v := Check("Aloo Paratha", []string{"wheat flour"})
+	if v.Status != VerdictNonJain {
+		t.Error("expected item name to be checked")
+	}
+	// Substrings of other words must not match
+	if v := Check("Eggless cake", []string{"gingerly folded cream", "figure-8 cookies"}); v.Status != VerdictJain {
+		t.Errorf("unexpected findings %+v", v.Findings)
+	}
+}
+
+func TestCheckCaution(t *testing.T) {
+	v := Check("", []string{"vinegar", "paneer"})
+	if v.Status != VerdictCaution || !v.Compliant {
+		t.Errorf("expected caution but compliant, got %+v", v)
+	}
+	v = Check("", []string{"vinegar", "onion"})
+	if v.Status != VerdictNonJain {
+		t.Errorf("forbidden should outrank caution, got %s", v.Status)
+	}
+	if len(v.Forbidden()) != 1 || len(v.Reasons()) != 2 {
+		t.Errorf("unexpected findings %+v", v.Findings)
+	}
+}
+
+func TestExclusionTerms(t *testing.T) {
+	terms := ExclusionTerms(CategoryBulb)
+	found := map[string]bool{}
+	for _, term := range terms {
+		found[term] = true
+	}
+	if !found["lehsun"] || !found["pyaz"] || found["aloo"] {
+		t.Errorf("unexpected exclusion terms %v", terms)
+	}
+}
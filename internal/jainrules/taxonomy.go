@@ -0,0 +1,152 @@
+package jainrules
+
+// TaxonomyVersion identifies the ingredient list below. Bump it whenever an
+// entry is added, removed or reclassified so stored verdicts can be rechecked.
+const TaxonomyVersion = "2026.1"
+
+// Ingredient categories.
+const (
+	CategoryRootVegetable = "root-vegetable" // Uprooting kills the plant and the organisms in the soil
+	CategoryBulb          = "bulb"           // Onion/garlic family
+	CategoryFungus        = "fungus"
+	CategoryManySeeded    = "many-seeded" // Bahubija: many seeds, each a potential life
+	CategoryInsectProne   = "insect-prone"
+	CategoryFermented     = "fermented"
+	CategoryAnimal        = "animal"
+	CategoryHoney         = "honey"
+	CategoryAlcohol       = "alcohol"
+	CategoryPermitted     = "permitted" // Processed forms that are acceptable (e.g. dry ginger)
+)
+
+// Status is how an ingredient affects Jain compliance.
+type Status string
+
+const (
+	StatusForbidden Status = "forbidden"
+	StatusCaution   Status = "caution" // Avoided by many, but practice varies
+	StatusAllowed   Status = "allowed"
+)
+
+// Ingredient is a taxonomy entry. Synonyms are matched as whole words in
+// English, Hinglish transliteration and Devanagari.
+type Ingredient struct {
+	Key      string   `json:"key"`
+	Category string   `json:"category"`
+	Status   Status   `json:"status"`
+	Synonyms []string `json:"synonyms"`
+	Reason   string   `json:"reason"`
+}
+
+// taxonomy is the curated ingredient list, based on the platform's terms and
+// conditions (root vegetables, onion, garlic, potato, mushroom, cauliflower,
+// brinjal, sabudana, anjeer, dragon fruit and silver varq).
+var taxonomy = []Ingredient{
+	// Root vegetables
+	{Key: "potato", Category: CategoryRootVegetable, Status: StatusForbidden,
+		Synonyms: []string{"potato", "potatoes", "aloo", "alu", "batata", "आलू", "बटाटा"},
+		Reason:   "potato is a root vegetable"},
+	{Key: "sweet-potato", Category: CategoryRootVegetable, Status: StatusForbidden,
+		Synonyms: []string{"sweet potato", "sweet potatoes", "shakarkandi", "shakarkand", "शकरकंद", "शकरकंदी"},
+		Reason:   "sweet potato is a root vegetable"},
+	{Key: "ginger", Category: CategoryRootVegetable, Status: StatusForbidden,
+		Synonyms: []string{"ginger", "fresh ginger", "ginger paste", "adrak", "adrakh", "अदरक"},
+		Reason:   "fresh ginger is a root (dry ginger/saunth is acceptable)"},
+	{Key: "carrot", Category: CategoryRootVegetable, Status: StatusForbidden,
+		Synonyms: []string{"carrot", "carrots", "gajar", "गाजर"},
+		Reason:   "carrot is a root vegetable"},
+	{Key: "radish", Category: CategoryRootVegetable, Status: StatusForbidden,
+		Synonyms: []string{"radish", "radishes", "mooli", "muli", "मूली"},
+		Reason:   "radish is a root vegetable"},
+	{Key: "beetroot", Category: CategoryRootVegetable, Status: StatusForbidden,
+		Synonyms: []string{"beetroot", "beet", "beets", "chukandar", "चुकंदर"},
+		Reason:   "beetroot is a root vegetable"},
+	{Key: "colocasia", Category: CategoryRootVegetable, Status: StatusForbidden,
+		Synonyms: []string{"colocasia", "taro", "arbi", "arvi", "अरबी", "अरवी"},
+		Reason:   "arbi (colocasia) is a root vegetable"},
+	{Key: "yam", Category: CategoryRootVegetable, Status: StatusForbidden,
+		Synonyms: []string{"yam", "elephant foot yam", "suran", "jimikand", "zimikand", "सूरन", "जिमीकंद"},
+		Reason:   "yam is a root vegetable"},
+	{Key: "turnip", Category: CategoryRootVegetable, Status: StatusForbidden,
+		Synonyms: []string{"turnip", "turnips", "shalgam", "शलजम"},
+		Reason:   "turnip is a root vegetable"},
+	{Key: "raw-turmeric", Category: CategoryRootVegetable, Status: StatusForbidden,
+		Synonyms: []string{"raw turmeric", "fresh turmeric", "kachi haldi", "कच्ची हल्दी"},
+		Reason:   "fresh turmeric is a root (dried turmeric powder is acceptable)"},
+
+	// Onion and garlic family
+	{Key: "onion", Category: CategoryBulb, Status: StatusForbidden,
+		Synonyms: []string{"onion", "onions", "spring onion", "shallot", "shallots", "leek", "pyaz", "pyaaz", "kanda", "प्याज", "प्याज़", "कांदा"},
+		Reason:   "onion grows underground as a bulb"},
+	{Key: "garlic", Category: CategoryBulb, Status: StatusForbidden,
+		Synonyms: []string{"garlic", "garlic paste", "lehsun", "lahsun", "lasun", "लहसुन"},
+		Reason:   "garlic grows underground as a bulb"},
+
+	// Fungi
+	{Key: "mushroom", Category: CategoryFungus, Status: StatusForbidden,
+		Synonyms: []string{"mushroom", "mushrooms", "khumb", "khumbi", "मशरूम", "खुंभी"},
+		Reason:   "mushroom is a fungus"},
+	{Key: "yeast", Category: CategoryFungus, Status: StatusCaution,
+		Synonyms: []string{"yeast", "khameer", "खमीर"},
+		Reason:   "yeast is a living organism; many Jains avoid it"},
+
+	// Many-seeded and insect-prone produce
+	{Key: "brinjal", Category: CategoryManySeeded, Status: StatusForbidden,
+		Synonyms: []string{"brinjal", "eggplant", "aubergine", "baingan", "bengan", "बैंगन"},
+		Reason:   "brinjal has many seeds"},
+	{Key: "fig", Category: CategoryManySeeded, Status: StatusForbidden,
+		Synonyms: []string{"fig", "figs", "anjeer", "anjir", "अंजीर"},
+		Reason:   "anjeer (fig) has many seeds and may contain insects"},
+	{Key: "dragon-fruit", Category: CategoryManySeeded, Status: StatusForbidden,
+		Synonyms: []string{"dragon fruit", "pitaya", "ड्रैगन फ्रूट"},
+		Reason:   "dragon fruit has many seeds"},
+	{Key: "cauliflower", Category: CategoryInsectProne, Status: StatusForbidden,
+		Synonyms: []string{"cauliflower", "gobi", "gobhi", "phool gobi", "phool gobhi", "फूलगोभी", "फूल गोभी", "गोभी"},
+		Reason:   "cauliflower harbours insects that cannot be removed"},
+	{Key: "broccoli", Category: CategoryInsectProne, Status: StatusCaution,
+		Synonyms: []string{"broccoli", "ब्रोकली"},
+		Reason:   "broccoli harbours insects; many Jains avoid it"},
+	{Key: "cabbage", Category: CategoryInsectProne, Status: StatusCaution,
+		Synonyms: []string{"cabbage", "patta gobi", "patta gobhi", "band gobhi", "पत्ता गोभी", "बंद गोभी", "पत्तागोभी"},
+		Reason:   "cabbage layers harbour insects; many Jains avoid it"},
+
+	// Processed ingredients
+	{Key: "sabudana", Category: CategoryFermented, Status: StatusForbidden,
+		Synonyms: []string{"sabudana", "sago", "tapioca pearls", "साबूदाना", "साबुदाना"},
+		Reason:   "sabudana is made by fermenting tapioca root"},
+	{Key: "vinegar", Category: CategoryFermented, Status: StatusCaution,
+		Synonyms: []string{"vinegar", "sirka", "सिरका"},
+		Reason:   "vinegar is fermented; many Jains avoid it"},
+	{Key: "silver-varq", Category: CategoryAnimal, Status: StatusForbidden,
+		Synonyms: []string{"silver varq", "silver vark", "silver leaf", "chandi varq", "chandi ka varq", "varq", "vark", "चांदी का वर्क", "वर्क"},
+		Reason:   "silver varq is traditionally beaten between animal membranes"},
+	{Key: "gelatin", Category: CategoryAnimal, Status: StatusForbidden,
+		Synonyms: []string{"gelatin", "gelatine", "जिलेटिन"},
+		Reason:   "gelatin is made from animal tissue"},
+	{Key: "egg", Category: CategoryAnimal, Status: StatusForbidden,
+		Synonyms: []string{"egg", "eggs", "anda", "ande", "अंडा", "अंडे"},
+		Reason:   "egg is an animal product"},
+	{Key: "meat", Category: CategoryAnimal, Status: StatusForbidden,
+		Synonyms: []string{"meat", "chicken", "mutton", "fish", "prawn", "prawns", "beef", "pork", "मांस", "चिकन", "मछली"},
+		Reason:   "meat and fish are not vegetarian"},
+	{Key: "honey", Category: CategoryHoney, Status: StatusForbidden,
+		Synonyms: []string{"honey", "shahad", "madhu", "शहद"},
+		Reason:   "honey harms bees"},
+	{Key: "alcohol", Category: CategoryAlcohol, Status: StatusForbidden,
+		Synonyms: []string{"alcohol", "wine", "rum", "beer", "brandy", "शराब"},
+		Reason:   "alcohol is forbidden"},
+
+	// Acceptable processed forms that would otherwise match a forbidden word
+	{Key: "dry-ginger", Category: CategoryPermitted, Status: StatusAllowed,
+		Synonyms: []string{"dry ginger", "dried ginger", "ginger powder", "saunth", "sonth", "सोंठ"},
+		Reason:   "dry ginger (saunth) is acceptable"},
+	{Key: "turmeric-powder", Category: CategoryPermitted, Status: StatusAllowed,
+		Synonyms: []string{"turmeric", "turmeric powder", "haldi", "haldi powder", "हल्दी"},
+		Reason:   "dried turmeric powder is acceptable"},
+}
+
+// Taxonomy returns a copy of the ingredient taxonomy.
+func Taxonomy() []Ingredient {
+	out := make([]Ingredient, len(taxonomy))
+	copy(out, taxonomy)
+	return out
+}
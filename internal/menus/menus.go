@@ -6,7 +6,9 @@ import (
 
 	"github.com/google/uuid"
 	"jainfood/internal/db"
+	"jainfood/internal/jainrules"
 	"jainfood/internal/models"
+	"jainfood/internal/translit"
 )
 
 // CreateMenu creates a new menu for a provider.
@@ -91,14 +93,21 @@ func DeleteMenu(ctx context.Context, menuID string) error {
 	return nil
 }
 
-// CreateMenuItem adds an item to a menu.
-func CreateMenuItem(ctx context.Context, menuID, name string, price float64, ingredients []string, isJain, availability bool, imageURL string) (*models.MenuItem, error) {
+// CreateMenuItem adds an item to a menu, together with its Jain verdict and
+// dietary tags, so an item is never stored without them.
+func CreateMenuItem(ctx context.Context, menuID, name string, price float64, ingredients []string, isJain, availability bool, imageURL string, verdict *jainrules.Verdict, tags []string) (*models.MenuItem, error) {
 	id := uuid.New().String()
+	if tags == nil {
+		tags = []string{}
+	}
+	reasons := verdict.Reasons()
 
 	_, err := db.Pool.Exec(ctx, `
-		INSERT INTO menu_items (id, menu_id, name, price, ingredients, is_jain, availability, image_url)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`, id, menuID, name, price, ingredients, isJain, availability, imageURL)
+		INSERT INTO menu_items (id, menu_id, name, price, ingredients, is_jain, availability, image_url, search_key,
+		                        jain_status, jain_reasons, jain_rules_version, dietary_tags)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, id, menuID, name, price, ingredients, isJain, availability, imageURL, translit.Key(name),
+		verdict.Status, reasons, verdict.Version, tags)
 	if err != nil {
 		return nil, err
 	}
@@ -110,6 +119,9 @@ func CreateMenuItem(ctx context.Context, menuID, name string, price float64, ing
 		Price:        price,
 		Ingredients:  ingredients,
 		IsJain:       isJain,
+		JainStatus:   verdict.Status,
+		JainReasons:  reasons,
+		DietaryTags:  tags,
 		Availability: availability,
 		ImageURL:     imageURL,
 	}, nil
@@ -119,11 +131,14 @@ func CreateMenuItem(ctx context.Context, menuID, name string, price float64, ing
 func GetMenuItem(ctx context.Context, itemID string) (*models.MenuItem, error) {
 	item := &models.MenuItem{}
 	err := db.Pool.QueryRow(ctx, `
-		SELECT id, menu_id, name, price, ingredients, is_jain, availability, image_url, created_at
+		SELECT id, menu_id, name, price, ingredients, is_jain,
+		       COALESCE(jain_status, ''), COALESCE(jain_reasons, '{}'), COALESCE(dietary_tags, '{}'),
+		       availability, image_url, created_at
 		FROM menu_items WHERE id = $1
 	`, itemID).Scan(
 		&item.ID, &item.MenuID, &item.Name, &item.Price,
-		&item.Ingredients, &item.IsJain, &item.Availability, &item.ImageURL, &item.CreatedAt,
+		&item.Ingredients, &item.IsJain, &item.JainStatus, &item.JainReasons, &item.DietaryTags,
+		&item.Availability, &item.ImageURL, &item.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -131,13 +146,19 @@ func GetMenuItem(ctx context.Context, itemID string) (*models.MenuItem, error) {
 	return item, nil
 }
 
-// GetMenuItems retrieves all items for a menu.
-func GetMenuItems(ctx context.Context, menuID string) ([]*models.MenuItem, error) {
+// GetMenuItems retrieves all items for a menu, optionally only those
+// carrying all of the given dietary tags.
+func GetMenuItems(ctx context.Context, menuID string, tags ...string) ([]*models.MenuItem, error) {
+	if tags == nil {
+		tags = []string{}
+	}
 	rows, err := db.Pool.Query(ctx, `
-		SELECT id, menu_id, name, price, ingredients, is_jain, availability, image_url, created_at
-		FROM menu_items WHERE menu_id = $1
+		SELECT id, menu_id, name, price, ingredients, is_jain,
+		       COALESCE(jain_status, ''), COALESCE(jain_reasons, '{}'), COALESCE(dietary_tags, '{}'),
+		       availability, image_url, created_at
+		FROM menu_items WHERE menu_id = $1 AND COALESCE(dietary_tags, '{}') @> $2
 		ORDER BY name ASC
-	`, menuID)
+	`, menuID, tags)
 	if err != nil {
 		return nil, err
 	}
@@ -148,7 +169,8 @@ func GetMenuItems(ctx context.Context, menuID string) ([]*models.MenuItem, error
 		item := &models.MenuItem{}
 		if err := rows.Scan(
 			&item.ID, &item.MenuID, &item.Name, &item.Price,
-			&item.Ingredients, &item.IsJain, &item.Availability, &item.ImageURL, &item.CreatedAt,
+			&item.Ingredients, &item.IsJain, &item.JainStatus, &item.JainReasons, &item.DietaryTags,
+			&item.Availability, &item.ImageURL, &item.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -157,13 +179,32 @@ func GetMenuItems(ctx context.Context, menuID string) ([]*models.MenuItem, error
 	return items, nil
 }
 
-// UpdateMenuItem updates item details.
-func UpdateMenuItem(ctx context.Context, itemID, name string, price float64, ingredients []string, isJain, availability bool, imageURL string) error {
+// UpdateMenuItem updates item details, the Jain verdict for them and, unless
+// tags is nil, the dietary tags, all in one statement.
+func UpdateMenuItem(ctx context.Context, itemID, name string, price float64, ingredients []string, isJain, availability bool, imageURL string, verdict *jainrules.Verdict, tags []string) error {
 	ct, err := db.Pool.Exec(ctx, `
 		UPDATE menu_items
-		SET name = $2, price = $3, ingredients = $4, is_jain = $5, availability = $6, image_url = $7
+		SET name = $2, price = $3, ingredients = $4, is_jain = $5, availability = $6, image_url = $7,
+		    search_key = $8, jain_status = $9, jain_reasons = $10, jain_rules_version = $11,
+		    dietary_tags = COALESCE($12, dietary_tags)
 		WHERE id = $1
-	`, itemID, name, price, ingredients, isJain, availability, imageURL)
+	`, itemID, name, price, ingredients, isJain, availability, imageURL, translit.Key(name),
+		verdict.Status, verdict.Reasons(), verdict.Version, tags)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return fmt.Errorf("menu item not found")
+	}
+	return nil
+}
+
+// SetDietaryTags replaces an item's dietary tags.
+func SetDietaryTags(ctx context.Context, itemID string, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+	ct, err := db.Pool.Exec(ctx, `UPDATE menu_items SET dietary_tags = $2 WHERE id = $1`, itemID, tags)
 	if err != nil {
 		return err
 	}
@@ -198,3 +239,74 @@ func DeleteMenuItem(ctx context.Context, itemID string) error {
 	}
 	return nil
 }
+
+// SetJainVerdict stores the computed Jain compliance status for an item.
+func SetJainVerdict(ctx context.Context, itemID, status string, reasons []string, version string) error {
+	ct, err := db.Pool.Exec(ctx, `
+		UPDATE menu_items SET jain_status = $2, jain_reasons = $3, jain_rules_version = $4
+		WHERE id = $1
+	`, itemID, status, reasons, version)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return fmt.Errorf("menu item not found")
+	}
+	return nil
+}
+
+// GetItemsNeedingJainCheck returns items whose verdict was computed with a
+// different taxonomy version (or never computed).
+func GetItemsNeedingJainCheck(ctx context.Context, version string, limit int) ([]*models.MenuItem, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT id, name, COALESCE(ingredients, '{}'), is_jain
+		FROM menu_items
+		WHERE jain_rules_version IS DISTINCT FROM $1
+		LIMIT $2
+	`, version, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.MenuItem
+	for rows.Next() {
+		item := &models.MenuItem{}
+		if err := rows.Scan(&item.ID, &item.Name, &item.Ingredients, &item.IsJain); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// GetFlaggedJainItems lists items that claim is_jain but were computed non-Jain.
+func GetFlaggedJainItems(ctx context.Context, limit, offset int) ([]*models.MenuItem, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT id, menu_id, name, price, ingredients, is_jain,
+		       COALESCE(jain_status, ''), COALESCE(jain_reasons, '{}'), COALESCE(dietary_tags, '{}'),
+		       availability, image_url, created_at
+		FROM menu_items
+		WHERE is_jain = TRUE AND jain_status = 'non-jain'
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*models.MenuItem{}
+	for rows.Next() {
+		item := &models.MenuItem{}
+		if err := rows.Scan(
+			&item.ID, &item.MenuID, &item.Name, &item.Price,
+			&item.Ingredients, &item.IsJain, &item.JainStatus, &item.JainReasons, &item.DietaryTags,
+			&item.Availability, &item.ImageURL, &item.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
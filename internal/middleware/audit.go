@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"jainfood/internal/events"
+)
+
+// auditDetailsKey holds details a handler adds to its audit event, and
+// auditedKey marks a request whose event has been recorded.
+const (
+	auditDetailsKey = "audit_details"
+	auditedKey      = "audited"
+)
+
+// AuditMiddleware records every admin request that changes something as an
+// ADMIN_ACTION event against the admin, with the route, its parameters, the
+// response status and any details the handler added with AuditDetail. It
+// runs after the handler so failed and refused attempts are recorded too.
+// It must run after AuthMiddleware.
+func AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		recordAudit(c)
+	}
+}
+
+// AuditAdminMiddleware is AuditMiddleware for routes admins share with
+// providers or buyers: it records the request only when the caller is an
+// admin. It can be used on a group whose routes authenticate individually,
+// and skips requests an inner AuditMiddleware has already recorded.
+func AuditAdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if role, _ := GetRoleFromContext(c); role == "admin" && !c.GetBool(auditedKey) {
+			recordAudit(c)
+		}
+	}
+}
+
+func recordAudit(c *gin.Context) {
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		return
+	}
+	adminID, ok := GetUserIDFromContext(c)
+	if !ok {
+		return
+	}
+	c.Set(auditedKey, true)
+
+	params := map[string]interface{}{}
+	for _, p := range c.Params {
+		params[p.Key] = p.Value
+	}
+	payload := map[string]interface{}{
+		"method": c.Request.Method,
+		"route":  c.FullPath(),
+		"params": params,
+		"status": c.Writer.Status(),
+	}
+	if details, ok := c.Get(auditDetailsKey); ok {
+		payload["details"] = details
+	}
+	_ = events.LogEvent(context.Background(), "admin", adminID, events.EventAdminAction, payload)
+}
+
+// AuditDetail adds a detail, such as a reason or a bulk action's outcome,
+// to the request's audit event.
+func AuditDetail(c *gin.Context, key string, value interface{}) {
+	details, _ := c.Get(auditDetailsKey)
+	m, ok := details.(map[string]interface{})
+	if !ok {
+		m = map[string]interface{}{}
+		c.Set(auditDetailsKey, m)
+	}
+	m[key] = value
+}
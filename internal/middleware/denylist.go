@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"jainfood/internal/db"
+	"jainfood/internal/redisclient"
+	"jainfood/internal/users"
+)
+
+// Blocked users are kept on a Redis denylist so their tokens stop working
+// at once instead of when they expire. Each entry's TTL matches the block,
+// so temporary blocks lift themselves. The users table remains the source
+// of truth, and the API reloads active blocks into the denylist at startup.
+
+func denylistKey(userID string) string {
+	return "denylist:user:" + userID
+}
+
+// DenyUser refuses the user's tokens until until, or indefinitely when
+// until is nil.
+func DenyUser(ctx context.Context, userID string, until *time.Time) error {
+	ttl := time.Duration(0)
+	if until != nil {
+		ttl = time.Until(*until)
+		if ttl <= 0 {
+			return AllowUser(ctx, userID)
+		}
+	}
+	return redisclient.Rdb.Set(ctx, denylistKey(userID), 1, ttl).Err()
+}
+
+// AllowUser takes the user off the denylist.
+func AllowUser(ctx context.Context, userID string) error {
+	return redisclient.Rdb.Del(ctx, denylistKey(userID)).Err()
+}
+
+// IsDenied reports whether the user is on the denylist. When Redis is
+// unavailable it checks the users table instead, and only fails open if
+// that fails too.
+func IsDenied(ctx context.Context, userID string) bool {
+	if userID == "" {
+		return false
+	}
+	if redisclient.Rdb != nil {
+		n, err := redisclient.Rdb.Exists(ctx, denylistKey(userID)).Result()
+		if err == nil {
+			return n > 0
+		}
+	}
+	if db.Pool == nil {
+		return false
+	}
+	blocked, err := users.IsBlocked(ctx, userID)
+	return err == nil && blocked
+}
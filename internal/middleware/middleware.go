@@ -48,15 +48,40 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
+		if IsDenied(c.Request.Context(), claims.UserID) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "user_blocked", "message": "Your account has been blocked. Please contact support."})
+			return
+		}
+
 		// Set user info in context for handlers
 		c.Set("user_id", claims.UserID)
 		c.Set("phone", claims.Phone)
-		c.Set("role", claims.Role)
+		c.Set("role", CurrentRole(c.Request.Context(), claims.UserID, claims.Role))
 
 		c.Next()
 	}
 }
 
+// OptionalAuthMiddleware sets user info when a valid bearer token is
+// present, and lets anonymous requests through otherwise.
+func OptionalAuthMiddleware(jwtSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
+			claims := &Claims{}
+			token, err := jwt.ParseWithClaims(parts[1], claims, func(token *jwt.Token) (interface{}, error) {
+				return []byte(jwtSecret), nil
+			})
+			if err == nil && token.Valid && !IsDenied(c.Request.Context(), claims.UserID) {
+				c.Set("user_id", claims.UserID)
+				c.Set("phone", claims.Phone)
+				c.Set("role", CurrentRole(c.Request.Context(), claims.UserID, claims.Role))
+			}
+		}
+		c.Next()
+	}
+}
+
 // RoleMiddleware restricts access based on user role.
 func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -204,7 +229,7 @@ func GenerateJWT(jwtSecret, userID, phone, role string) (string, error) {
 		Phone:  phone,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(tokenLifetime)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "jainfood",
 		},
@@ -1,9 +1,12 @@
 package middleware
 
 import (
+	"context"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -98,3 +101,38 @@ func TestGenerateJWT_DifferentSecrets(t *testing.T) {
 		t.Error("Token should not be valid with different secret")
 	}
 }
+
+func TestOptionalAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := "test-secret"
+	r := gin.New()
+	r.GET("/", OptionalAuthMiddleware(secret), func(c *gin.Context) {
+		userID, _ := GetUserIDFromContext(c)
+		c.String(200, userID)
+	})
+
+	token, _ := GenerateJWT(secret, "user-123", "+1234567890", "buyer")
+	cases := map[string]string{
+		"":                   "",
+		"Bearer " + token:    "user-123",
+		"Bearer not-a-token": "",
+		"Basic dXNlcjpwYXNz": "",
+	}
+	for header, want := range cases {
+		req := httptest.NewRequest("GET", "/", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != 200 || w.Body.String() != want {
+			t.Errorf("%q: got %d %q, want 200 %q", header, w.Code, w.Body.String(), want)
+		}
+	}
+}
+
+func TestCurrentRoleWithoutRedis(t *testing.T) {
+	if got := CurrentRole(context.Background(), "user-123", "buyer"); got != "buyer" {
+		t.Errorf("CurrentRole = %q, want the token's role", got)
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"jainfood/internal/redisclient"
+)
+
+// A token carries the role its user had when they signed in. When the role
+// changes later (a buyer added as outlet staff becomes a provider) the new
+// role is kept in Redis for as long as older tokens can live, and
+// AuthMiddleware applies it to them on their next request.
+
+// tokenLifetime is how long a token from GenerateJWT is valid.
+const tokenLifetime = 24 * time.Hour
+
+func roleKey(userID string) string {
+	return "role:user:" + userID
+}
+
+// SetRole makes the user's existing tokens act with role.
+func SetRole(ctx context.Context, userID, role string) error {
+	return redisclient.Rdb.Set(ctx, roleKey(userID), role, tokenLifetime).Err()
+}
+
+// CurrentRole returns the role set for the user since their token was
+// issued, or the token's role. It falls back to the token when Redis is
+// unavailable.
+func CurrentRole(ctx context.Context, userID, tokenRole string) string {
+	if redisclient.Rdb == nil || userID == "" {
+		return tokenRole
+	}
+	role, err := redisclient.Rdb.Get(ctx, roleKey(userID)).Result()
+	if err != nil || role == "" {
+		return tokenRole
+	}
+	return role
+}
@@ -11,16 +11,71 @@ type User struct {
 	Role              string                 `json:"role"` // buyer, provider, admin
 	Preferences       map[string]interface{} `json:"preferences"`
 	Language          string                 `json:"language"` // "en" or "hi"
+	PreferredChannel  string                 `json:"preferred_channel"` // "sms" or "whatsapp"
 	Blocked           bool                   `json:"blocked"`
 	BlockedReason     string                 `json:"blocked_reason,omitempty"`
+	BlockedUntil      *time.Time             `json:"blocked_until,omitempty"` // Nil blocks indefinitely
 	TermsAcceptedAt   *time.Time             `json:"terms_accepted_at,omitempty"`
 	CreatedAt         time.Time              `json:"created_at"`
 }
 
+// DietaryProfile is a user's typed dietary preferences, applied to search.
+type DietaryProfile struct {
+	UserID             string    `json:"user_id"`
+	Strictness         string    `json:"strictness"`          // relaxed, standard, strict
+	ExcludeIngredients []string  `json:"exclude_ingredients"` // Extra exclusions, e.g. "dairy" for vegan Jains
+	Allergens          []string  `json:"allergens"`           // dairy, nuts, peanut, gluten, soy, sesame, mustard
+	ObserveTithi       bool      `json:"observe_tithi"`       // Only tithi-safe items on ashtami, chaudas and Paryushan
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// Address is a saved buyer delivery address. Orders keep a copy, so later
+// edits don't change past orders.
+type Address struct {
+	ID        string    `json:"id,omitempty"`
+	Label     string    `json:"label"` // home, work, or the buyer's own
+	Line1     string    `json:"line1"`
+	Line2     string    `json:"line2,omitempty"`
+	Landmark  string    `json:"landmark,omitempty"`
+	PinCode   string    `json:"pin_code"`
+	City      string    `json:"city"`
+	District  string    `json:"district,omitempty"`
+	State     string    `json:"state"`
+	Lat       float64   `json:"lat"`
+	Lng       float64   `json:"lng"`
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+// PinCode is an entry of the offline pin-code dataset.
+type PinCode struct {
+	Pin      string  `json:"pin"`
+	CitySlug string  `json:"city_slug,omitempty"`
+	City     string  `json:"city"`
+	District string  `json:"district"`
+	State    string  `json:"state"`
+	Lat      float64 `json:"lat"` // Centroid
+	Lng      float64 `json:"lng"`
+}
+
+// City is a place in the city directory, with counts of the verified
+// providers around it.
+type City struct {
+	Slug          string         `json:"slug"`
+	Name          string         `json:"name"`
+	NameHi        string         `json:"name_hi,omitempty"`
+	State         string         `json:"state"`
+	Lat           float64        `json:"lat"`
+	Lng           float64        `json:"lng"`
+	ProviderCount int            `json:"provider_count"`
+	Categories    map[string]int `json:"categories"` // Providers by provider_category
+}
+
 // Provider represents a food provider (cloud kitchen, home cook, hotel).
 type Provider struct {
 	ID                    string    `json:"id"`
 	UserID                string    `json:"user_id"`
+	OrganisationID        string    `json:"organisation_id,omitempty"`
 	BusinessName          string    `json:"business_name"`
 	Address               string    `json:"address"`
 	PinCode               string    `json:"pin_code"`
@@ -28,7 +83,7 @@ type Provider struct {
 	Lng                   float64   `json:"lng"`
 	Verified              bool      `json:"verified"`
 	AadharVerified        bool      `json:"aadhar_verified"`
-	AadharNumber          string    `json:"aadhar_number,omitempty"` // Encrypted/masked
+	AadharNumber          string    `json:"aadhar_number,omitempty"` // Masked (XXXX-XXXX-1234); stored encrypted, see internal/pii
 	Tags                  []string  `json:"tags"` // e.g., "sattvic", "no-root-veggies", "home-cook"
 	ProviderCategory      string    `json:"provider_category"` // tiffin-center, caterer, bhojnalaya, restaurant, baker, raw-material, sodh-khana
 	FoodCategories        []string  `json:"food_categories"` // bakery, sweets, namkeen, dry-fruits, tiffin-thali, etc.
@@ -49,6 +104,159 @@ type Provider struct {
 	CreatedAt             time.Time `json:"created_at"`
 }
 
+// OpeningSlot is one weekly opening period in the provider's timezone. A
+// slot closing at or before it opens runs past midnight (e.g. 18:00-01:00).
+type OpeningSlot struct {
+	Weekday     int    `json:"weekday"`                // 0 = Sunday
+	Opens       string `json:"opens"`                  // HH:MM
+	Closes      string `json:"closes"`                 // HH:MM
+	OrderCutoff string `json:"order_cutoff,omitempty"` // Latest HH:MM to order for this slot, e.g. 10:00 for a 12:00 tiffin
+}
+
+// Holiday is a closure covering whole days, inclusive.
+type Holiday struct {
+	ID     string `json:"id"`
+	From   string `json:"from"` // YYYY-MM-DD
+	To     string `json:"to"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ProviderSchedule is a provider's opening hours. Providers without slots
+// are treated as always open, subject to holidays and AvailableToday.
+type ProviderSchedule struct {
+	ProviderID     string        `json:"provider_id"`
+	Timezone       string        `json:"timezone"`
+	AvailableToday bool          `json:"available_today"`
+	Slots          []OpeningSlot `json:"slots"`
+	Holidays       []Holiday     `json:"holidays"`
+}
+
+// FeeSlab charges Fee for deliveries up to UpToKm from the provider.
+type FeeSlab struct {
+	UpToKm float64 `json:"up_to_km"`
+	Fee    float64 `json:"fee"`
+}
+
+// DeliveryZone is the area a provider delivers to: a radius around the
+// provider or a polygon, with fees by distance. Beyond the last slab (but
+// inside the zone) the last slab's fee applies.
+type DeliveryZone struct {
+	ProviderID string       `json:"provider_id"`
+	RadiusKm   float64      `json:"radius_km,omitempty"`
+	Area       [][2]float64 `json:"area,omitempty"` // Polygon ring of [lng, lat] points
+	FeeSlabs   []FeeSlab    `json:"fee_slabs"`
+}
+
+// DeliveryQuote is the delivery fee for one order destination.
+type DeliveryQuote struct {
+	Deliverable  bool    `json:"deliverable"`
+	DistanceKm   float64 `json:"distance_km"`
+	Fee          float64 `json:"fee"`
+	FreeDelivery bool    `json:"free_delivery"`
+}
+
+// Organisation is a provider business that runs one or more outlets. Each
+// outlet is a Provider with its own location, hours and menus.
+type Organisation struct {
+	ID        string      `json:"id"`
+	Name      string      `json:"name"`
+	OwnerID   string      `json:"owner_id"`
+	Outlets   []*Provider `json:"outlets"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// StaffMember gives a user a role in an organisation, for one outlet or
+// (with no ProviderID) all of them.
+type StaffMember struct {
+	ID             string    `json:"id"`
+	OrganisationID string    `json:"organisation_id"`
+	UserID         string    `json:"user_id"`
+	Name           string    `json:"name,omitempty"`
+	Phone          string    `json:"phone,omitempty"`
+	ProviderID     string    `json:"outlet_id,omitempty"` // Empty: every outlet
+	Role           string    `json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// OutletAccess is an outlet a user works at and their role there.
+type OutletAccess struct {
+	ProviderID     string `json:"outlet_id"`
+	OrganisationID string `json:"organisation_id,omitempty"`
+	BusinessName   string `json:"business_name"`
+	Role           string `json:"role"`
+}
+
+// ProviderApplication is a provider's verification application: business
+// details and documents an admin reviews before the provider is verified.
+type ProviderApplication struct {
+	ID             string                 `json:"id"`
+	ProviderID     string                 `json:"provider_id"`
+	BusinessName   string                 `json:"business_name,omitempty"` // Set in the admin queue
+	State          string                 `json:"state"`                   // draft, submitted, under_review, changes_requested, approved, rejected
+	LegalName      string                 `json:"legal_name"`
+	BusinessType   string                 `json:"business_type"` // individual, proprietorship, partnership, company
+	GSTIN          string                 `json:"gstin,omitempty"`
+	FSSAINumber    string                 `json:"fssai_number"`
+	FSSAIExpiresOn string                 `json:"fssai_expires_on,omitempty"` // YYYY-MM-DD
+	IDProofType    string                 `json:"id_proof_type"`              // aadhaar, pan, passport, voter_id, driving_licence
+	AadharNumber   string                 `json:"aadhar_number,omitempty"`    // Masked: XXXX-XXXX-1234
+	Documents      []*ApplicationDocument `json:"documents"`
+	History        []*ApplicationComment  `json:"history"`
+	SubmittedAt    *time.Time             `json:"submitted_at,omitempty"`
+	ReviewedBy     string                 `json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time             `json:"reviewed_at,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
+}
+
+// ApplicationDocument is an uploaded file attached to an application; the
+// object key comes from POST /v1/media/upload-url.
+type ApplicationDocument struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"` // fssai_licence, id_proof, kitchen_photo
+	ObjectKey  string    `json:"object_key"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+// ApplicationComment records a state change of an application, with the
+// reviewer's (or provider's) comment.
+type ApplicationComment struct {
+	ID        string    `json:"id"`
+	AuthorID  string    `json:"author_id"`
+	FromState string    `json:"from_state"`
+	ToState   string    `json:"to_state"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DeviceToken is a push notification token registered by a user's device.
+type DeviceToken struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	Token      string    `json:"token"`
+	Platform   string    `json:"platform"` // android, ios, web
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// Campaign is a broadcast notification sent to the subscribers of a topic.
+type Campaign struct {
+	ID         string            `json:"id"`
+	CreatedBy  string            `json:"created_by"`
+	ProviderID string            `json:"provider_id,omitempty"` // Empty for admin campaigns
+	Topic      string            `json:"topic"`
+	Title      string            `json:"title"`
+	Body       string            `json:"body"`
+	Data       map[string]string `json:"data,omitempty"`
+	Status     string            `json:"status"` // scheduled, sending, sent, cancelled, failed
+	SendAt     time.Time         `json:"send_at"`
+	SentAt     *time.Time        `json:"sent_at,omitempty"`
+	Recipients int               `json:"recipients"`
+	Skipped    int               `json:"skipped"`
+	Error      string            `json:"error,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
 // Menu represents a provider's menu.
 type Menu struct {
 	ID          string    `json:"id"`
@@ -67,6 +275,9 @@ type MenuItem struct {
 	Quantity      string    `json:"quantity,omitempty"` // e.g., "500g", "1 plate", optional
 	Ingredients   []string  `json:"ingredients"`
 	IsJain        bool      `json:"is_jain"`
+	JainStatus    string    `json:"jain_status,omitempty"`  // Computed: jain, caution, non-jain
+	JainReasons   []string  `json:"jain_reasons,omitempty"` // Why the computed status isn't "jain"
+	DietaryTags   []string  `json:"dietary_tags,omitempty"` // tithi-safe, ayambil
 	FoodCategory  string    `json:"food_category"` // bakery, sweets, namkeen, dry-fruits, tiffin, sodh, etc.
 	Availability  bool      `json:"availability"`
 	ImageURL      string    `json:"image_url"`
@@ -76,15 +287,17 @@ type MenuItem struct {
 
 // Order represents a food order.
 type Order struct {
-	ID            string      `json:"id"`
-	OrderCode     string      `json:"order_code"`
-	BuyerID       string      `json:"buyer_id"`
-	ProviderID    string      `json:"provider_id"`
-	Items         interface{} `json:"items"` // array of { item_id, qty, price }
-	TotalEstimate float64     `json:"total_estimate"`
-	Status        string      `json:"status"` // CREATED, PENDING_PROVIDER_ACK, CONFIRMED, COMPLETED, CANCELLED
-	OrderType     string      `json:"order_type"` // individual, bulk
-	CreatedAt     time.Time   `json:"created_at"`
+	ID              string      `json:"id"`
+	OrderCode       string      `json:"order_code"`
+	BuyerID         string      `json:"buyer_id"`
+	ProviderID      string      `json:"provider_id"`
+	Items           interface{} `json:"items"`          // array of { item_id, qty, price }
+	TotalEstimate   float64     `json:"total_estimate"` // Items plus delivery fee
+	DeliveryFee     float64     `json:"delivery_fee"`
+	DeliveryAddress *Address    `json:"delivery_address,omitempty"` // As it was when the order was placed
+	Status          string      `json:"status"`                     // CREATED, PENDING_PROVIDER_ACK, CONFIRMED, READY, COMPLETED, CANCELLED
+	OrderType       string      `json:"order_type"`                 // individual, bulk
+	CreatedAt       time.Time   `json:"created_at"`
 }
 
 // OrderItem represents an item in an order.
@@ -105,6 +318,122 @@ type Review struct {
 	Comment    string    `json:"comment"`
 	PhotoURLs  []string  `json:"photo_urls,omitempty"`
 	CreatedAt  time.Time `json:"created_at"`
+
+	// Moderation; names are filled in for admin listings
+	ModerationState string   `json:"moderation_state,omitempty"`
+	ReportCount     int      `json:"report_count,omitempty"`
+	ReportReasons   []string `json:"report_reasons,omitempty"`
+	UserName        string   `json:"user_name,omitempty"`
+	ProviderName    string   `json:"provider_name,omitempty"`
+}
+
+// AdminKPIs are platform-wide figures for the admin dashboard over a range
+// of days.
+type AdminKPIs struct {
+	From                  string         `json:"from"`
+	To                    string         `json:"to"`
+	Users                 int            `json:"users"` // All time
+	Signups               int            `json:"signups"`
+	SignupsByRole         map[string]int `json:"signups_by_role"`
+	Orders                int            `json:"orders"`
+	GMV                   float64        `json:"gmv"` // Confirmed, ready and completed orders
+	ActiveProviders       int            `json:"active_providers"`
+	ActiveProvidersByCity []CityCount    `json:"active_providers_by_city"`
+	PendingApplications   int            `json:"pending_applications"`
+	FlaggedReviews        int            `json:"flagged_reviews"`
+	BlockedUsers          int            `json:"blocked_users"`
+	UpdatedAt             *time.Time     `json:"updated_at,omitempty"` // Order figures include changes up to here
+}
+
+// Promotion is a time-boxed promoted slot for a provider in a city,
+// optionally limited to one provider category.
+type Promotion struct {
+	ID               string     `json:"id"`
+	ProviderID       string     `json:"provider_id"`
+	BusinessName     string     `json:"business_name,omitempty"`
+	CitySlug         string     `json:"city"`
+	ProviderCategory string     `json:"provider_category,omitempty"` // Empty for every category
+	StartsAt         time.Time  `json:"starts_at"`
+	EndsAt           time.Time  `json:"ends_at"`
+	DailyCap         int        `json:"daily_cap"` // Impressions per local day
+	Status           string     `json:"status"`
+	Source           string     `json:"source"` // admin or payment
+	AmountPaise      int64      `json:"amount_paise"`
+	PaymentOrderID   string     `json:"payment_order_id,omitempty"`
+	PaymentID        string     `json:"payment_id,omitempty"`
+	CreatedBy        string     `json:"created_by,omitempty"`
+	Impressions      int        `json:"impressions"` // All time
+	Clicks           int        `json:"clicks"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+}
+
+// PromotionDay is a promotion's impressions and clicks on one local day.
+type PromotionDay struct {
+	Day         string  `json:"day"`
+	Impressions int     `json:"impressions"`
+	Clicks      int     `json:"clicks"`
+	CTR         float64 `json:"ctr"` // Clicks per impression
+}
+
+// PromotionStats is a promotion's delivery report, used for billing.
+type PromotionStats struct {
+	Promotion *Promotion     `json:"promotion"`
+	Days      []PromotionDay `json:"days"`
+	CTR       float64        `json:"ctr"`
+}
+
+// CityCount counts something per city.
+type CityCount struct {
+	City  string `json:"city"`
+	Count int    `json:"count"`
+}
+
+// ProviderAnalytics is a provider's order and rating report for a range of
+// local days, built from daily rollups.
+type ProviderAnalytics struct {
+	ProviderID         string            `json:"provider_id"`
+	From               string            `json:"from"` // YYYY-MM-DD, inclusive
+	To                 string            `json:"to"`   // YYYY-MM-DD, inclusive
+	Granularity        string            `json:"granularity"`
+	Timezone           string            `json:"timezone"`
+	Orders             int               `json:"orders"`
+	OrdersByStatus     map[string]int    `json:"orders_by_status"`
+	Revenue            float64           `json:"revenue"` // Confirmed, ready and completed orders
+	AverageOrderValue  float64           `json:"average_order_value"`
+	Customers          int               `json:"customers"`
+	RepeatCustomers    int               `json:"repeat_customers"` // Ordered more than once in the range
+	RepeatCustomerRate float64           `json:"repeat_customer_rate"`
+	Ratings            int               `json:"ratings"`
+	AverageRating      float64           `json:"average_rating"`
+	Series             []AnalyticsPeriod `json:"series"`
+	TopItems           []TopItem         `json:"top_items"`
+	PeakHours          []HourlyOrders    `json:"peak_hours"`           // Busiest first
+	UpdatedAt          *time.Time        `json:"updated_at,omitempty"` // Rollups include changes up to here
+}
+
+// AnalyticsPeriod is one day, week or month of a ProviderAnalytics series.
+type AnalyticsPeriod struct {
+	Period            string  `json:"period"` // First day, YYYY-MM-DD
+	Orders            int     `json:"orders"`
+	Revenue           float64 `json:"revenue"`
+	AverageOrderValue float64 `json:"average_order_value"`
+	Ratings           int     `json:"ratings"`
+	AverageRating     float64 `json:"average_rating"`
+}
+
+// TopItem is a best-selling item in a ProviderAnalytics report.
+type TopItem struct {
+	ItemID   string  `json:"item_id"`
+	Name     string  `json:"name"`
+	Quantity int     `json:"quantity"`
+	Revenue  float64 `json:"revenue"`
+}
+
+// HourlyOrders counts orders placed in one local hour of the day.
+type HourlyOrders struct {
+	Hour   int `json:"hour"` // 0-23
+	Orders int `json:"orders"`
 }
 
 // Offer represents a promotional offer from a provider.
@@ -172,6 +501,19 @@ const (
 	TagPureJain      = "pure-jain"
 )
 
+// Menu item dietary tags set by providers for observance days.
+const (
+	ItemTagTithiSafe = "tithi-safe" // No green vegetables; fit for ashtami, chaudas and Paryushan
+	ItemTagAyambil   = "ayambil"    // Boiled grains and pulses without oil, ghee, milk, sugar or spices
+)
+
+// Dietary profile strictness levels.
+const (
+	StrictnessRelaxed  = "relaxed"  // Trust the provider's is_jain claim
+	StrictnessStandard = "standard" // Also exclude items the ingredient checker marked non-Jain
+	StrictnessStrict   = "strict"   // Also exclude "caution" items (yeast, vinegar, cabbage, ...)
+)
+
 // Provider category constants.
 const (
 	ProviderCategoryTiffinCenter   = "tiffin-center"
@@ -199,11 +541,26 @@ const (
 	FoodCategoryNirvaanLaddu   = "nirvaan-laddu"
 )
 
+// ProviderCategories and FoodCategories list the valid category values.
+var (
+	ProviderCategories = []string{
+		ProviderCategoryTiffinCenter, ProviderCategoryCaterer, ProviderCategoryBhojnalaya,
+		ProviderCategoryRestaurant, ProviderCategoryBaker, ProviderCategoryRawMaterial,
+		ProviderCategorySodhKhana, ProviderCategoryHomeChef, ProviderCategoryChaukaBai,
+	}
+	FoodCategories = []string{
+		FoodCategoryRawMaterials, FoodCategoryBakery, FoodCategorySweets, FoodCategoryIcecream,
+		FoodCategoryNamkeen, FoodCategoryDryFruits, FoodCategoryTiffinThali,
+		FoodCategorySodhKhana, FoodCategorySodhSamgri, FoodCategoryNirvaanLaddu,
+	}
+)
+
 // Order status constants.
 const (
 	OrderStatusCreated            = "CREATED"
 	OrderStatusPendingProviderAck = "PENDING_PROVIDER_ACK"
 	OrderStatusConfirmed          = "CONFIRMED"
+	OrderStatusReady              = "READY"
 	OrderStatusCompleted          = "COMPLETED"
 	OrderStatusCancelled          = "CANCELLED"
 )
@@ -221,6 +578,74 @@ const (
 	RoleAdmin    = "admin"
 )
 
+// Device platform constants.
+const (
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+	PlatformWeb     = "web"
+)
+
+// Campaign status constants.
+const (
+	CampaignStatusScheduled = "scheduled"
+	CampaignStatusSending   = "sending"
+	CampaignStatusSent      = "sent"
+	CampaignStatusCancelled = "cancelled"
+	CampaignStatusFailed    = "failed"
+)
+
+// Provider application state constants.
+const (
+	ApplicationDraft            = "draft"
+	ApplicationSubmitted        = "submitted"
+	ApplicationUnderReview      = "under_review"
+	ApplicationChangesRequested = "changes_requested"
+	ApplicationApproved         = "approved"
+	ApplicationRejected         = "rejected"
+)
+
+// Staff role constants, most senior first.
+const (
+	StaffRoleOwner        = "owner"
+	StaffRoleManager      = "manager"
+	StaffRoleKitchenStaff = "kitchen_staff"
+)
+
+// Review moderation state constants.
+const (
+	ReviewPublished = "published"
+	ReviewFlagged   = "flagged"  // Reported, awaiting moderation
+	ReviewApproved  = "approved" // Kept by a moderator
+	ReviewHidden    = "hidden"   // Removed from listings and ratings
+)
+
+// Promotion status constants.
+const (
+	PromotionPendingPayment = "pending_payment"
+	PromotionActive         = "active"
+	PromotionCancelled      = "cancelled"
+	PromotionExpired        = "expired"
+)
+
+// Promotion source constants.
+const (
+	PromotionSourceAdmin   = "admin"
+	PromotionSourcePayment = "payment"
+)
+
+// Application document kind constants.
+const (
+	DocumentFSSAILicence = "fssai_licence"
+	DocumentIDProof      = "id_proof"
+	DocumentKitchenPhoto = "kitchen_photo"
+)
+
+// Notification channel constants.
+const (
+	ChannelSMS      = "sms"
+	ChannelWhatsApp = "whatsapp"
+)
+
 // Language constants.
 const (
 	LangEnglish = "en"
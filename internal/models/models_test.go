@@ -10,6 +10,7 @@ func TestOrderStatusConstants(t *testing.T) {
 		OrderStatusCreated,
 		OrderStatusPendingProviderAck,
 		OrderStatusConfirmed,
+		OrderStatusReady,
 		OrderStatusCompleted,
 		OrderStatusCancelled,
 	}
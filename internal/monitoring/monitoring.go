@@ -72,6 +72,20 @@ func (m *Metrics) RecordError(errorType string) {
 	m.mu.Unlock()
 }
 
+// SetCustom sets a named gauge (e.g. queue depth)
+func (m *Metrics) SetCustom(name string, value float64) {
+	m.mu.Lock()
+	m.Custom[name] = value
+	m.mu.Unlock()
+}
+
+// IncrCustom increments a named counter
+func (m *Metrics) IncrCustom(name string) {
+	m.mu.Lock()
+	m.Custom[name]++
+	m.mu.Unlock()
+}
+
 // GetSnapshot returns metrics snapshot
 func (m *Metrics) GetSnapshot() map[string]interface{} {
 	m.mu.RLock()
@@ -86,6 +100,11 @@ func (m *Metrics) GetSnapshot() map[string]interface{} {
 		avgLatency = float64(atomic.LoadUint64(&m.TotalLatencyNs)) / float64(total) / 1e6
 	}
 
+	custom := make(map[string]float64, len(m.Custom))
+	for k, v := range m.Custom {
+		custom[k] = v
+	}
+
 	return map[string]interface{}{
 		"requests": map[string]interface{}{
 			"total":          total,
@@ -105,6 +124,7 @@ func (m *Metrics) GetSnapshot() map[string]interface{} {
 			"memory_mb":      float64(memStats.Alloc) / 1024 / 1024,
 			"gc_cycles":      memStats.NumGC,
 		},
+		"custom": custom,
 	}
 }
 
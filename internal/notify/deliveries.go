@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"context"
+
+	"jainfood/internal/db"
+)
+
+// RecordDelivery stores a sent message so delivery webhooks can update it.
+func RecordDelivery(ctx context.Context, channel, messageID, recipient, template string) error {
+	_, err := db.Pool.Exec(ctx, `
+		INSERT INTO notification_deliveries (message_id, channel, recipient, template, status)
+		VALUES ($1, $2, $3, $4, 'sent')
+		ON CONFLICT (message_id) DO NOTHING
+	`, messageID, channel, recipient, template)
+	return err
+}
+
+// UpdateDeliveryStatus applies a webhook status update. Out-of-order updates
+// never move a message back to an earlier state (e.g. read -> delivered).
+func UpdateDeliveryStatus(ctx context.Context, channel string, s WhatsAppStatus) error {
+	_, err := db.Pool.Exec(ctx, `
+		INSERT INTO notification_deliveries (message_id, channel, recipient, status, error_code, error_title, updated_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, 0), NULLIF($6, ''), now())
+		ON CONFLICT (message_id) DO UPDATE
+		SET status = EXCLUDED.status,
+		    error_code = COALESCE(EXCLUDED.error_code, notification_deliveries.error_code),
+		    error_title = COALESCE(EXCLUDED.error_title, notification_deliveries.error_title),
+		    updated_at = now()
+		WHERE array_position(ARRAY['sent','delivered','read'], EXCLUDED.status::text) IS NULL
+		   OR array_position(ARRAY['sent','delivered','read'], notification_deliveries.status::text) IS NULL
+		   OR array_position(ARRAY['sent','delivered','read'], EXCLUDED.status::text)
+		      > array_position(ARRAY['sent','delivered','read'], notification_deliveries.status::text)
+	`, s.MessageID, channel, s.RecipientID, s.Status, s.ErrorCode, s.ErrorTitle)
+	return err
+}
@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"jainfood/internal/queue"
+)
+
+// Job types handled by the notification worker.
+const (
+	JobSendOTP     = "notify.send_otp"
+	JobOrderUpdate = "notify.order_update"
+)
+
+// OTPJob is the payload of a JobSendOTP job.
+type OTPJob struct {
+	Phone   string `json:"phone"`
+	OTP     string `json:"otp"`
+	Purpose string `json:"purpose,omitempty"` // login, register, order
+	Channel string `json:"channel,omitempty"` // Explicit channel; empty uses the user's preference
+}
+
+// OrderUpdateJob is the payload of a JobOrderUpdate job.
+type OrderUpdateJob struct {
+	Phone     string `json:"phone"`
+	OrderCode string `json:"order_code"`
+	Status    string `json:"status"`
+}
+
+// ChannelSender is implemented by notifiers that can send over a chosen channel.
+type ChannelSender interface {
+	OTPChannels(ctx context.Context, channel, phone string) []string
+	SendOTPOn(ctx context.Context, channel, phone, otp string) error
+}
+
+// OrderUpdater is implemented by notifiers that can send order status updates.
+type OrderUpdater interface {
+	SendOrderUpdate(ctx context.Context, phone, orderCode, status string) error
+}
+
+// EnqueueOTP queues an OTP send at high priority. The job is dropped if it
+// is still queued after ttl, since the OTP would have expired anyway.
+func EnqueueOTP(ctx context.Context, q *queue.Queue, phone, otp, purpose, channel string, ttl time.Duration) error {
+	job, err := queue.NewJob(JobSendOTP, OTPJob{Phone: phone, OTP: otp, Purpose: purpose, Channel: channel})
+	if err != nil {
+		return err
+	}
+	job.Priority = queue.PriorityHigh
+	job.MaxAttempts = 4
+	job.Redact = []string{"otp"}
+	if ttl > 0 {
+		expires := time.Now().Add(ttl).UTC()
+		job.ExpiresAt = &expires
+	}
+	return q.Enqueue(ctx, job)
+}
+
+// OTPHandler returns a queue handler that sends OTPs through n.
+func OTPHandler(n NotifyService) queue.HandlerFunc {
+	return func(ctx context.Context, job *queue.Job) error {
+		var p OTPJob
+		if err := job.Decode(&p); err != nil {
+			return queue.Permanent(err)
+		}
+		if p.Phone == "" || p.OTP == "" {
+			return queue.Permanent(errors.New("otp job missing phone or otp"))
+		}
+		if cs, ok := n.(ChannelSender); ok {
+			// One channel per attempt: a retry moves on to the fallback
+			// channel instead of sending over every channel again
+			channels := cs.OTPChannels(ctx, p.Channel, p.Phone)
+			return cs.SendOTPOn(ctx, channels[job.Attempts%len(channels)], p.Phone, p.OTP)
+		}
+		return n.SendOTP(ctx, p.Phone, p.OTP)
+	}
+}
+
+// Notifier is what the notification handlers send with.
+type Notifier interface {
+	NotifyService
+	OrderUpdater
+}
+
+// RegisterHandlers registers the notification job handlers on w, for the
+// API's in-process workers and the standalone worker alike.
+func RegisterHandlers(w *queue.Worker, n Notifier) {
+	w.Handle(JobSendOTP, OTPHandler(n))
+	w.Handle(JobOrderUpdate, OrderUpdateHandler(n))
+}
+
+// EnqueueOrderUpdate queues an order status notification for the buyer.
+func EnqueueOrderUpdate(ctx context.Context, q *queue.Queue, phone, orderCode, status string) error {
+	job, err := queue.NewJob(JobOrderUpdate, OrderUpdateJob{Phone: phone, OrderCode: orderCode, Status: status})
+	if err != nil {
+		return err
+	}
+	return q.Enqueue(ctx, job)
+}
+
+// OrderUpdateHandler returns a queue handler that sends order updates through u.
+func OrderUpdateHandler(u OrderUpdater) queue.HandlerFunc {
+	return func(ctx context.Context, job *queue.Job) error {
+		var p OrderUpdateJob
+		if err := job.Decode(&p); err != nil {
+			return queue.Permanent(err)
+		}
+		if p.Phone == "" {
+			return queue.Permanent(errors.New("order update job missing phone"))
+		}
+		return u.SendOrderUpdate(ctx, p.Phone, p.OrderCode, p.Status)
+	}
+}
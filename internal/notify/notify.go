@@ -2,19 +2,32 @@ package notify
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/smtp"
 	"os"
 	"strings"
+	"time"
 )
 
-// NotifyService defines the interface for sending notifications
+// NotifyService defines the interface for sending notifications. Sends
+// give up when ctx is done, so a queued job can't outlive its handler
+// timeout and be sent twice.
 type NotifyService interface {
-	SendOTP(phone, otp string) error
+	SendOTP(ctx context.Context, phone, otp string) error
 }
 
+// sendTimeout bounds a single send to a provider, like WhatsAppNotifier's
+// client.
+const sendTimeout = 15 * time.Second
+
+// httpClient is shared by the HTTP-based SMS and email notifiers.
+var httpClient = &http.Client{Timeout: sendTimeout}
+
 // ============================================
 // CONSOLE NOTIFIER (Development)
 // ============================================
@@ -26,7 +39,7 @@ func NewConsoleNotifier() *ConsoleNotifier {
 	return &ConsoleNotifier{}
 }
 
-func (c *ConsoleNotifier) SendOTP(phone, otp string) error {
+func (c *ConsoleNotifier) SendOTP(ctx context.Context, phone, otp string) error {
 	fmt.Printf("\n==========================================\n")
 	fmt.Printf("📱 OTP NOTIFICATION (Development Mode)\n")
 	fmt.Printf("==========================================\n")
@@ -45,6 +58,7 @@ type EmailNotifier struct {
 	APIKey    string
 	FromEmail string
 	ToEmail   string // For dev, send to a single test email
+	BaseURL   string
 }
 
 func NewEmailNotifier(apiKey, fromEmail, toEmail string) *EmailNotifier {
@@ -52,12 +66,13 @@ func NewEmailNotifier(apiKey, fromEmail, toEmail string) *EmailNotifier {
 		APIKey:    apiKey,
 		FromEmail: fromEmail,
 		ToEmail:   toEmail,
+		BaseURL:   "https://api.resend.com/emails",
 	}
 }
 
-func (e *EmailNotifier) SendOTP(phone, otp string) error {
+func (e *EmailNotifier) SendOTP(ctx context.Context, phone, otp string) error {
 	// Resend API
-	url := "https://api.resend.com/emails"
+	url := e.BaseURL
 
 	payload := map[string]interface{}{
 		"from":    e.FromEmail,
@@ -83,7 +98,7 @@ func (e *EmailNotifier) SendOTP(phone, otp string) error {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return err
 	}
@@ -91,8 +106,7 @@ func (e *EmailNotifier) SendOTP(phone, otp string) error {
 	req.Header.Set("Authorization", "Bearer "+e.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -144,7 +158,7 @@ func sanitizePhoneForEmail(phone string) string {
 	return b.String()
 }
 
-func (s *SMTPNotifier) SendOTP(phone, otp string) error {
+func (s *SMTPNotifier) SendOTP(ctx context.Context, phone, otp string) error {
 	auth := smtp.PlainAuth("", s.Username, s.Password, s.Host)
 
 	subject := fmt.Sprintf("JainFood OTP: %s", otp)
@@ -162,7 +176,52 @@ This OTP is valid for 10 minutes.
 		"\r\n"+
 		"%s", s.FromName, s.Username, s.ToEmail, subject, body))
 
-	return smtp.SendMail(s.Host+":"+s.Port, auth, s.Username, []string{s.ToEmail}, msg)
+	return s.send(ctx, auth, msg)
+}
+
+// send delivers msg as smtp.SendMail does, within ctx and sendTimeout.
+func (s *SMTPNotifier) send(ctx context.Context, auth smtp.Auth, msg []byte) error {
+	conn, err := (&net.Dialer{Timeout: sendTimeout}).DialContext(ctx, "tcp", s.Host+":"+s.Port)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(sendTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.Host}); err != nil {
+			return err
+		}
+	}
+	if err := c.Auth(auth); err != nil {
+		return err
+	}
+	if err := c.Mail(s.Username); err != nil {
+		return err
+	}
+	if err := c.Rcpt(s.ToEmail); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
 }
 
 // ============================================
@@ -174,6 +233,7 @@ type MSG91Notifier struct {
 	AuthKey    string
 	TemplateID string
 	SenderID   string
+	BaseURL    string
 }
 
 func NewMSG91Notifier(authKey, templateID, senderID string) *MSG91Notifier {
@@ -181,11 +241,12 @@ func NewMSG91Notifier(authKey, templateID, senderID string) *MSG91Notifier {
 		AuthKey:    authKey,
 		TemplateID: templateID,
 		SenderID:   senderID,
+		BaseURL:    "https://api.msg91.com/api/v5/otp",
 	}
 }
 
-func (m *MSG91Notifier) SendOTP(phone, otp string) error {
-	url := "https://api.msg91.com/api/v5/otp"
+func (m *MSG91Notifier) SendOTP(ctx context.Context, phone, otp string) error {
+	url := m.BaseURL
 
 	// Ensure phone has country code
 	if !strings.HasPrefix(phone, "91") {
@@ -204,7 +265,7 @@ func (m *MSG91Notifier) SendOTP(phone, otp string) error {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return err
 	}
@@ -212,8 +273,7 @@ func (m *MSG91Notifier) SendOTP(phone, otp string) error {
 	req.Header.Set("authkey", m.AuthKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -235,7 +295,8 @@ func (m *MSG91Notifier) SendOTP(phone, otp string) error {
 // Free tier: 1 SMS/day with key "textbelt"
 // Paid tier: $0.005/SMS with purchased key
 type TextbeltNotifier struct {
-	APIKey string // Use "textbelt" for free tier (1 SMS/day)
+	APIKey  string // Use "textbelt" for free tier (1 SMS/day)
+	BaseURL string
 }
 
 func NewTextbeltNotifier(apiKey string) *TextbeltNotifier {
@@ -243,12 +304,13 @@ func NewTextbeltNotifier(apiKey string) *TextbeltNotifier {
 		apiKey = "textbelt" // Free tier key
 	}
 	return &TextbeltNotifier{
-		APIKey: apiKey,
+		APIKey:  apiKey,
+		BaseURL: "https://textbelt.com/text",
 	}
 }
 
-func (t *TextbeltNotifier) SendOTP(phone, otp string) error {
-	url := "https://textbelt.com/text"
+func (t *TextbeltNotifier) SendOTP(ctx context.Context, phone, otp string) error {
+	url := t.BaseURL
 
 	// Format phone with country code for India
 	formattedPhone := phone
@@ -271,15 +333,14 @@ func (t *TextbeltNotifier) SendOTP(phone, otp string) error {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -317,6 +378,7 @@ type SMSIndiaHubNotifier struct {
 	Route    string
 	PEId     string // Principal Entity ID (for DLT registration)
 	DCS      string // Data Coding Scheme (0 for normal, 8 for Unicode)
+	BaseURL  string
 }
 
 func NewSMSIndiaHubNotifier(apiKey, senderID, channel, route, peId string) *SMSIndiaHubNotifier {
@@ -333,10 +395,11 @@ func NewSMSIndiaHubNotifier(apiKey, senderID, channel, route, peId string) *SMSI
 		Route:    route,
 		PEId:     peId,
 		DCS:      "0",
+		BaseURL:  "https://cloud.smsindiahub.in/api/mt/SendSMS",
 	}
 }
 
-func (s *SMSIndiaHubNotifier) SendOTP(phone, otp string) error {
+func (s *SMSIndiaHubNotifier) SendOTP(ctx context.Context, phone, otp string) error {
 	// Format phone number - ensure it has 91 prefix
 	formattedPhone := phone
 	if !strings.HasPrefix(phone, "91") {
@@ -348,7 +411,7 @@ func (s *SMSIndiaHubNotifier) SendOTP(phone, otp string) error {
 	message := fmt.Sprintf("Your JainFood OTP is %s. Valid for 10 minutes. Do not share with anyone. - JainFood", otp)
 
 	// Build URL with query parameters
-	baseURL := "https://cloud.smsindiahub.in/api/mt/SendSMS"
+	baseURL := s.BaseURL
 
 	// URL encode the message
 	encodedMessage := strings.ReplaceAll(message, " ", "%20")
@@ -372,7 +435,11 @@ func (s *SMSIndiaHubNotifier) SendOTP(phone, otp string) error {
 	}
 
 	// Make the request
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("SMS India Hub request failed: %v", err)
 	}
@@ -449,6 +516,11 @@ func NewNotifier() NotifyService {
 			return NewSMTPNotifier(host, port, username, password, "JainFood", toEmail)
 		}
 
+	case "whatsapp":
+		if w := NewWhatsAppNotifierFromEnv(); w != nil {
+			return w
+		}
+
 	case "msg91":
 		authKey := os.Getenv("MSG91_AUTH_KEY")
 		templateID := os.Getenv("MSG91_TEMPLATE_ID")
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSMSIndiaHubNotifier_SendOTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("number"); got != "919876543210" {
+			t.Errorf("number = %q, want 919876543210", got)
+		}
+		_, _ = w.Write([]byte(`{"ErrorCode":"000","ErrorMessage":"Done","JobId":"1","MessageData":[{"Number":"919876543210","MessageId":"m1"}]}`))
+	}))
+	defer srv.Close()
+
+	n := NewSMSIndiaHubNotifier("key", "", "", "", "")
+	n.BaseURL = srv.URL
+	if err := n.SendOTP(context.Background(), "9876543210", "123456"); err != nil {
+		t.Fatalf("SendOTP() error = %v", err)
+	}
+}
+
+func TestSMSIndiaHubNotifier_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ErrorCode":"013","ErrorMessage":"Invalid API key"}`))
+	}))
+	defer srv.Close()
+
+	n := NewSMSIndiaHubNotifier("bad", "", "", "", "")
+	n.BaseURL = srv.URL
+	if err := n.SendOTP(context.Background(), "9876543210", "123456"); err == nil {
+		t.Fatal("SendOTP() should fail when ErrorCode != 000")
+	}
+}
+
+func TestTextbeltNotifier_SendOTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success":true,"textId":"t1","quotaRemaining":9}`))
+	}))
+	defer srv.Close()
+
+	n := NewTextbeltNotifier("")
+	n.BaseURL = srv.URL
+	if err := n.SendOTP(context.Background(), "9876543210", "123456"); err != nil {
+		t.Fatalf("SendOTP() error = %v", err)
+	}
+}
+
+func TestTextbeltNotifier_StopsWithContext(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	n := NewTextbeltNotifier("")
+	n.BaseURL = srv.URL
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := n.SendOTP(ctx, "9876543210", "123456"); err == nil {
+		t.Fatal("SendOTP() should give up when ctx is done")
+	}
+}
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"jainfood/internal/models"
+)
+
+// ChannelRouter delivers notifications over each recipient's preferred
+// channel (SMS or WhatsApp), falling back to the other channel for OTPs.
+type ChannelRouter struct {
+	SMS      NotifyService
+	WhatsApp *WhatsAppNotifier // nil when WhatsApp is not configured
+
+	// Lookup returns the preferred channel for a phone number. Unknown
+	// numbers (e.g. during registration) should return "" with no error.
+	Lookup func(ctx context.Context, phone string) (string, error)
+}
+
+func NewChannelRouter(sms NotifyService, whatsapp *WhatsAppNotifier, lookup func(ctx context.Context, phone string) (string, error)) *ChannelRouter {
+	return &ChannelRouter{
+		SMS:      sms,
+		WhatsApp: whatsapp,
+		Lookup:   lookup,
+	}
+}
+
+// channelFor resolves the channel for a phone; an explicit channel wins.
+func (r *ChannelRouter) channelFor(ctx context.Context, channel, phone string) string {
+	if channel == "" && r.Lookup != nil {
+		channel, _ = r.Lookup(ctx, phone)
+	}
+	if channel == models.ChannelWhatsApp && r.WhatsApp != nil {
+		return models.ChannelWhatsApp
+	}
+	return models.ChannelSMS
+}
+
+// SendOTP sends an OTP over the recipient's preferred channel.
+func (r *ChannelRouter) SendOTP(ctx context.Context, phone, otp string) error {
+	return r.SendOTPVia(ctx, "", phone, otp)
+}
+
+// SendOTPVia sends an OTP over the given channel ("" means preferred),
+// retrying once on the other channel if the first send fails. Queued OTPs
+// use SendOTPOn instead, so a retried job doesn't send over both again.
+func (r *ChannelRouter) SendOTPVia(ctx context.Context, channel, phone, otp string) error {
+	channels := r.OTPChannels(ctx, channel, phone)
+	err := r.SendOTPOn(ctx, channels[0], phone, otp)
+	if err == nil || len(channels) == 1 {
+		return err
+	}
+	if fbErr := r.SendOTPOn(ctx, channels[1], phone, otp); fbErr != nil {
+		return fmt.Errorf("%s: %v; %s fallback: %v", channels[0], err, channels[1], fbErr)
+	}
+	return nil
+}
+
+// OTPChannels returns the channels an OTP can go over: the given channel
+// ("" means preferred) first, then the other one if it is configured.
+func (r *ChannelRouter) OTPChannels(ctx context.Context, channel, phone string) []string {
+	if r.channelFor(ctx, channel, phone) == models.ChannelWhatsApp {
+		if r.SMS == nil {
+			return []string{models.ChannelWhatsApp}
+		}
+		return []string{models.ChannelWhatsApp, models.ChannelSMS}
+	}
+	if r.WhatsApp == nil {
+		return []string{models.ChannelSMS}
+	}
+	return []string{models.ChannelSMS, models.ChannelWhatsApp}
+}
+
+// SendOTPOn sends an OTP over exactly one channel, with no fallback.
+func (r *ChannelRouter) SendOTPOn(ctx context.Context, channel, phone, otp string) error {
+	if channel == models.ChannelWhatsApp && r.WhatsApp != nil {
+		return r.WhatsApp.SendOTP(ctx, phone, otp)
+	}
+	return r.SMS.SendOTP(ctx, phone, otp)
+}
+
+// SendOrderUpdate sends an order status update to recipients who prefer
+// WhatsApp. SMS order updates are not supported, so other recipients are skipped.
+func (r *ChannelRouter) SendOrderUpdate(ctx context.Context, phone, orderCode, status string) error {
+	if r.channelFor(ctx, "", phone) != models.ChannelWhatsApp {
+		return nil
+	}
+	return r.WhatsApp.SendOrderUpdate(ctx, phone, orderCode, status)
+}
@@ -0,0 +1,275 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ============================================
+// WHATSAPP CLOUD API NOTIFIER
+// https://developers.facebook.com/docs/whatsapp/cloud-api
+// Uses pre-approved template messages (no DLT registration needed)
+// ============================================
+
+// WhatsAppNotifier sends OTPs and order updates as WhatsApp template messages.
+type WhatsAppNotifier struct {
+	AccessToken   string
+	PhoneNumberID string
+	OTPTemplate   string // Authentication template with a copy-code button
+	OrderTemplate string // Utility template: {{1}} order code, {{2}} status
+	Language      string // Template language code, e.g. "en" or "hi"
+	BaseURL       string
+	Client        *http.Client
+
+	// OnSent, if set, is called with the WhatsApp message ID after each
+	// accepted send so delivery webhooks can be matched back.
+	OnSent func(messageID, recipient, template string)
+}
+
+func NewWhatsAppNotifier(accessToken, phoneNumberID, otpTemplate, orderTemplate, language string) *WhatsAppNotifier {
+	if otpTemplate == "" {
+		otpTemplate = "jainfood_otp"
+	}
+	if orderTemplate == "" {
+		orderTemplate = "jainfood_order_update"
+	}
+	if language == "" {
+		language = "en"
+	}
+	return &WhatsAppNotifier{
+		AccessToken:   accessToken,
+		PhoneNumberID: phoneNumberID,
+		OTPTemplate:   otpTemplate,
+		OrderTemplate: orderTemplate,
+		Language:      language,
+		BaseURL:       "https://graph.facebook.com/v19.0",
+		Client:        &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// NewWhatsAppNotifierFromEnv builds a WhatsApp notifier from WHATSAPP_* env
+// vars, or returns nil if WhatsApp is not configured.
+func NewWhatsAppNotifierFromEnv() *WhatsAppNotifier {
+	token := os.Getenv("WHATSAPP_ACCESS_TOKEN")
+	phoneNumberID := os.Getenv("WHATSAPP_PHONE_NUMBER_ID")
+	if token == "" || phoneNumberID == "" {
+		return nil
+	}
+	w := NewWhatsAppNotifier(
+		token,
+		phoneNumberID,
+		os.Getenv("WHATSAPP_OTP_TEMPLATE"),
+		os.Getenv("WHATSAPP_ORDER_TEMPLATE"),
+		os.Getenv("WHATSAPP_TEMPLATE_LANG"),
+	)
+	if baseURL := os.Getenv("WHATSAPP_API_URL"); baseURL != "" {
+		w.BaseURL = strings.TrimRight(baseURL, "/")
+	}
+	return w
+}
+
+type waTemplateMessage struct {
+	MessagingProduct string     `json:"messaging_product"`
+	To               string     `json:"to"`
+	Type             string     `json:"type"`
+	Template         waTemplate `json:"template"`
+}
+
+type waTemplate struct {
+	Name       string        `json:"name"`
+	Language   waLanguage    `json:"language"`
+	Components []waComponent `json:"components,omitempty"`
+}
+
+type waLanguage struct {
+	Code string `json:"code"`
+}
+
+type waComponent struct {
+	Type       string        `json:"type"`
+	SubType    string        `json:"sub_type,omitempty"`
+	Index      string        `json:"index,omitempty"`
+	Parameters []waParameter `json:"parameters"`
+}
+
+type waParameter struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// formatWhatsAppPhone returns the phone in international format without "+",
+// assuming India (91) when no country code is present.
+func formatWhatsAppPhone(phone string) string {
+	phone = strings.TrimPrefix(strings.TrimSpace(phone), "+")
+	phone = strings.NewReplacer(" ", "", "-", "").Replace(phone)
+	if len(phone) == 10 {
+		phone = "91" + phone
+	}
+	return phone
+}
+
+// SendOTP sends the OTP using the authentication template.
+func (w *WhatsAppNotifier) SendOTP(ctx context.Context, phone, otp string) error {
+	return w.sendTemplate(ctx, phone, w.OTPTemplate, []waComponent{
+		{Type: "body", Parameters: []waParameter{{Type: "text", Text: otp}}},
+		// Authentication templates carry the code again for the copy-code button
+		{Type: "button", SubType: "url", Index: "0", Parameters: []waParameter{{Type: "text", Text: otp}}},
+	})
+}
+
+// SendOrderUpdate sends an order status change using the order template.
+func (w *WhatsAppNotifier) SendOrderUpdate(ctx context.Context, phone, orderCode, status string) error {
+	return w.sendTemplate(ctx, phone, w.OrderTemplate, []waComponent{
+		{Type: "body", Parameters: []waParameter{
+			{Type: "text", Text: orderCode},
+			{Type: "text", Text: status},
+		}},
+	})
+}
+
+func (w *WhatsAppNotifier) sendTemplate(ctx context.Context, phone, template string, components []waComponent) error {
+	to := formatWhatsAppPhone(phone)
+	payload := waTemplateMessage{
+		MessagingProduct: "whatsapp",
+		To:               to,
+		Type:             "template",
+		Template: waTemplate{
+			Name:       template,
+			Language:   waLanguage{Code: w.Language},
+			Components: components,
+		},
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/%s/messages", w.BaseURL, w.PhoneNumberID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+w.AccessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("whatsapp request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+		Error *struct {
+			Message string `json:"message"`
+			Code    int    `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("whatsapp response decode error: %v (status %d)", err, resp.StatusCode)
+	}
+
+	if resp.StatusCode >= 400 || result.Error != nil {
+		if result.Error != nil {
+			return fmt.Errorf("whatsapp API error: %s (code: %d)", result.Error.Message, result.Error.Code)
+		}
+		return fmt.Errorf("whatsapp API error: status %d", resp.StatusCode)
+	}
+
+	if len(result.Messages) > 0 && w.OnSent != nil {
+		w.OnSent(result.Messages[0].ID, to, template)
+	}
+	return nil
+}
+
+// ============================================
+// WHATSAPP DELIVERY STATUS WEBHOOK
+// ============================================
+
+// WhatsAppStatus is a single delivery status update from the webhook.
+type WhatsAppStatus struct {
+	MessageID   string    `json:"message_id"`
+	Status      string    `json:"status"` // sent, delivered, read, failed
+	RecipientID string    `json:"recipient_id"`
+	Timestamp   time.Time `json:"timestamp"`
+	ErrorCode   int       `json:"error_code,omitempty"`
+	ErrorTitle  string    `json:"error_title,omitempty"`
+}
+
+// VerifyWhatsAppSignature checks the X-Hub-Signature-256 header against the
+// raw request body using the app secret.
+func VerifyWhatsAppSignature(appSecret string, body []byte, header string) bool {
+	sig := strings.TrimPrefix(header, "sha256=")
+	expected, err := hex.DecodeString(sig)
+	if err != nil || appSecret == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// ParseWhatsAppWebhook extracts delivery status updates from a webhook body.
+// Other change types (inbound messages etc.) are ignored.
+func ParseWhatsAppWebhook(body []byte) ([]WhatsAppStatus, error) {
+	var payload struct {
+		Entry []struct {
+			Changes []struct {
+				Field string `json:"field"`
+				Value struct {
+					Statuses []struct {
+						ID          string `json:"id"`
+						Status      string `json:"status"`
+						Timestamp   string `json:"timestamp"`
+						RecipientID string `json:"recipient_id"`
+						Errors      []struct {
+							Code  int    `json:"code"`
+							Title string `json:"title"`
+						} `json:"errors"`
+					} `json:"statuses"`
+				} `json:"value"`
+			} `json:"changes"`
+		} `json:"entry"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	var statuses []WhatsAppStatus
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != "messages" {
+				continue
+			}
+			for _, s := range change.Value.Statuses {
+				st := WhatsAppStatus{
+					MessageID:   s.ID,
+					Status:      s.Status,
+					RecipientID: s.RecipientID,
+				}
+				var unix int64
+				if _, err := fmt.Sscanf(s.Timestamp, "%d", &unix); err == nil {
+					st.Timestamp = time.Unix(unix, 0).UTC()
+				}
+				if len(s.Errors) > 0 {
+					st.ErrorCode = s.Errors[0].Code
+					st.ErrorTitle = s.Errors[0].Title
+				}
+				statuses = append(statuses, st)
+			}
+		}
+	}
+	return statuses, nil
+}
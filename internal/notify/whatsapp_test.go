@@ -0,0 +1,189 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"jainfood/internal/models"
+	"jainfood/internal/queue"
+)
+
+func newWhatsAppStub(t *testing.T, status int, response string, captured *waTemplateMessage) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/12345/messages" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		if captured != nil {
+			if err := json.NewDecoder(r.Body).Decode(captured); err != nil {
+				t.Errorf("decode request: %v", err)
+			}
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+}
+
+func TestWhatsAppNotifier_SendOTP(t *testing.T) {
+	var req waTemplateMessage
+	srv := newWhatsAppStub(t, 200, `{"messages":[{"id":"wamid.ABC"}]}`, &req)
+	defer srv.Close()
+
+	w := NewWhatsAppNotifier("test-token", "12345", "", "", "hi")
+	w.BaseURL = srv.URL
+
+	var sentID, sentTo string
+	w.OnSent = func(id, to, template string) { sentID, sentTo = id, to }
+
+	if err := w.SendOTP(context.Background(), "9876543210", "123456"); err != nil {
+		t.Fatalf("SendOTP() error = %v", err)
+	}
+
+	if req.To != "919876543210" {
+		t.Errorf("To = %q, want 919876543210", req.To)
+	}
+	if req.Template.Name != "jainfood_otp" || req.Template.Language.Code != "hi" {
+		t.Errorf("Template = %+v, want jainfood_otp/hi", req.Template)
+	}
+	if len(req.Template.Components) != 2 || req.Template.Components[0].Parameters[0].Text != "123456" {
+		t.Errorf("Components = %+v, want OTP in body and button", req.Template.Components)
+	}
+	if sentID != "wamid.ABC" || sentTo != "919876543210" {
+		t.Errorf("OnSent got (%q, %q)", sentID, sentTo)
+	}
+}
+
+func TestWhatsAppNotifier_APIError(t *testing.T) {
+	srv := newWhatsAppStub(t, 400, `{"error":{"message":"Template not found","code":132001}}`, nil)
+	defer srv.Close()
+
+	w := NewWhatsAppNotifier("test-token", "12345", "", "", "")
+	w.BaseURL = srv.URL
+
+	err := w.SendOrderUpdate(context.Background(), "+91 98765 43210", "JF-123", "CONFIRMED")
+	if err == nil {
+		t.Fatal("SendOrderUpdate() should fail on API error")
+	}
+}
+
+func TestParseWhatsAppWebhook(t *testing.T) {
+	body := []byte(`{
+		"object": "whatsapp_business_account",
+		"entry": [{"id": "1", "changes": [{"field": "messages", "value": {
+			"statuses": [
+				{"id": "wamid.1", "status": "delivered", "timestamp": "1700000000", "recipient_id": "919876543210"},
+				{"id": "wamid.2", "status": "failed", "timestamp": "1700000001", "recipient_id": "919876543211",
+				 "errors": [{"code": 131026, "title": "Message undeliverable"}]}
+			]
+		}}]}]
+	}`)
+
+	statuses, err := ParseWhatsAppWebhook(body)
+	if err != nil {
+		t.Fatalf("ParseWhatsAppWebhook() error = %v", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("got %d statuses, want 2", len(statuses))
+	}
+	if statuses[0].Status != "delivered" || statuses[0].Timestamp.Unix() != 1700000000 {
+		t.Errorf("statuses[0] = %+v", statuses[0])
+	}
+	if statuses[1].ErrorCode != 131026 {
+		t.Errorf("statuses[1].ErrorCode = %d, want 131026", statuses[1].ErrorCode)
+	}
+}
+
+func TestVerifyWhatsAppSignature(t *testing.T) {
+	body := []byte(`{"entry":[]}`)
+	mac := hmac.New(sha256.New, []byte("app-secret"))
+	mac.Write(body)
+	header := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !VerifyWhatsAppSignature("app-secret", body, header) {
+		t.Error("valid signature rejected")
+	}
+	if VerifyWhatsAppSignature("other-secret", body, header) {
+		t.Error("signature with wrong secret accepted")
+	}
+	if VerifyWhatsAppSignature("", body, header) {
+		t.Error("signature accepted without a configured secret")
+	}
+}
+
+type stubNotifier struct {
+	sent []string
+	err  error
+}
+
+func (s *stubNotifier) SendOTP(ctx context.Context, phone, otp string) error {
+	s.sent = append(s.sent, phone)
+	return s.err
+}
+
+func TestChannelRouter_FallsBackToSMS(t *testing.T) {
+	srv := newWhatsAppStub(t, 500, `{"error":{"message":"internal","code":1}}`, nil)
+	defer srv.Close()
+
+	wa := NewWhatsAppNotifier("test-token", "12345", "", "", "")
+	wa.BaseURL = srv.URL
+	sms := &stubNotifier{}
+
+	r := NewChannelRouter(sms, wa, func(ctx context.Context, phone string) (string, error) {
+		return models.ChannelWhatsApp, nil
+	})
+	if err := r.SendOTP(context.Background(), "9876543210", "123456"); err != nil {
+		t.Fatalf("SendOTP() error = %v", err)
+	}
+	if len(sms.sent) != 1 {
+		t.Errorf("SMS fallback sends = %d, want 1", len(sms.sent))
+	}
+}
+
+func TestChannelRouter_SkipsOrderUpdatesForSMSUsers(t *testing.T) {
+	sms := &stubNotifier{err: errors.New("should not be called")}
+	r := NewChannelRouter(sms, nil, nil)
+	if err := r.SendOrderUpdate(context.Background(), "9876543210", "JF-1", "CONFIRMED"); err != nil {
+		t.Errorf("SendOrderUpdate() error = %v, want nil", err)
+	}
+}
+
+func TestOTPHandler_OneChannelPerAttempt(t *testing.T) {
+	srv := newWhatsAppStub(t, 500, `{"error":{"message":"internal","code":1}}`, nil)
+	defer srv.Close()
+
+	wa := NewWhatsAppNotifier("test-token", "12345", "", "", "")
+	wa.BaseURL = srv.URL
+	sms := &stubNotifier{}
+	r := NewChannelRouter(sms, wa, nil)
+
+	job, err := queue.NewJob(JobSendOTP, OTPJob{Phone: "9876543210", OTP: "123456", Channel: models.ChannelWhatsApp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := OTPHandler(r)
+
+	if err := handler(context.Background(), job); err == nil {
+		t.Fatal("first attempt should fail with WhatsApp down")
+	}
+	if len(sms.sent) != 0 {
+		t.Fatalf("first attempt also sent %d SMS", len(sms.sent))
+	}
+
+	job.Attempts = 1
+	if err := handler(context.Background(), job); err != nil {
+		t.Fatalf("retry error = %v", err)
+	}
+	if len(sms.sent) != 1 {
+		t.Errorf("retry SMS sends = %d, want 1", len(sms.sent))
+	}
+}
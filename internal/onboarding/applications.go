@@ -0,0 +1,416 @@
+package onboarding
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+	"jainfood/internal/pii"
+)
+
+const applicationColumns = `a.id, a.provider_id, p.business_name, a.state, a.legal_name, a.business_type,
+	COALESCE(a.gstin, ''), a.fssai_number, COALESCE(to_char(a.fssai_expires_on, 'YYYY-MM-DD'), ''),
+	a.id_proof_type, COALESCE(a.aadhar_last4, ''), a.submitted_at, COALESCE(a.reviewed_by::text, ''),
+	a.reviewed_at, a.created_at, a.updated_at`
+
+func scanApplication(row pgx.Row) (*models.ProviderApplication, error) {
+	a := &models.ProviderApplication{Documents: []*models.ApplicationDocument{}, History: []*models.ApplicationComment{}}
+	var aadharLast4 string
+	err := row.Scan(&a.ID, &a.ProviderID, &a.BusinessName, &a.State, &a.LegalName, &a.BusinessType,
+		&a.GSTIN, &a.FSSAINumber, &a.FSSAIExpiresOn,
+		&a.IDProofType, &aadharLast4, &a.SubmittedAt, &a.ReviewedBy,
+		&a.ReviewedAt, &a.CreatedAt, &a.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, db.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if aadharLast4 != "" {
+		a.AadharNumber = pii.MaskAadhaar(aadharLast4)
+	}
+	return a, nil
+}
+
+// GetApplication returns a provider's application with its documents and
+// history, or db.ErrNotFound.
+func GetApplication(ctx context.Context, providerID string) (*models.ProviderApplication, error) {
+	return getApplication(ctx, db.Pool, "a.provider_id = $1", providerID)
+}
+
+// GetApplicationByID returns an application with its documents and
+// history, or db.ErrNotFound.
+func GetApplicationByID(ctx context.Context, id string) (*models.ProviderApplication, error) {
+	return getApplication(ctx, db.Pool, "a.id = $1", id)
+}
+
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+func getApplication(ctx context.Context, q querier, where string, arg string) (*models.ProviderApplication, error) {
+	a, err := scanApplication(q.QueryRow(ctx, `
+		SELECT `+applicationColumns+`
+		FROM provider_applications a JOIN providers p ON p.id = a.provider_id
+		WHERE `+where, arg))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT id, kind, object_key, uploaded_at
+		FROM provider_application_documents WHERE application_id = $1
+		ORDER BY uploaded_at
+	`, a.ID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		d := &models.ApplicationDocument{}
+		if err := rows.Scan(&d.ID, &d.Kind, &d.ObjectKey, &d.UploadedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		a.Documents = append(a.Documents, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = q.Query(ctx, `
+		SELECT id, author_id, from_state, to_state, COALESCE(comment, ''), created_at
+		FROM provider_application_comments WHERE application_id = $1
+		ORDER BY created_at
+	`, a.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		c := &models.ApplicationComment{}
+		if err := rows.Scan(&c.ID, &c.AuthorID, &c.FromState, &c.ToState, &c.Comment, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.History = append(a.History, c)
+	}
+	return a, rows.Err()
+}
+
+// SaveDetails creates the provider's draft application or updates its
+// business details. aadhaar is the normalised Aadhaar number, or empty to
+// keep the one on file; it is stored encrypted with keys, with a keyed hash
+// and its last 4 digits. Returns ErrNotEditable once the application has
+// been submitted (until changes are requested), and ErrDuplicateAadhaar if
+// another provider has the number.
+func SaveDetails(ctx context.Context, keys *pii.Keyring, providerID string, app *models.ProviderApplication, aadhaar string) (*models.ProviderApplication, error) {
+	var enc, hash, last4 *string
+	if aadhaar != "" {
+		if keys == nil {
+			return nil, pii.ErrNotConfigured
+		}
+		e, err := keys.Encrypt(aadhaar)
+		if err != nil {
+			return nil, err
+		}
+		h, l := keys.Hash(aadhaar), aadhaar[len(aadhaar)-4:]
+		enc, hash, last4 = &e, &h, &l
+
+		var taken bool
+		if err := db.Pool.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM provider_applications WHERE aadhar_number_hash = $1 AND provider_id <> $2)
+		`, h, providerID).Scan(&taken); err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrDuplicateAadhaar
+		}
+	}
+	var id string
+	err := db.Pool.QueryRow(ctx, `
+		INSERT INTO provider_applications AS a (provider_id, legal_name, business_type, gstin, fssai_number,
+		                                        fssai_expires_on, id_proof_type, aadhar_number_enc,
+		                                        aadhar_number_hash, aadhar_last4)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, '')::date, $7, $8, $9, $10)
+		ON CONFLICT (provider_id) DO UPDATE
+		SET legal_name = EXCLUDED.legal_name, business_type = EXCLUDED.business_type,
+		    gstin = EXCLUDED.gstin, fssai_number = EXCLUDED.fssai_number,
+		    fssai_expires_on = EXCLUDED.fssai_expires_on, id_proof_type = EXCLUDED.id_proof_type,
+		    aadhar_number_enc = CASE WHEN EXCLUDED.id_proof_type <> 'aadhaar' THEN NULL
+		                             ELSE COALESCE(EXCLUDED.aadhar_number_enc, a.aadhar_number_enc) END,
+		    aadhar_number_hash = CASE WHEN EXCLUDED.id_proof_type <> 'aadhaar' THEN NULL
+		                              ELSE COALESCE(EXCLUDED.aadhar_number_hash, a.aadhar_number_hash) END,
+		    aadhar_last4 = CASE WHEN EXCLUDED.id_proof_type <> 'aadhaar' THEN NULL
+		                        ELSE COALESCE(EXCLUDED.aadhar_last4, a.aadhar_last4) END,
+		    updated_at = now()
+		WHERE a.state IN ($11, $12)
+		RETURNING a.id
+	`, providerID, app.LegalName, app.BusinessType, app.GSTIN, app.FSSAINumber,
+		app.FSSAIExpiresOn, app.IDProofType, enc, hash, last4,
+		models.ApplicationDraft, models.ApplicationChangesRequested).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return nil, ErrNotEditable
+	}
+	if err != nil {
+		return nil, err
+	}
+	return GetApplicationByID(ctx, id)
+}
+
+// DocumentProvider returns the provider whose application has objectKey
+// attached, or db.ErrNotFound.
+func DocumentProvider(ctx context.Context, objectKey string) (string, error) {
+	var providerID string
+	err := db.Pool.QueryRow(ctx, `
+		SELECT a.provider_id FROM provider_application_documents d
+		JOIN provider_applications a ON a.id = d.application_id
+		WHERE d.object_key = $1
+		LIMIT 1
+	`, objectKey).Scan(&providerID)
+	if err == pgx.ErrNoRows {
+		return "", db.ErrNotFound
+	}
+	return providerID, err
+}
+
+// editableApplication locks the provider's application for a change by the
+// provider and returns its ID.
+func editableApplication(ctx context.Context, tx pgx.Tx, providerID string) (string, error) {
+	var id, state string
+	err := tx.QueryRow(ctx, `
+		SELECT id, state FROM provider_applications WHERE provider_id = $1 FOR UPDATE
+	`, providerID).Scan(&id, &state)
+	if err == pgx.ErrNoRows {
+		return "", db.ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if !Editable(state) {
+		return "", ErrNotEditable
+	}
+	return id, nil
+}
+
+// AddDocument attaches an uploaded file to the provider's application. The
+// caller checks kind and objectKey; a kind that already has MaxDocuments
+// returns ErrTooManyDocuments.
+func AddDocument(ctx context.Context, providerID, kind, objectKey string) (*models.ApplicationDocument, error) {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	appID, err := editableApplication(ctx, tx, providerID)
+	if err != nil {
+		return nil, err
+	}
+	var n int
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM provider_application_documents WHERE application_id = $1 AND kind = $2
+	`, appID, kind).Scan(&n); err != nil {
+		return nil, err
+	}
+	if n >= MaxDocuments(kind) {
+		return nil, ErrTooManyDocuments
+	}
+
+	d := &models.ApplicationDocument{Kind: kind, ObjectKey: objectKey}
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO provider_application_documents (application_id, kind, object_key)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (application_id, object_key) DO UPDATE SET kind = EXCLUDED.kind
+		RETURNING id, uploaded_at
+	`, appID, kind, objectKey).Scan(&d.ID, &d.UploadedAt); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE provider_applications SET updated_at = now() WHERE id = $1`, appID); err != nil {
+		return nil, err
+	}
+	return d, tx.Commit(ctx)
+}
+
+// DeleteDocument removes a document from the provider's application.
+func DeleteDocument(ctx context.Context, providerID, documentID string) error {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	appID, err := editableApplication(ctx, tx, providerID)
+	if err != nil {
+		return err
+	}
+	ct, err := tx.Exec(ctx, `
+		DELETE FROM provider_application_documents WHERE id = $1 AND application_id = $2
+	`, documentID, appID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return db.ErrNotFound
+	}
+	return tx.Commit(ctx)
+}
+
+// Submit sends the provider's application for review. An incomplete
+// application returns an *IncompleteError.
+func Submit(ctx context.Context, providerID, userID, comment string, now time.Time) (*models.ProviderApplication, error) {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var state string
+	err = tx.QueryRow(ctx, `
+		SELECT state FROM provider_applications WHERE provider_id = $1 FOR UPDATE
+	`, providerID).Scan(&state)
+	if err == pgx.ErrNoRows {
+		return nil, db.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	to, err := SubmitTransition(state)
+	if err != nil {
+		return nil, err
+	}
+	app, err := getApplication(ctx, tx, "a.provider_id = $1", providerID)
+	if err != nil {
+		return nil, err
+	}
+	if missing := Missing(app, now.Format(time.DateOnly)); len(missing) > 0 {
+		return nil, &IncompleteError{Missing: missing}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE provider_applications SET state = $2, submitted_at = $3, updated_at = now() WHERE id = $1
+	`, app.ID, to, now); err != nil {
+		return nil, err
+	}
+	if err := addComment(ctx, tx, app.ID, userID, state, to, comment); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return GetApplicationByID(ctx, app.ID)
+}
+
+// Review applies an admin action to an application. Approval verifies the
+// provider and copies the licence and Aadhaar check onto it.
+func Review(ctx context.Context, applicationID, reviewerID, action, comment string) (*models.ProviderApplication, error) {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var state, providerID string
+	err = tx.QueryRow(ctx, `
+		SELECT state, provider_id FROM provider_applications WHERE id = $1 FOR UPDATE
+	`, applicationID).Scan(&state, &providerID)
+	if err == pgx.ErrNoRows {
+		return nil, db.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	to, err := ReviewTransition(state, action, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE provider_applications
+		SET state = $2, reviewed_by = $3, reviewed_at = now(), updated_at = now()
+		WHERE id = $1
+	`, applicationID, to, reviewerID); err != nil {
+		return nil, err
+	}
+	if to == models.ApplicationApproved {
+		if _, err := tx.Exec(ctx, `
+			UPDATE providers p
+			SET verified = TRUE,
+			    aadhar_verified = a.id_proof_type = 'aadhaar' AND a.aadhar_number_enc IS NOT NULL,
+			    aadhar_number_enc = a.aadhar_number_enc, aadhar_number_hash = a.aadhar_number_hash,
+			    aadhar_last4 = a.aadhar_last4,
+			    fssai_number = a.fssai_number, fssai_expires_on = a.fssai_expires_on
+			FROM provider_applications a
+			WHERE a.id = $1 AND p.id = a.provider_id
+		`, applicationID); err != nil {
+			return nil, err
+		}
+	}
+	if err := addComment(ctx, tx, applicationID, reviewerID, state, to, comment); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return GetApplicationByID(ctx, applicationID)
+}
+
+func addComment(ctx context.Context, tx pgx.Tx, applicationID, authorID, from, to, comment string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO provider_application_comments (application_id, author_id, from_state, to_state, comment)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+	`, applicationID, authorID, from, to, comment)
+	return err
+}
+
+// ListQueue returns applications in the given states, oldest submission
+// first, without documents or history. No states means the open queue:
+// submitted and under review.
+func ListQueue(ctx context.Context, states []string, limit, offset int) ([]*models.ProviderApplication, error) {
+	if len(states) == 0 {
+		states = []string{models.ApplicationSubmitted, models.ApplicationUnderReview}
+	}
+	rows, err := db.Pool.Query(ctx, `
+		SELECT `+applicationColumns+`
+		FROM provider_applications a JOIN providers p ON p.id = a.provider_id
+		WHERE a.state = ANY($1)
+		ORDER BY a.submitted_at NULLS LAST, a.created_at
+		LIMIT $2 OFFSET $3
+	`, states, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*models.ProviderApplication{}
+	for rows.Next() {
+		a, err := scanApplication(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// RevealAadhaar decrypts the Aadhaar number on an application, for a
+// reviewer checking it against the ID proof. Returns db.ErrNotFound when
+// the application has none.
+func RevealAadhaar(ctx context.Context, keys *pii.Keyring, applicationID string) (string, error) {
+	var enc *string
+	err := db.Pool.QueryRow(ctx, `
+		SELECT aadhar_number_enc FROM provider_applications WHERE id = $1
+	`, applicationID).Scan(&enc)
+	if err == pgx.ErrNoRows || (err == nil && enc == nil) {
+		return "", db.ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if keys == nil {
+		return "", pii.ErrNotConfigured
+	}
+	return keys.Decrypt(*enc)
+}
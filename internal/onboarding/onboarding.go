@@ -0,0 +1,297 @@
+// Package onboarding runs provider verification applications: the provider
+// fills in business details and uploads documents, submits, and an admin
+// reviews the application until it is approved or rejected.
+package onboarding
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"jainfood/internal/models"
+)
+
+// DocumentFolder is the media folder application documents are uploaded to.
+const DocumentFolder = "provider-docs"
+
+// Limits on application documents.
+const (
+	MinKitchenPhotos = 2
+	MaxKitchenPhotos = 10
+	MaxOtherDocs     = 3 // Per kind, e.g. both sides of an ID card
+)
+
+// Review actions an admin can take.
+const (
+	ActionStartReview    = "start_review"
+	ActionRequestChanges = "request_changes"
+	ActionApprove        = "approve"
+	ActionReject         = "reject"
+)
+
+// Business types and ID proof types an application may declare.
+var (
+	BusinessTypes = []string{"individual", "proprietorship", "partnership", "company"}
+	IDProofTypes  = []string{"aadhaar", "pan", "passport", "voter_id", "driving_licence"}
+)
+
+// ErrNotEditable is returned when a provider changes an application that is
+// with the reviewers or already decided.
+var ErrNotEditable = errors.New("application can't be changed in its current state")
+
+// ErrDuplicateAadhaar is returned when another provider's application
+// already has the Aadhaar number.
+var ErrDuplicateAadhaar = errors.New("this Aadhaar number is already registered with another provider")
+
+// ErrTooManyDocuments is returned when an application already holds
+// MaxDocuments of a kind.
+var ErrTooManyDocuments = errors.New("too many documents of this kind; delete one first")
+
+// TransitionError is returned for an action the application's state doesn't
+// allow.
+type TransitionError struct {
+	State  string
+	Action string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("can't %s an application that is %s", strings.ReplaceAll(e.Action, "_", " "), e.State)
+}
+
+// IncompleteError lists what an application is missing before it can be
+// submitted.
+type IncompleteError struct {
+	Missing []string
+}
+
+func (e *IncompleteError) Error() string {
+	return "application is incomplete: " + strings.Join(e.Missing, "; ")
+}
+
+// reviewTransitions maps an admin action to the states it applies to and the
+// state it leads to.
+var reviewTransitions = map[string]struct {
+	from []string
+	to   string
+}{
+	ActionStartReview:    {[]string{models.ApplicationSubmitted}, models.ApplicationUnderReview},
+	ActionRequestChanges: {[]string{models.ApplicationSubmitted, models.ApplicationUnderReview}, models.ApplicationChangesRequested},
+	ActionApprove:        {[]string{models.ApplicationSubmitted, models.ApplicationUnderReview}, models.ApplicationApproved},
+	ActionReject:         {[]string{models.ApplicationSubmitted, models.ApplicationUnderReview}, models.ApplicationRejected},
+}
+
+// Editable reports whether the provider may change an application in state.
+func Editable(state string) bool {
+	return state == models.ApplicationDraft || state == models.ApplicationChangesRequested
+}
+
+// ValidateReview checks an admin action. Requesting changes and rejecting
+// need a comment for the provider.
+func ValidateReview(action, comment string) error {
+	if _, ok := reviewTransitions[action]; !ok {
+		return fmt.Errorf("action must be one of %s, %s, %s or %s",
+			ActionStartReview, ActionRequestChanges, ActionApprove, ActionReject)
+	}
+	if (action == ActionRequestChanges || action == ActionReject) && strings.TrimSpace(comment) == "" {
+		return fmt.Errorf("a comment is required to %s", strings.ReplaceAll(action, "_", " "))
+	}
+	return nil
+}
+
+// ReviewTransition returns the state an admin action moves an application
+// in state to, after ValidateReview.
+func ReviewTransition(state, action, comment string) (string, error) {
+	if err := ValidateReview(action, comment); err != nil {
+		return "", err
+	}
+	t := reviewTransitions[action]
+	for _, from := range t.from {
+		if state == from {
+			return t.to, nil
+		}
+	}
+	return "", &TransitionError{State: state, Action: action}
+}
+
+// SubmitTransition returns the state a provider's submission moves an
+// application in state to.
+func SubmitTransition(state string) (string, error) {
+	if !Editable(state) {
+		return "", &TransitionError{State: state, Action: "submit"}
+	}
+	return models.ApplicationSubmitted, nil
+}
+
+var (
+	fssaiPattern = regexp.MustCompile(`^[12][0-9]{13}$`)
+	gstinPattern = regexp.MustCompile(`^[0-9]{2}[A-Z]{5}[0-9]{4}[A-Z][1-9A-Z]Z[0-9A-Z]$`)
+	digitsOnly   = strings.NewReplacer(" ", "", "-", "")
+)
+
+// ValidFSSAI reports whether n looks like an FSSAI licence or registration
+// number: 14 digits, starting with 1 (licence) or 2 (registration).
+func ValidFSSAI(n string) bool {
+	return fssaiPattern.MatchString(n)
+}
+
+// ValidGSTIN reports whether s has the shape of a GSTIN: state code, PAN,
+// entity number, Z and a check character.
+func ValidGSTIN(s string) bool {
+	return gstinPattern.MatchString(s)
+}
+
+// NormaliseAadhaar strips the spaces and dashes Aadhaar numbers are often
+// written with.
+func NormaliseAadhaar(n string) string {
+	return digitsOnly.Replace(strings.TrimSpace(n))
+}
+
+// Verhoeff tables, used by Aadhaar for its check digit.
+var (
+	verhoeffD = [10][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 2, 3, 4, 0, 6, 7, 8, 9, 5},
+		{2, 3, 4, 0, 1, 7, 8, 9, 5, 6},
+		{3, 4, 0, 1, 2, 8, 9, 5, 6, 7},
+		{4, 0, 1, 2, 3, 9, 5, 6, 7, 8},
+		{5, 9, 8, 7, 6, 0, 4, 3, 2, 1},
+		{6, 5, 9, 8, 7, 1, 0, 4, 3, 2},
+		{7, 6, 5, 9, 8, 2, 1, 0, 4, 3},
+		{8, 7, 6, 5, 9, 3, 2, 1, 0, 4},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+	}
+	verhoeffP = [8][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 5, 7, 6, 2, 8, 3, 0, 9, 4},
+		{5, 8, 0, 3, 7, 9, 6, 1, 4, 2},
+		{8, 9, 1, 6, 0, 4, 3, 5, 2, 7},
+		{9, 4, 5, 3, 1, 2, 6, 8, 7, 0},
+		{4, 2, 8, 6, 5, 7, 3, 9, 0, 1},
+		{2, 7, 9, 3, 8, 0, 6, 4, 1, 5},
+		{7, 0, 4, 6, 9, 1, 3, 2, 5, 8},
+	}
+)
+
+// ValidAadhaar reports whether n (normalised) is a well-formed Aadhaar
+// number: 12 digits, not starting with 0 or 1, with a valid Verhoeff check
+// digit.
+func ValidAadhaar(n string) bool {
+	if len(n) != 12 || n[0] < '2' {
+		return false
+	}
+	c := 0
+	for i := 0; i < len(n); i++ {
+		d := n[len(n)-1-i]
+		if d < '0' || d > '9' {
+			return false
+		}
+		c = verhoeffD[c][verhoeffP[i%8][d-'0']]
+	}
+	return c == 0
+}
+
+// UserDocumentFolder is the folder the media upload endpoint puts userID's
+// documents in, so an attached key can be tied to the user who uploaded it.
+func UserDocumentFolder(userID string) string {
+	return DocumentFolder + "/" + userID
+}
+
+// ValidObjectKey reports whether key was issued to userID for
+// DocumentFolder by the media upload endpoint.
+func ValidObjectKey(key, userID string) bool {
+	prefix := UserDocumentFolder(userID) + "/"
+	return userID != "" && strings.HasPrefix(key, prefix) && !strings.Contains(key, "..") && len(key) > len(prefix)
+}
+
+// IsDocumentKey reports whether key, or a media folder, lies in
+// DocumentFolder however it is spelled (leading slashes, "./" and so on).
+func IsDocumentKey(key string) bool {
+	p := path.Clean("/" + key)
+	return p == "/"+DocumentFolder || strings.HasPrefix(p, "/"+DocumentFolder+"/")
+}
+
+// ValidateDetails checks the business details a provider saves. Empty
+// fields are allowed in a draft; Missing reports them at submission.
+func ValidateDetails(app *models.ProviderApplication) error {
+	if app.BusinessType != "" && !contains(BusinessTypes, app.BusinessType) {
+		return fmt.Errorf("business_type must be one of %s", strings.Join(BusinessTypes, ", "))
+	}
+	if app.IDProofType != "" && !contains(IDProofTypes, app.IDProofType) {
+		return fmt.Errorf("id_proof_type must be one of %s", strings.Join(IDProofTypes, ", "))
+	}
+	if app.FSSAINumber != "" && !ValidFSSAI(app.FSSAINumber) {
+		return fmt.Errorf("fssai_number must be 14 digits")
+	}
+	if app.FSSAIExpiresOn != "" {
+		if _, err := time.Parse(time.DateOnly, app.FSSAIExpiresOn); err != nil {
+			return fmt.Errorf("fssai_expires_on must be YYYY-MM-DD")
+		}
+	}
+	if app.GSTIN != "" && !ValidGSTIN(app.GSTIN) {
+		return fmt.Errorf("gstin is not valid")
+	}
+	return nil
+}
+
+// Missing lists what an application needs before it can be submitted, or
+// nil. today (YYYY-MM-DD) rules out expired licences.
+func Missing(app *models.ProviderApplication, today string) []string {
+	var missing []string
+	if strings.TrimSpace(app.LegalName) == "" {
+		missing = append(missing, "legal_name is required")
+	}
+	if app.BusinessType == "" {
+		missing = append(missing, "business_type is required")
+	}
+	if app.FSSAINumber == "" {
+		missing = append(missing, "fssai_number is required")
+	}
+	if app.FSSAIExpiresOn != "" && app.FSSAIExpiresOn < today {
+		missing = append(missing, "the FSSAI licence has expired")
+	}
+	if app.IDProofType == "" {
+		missing = append(missing, "id_proof_type is required")
+	}
+	if app.IDProofType == "aadhaar" && app.AadharNumber == "" {
+		missing = append(missing, "aadhar_number is required for Aadhaar ID proof")
+	}
+
+	counts := map[string]int{}
+	for _, d := range app.Documents {
+		counts[d.Kind]++
+	}
+	if counts[models.DocumentFSSAILicence] == 0 {
+		missing = append(missing, "upload the FSSAI licence")
+	}
+	if counts[models.DocumentIDProof] == 0 {
+		missing = append(missing, "upload the ID proof")
+	}
+	if counts[models.DocumentKitchenPhoto] < MinKitchenPhotos {
+		missing = append(missing, fmt.Sprintf("upload at least %d kitchen photos", MinKitchenPhotos))
+	}
+	return missing
+}
+
+// MaxDocuments is how many documents of a kind an application may hold, or
+// 0 for an unknown kind.
+func MaxDocuments(kind string) int {
+	switch kind {
+	case models.DocumentKitchenPhoto:
+		return MaxKitchenPhotos
+	case models.DocumentFSSAILicence, models.DocumentIDProof:
+		return MaxOtherDocs
+	}
+	return 0
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package onboarding
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"jainfood/internal/models"
+)
+
+func TestReviewTransition(t *testing.T) {
+	cases := []struct {
+		state, action, comment string
+		want                   string
+	}{
+		{models.ApplicationSubmitted, ActionStartReview, "", models.ApplicationUnderReview},
+		{models.ApplicationSubmitted, ActionApprove, "", models.ApplicationApproved},
+		{models.ApplicationUnderReview, ActionApprove, "", models.ApplicationApproved},
+		{models.ApplicationUnderReview, ActionRequestChanges, "Licence photo is blurred", models.ApplicationChangesRequested},
+		{models.ApplicationUnderReview, ActionReject, "Not a Jain kitchen", models.ApplicationRejected},
+	}
+	for _, c := range cases {
+		got, err := ReviewTransition(c.state, c.action, c.comment)
+		if err != nil || got != c.want {
+			t.Errorf("ReviewTransition(%s, %s) = %q, %v; want %q", c.state, c.action, got, err, c.want)
+		}
+	}
+
+	var te *TransitionError
+	for _, state := range []string{models.ApplicationDraft, models.ApplicationChangesRequested, models.ApplicationApproved, models.ApplicationRejected} {
+		if _, err := ReviewTransition(state, ActionApprove, ""); !errors.As(err, &te) {
+			t.Errorf("approving a %s application: err = %v, want TransitionError", state, err)
+		}
+	}
+	if _, err := ReviewTransition(models.ApplicationUnderReview, ActionStartReview, ""); !errors.As(err, &te) {
+		t.Errorf("starting review twice: err = %v, want TransitionError", err)
+	}
+	if _, err := ReviewTransition(models.ApplicationUnderReview, ActionReject, " "); err == nil {
+		t.Error("rejecting without a comment succeeded")
+	}
+	if _, err := ReviewTransition(models.ApplicationSubmitted, "verify", ""); err == nil {
+		t.Error("unknown action succeeded")
+	}
+}
+
+func TestSubmitTransition(t *testing.T) {
+	for _, state := range []string{models.ApplicationDraft, models.ApplicationChangesRequested} {
+		if got, err := SubmitTransition(state); err != nil || got != models.ApplicationSubmitted {
+			t.Errorf("SubmitTransition(%s) = %q, %v", state, got, err)
+		}
+	}
+	for _, state := range []string{models.ApplicationSubmitted, models.ApplicationUnderReview, models.ApplicationApproved, models.ApplicationRejected} {
+		if _, err := SubmitTransition(state); err == nil {
+			t.Errorf("SubmitTransition(%s) succeeded", state)
+		}
+	}
+}
+
+func TestValidAadhaar(t *testing.T) {
+	valid := []string{"234123412346", "999941057058"}
+	for _, n := range valid {
+		if !ValidAadhaar(n) {
+			t.Errorf("ValidAadhaar(%s) = false", n)
+		}
+	}
+	invalid := []string{"234123412345", "123412341234", "23412341234", "2341234123a6", ""}
+	for _, n := range invalid {
+		if ValidAadhaar(n) {
+			t.Errorf("ValidAadhaar(%s) = true", n)
+		}
+	}
+	if got := NormaliseAadhaar(" 2341-2341 2346 "); got != "234123412346" {
+		t.Errorf("NormaliseAadhaar = %q", got)
+	}
+}
+
+func TestValidateDetails(t *testing.T) {
+	ok := &models.ProviderApplication{
+		BusinessType: "proprietorship", IDProofType: "aadhaar",
+		FSSAINumber: "21524005001234", FSSAIExpiresOn: "2027-03-31", GSTIN: "24AAACJ1234M1Z5",
+	}
+	if err := ValidateDetails(ok); err != nil {
+		t.Errorf("ValidateDetails = %v", err)
+	}
+	if err := ValidateDetails(&models.ProviderApplication{}); err != nil {
+		t.Errorf("empty draft: ValidateDetails = %v", err)
+	}
+	bad := []*models.ProviderApplication{
+		{BusinessType: "trust"},
+		{IDProofType: "ration_card"},
+		{FSSAINumber: "1234"},
+		{FSSAINumber: "31524005001234"},
+		{FSSAIExpiresOn: "31-03-2027"},
+		{GSTIN: "24AAACJ1234M1X5"},
+	}
+	for _, a := range bad {
+		if err := ValidateDetails(a); err == nil {
+			t.Errorf("ValidateDetails(%+v) succeeded", a)
+		}
+	}
+}
+
+func TestMissing(t *testing.T) {
+	app := &models.ProviderApplication{}
+	if got := Missing(app, "2026-10-18"); len(got) != 7 {
+		t.Errorf("empty application: Missing = %v", got)
+	}
+
+	app = &models.ProviderApplication{
+		LegalName: "Shah Jain Rasoi", BusinessType: "individual",
+		FSSAINumber: "21524005001234", FSSAIExpiresOn: "2027-03-31",
+		IDProofType: "aadhaar", AadharNumber: "XXXX-XXXX-2346",
+		Documents: []*models.ApplicationDocument{
+			{Kind: models.DocumentFSSAILicence},
+			{Kind: models.DocumentIDProof},
+			{Kind: models.DocumentKitchenPhoto},
+			{Kind: models.DocumentKitchenPhoto},
+		},
+	}
+	if got := Missing(app, "2026-10-18"); got != nil {
+		t.Errorf("complete application: Missing = %v", got)
+	}
+	if got := Missing(app, "2027-04-01"); len(got) != 1 || !strings.Contains(got[0], "expired") {
+		t.Errorf("expired licence: Missing = %v", got)
+	}
+	app.Documents = app.Documents[:3]
+	if got := Missing(app, "2026-10-18"); len(got) != 1 || !strings.Contains(got[0], "kitchen photos") {
+		t.Errorf("one kitchen photo: Missing = %v", got)
+	}
+}
+
+func TestValidObjectKey(t *testing.T) {
+	if !ValidObjectKey("provider-docs/u1/4f1c-licence.pdf", "u1") {
+		t.Error("provider-docs key rejected")
+	}
+	for _, key := range []string{
+		"items/4f1c-photo.jpg", "provider-docs/", "provider-docs/u1/", "provider-docs/u1/../u2/x.pdf",
+		"provider-docsx/a.pdf", "provider-docs/4f1c-licence.pdf", "provider-docs/u2/4f1c-licence.pdf",
+		"provider-docs/u12/4f1c-licence.pdf",
+	} {
+		if ValidObjectKey(key, "u1") {
+			t.Errorf("ValidObjectKey(%q) = true", key)
+		}
+	}
+	if ValidObjectKey("provider-docs//x.pdf", "") {
+		t.Error("key accepted without a user")
+	}
+}
+
+func TestIsDocumentKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"provider-docs/u1/4f1c-licence.pdf":  true,
+		"provider-docs":                      true,
+		"/provider-docs/u1/x.pdf":            true,
+		"./provider-docs/u1/x.pdf":           true,
+		"items/../provider-docs/u1/x.pdf":    true,
+		"items/4f1c-photo.jpg":               false,
+		"provider-docsx/a.pdf":               false,
+		"items/provider-docs/4f1c-photo.jpg": false,
+	} {
+		if got := IsDocumentKey(key); got != want {
+			t.Errorf("IsDocumentKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -8,9 +8,12 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	ulid "github.com/oklog/ulid/v2"
 	"jainfood/internal/db"
 	"jainfood/internal/models"
+	"jainfood/internal/pii"
+	"jainfood/internal/users"
 )
 
 // GenerateOrderCode returns a human-friendly sortable ULID-based code.
@@ -26,7 +29,11 @@ func GenerateOrderCode() (string, error) {
 	return "JF-" + id.String(), nil
 }
 
-func CreateOrder(ctx context.Context, buyerID, providerID string, items interface{}, total float64) (string, string, error) {
+// CreateOrder stores a new order. quote is the delivery to the buyer's
+// address, or nil for pickup; its fee is added to the items subtotal, and
+// the address is stored as a snapshot with its street lines encrypted
+// under keys.
+func CreateOrder(ctx context.Context, keys *pii.Keyring, buyerID, providerID string, items interface{}, subtotal float64, quote *models.DeliveryQuote, address *models.Address) (string, string, error) {
 	id := uuid.New().String()
 	orderCode, err := GenerateOrderCode()
 	if err != nil {
@@ -36,16 +43,66 @@ func CreateOrder(ctx context.Context, buyerID, providerID string, items interfac
 	if err != nil {
 		return "", "", err
 	}
-	_, err = db.Pool.Exec(ctx, `INSERT INTO orders (id, order_code, buyer_id, provider_id, items, total_estimate, status, created_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
-		id, orderCode, buyerID, providerID, itemsJSON, total, models.OrderStatusCreated, time.Now())
+	var fee float64
+	var distanceKm *float64
+	if quote != nil {
+		fee, distanceKm = quote.Fee, &quote.DistanceKm
+	}
+	addressJSON, sealed, err := snapshotAddress(keys, address)
+	if err != nil {
+		return "", "", err
+	}
+	_, err = db.Pool.Exec(ctx, `INSERT INTO orders (id, order_code, buyer_id, provider_id, items, total_estimate, delivery_fee, delivery_distance_km, delivery_address, delivery_street_enc, status, created_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,NULLIF($10, ''),$11,$12)`,
+		id, orderCode, buyerID, providerID, itemsJSON, subtotal+fee, fee, distanceKm, addressJSON, sealed, models.OrderStatusCreated, time.Now())
 	if err != nil {
 		return "", "", err
 	}
 	return id, orderCode, nil
 }
 
+// snapshotAddress encodes the address copied onto an order, with its street
+// lines sealed separately when keys is set. A nil address encodes as NULL.
+func snapshotAddress(keys *pii.Keyring, address *models.Address) ([]byte, string, error) {
+	if address == nil {
+		return nil, "", nil
+	}
+	sealed, err := users.SealStreet(keys, address)
+	if err != nil {
+		return nil, "", err
+	}
+	snapshot := *address
+	if sealed != "" {
+		snapshot.Line1, snapshot.Line2, snapshot.Landmark = "", "", ""
+	}
+	data, err := json.Marshal(snapshot)
+	return data, sealed, err
+}
+
+// orderColumns are the columns scanOrder reads.
+const orderColumns = `id, order_code, buyer_id, provider_id, items, total_estimate, delivery_fee, delivery_address,
+		       COALESCE(delivery_street_enc, ''), status, created_at`
+
+func scanOrder(keys *pii.Keyring, row pgx.Row) (*models.Order, error) {
+	order := &models.Order{}
+	var itemsJSON []byte
+	var sealed string
+	if err := row.Scan(
+		&order.ID, &order.OrderCode, &order.BuyerID, &order.ProviderID,
+		&itemsJSON, &order.TotalEstimate, &order.DeliveryFee, &order.DeliveryAddress, &sealed, &order.Status, &order.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	_ = json.Unmarshal(itemsJSON, &order.Items)
+	if order.DeliveryAddress != nil {
+		if err := users.OpenStreet(keys, order.DeliveryAddress, sealed); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
 func ConfirmOrder(ctx context.Context, orderID string) error {
-	ct, err := db.Pool.Exec(ctx, `UPDATE orders SET status=$1 WHERE id=$2`, models.OrderStatusConfirmed, orderID)
+	ct, err := db.Pool.Exec(ctx, `UPDATE orders SET status=$1, updated_at=now() WHERE id=$2`, models.OrderStatusConfirmed, orderID)
 	if err != nil {
 		return err
 	}
@@ -57,7 +114,7 @@ func ConfirmOrder(ctx context.Context, orderID string) error {
 
 // CancelOrder sets order status to CANCELLED.
 func CancelOrder(ctx context.Context, orderID string) error {
-	ct, err := db.Pool.Exec(ctx, `UPDATE orders SET status=$1 WHERE id=$2`, models.OrderStatusCancelled, orderID)
+	ct, err := db.Pool.Exec(ctx, `UPDATE orders SET status=$1, updated_at=now() WHERE id=$2`, models.OrderStatusCancelled, orderID)
 	if err != nil {
 		return err
 	}
@@ -69,7 +126,7 @@ func CancelOrder(ctx context.Context, orderID string) error {
 
 // CompleteOrder sets order status to COMPLETED.
 func CompleteOrder(ctx context.Context, orderID string) error {
-	ct, err := db.Pool.Exec(ctx, `UPDATE orders SET status=$1 WHERE id=$2`, models.OrderStatusCompleted, orderID)
+	ct, err := db.Pool.Exec(ctx, `UPDATE orders SET status=$1, updated_at=now() WHERE id=$2`, models.OrderStatusCompleted, orderID)
 	if err != nil {
 		return err
 	}
@@ -90,27 +147,14 @@ func GetOrderByCode(ctx context.Context, orderCode string) (string, error) {
 }
 
 // GetOrderByID fetches a full order by ID.
-func GetOrderByID(ctx context.Context, orderID string) (*models.Order, error) {
-	order := &models.Order{}
-	var itemsJSON []byte
-	err := db.Pool.QueryRow(ctx, `
-		SELECT id, order_code, buyer_id, provider_id, items, total_estimate, status, created_at
-		FROM orders WHERE id = $1
-	`, orderID).Scan(
-		&order.ID, &order.OrderCode, &order.BuyerID, &order.ProviderID,
-		&itemsJSON, &order.TotalEstimate, &order.Status, &order.CreatedAt,
-	)
-	if err != nil {
-		return nil, err
-	}
-	_ = json.Unmarshal(itemsJSON, &order.Items)
-	return order, nil
+func GetOrderByID(ctx context.Context, keys *pii.Keyring, orderID string) (*models.Order, error) {
+	return scanOrder(keys, db.Pool.QueryRow(ctx, `SELECT `+orderColumns+` FROM orders WHERE id = $1`, orderID))
 }
 
 // GetOrdersByBuyer retrieves orders for a buyer.
-func GetOrdersByBuyer(ctx context.Context, buyerID string, limit, offset int) ([]*models.Order, error) {
+func GetOrdersByBuyer(ctx context.Context, keys *pii.Keyring, buyerID string, limit, offset int) ([]*models.Order, error) {
 	rows, err := db.Pool.Query(ctx, `
-		SELECT id, order_code, buyer_id, provider_id, items, total_estimate, status, created_at
+		SELECT `+orderColumns+`
 		FROM orders WHERE buyer_id = $1
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
@@ -122,24 +166,19 @@ func GetOrdersByBuyer(ctx context.Context, buyerID string, limit, offset int) ([
 
 	var orders []*models.Order
 	for rows.Next() {
-		order := &models.Order{}
-		var itemsJSON []byte
-		if err := rows.Scan(
-			&order.ID, &order.OrderCode, &order.BuyerID, &order.ProviderID,
-			&itemsJSON, &order.TotalEstimate, &order.Status, &order.CreatedAt,
-		); err != nil {
+		order, err := scanOrder(keys, rows)
+		if err != nil {
 			return nil, err
 		}
-		_ = json.Unmarshal(itemsJSON, &order.Items)
 		orders = append(orders, order)
 	}
 	return orders, nil
 }
 
 // GetOrdersByProvider retrieves orders for a provider.
-func GetOrdersByProvider(ctx context.Context, providerID string, limit, offset int) ([]*models.Order, error) {
+func GetOrdersByProvider(ctx context.Context, keys *pii.Keyring, providerID string, limit, offset int) ([]*models.Order, error) {
 	rows, err := db.Pool.Query(ctx, `
-		SELECT id, order_code, buyer_id, provider_id, items, total_estimate, status, created_at
+		SELECT `+orderColumns+`
 		FROM orders WHERE provider_id = $1
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
@@ -151,15 +190,36 @@ func GetOrdersByProvider(ctx context.Context, providerID string, limit, offset i
 
 	var orders []*models.Order
 	for rows.Next() {
-		order := &models.Order{}
-		var itemsJSON []byte
-		if err := rows.Scan(
-			&order.ID, &order.OrderCode, &order.BuyerID, &order.ProviderID,
-			&itemsJSON, &order.TotalEstimate, &order.Status, &order.CreatedAt,
-		); err != nil {
+		order, err := scanOrder(keys, rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+// GetOrdersByProviders retrieves orders for any of several outlets, newest
+// first, optionally only those with status.
+func GetOrdersByProviders(ctx context.Context, keys *pii.Keyring, providerIDs []string, status string, limit, offset int) ([]*models.Order, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT `+orderColumns+`
+		FROM orders
+		WHERE provider_id = ANY($1::uuid[]) AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`, providerIDs, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []*models.Order{}
+	for rows.Next() {
+		order, err := scanOrder(keys, rows)
+		if err != nil {
 			return nil, err
 		}
-		_ = json.Unmarshal(itemsJSON, &order.Items)
 		orders = append(orders, order)
 	}
 	return orders, nil
@@ -196,3 +256,52 @@ func UpdateStatus(ctx context.Context, orderID string, status string) error {
 	}
 	return nil
 }
+
+// EncryptStoredAddresses seals the street lines of order address snapshots
+// stored in the clear, and reports how many orders it encrypted.
+func EncryptStoredAddresses(ctx context.Context, keys *pii.Keyring) (int, error) {
+	done := 0
+	for {
+		rows, err := db.Pool.Query(ctx, `
+			SELECT id::text, delivery_address FROM orders
+			WHERE delivery_street_enc IS NULL
+			  AND COALESCE(delivery_address->>'line1', '') || COALESCE(delivery_address->>'line2', '') ||
+			      COALESCE(delivery_address->>'landmark', '') <> ''
+			LIMIT 500
+		`)
+		if err != nil {
+			return done, err
+		}
+		ids, addresses := []string{}, []*models.Address{}
+		for rows.Next() {
+			var id string
+			a := &models.Address{}
+			if err := rows.Scan(&id, a); err != nil {
+				rows.Close()
+				return done, err
+			}
+			ids, addresses = append(ids, id), append(addresses, a)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return done, err
+		}
+		if len(ids) == 0 {
+			return done, nil
+		}
+
+		for i, a := range addresses {
+			data, sealed, err := snapshotAddress(keys, a)
+			if err != nil {
+				return done, err
+			}
+			if _, err := db.Pool.Exec(ctx, `
+				UPDATE orders SET delivery_address = $2, delivery_street_enc = $3
+				WHERE id = $1 AND delivery_street_enc IS NULL
+			`, ids[i], data, sealed); err != nil {
+				return done, err
+			}
+			done++
+		}
+	}
+}
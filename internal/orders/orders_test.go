@@ -1,8 +1,14 @@
 package orders
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"strings"
 	"testing"
+
+	"jainfood/internal/models"
+	"jainfood/internal/pii"
+	"jainfood/internal/users"
 )
 
 func TestGenerateOrderCode(t *testing.T) {
@@ -37,3 +43,39 @@ func TestGenerateOrderCode_Uniqueness(t *testing.T) {
 		codes[code] = true
 	}
 }
+
+func TestSnapshotAddressSealsStreet(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	keys, err := pii.NewKeyring("k1:"+key, "k1", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := &models.Address{Line1: "12 Jain Mandir Marg", Landmark: "Opp. upashray", PinCode: "380001", City: "Ahmedabad"}
+
+	data, sealed, err := snapshotAddress(keys, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sealed == "" || strings.Contains(string(data), "Mandir") || strings.Contains(string(data), "upashray") {
+		t.Fatalf("snapshot %s keeps the street in the clear (sealed %q)", data, sealed)
+	}
+	if address.Line1 == "" {
+		t.Error("snapshotAddress cleared the caller's address")
+	}
+
+	var got models.Address
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := users.OpenStreet(keys, &got, sealed); err != nil {
+		t.Fatal(err)
+	}
+	if got.Line1 != address.Line1 || got.Landmark != address.Landmark || got.City != "Ahmedabad" {
+		t.Errorf("reopened snapshot = %+v", got)
+	}
+
+	// Without keys (development) the snapshot stays as it was
+	if data, sealed, err := snapshotAddress(nil, address); err != nil || sealed != "" || !strings.Contains(string(data), "Mandir") {
+		t.Errorf("snapshot without keys = %s, %q, %v", data, sealed, err)
+	}
+}
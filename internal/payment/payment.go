@@ -22,6 +22,7 @@ type PaymentService interface {
 	CreateOrder(amount int64, currency string, receipt string, notes map[string]string) (*PaymentOrder, error)
 	VerifyPayment(orderID, paymentID, signature string) bool
 	GetPaymentDetails(paymentID string) (*PaymentDetails, error)
+	RefundPayment(paymentID string, amount int64, notes map[string]string) (*Refund, error)
 }
 
 // PaymentOrder represents a payment order
@@ -52,6 +53,17 @@ type PaymentDetails struct {
 	CreatedAt     int64  `json:"created_at"`
 }
 
+// Refund represents a refund of a payment
+type Refund struct {
+	ID        string `json:"id"`
+	Entity    string `json:"entity"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	PaymentID string `json:"payment_id"`
+	Status    string `json:"status"`
+	CreatedAt int64  `json:"created_at"`
+}
+
 // ============================================
 // RAZORPAY PAYMENT SERVICE
 // https://razorpay.com/docs/api/
@@ -162,6 +174,44 @@ func (r *RazorpayService) GetPaymentDetails(paymentID string) (*PaymentDetails,
 	return &payment, nil
 }
 
+// RefundPayment refunds amount paise of a captured payment
+func (r *RazorpayService) RefundPayment(paymentID string, amount int64, notes map[string]string) (*Refund, error) {
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"amount": amount,
+		"notes":  notes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", r.BaseURL+"/payments/"+paymentID+"/refund", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.SetBasicAuth(r.KeyID, r.KeySecret)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("razorpay API error: %s", string(body))
+	}
+
+	var refund Refund
+	if err := json.NewDecoder(resp.Body).Decode(&refund); err != nil {
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
 // ============================================
 // MOCK PAYMENT SERVICE (Development)
 // ============================================
@@ -205,6 +255,18 @@ func (m *MockPaymentService) GetPaymentDetails(paymentID string) (*PaymentDetail
 	}, nil
 }
 
+func (m *MockPaymentService) RefundPayment(paymentID string, amount int64, notes map[string]string) (*Refund, error) {
+	return &Refund{
+		ID:        "rfnd_mock_" + fmt.Sprintf("%d", time.Now().UnixNano()),
+		Entity:    "refund",
+		Amount:    amount,
+		Currency:  "INR",
+		PaymentID: paymentID,
+		Status:    "processed",
+		CreatedAt: time.Now().Unix(),
+	}, nil
+}
+
 // ============================================
 // FACTORY FUNCTION
 // ============================================
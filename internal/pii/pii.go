@@ -0,0 +1,250 @@
+// Package pii encrypts personal identifiers (Aadhaar numbers, street addresses)
+// before they reach the database, hashes them for duplicate detection and
+// masks them for API responses.
+//
+// Values are envelope-encrypted: each one gets a fresh AES-256-GCM data key,
+// and the data key is wrapped with a master key from config. Ciphertexts
+// name the master key they were wrapped with, so keys can be rotated by
+// adding a new key, making it active and re-wrapping (see cmd/rekey).
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// version prefixes every ciphertext, so the format can change later.
+const version = "v1"
+
+// ErrNotConfigured is returned when PII is stored without PII_KEYS set.
+var ErrNotConfigured = errors.New("pii encryption keys are not configured")
+
+// ErrUnknownKey is returned for a ciphertext wrapped with a key that is no
+// longer in the keyring.
+var ErrUnknownKey = errors.New("pii: ciphertext uses an unknown key")
+
+var (
+	b64       = base64.RawURLEncoding
+	keyIDName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+)
+
+// Keyring holds the master keys by ID, the active one new values are
+// wrapped with, and the key for keyed hashes.
+type Keyring struct {
+	keys    map[string]cipher.AEAD
+	active  string
+	hashKey []byte
+}
+
+// NewKeyring parses keys ("id:base64key,id2:base64key", each key 32 bytes)
+// and checks that active is one of them. hashKey (base64, at least 32
+// bytes) keys the duplicate-detection hash; it can't be rotated without
+// rehashing (cmd/rekey -rehash).
+func NewKeyring(keys, active, hashKey string) (*Keyring, error) {
+	k := &Keyring{keys: map[string]cipher.AEAD{}, active: active}
+	for _, entry := range strings.Split(keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || !keyIDName.MatchString(id) {
+			return nil, fmt.Errorf("pii: key entries must be id:base64key with a short alphanumeric id")
+		}
+		raw, err := decodeKey(encoded)
+		if err != nil || len(raw) != 32 {
+			return nil, fmt.Errorf("pii: key %s must be 32 bytes, base64-encoded", id)
+		}
+		aead, err := newAEAD(raw)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+	}
+	if len(k.keys) == 0 {
+		return nil, ErrNotConfigured
+	}
+	if _, ok := k.keys[active]; !ok {
+		return nil, fmt.Errorf("pii: active key %q is not in the keyring", active)
+	}
+	raw, err := decodeKey(hashKey)
+	if err != nil || len(raw) < 32 {
+		return nil, fmt.Errorf("pii: the hash key must be at least 32 bytes, base64-encoded")
+	}
+	k.hashKey = raw
+	return k, nil
+}
+
+// decodeKey accepts standard or URL-safe base64, padded or not.
+func decodeKey(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+	return b64.DecodeString(s)
+}
+
+// GenerateKey returns a new random 32-byte key, base64-encoded.
+func GenerateKey() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext with aead, prefixing the random nonce.
+func seal(aead cipher.AEAD, plaintext, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+func open(aead cipher.AEAD, sealed, additional []byte) ([]byte, error) {
+	n := aead.NonceSize()
+	if len(sealed) < n {
+		return nil, fmt.Errorf("pii: ciphertext is too short")
+	}
+	return aead.Open(nil, sealed[:n], sealed[n:], additional)
+}
+
+// ActiveKeyID is the ID of the key new values are wrapped with.
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// Encrypt envelope-encrypts plaintext under the active key. The result is
+// "v1:<key id>:<wrapped data key>:<ciphertext>".
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	body, err := seal(aead, []byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+	return k.wrap(k.active, dataKey, body)
+}
+
+// wrap seals dataKey with the master key id and formats the ciphertext.
+// The key ID is authenticated with the wrapped key.
+func (k *Keyring) wrap(id string, dataKey, body []byte) (string, error) {
+	wrapped, err := seal(k.keys[id], dataKey, []byte(id))
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{version, id, b64.EncodeToString(wrapped), b64.EncodeToString(body)}, ":"), nil
+}
+
+// parse splits a ciphertext and unwraps its data key.
+func (k *Keyring) parse(ciphertext string) (id string, dataKey, body []byte, err error) {
+	parts := strings.Split(ciphertext, ":")
+	if len(parts) != 4 || parts[0] != version {
+		return "", nil, nil, fmt.Errorf("pii: malformed ciphertext")
+	}
+	id = parts[1]
+	master, ok := k.keys[id]
+	if !ok {
+		return "", nil, nil, ErrUnknownKey
+	}
+	wrapped, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("pii: malformed ciphertext")
+	}
+	if body, err = b64.DecodeString(parts[3]); err != nil {
+		return "", nil, nil, fmt.Errorf("pii: malformed ciphertext")
+	}
+	if dataKey, err = open(master, wrapped, []byte(id)); err != nil {
+		return "", nil, nil, fmt.Errorf("pii: data key doesn't authenticate: %w", err)
+	}
+	return id, dataKey, body, nil
+}
+
+// Decrypt returns the plaintext of a value from Encrypt.
+func (k *Keyring) Decrypt(ciphertext string) (string, error) {
+	_, dataKey, body, err := k.parse(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(aead, body, nil)
+	if err != nil {
+		return "", fmt.Errorf("pii: ciphertext doesn't authenticate: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// Rewrap re-wraps a ciphertext's data key with the active key, leaving the
+// encrypted value itself alone. It reports false (and returns the input)
+// when the active key already wraps it.
+func (k *Keyring) Rewrap(ciphertext string) (string, bool, error) {
+	id, dataKey, body, err := k.parse(ciphertext)
+	if err != nil {
+		return "", false, err
+	}
+	if id == k.active {
+		return ciphertext, false, nil
+	}
+	out, err := k.wrap(k.active, dataKey, body)
+	return out, err == nil, err
+}
+
+// Hash returns a keyed hash of value for duplicate detection. Equal values
+// hash equally; without the hash key the hash can't be brute-forced back
+// to a 12-digit number.
+func (k *Keyring) Hash(value string) string {
+	mac := hmac.New(sha256.New, k.hashKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// MaskAadhaar formats the last four digits of an Aadhaar number as
+// XXXX-XXXX-1234. Anything shorter than four digits is fully masked.
+func MaskAadhaar(n string) string {
+	if len(n) < 4 {
+		return "XXXX-XXXX-XXXX"
+	}
+	return "XXXX-XXXX-" + n[len(n)-4:]
+}
+
+// Column is an encrypted column and the keyed-hash column kept beside it,
+// in a table with a uuid id primary key.
+type Column struct {
+	Table     string
+	Cipher    string
+	Hash      string // Empty if the column isn't hashed
+	Partition string // Timestamp partition column that is part of the primary key, if any
+}
+
+// Columns lists the encrypted columns cmd/rekey rotates.
+var Columns = []Column{
+	{Table: "provider_applications", Cipher: "aadhar_number_enc", Hash: "aadhar_number_hash"},
+	{Table: "providers", Cipher: "aadhar_number_enc", Hash: "aadhar_number_hash"},
+	{Table: "user_addresses", Cipher: "street_enc"},
+	{Table: "orders", Cipher: "delivery_street_enc", Partition: "created_at"},
+}
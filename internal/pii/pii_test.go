@@ -0,0 +1,134 @@
+package pii
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(rune(b)), 32)))
+}
+
+func mustKeyring(t *testing.T, keys, active string) *Keyring {
+	t.Helper()
+	k, err := NewKeyring(keys, active, testKey('h'))
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	return k
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	k := mustKeyring(t, "k1:"+testKey('a'), "k1")
+	ct, err := k.Encrypt("234123412346")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if strings.Contains(ct, "234123412346") || !strings.HasPrefix(ct, "v1:k1:") {
+		t.Errorf("ciphertext = %q", ct)
+	}
+	if got, err := k.Decrypt(ct); err != nil || got != "234123412346" {
+		t.Errorf("Decrypt = %q, %v", got, err)
+	}
+
+	again, _ := k.Encrypt("234123412346")
+	if again == ct {
+		t.Error("encrypting twice gave the same ciphertext")
+	}
+
+	parts := strings.Split(ct, ":")
+	body, _ := b64.DecodeString(parts[3])
+	body[len(body)-1] ^= 1
+	parts[3] = b64.EncodeToString(body)
+	if _, err := k.Decrypt(strings.Join(parts, ":")); err == nil {
+		t.Error("tampered ciphertext decrypted")
+	}
+
+	// Relabelling the key ID must fail even if the key exists
+	other := mustKeyring(t, "k1:"+testKey('a')+",k2:"+testKey('a'), "k1")
+	relabelled := strings.Replace(ct, "v1:k1:", "v1:k2:", 1)
+	if _, err := other.Decrypt(relabelled); err == nil {
+		t.Error("ciphertext with a swapped key ID decrypted")
+	}
+
+	if _, err := mustKeyring(t, "k9:"+testKey('z'), "k9").Decrypt(ct); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("unknown key: err = %v", err)
+	}
+	if _, err := k.Decrypt("not-a-ciphertext"); err == nil {
+		t.Error("malformed ciphertext decrypted")
+	}
+}
+
+func TestRewrap(t *testing.T) {
+	old := mustKeyring(t, "k1:"+testKey('a'), "k1")
+	ct, _ := old.Encrypt("999941057058")
+
+	rotated := mustKeyring(t, "k1:"+testKey('a')+",k2:"+testKey('b'), "k2")
+	if got, err := rotated.Decrypt(ct); err != nil || got != "999941057058" {
+		t.Fatalf("Decrypt under an old key = %q, %v", got, err)
+	}
+	out, changed, err := rotated.Rewrap(ct)
+	if err != nil || !changed || !strings.HasPrefix(out, "v1:k2:") {
+		t.Fatalf("Rewrap = %q, %v, %v", out, changed, err)
+	}
+	if strings.Split(out, ":")[3] != strings.Split(ct, ":")[3] {
+		t.Error("Rewrap re-encrypted the value instead of its data key")
+	}
+	if got, err := mustKeyring(t, "k2:"+testKey('b'), "k2").Decrypt(out); err != nil || got != "999941057058" {
+		t.Errorf("Decrypt after dropping the old key = %q, %v", got, err)
+	}
+	if same, changed, err := rotated.Rewrap(out); err != nil || changed || same != out {
+		t.Errorf("Rewrap under the active key = %q, %v, %v", same, changed, err)
+	}
+}
+
+func TestHash(t *testing.T) {
+	a := mustKeyring(t, "k1:"+testKey('a'), "k1")
+	b := mustKeyring(t, "k2:"+testKey('b'), "k2")
+	if a.Hash("234123412346") != b.Hash("234123412346") {
+		t.Error("hash depends on the master key")
+	}
+	if a.Hash("234123412346") == a.Hash("999941057058") {
+		t.Error("different values hash equally")
+	}
+	c, _ := NewKeyring("k1:"+testKey('a'), "k1", testKey('x'))
+	if a.Hash("234123412346") == c.Hash("234123412346") {
+		t.Error("hash ignores the hash key")
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	bad := []struct{ keys, active, hashKey string }{
+		{"", "k1", testKey('h')},
+		{"k1:" + testKey('a'), "k2", testKey('h')},
+		{"k1:c2hvcnQ=", "k1", testKey('h')},
+		{"k1=" + testKey('a'), "k1", testKey('h')},
+		{"k1:" + testKey('a'), "k1", "c2hvcnQ="},
+	}
+	for _, c := range bad {
+		if _, err := NewKeyring(c.keys, c.active, c.hashKey); err == nil {
+			t.Errorf("NewKeyring(%q, %q, %q) succeeded", c.keys, c.active, c.hashKey)
+		}
+	}
+	if _, err := NewKeyring("", "", ""); err != ErrNotConfigured {
+		t.Errorf("no keys: err = %v", err)
+	}
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewKeyring(" k1:"+key+" , k2:"+testKey('b'), "k1", key); err != nil {
+		t.Errorf("generated key rejected: %v", err)
+	}
+}
+
+func TestMaskAadhaar(t *testing.T) {
+	if got := MaskAadhaar("234123412346"); got != "XXXX-XXXX-2346" {
+		t.Errorf("MaskAadhaar = %q", got)
+	}
+	if got := MaskAadhaar("12"); got != "XXXX-XXXX-XXXX" {
+		t.Errorf("MaskAadhaar(short) = %q", got)
+	}
+}
@@ -0,0 +1,259 @@
+package places
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+	"jainfood/internal/promotions"
+)
+
+// CityRadiusMeters is how far from a city's centre a provider counts as
+// being in that city.
+const CityRadiusMeters = 25000
+
+// CityProvider is a provider in the city directory.
+type CityProvider struct {
+	models.Provider
+	District   string  `json:"district"`
+	DistanceKm float64 `json:"distance_km"` // From the city centre
+
+	// Set on providers in the directory's promoted slots
+	PromotionID string `json:"promotion_id,omitempty"`
+	Label       string `json:"label,omitempty"`
+}
+
+// District groups a city's providers by the district of their pin code.
+type District struct {
+	Name      string          `json:"name"`
+	Count     int             `json:"count"`
+	Providers []*CityProvider `json:"providers"`
+}
+
+// CityDirectory is a city's verified providers grouped by district, with
+// the providers in its promoted slots.
+type CityDirectory struct {
+	City      *models.City    `json:"city"`
+	Promoted  []*CityProvider `json:"promoted"`
+	Districts []*District     `json:"districts"`
+}
+
+// categoryCountsSQL counts verified providers within CityRadiusMeters of
+// c.geo by provider_category, as a jsonb object.
+var categoryCountsSQL = fmt.Sprintf(`(SELECT COALESCE(jsonb_object_agg(cat, n), '{}'::jsonb) FROM (
+		SELECT COALESCE(NULLIF(p.provider_category, ''), 'other') AS cat, COUNT(*) AS n
+		FROM providers p WHERE p.verified = TRUE AND COALESCE(p.blocked, FALSE) = FALSE AND ST_DWithin(p.geo, c.geo, %d)
+		GROUP BY 1) counts)`, CityRadiusMeters)
+
+// ListCities returns the city directory, busiest first. state filters by
+// state name when set.
+func ListCities(ctx context.Context, state string) ([]*models.City, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT c.slug, c.name, COALESCE(c.name_hi, ''), c.state,
+		       ST_Y(c.geo::geometry), ST_X(c.geo::geometry), `+categoryCountsSQL+`
+		FROM cities c
+		WHERE $1 = '' OR lower(c.state) = lower($1)
+	`, state)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*models.City{}
+	for rows.Next() {
+		city, err := scanCity(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, city)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sortCities(list)
+	return list, nil
+}
+
+// GetCity returns a city by slug, or db.ErrNotFound.
+func GetCity(ctx context.Context, slug string) (*models.City, error) {
+	city, err := scanCity(db.Pool.QueryRow(ctx, `
+		SELECT c.slug, c.name, COALESCE(c.name_hi, ''), c.state,
+		       ST_Y(c.geo::geometry), ST_X(c.geo::geometry), `+categoryCountsSQL+`
+		FROM cities c WHERE c.slug = $1
+	`, slug))
+	if err == pgx.ErrNoRows {
+		return nil, db.ErrNotFound
+	}
+	return city, err
+}
+
+// sortCities orders cities by provider count, then name.
+func sortCities(list []*models.City) {
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].ProviderCount != list[j].ProviderCount {
+			return list[i].ProviderCount > list[j].ProviderCount
+		}
+		return list[i].Name < list[j].Name
+	})
+}
+
+func scanCity(row pgx.Row) (*models.City, error) {
+	c := &models.City{}
+	if err := row.Scan(&c.Slug, &c.Name, &c.NameHi, &c.State, &c.Lat, &c.Lng, &c.Categories); err != nil {
+		return nil, err
+	}
+	for _, n := range c.Categories {
+		c.ProviderCount += n
+	}
+	return c, nil
+}
+
+// cityProviderColumns selects the provider columns of the directory; the
+// query adds the district and the distance in km.
+const cityProviderColumns = `p.id, p.user_id, p.business_name, p.address, COALESCE(p.pin_code, ''),
+		       ST_Y(p.geo::geometry), ST_X(p.geo::geometry),
+		       p.verified, COALESCE(p.tags, '{}'), COALESCE(p.rating, 0), COALESCE(p.total_ratings, 0),
+		       COALESCE(p.provider_category, ''), COALESCE(p.food_categories, '{}'), p.created_at`
+
+func scanCityProvider(rows pgx.Rows, extra ...interface{}) (*CityProvider, error) {
+	cp := &CityProvider{}
+	p := &cp.Provider
+	dest := []interface{}{
+		&p.ID, &p.UserID, &p.BusinessName, &p.Address, &p.PinCode, &p.Lat, &p.Lng,
+		&p.Verified, &p.Tags, &p.Rating, &p.TotalRatings, &p.ProviderCategory, &p.FoodCategories, &p.CreatedAt,
+		&cp.District, &cp.DistanceKm,
+	}
+	err := rows.Scan(append(dest, extra...)...)
+	return cp, err
+}
+
+// CityProviders returns a city's verified providers grouped by district,
+// optionally of one provider_category, nearest the centre first within
+// each district.
+func CityProviders(ctx context.Context, slug, category string) (*CityDirectory, error) {
+	city, err := GetCity(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT `+cityProviderColumns+`,
+		       COALESCE(pc.district, c.name), ST_Distance(p.geo, c.geo) / 1000
+		FROM cities c
+		JOIN providers p ON ST_DWithin(p.geo, c.geo, $2)
+		LEFT JOIN pin_codes pc ON pc.pin = p.pin_code
+		WHERE c.slug = $1 AND p.verified = TRUE AND COALESCE(p.blocked, FALSE) = FALSE
+		  AND ($3 = '' OR p.provider_category = $3)
+		ORDER BY 15, 16
+	`, slug, float64(CityRadiusMeters), category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dir := &CityDirectory{City: city, Promoted: []*CityProvider{}, Districts: []*District{}}
+	byName := map[string]*District{}
+	for rows.Next() {
+		cp, err := scanCityProvider(rows)
+		if err != nil {
+			return nil, err
+		}
+		d, ok := byName[cp.District]
+		if !ok {
+			d = &District{Name: cp.District}
+			byName[cp.District] = d
+			dir.Districts = append(dir.Districts, d)
+		}
+		d.Count++
+		d.Providers = append(d.Providers, cp)
+	}
+	return dir, rows.Err()
+}
+
+// ProvidersAlong returns verified providers within corridorMeters of the
+// straight line between two cities, leaving out those in either city. The
+// distance is from the first city.
+func ProvidersAlong(ctx context.Context, fromSlug, toSlug string, corridorMeters float64, category string) ([]*CityProvider, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT `+cityProviderColumns+`,
+		       COALESCE(pc.district, ''), ST_Distance(p.geo, a.geo) / 1000
+		FROM cities a
+		JOIN cities b ON b.slug = $2
+		JOIN providers p ON ST_DWithin(p.geo, ST_MakeLine(a.geo::geometry, b.geo::geometry)::geography, $3)
+		LEFT JOIN pin_codes pc ON pc.pin = p.pin_code
+		WHERE a.slug = $1 AND p.verified = TRUE AND COALESCE(p.blocked, FALSE) = FALSE
+		  AND NOT ST_DWithin(p.geo, a.geo, $4) AND NOT ST_DWithin(p.geo, b.geo, $4)
+		  AND ($5 = '' OR p.provider_category = $5)
+		ORDER BY 16
+	`, fromSlug, toSlug, corridorMeters, float64(CityRadiusMeters), category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*CityProvider{}
+	for rows.Next() {
+		cp, err := scanCityProvider(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, cp)
+	}
+	return list, rows.Err()
+}
+
+// PromotedInCity fills up to slots promoted slots of a city's directory,
+// optionally of one provider_category, with providers whose promotion for
+// the city is running and under its cap for day (a local day as a UTC
+// midnight). The least delivered promotions come first. Callers record the
+// impressions.
+func PromotedInCity(ctx context.Context, slug, category string, day time.Time, slots int) ([]*CityProvider, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT `+cityProviderColumns+`,
+		       COALESCE(pc.district, c.name), ST_Distance(p.geo, c.geo) / 1000, promo.id
+		FROM cities c
+		JOIN providers p ON ST_DWithin(p.geo, c.geo, $2)
+		JOIN LATERAL `+promotions.PickSQL("p.id", "pr.city_slug = c.slug", "$3", "$4")+` ON TRUE
+		LEFT JOIN pin_codes pc ON pc.pin = p.pin_code
+		WHERE c.slug = $1 AND p.verified = TRUE AND COALESCE(p.blocked, FALSE) = FALSE
+		  AND ($3 = '' OR p.provider_category = $3)
+		ORDER BY promo.delivered, random()
+		LIMIT $5
+	`, slug, float64(CityRadiusMeters), category, day, slots)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*CityProvider{}
+	for rows.Next() {
+		var promotionID string
+		cp, err := scanCityProvider(rows, &promotionID)
+		if err != nil {
+			return nil, err
+		}
+		cp.PromotionID, cp.Label = promotionID, promotions.Label
+		list = append(list, cp)
+	}
+	return list, rows.Err()
+}
+
+// ProviderInCity reports whether a provider is within CityRadiusMeters of a
+// city's centre. It returns db.ErrNotFound if the city or provider doesn't
+// exist.
+func ProviderInCity(ctx context.Context, providerID, slug string) (bool, error) {
+	var in bool
+	err := db.Pool.QueryRow(ctx, `
+		SELECT ST_DWithin(p.geo, c.geo, $3)
+		FROM providers p, cities c
+		WHERE p.id = $1 AND c.slug = $2
+	`, providerID, slug, float64(CityRadiusMeters)).Scan(&in)
+	if err == pgx.ErrNoRows {
+		return false, db.ErrNotFound
+	}
+	return in, err
+}
@@ -0,0 +1,125 @@
+// Package places resolves pin codes and cities to coordinates using the
+// offline pin_codes and cities tables, so callers can search by place
+// instead of raw lat/lng.
+package places
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+	"jainfood/internal/translit"
+)
+
+// NearestPinMeters bounds NearestPin: further than this, the pin would be
+// a guess.
+const NearestPinMeters = 5000
+
+// ValidPin reports whether s looks like an Indian pin code: six digits, not
+// starting with 0.
+func ValidPin(s string) bool {
+	if len(s) != 6 || s[0] == '0' {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// LookupPin returns the dataset entry for a pin code, or db.ErrNotFound.
+func LookupPin(ctx context.Context, pin string) (*models.PinCode, error) {
+	pc := &models.PinCode{}
+	err := db.Pool.QueryRow(ctx, `
+		SELECT pin, COALESCE(city_slug, ''), city, district, state,
+		       ST_Y(geo::geometry), ST_X(geo::geometry)
+		FROM pin_codes WHERE pin = $1
+	`, strings.TrimSpace(pin)).Scan(&pc.Pin, &pc.CitySlug, &pc.City, &pc.District, &pc.State, &pc.Lat, &pc.Lng)
+	if err == pgx.ErrNoRows {
+		return nil, db.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+// NearestPin returns the closest known pin code within NearestPinMeters of
+// lat/lng, or db.ErrNotFound.
+func NearestPin(ctx context.Context, lat, lng float64) (*models.PinCode, error) {
+	pc := &models.PinCode{}
+	err := db.Pool.QueryRow(ctx, `
+		SELECT pin, COALESCE(city_slug, ''), city, district, state,
+		       ST_Y(geo::geometry), ST_X(geo::geometry)
+		FROM pin_codes
+		WHERE ST_DWithin(geo, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, $3)
+		ORDER BY geo <-> ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography
+		LIMIT 1
+	`, lng, lat, float64(NearestPinMeters)).Scan(&pc.Pin, &pc.CitySlug, &pc.City, &pc.District, &pc.State, &pc.Lat, &pc.Lng)
+	if err == pgx.ErrNoRows {
+		return nil, db.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+// NearestPinSQL is a scalar subquery for the nearest known pin code within
+// NearestPinMeters of point (a geography expression), or NULL.
+func NearestPinSQL(point string) string {
+	return fmt.Sprintf(`(SELECT pc.pin FROM pin_codes pc WHERE ST_DWithin(pc.geo, %[1]s, %[2]d)
+		ORDER BY pc.geo <-> %[1]s LIMIT 1)`, point, NearestPinMeters)
+}
+
+// CityCentre returns the centre of a city given its slug or name, in
+// English or Hindi, or db.ErrNotFound.
+func CityCentre(ctx context.Context, city string) (lat, lng float64, err error) {
+	key := translit.Key(city)
+	err = db.Pool.QueryRow(ctx, `
+		SELECT ST_Y(geo::geometry), ST_X(geo::geometry)
+		FROM cities
+		WHERE slug = lower($1) OR $2 = ANY(string_to_array(search_key, ' '))
+		ORDER BY slug = lower($1) DESC
+		LIMIT 1
+	`, strings.TrimSpace(city), key).Scan(&lat, &lng)
+	if err == pgx.ErrNoRows {
+		return 0, 0, db.ErrNotFound
+	}
+	return lat, lng, err
+}
+
+// ErrUnknownPin is returned by FillAddress for a pin code that isn't in the
+// dataset when the address has no coordinates or city to fall back on.
+var ErrUnknownPin = errors.New("unknown pin code; send lat, lng, city and state")
+
+// FillAddress validates an address's pin code and fills the city, district,
+// state and (when missing) coordinates from the dataset. Pins missing from
+// the dataset are accepted with the caller's own coordinates, city and state.
+func FillAddress(ctx context.Context, a *models.Address) error {
+	a.PinCode = strings.TrimSpace(a.PinCode)
+	if !ValidPin(a.PinCode) {
+		return fmt.Errorf("pin_code must be 6 digits")
+	}
+	pc, err := LookupPin(ctx, a.PinCode)
+	if err == db.ErrNotFound {
+		if (a.Lat == 0 && a.Lng == 0) || a.City == "" || a.State == "" {
+			return ErrUnknownPin
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	a.City, a.District, a.State = pc.City, pc.District, pc.State
+	if a.Lat == 0 && a.Lng == 0 {
+		a.Lat, a.Lng = pc.Lat, pc.Lng
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package places
+
+import (
+	"strings"
+	"testing"
+
+	"jainfood/internal/models"
+)
+
+func TestValidPin(t *testing.T) {
+	for pin, want := range map[string]bool{
+		"380001":  true,
+		"110092":  true,
+		"012345":  false, // No pin zone 0
+		"38000":   false,
+		"3800011": false,
+		"38000a":  false,
+		"":        false,
+	} {
+		if got := ValidPin(pin); got != want {
+			t.Errorf("ValidPin(%q) = %v, want %v", pin, got, want)
+		}
+	}
+}
+
+func TestNearestPinSQL(t *testing.T) {
+	sql := NearestPinSQL("$pt")
+	for _, part := range []string{"ST_DWithin(pc.geo, $pt, 5000)", "ORDER BY pc.geo <-> $pt LIMIT 1"} {
+		if !strings.Contains(sql, part) {
+			t.Errorf("missing %q in %s", part, sql)
+		}
+	}
+}
+
+func TestSortCities(t *testing.T) {
+	list := []*models.City{
+		{Name: "Surat", ProviderCount: 3},
+		{Name: "Ahmedabad", ProviderCount: 12},
+		{Name: "Ajmer", ProviderCount: 3},
+	}
+	sortCities(list)
+	if list[0].Name != "Ahmedabad" || list[1].Name != "Ajmer" || list[2].Name != "Surat" {
+		t.Errorf("sortCities order = %s, %s, %s", list[0].Name, list[1].Name, list[2].Name)
+	}
+}
@@ -0,0 +1,40 @@
+package promotions
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Expirer switches promotions off when they end or go unpaid.
+type Expirer struct {
+	Interval time.Duration
+	logger   *zap.Logger
+}
+
+// NewExpirer creates an expirer that runs every interval.
+func NewExpirer(interval time.Duration, logger *zap.Logger) *Expirer {
+	return &Expirer{Interval: interval, logger: logger}
+}
+
+// Run expires promotions straight away and then every Interval until ctx
+// is cancelled.
+func (e *Expirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.Interval)
+	defer ticker.Stop()
+
+	for {
+		expired, unpaid, err := Expire(ctx)
+		if err != nil && ctx.Err() == nil {
+			e.logger.Error("promotion expiry failed", zap.Error(err))
+		} else if expired+unpaid > 0 {
+			e.logger.Info("promotions expired", zap.Int("ended", expired), zap.Int("unpaid", unpaid))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Package promotions sells time-boxed promoted slots to providers. A
+// promotion runs in one city, optionally for one provider category, and is
+// shown in labelled slots above search and city feed results until it ends
+// or reaches its daily impression cap. Impressions and clicks are counted
+// per local day for billing.
+package promotions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Label marks results shown in a promoted slot.
+const Label = "Sponsored"
+
+// Limits on promotions.
+const (
+	MaxDailyCap = 100000
+	// PaymentWindow is how long an unpaid promotion holds its slot.
+	PaymentWindow = 30 * time.Minute
+	// startGrace lets a promotion start a moment in the past, so "now"
+	// from a client's clock is accepted.
+	startGrace = 5 * time.Minute
+)
+
+var (
+	// ErrNoSlot is returned when every slot for the city and category is
+	// booked for part of the requested window.
+	ErrNoSlot = errors.New("no promoted slot is free in this city and category for these dates")
+	// ErrOverlap is returned when the provider already has a promotion in
+	// the same slot for part of the window.
+	ErrOverlap = errors.New("provider already has a promotion here for these dates")
+	// ErrNotPending is returned when paying for a promotion that is no
+	// longer awaiting payment, or with another payment order.
+	ErrNotPending = errors.New("promotion is not awaiting this payment")
+	// ErrEnded is returned when cancelling a promotion that has already
+	// ended or been cancelled.
+	ErrEnded = errors.New("promotion has already ended or been cancelled")
+)
+
+// Request is a promotion to book.
+type Request struct {
+	ProviderID       string
+	CitySlug         string
+	ProviderCategory string // Empty for every category
+	StartsAt         time.Time
+	EndsAt           time.Time
+	DailyCap         int
+}
+
+// Validate checks the window and cap against now. Promotions may start
+// slightly in the past and run for at most maxDays.
+func (r *Request) Validate(now time.Time, maxDays int) error {
+	r.CitySlug = strings.TrimSpace(r.CitySlug)
+	r.ProviderCategory = strings.TrimSpace(r.ProviderCategory)
+	switch {
+	case r.CitySlug == "":
+		return fmt.Errorf("city is required")
+	case r.StartsAt.IsZero() || r.EndsAt.IsZero():
+		return fmt.Errorf("starts_at and ends_at are required")
+	case r.StartsAt.Before(now.Add(-startGrace)):
+		return fmt.Errorf("starts_at must not be in the past")
+	case !r.EndsAt.After(r.StartsAt):
+		return fmt.Errorf("ends_at must be after starts_at")
+	case r.EndsAt.Sub(r.StartsAt) > time.Duration(maxDays)*24*time.Hour:
+		return fmt.Errorf("promotions can run for at most %d days", maxDays)
+	case r.DailyCap < 1 || r.DailyCap > MaxDailyCap:
+		return fmt.Errorf("daily_cap must be between 1 and %d", MaxDailyCap)
+	}
+	return nil
+}
+
+// Days returns how many days the promotion is billed for: its length in
+// 24-hour days, rounded up.
+func (r Request) Days() int {
+	d := r.EndsAt.Sub(r.StartsAt)
+	days := int(d / (24 * time.Hour))
+	if d%(24*time.Hour) > 0 {
+		days++
+	}
+	return days
+}
+
+// Price returns what the promotion costs in paise: its capped impressions
+// at cpmPaise per thousand, rounded up to the rupee.
+func (r Request) Price(cpmPaise int64) int64 {
+	paise := (int64(r.Days())*int64(r.DailyCap)*cpmPaise + 999) / 1000
+	return (paise + 99) / 100 * 100
+}
+
+// Today returns the local day of now in loc as a UTC midnight, the day
+// impressions and clicks are counted against.
+func Today(now time.Time, loc *time.Location) time.Time {
+	y, m, d := now.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// CTR returns clicks per impression, to four places.
+func CTR(clicks, impressions int) float64 {
+	if impressions == 0 {
+		return 0
+	}
+	return float64(clicks*10000/impressions) / 10000
+}
+
+// RunningSQL returns a condition true when provider (an SQL expression for
+// a provider id) has a promotion running now. It stands in for the legacy
+// providers.is_promoted column, which promotions don't maintain.
+func RunningSQL(provider string) string {
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM promotions pr
+		WHERE pr.provider_id = %s AND pr.status = 'active'
+		  AND pr.starts_at <= now() AND pr.ends_at > now()
+	)`, provider)
+}
+
+// PickSQL returns a LATERAL subquery picking provider's servable promotion
+// for a feed: active, running now, under today's cap, in a city matching
+// cityCond (on pr.city_slug) and for every category or category. It yields
+// promo.id and promo.delivered, the share of today's cap already shown, so
+// callers can serve the least delivered promotions first. day and category
+// are SQL parameters.
+func PickSQL(provider, cityCond, category, day string) string {
+	return fmt.Sprintf(`(
+		SELECT pr.id, COALESCE(s.impressions, 0)::float8 / pr.daily_cap AS delivered
+		FROM promotions pr
+		LEFT JOIN promotion_daily_stats s ON s.promotion_id = pr.id AND s.day = %[4]s::date
+		WHERE pr.provider_id = %[1]s AND pr.status = 'active'
+		  AND pr.starts_at <= now() AND pr.ends_at > now()
+		  AND COALESCE(s.impressions, 0) < pr.daily_cap
+		  AND %[2]s AND COALESCE(pr.provider_category, '') IN ('', %[3]s)
+		ORDER BY 2, pr.id
+		LIMIT 1
+	) promo`, provider, cityCond, category, day)
+}
@@ -0,0 +1,81 @@
+package promotions
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	ok := func() Request {
+		return Request{CitySlug: " ahmedabad ", StartsAt: now, EndsAt: now.Add(7 * 24 * time.Hour), DailyCap: 500}
+	}
+
+	r := ok()
+	if err := r.Validate(now, 90); err != nil || r.CitySlug != "ahmedabad" {
+		t.Fatalf("Validate = %v, city %q", err, r.CitySlug)
+	}
+	r = ok()
+	r.StartsAt = now.Add(-time.Minute)
+	if err := r.Validate(now, 90); err != nil {
+		t.Errorf("start a minute ago: %v", err)
+	}
+
+	for name, change := range map[string]func(*Request){
+		"no city":       func(r *Request) { r.CitySlug = " " },
+		"no end":        func(r *Request) { r.EndsAt = time.Time{} },
+		"past start":    func(r *Request) { r.StartsAt = now.Add(-time.Hour) },
+		"end first":     func(r *Request) { r.EndsAt = r.StartsAt },
+		"too long":      func(r *Request) { r.EndsAt = r.StartsAt.Add(91 * 24 * time.Hour) },
+		"no cap":        func(r *Request) { r.DailyCap = 0 },
+		"cap too large": func(r *Request) { r.DailyCap = MaxDailyCap + 1 },
+	} {
+		r := ok()
+		change(&r)
+		if err := r.Validate(now, 90); err == nil {
+			t.Errorf("%s: Validate succeeded", name)
+		}
+	}
+}
+
+func TestPrice(t *testing.T) {
+	start := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		length time.Duration
+		cap    int
+		days   int
+		paise  int64
+	}{
+		{7 * 24 * time.Hour, 1000, 7, 35000},  // 7000 impressions at ₹50 CPM
+		{25 * time.Hour, 1000, 2, 10000},      // Part days count as whole days
+		{time.Hour, 10, 1, 100},               // 50 paise, rounded up to the rupee
+		{30 * 24 * time.Hour, 333, 30, 50000}, // 9990 impressions = ₹499.50
+	}
+	for _, c := range cases {
+		r := Request{StartsAt: start, EndsAt: start.Add(c.length), DailyCap: c.cap}
+		if got := r.Days(); got != c.days {
+			t.Errorf("Days(%v) = %d, want %d", c.length, got, c.days)
+		}
+		if got := r.Price(5000); got != c.paise {
+			t.Errorf("Price(%v, cap %d) = %d, want %d", c.length, c.cap, got, c.paise)
+		}
+	}
+}
+
+func TestToday(t *testing.T) {
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	// 20:00 UTC on 9 March is already 10 March in India
+	got := Today(time.Date(2026, 3, 9, 20, 0, 0, 0, time.UTC), ist)
+	if want := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Today = %v, want %v", got, want)
+	}
+}
+
+func TestCTR(t *testing.T) {
+	if got := CTR(3, 0); got != 0 {
+		t.Errorf("CTR with no impressions = %v", got)
+	}
+	if got := CTR(1, 3); got != 0.3333 {
+		t.Errorf("CTR(1, 3) = %v", got)
+	}
+}
@@ -0,0 +1,360 @@
+package promotions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+)
+
+// promotionColumns selects a promotion with its provider's name and
+// all-time impressions and clicks.
+const promotionColumns = `pr.id, pr.provider_id, COALESCE(p.business_name, ''), pr.city_slug,
+		       COALESCE(pr.provider_category, ''), pr.starts_at, pr.ends_at, pr.daily_cap,
+		       pr.status, pr.source, pr.amount_paise, COALESCE(pr.payment_order_id, ''),
+		       COALESCE(pr.payment_id, ''), COALESCE(pr.created_by::text, ''),
+		       COALESCE(t.impressions, 0), COALESCE(t.clicks, 0), pr.created_at, pr.updated_at
+		FROM promotions pr
+		LEFT JOIN providers p ON p.id = pr.provider_id
+		LEFT JOIN LATERAL (
+			SELECT SUM(impressions)::int AS impressions, SUM(clicks)::int AS clicks
+			FROM promotion_daily_stats WHERE promotion_id = pr.id
+		) t ON TRUE`
+
+func scanPromotion(row pgx.Row) (*models.Promotion, error) {
+	pr := &models.Promotion{}
+	err := row.Scan(
+		&pr.ID, &pr.ProviderID, &pr.BusinessName, &pr.CitySlug,
+		&pr.ProviderCategory, &pr.StartsAt, &pr.EndsAt, &pr.DailyCap,
+		&pr.Status, &pr.Source, &pr.AmountPaise, &pr.PaymentOrderID,
+		&pr.PaymentID, &pr.CreatedBy,
+		&pr.Impressions, &pr.Clicks, &pr.CreatedAt, &pr.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, db.ErrNotFound
+	}
+	return pr, err
+}
+
+// Create books a promotion if the city and category have a slot free for
+// its whole window: fewer than slots active (or recently created unpaid)
+// promotions overlap it, none of them the provider's. Admin promotions start
+// active; paid ones wait for payment. Callers check the provider is in the
+// city first.
+func Create(ctx context.Context, r Request, source string, amountPaise int64, createdBy string, slots int) (*models.Promotion, error) {
+	status := models.PromotionActive
+	if source == models.PromotionSourcePayment {
+		status = models.PromotionPendingPayment
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockSlot(ctx, tx, r.CitySlug, r.ProviderCategory); err != nil {
+		return nil, err
+	}
+	booked, own, err := bookedSlots(ctx, tx, r, "")
+	if err != nil {
+		return nil, err
+	}
+	if own > 0 {
+		return nil, ErrOverlap
+	}
+	if booked >= slots {
+		return nil, ErrNoSlot
+	}
+
+	var id string
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO promotions (provider_id, city_slug, provider_category, starts_at, ends_at, daily_cap,
+		                        status, source, amount_paise, created_by)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9, NULLIF($10, '')::uuid)
+		RETURNING id
+	`, r.ProviderID, r.CitySlug, r.ProviderCategory, r.StartsAt, r.EndsAt, r.DailyCap,
+		status, source, amountPaise, createdBy).Scan(&id); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return Get(ctx, id)
+}
+
+// lockSlot serialises bookings and payments of a city and category's slots
+// until tx ends, so two can't both take the last one.
+func lockSlot(ctx context.Context, tx pgx.Tx, city, category string) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('promotion:' || $1::text || ':' || $2::text))`, city, category)
+	return err
+}
+
+// bookedSlots counts the promotions other than exclude holding a slot in r's
+// city and category for part of its window (active, or created unpaid within
+// PaymentWindow), and how many of them are r's provider's.
+func bookedSlots(ctx context.Context, tx pgx.Tx, r Request, exclude string) (booked, own int, err error) {
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE provider_id = $5)
+		FROM promotions
+		WHERE city_slug = $1 AND COALESCE(provider_category, '') = $2
+		  AND starts_at < $4 AND ends_at > $3
+		  AND (status = 'active' OR (status = 'pending_payment' AND created_at > $6))
+		  AND id::text <> $7
+	`, r.CitySlug, r.ProviderCategory, r.StartsAt, r.EndsAt, r.ProviderID, time.Now().Add(-PaymentWindow), exclude).Scan(&booked, &own)
+	return booked, own, err
+}
+
+// Get returns a promotion, or db.ErrNotFound.
+func Get(ctx context.Context, id string) (*models.Promotion, error) {
+	return scanPromotion(db.Pool.QueryRow(ctx, `SELECT `+promotionColumns+` WHERE pr.id = $1`, id))
+}
+
+// SetPaymentOrder records the payment order a provider pays an unpaid
+// promotion with.
+func SetPaymentOrder(ctx context.Context, id, orderID string) error {
+	tag, err := db.Pool.Exec(ctx, `
+		UPDATE promotions SET payment_order_id = $2, updated_at = now()
+		WHERE id = $1 AND status = 'pending_payment'
+	`, id, orderID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotPending
+	}
+	return nil
+}
+
+// Activate starts an unpaid promotion once its payment order is paid. Under
+// the same lock as Create it checks the promotion still holds its slot: it
+// was created within PaymentWindow (ErrNotPending otherwise) and fewer than
+// slots other promotions overlap it (ErrNoSlot otherwise).
+func Activate(ctx context.Context, id, orderID, paymentID string, slots int) (*models.Promotion, error) {
+	pr, err := Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockSlot(ctx, tx, pr.CitySlug, pr.ProviderCategory); err != nil {
+		return nil, err
+	}
+	r := Request{
+		ProviderID:       pr.ProviderID,
+		CitySlug:         pr.CitySlug,
+		ProviderCategory: pr.ProviderCategory,
+		StartsAt:         pr.StartsAt,
+		EndsAt:           pr.EndsAt,
+	}
+	booked, _, err := bookedSlots(ctx, tx, r, id)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := tx.Exec(ctx, `
+		UPDATE promotions SET status = 'active', payment_id = $3, updated_at = now()
+		WHERE id = $1 AND status = 'pending_payment' AND payment_order_id = $2 AND created_at > $4
+	`, id, orderID, paymentID, time.Now().Add(-PaymentWindow))
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrNotPending
+	}
+	if booked >= slots {
+		// Rolls the activation back; the unpaid promotion lapses
+		return nil, ErrNoSlot
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return Get(ctx, id)
+}
+
+// Cancel stops a promotion that is active or awaiting payment.
+func Cancel(ctx context.Context, id string) (*models.Promotion, error) {
+	tag, err := db.Pool.Exec(ctx, `
+		UPDATE promotions SET status = 'cancelled', updated_at = now()
+		WHERE id = $1 AND status IN ('active', 'pending_payment')
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := Get(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrEnded
+	}
+	return Get(ctx, id)
+}
+
+// Filter narrows a promotion listing. Empty fields match everything.
+type Filter struct {
+	ProviderID string
+	Status     string
+	CitySlug   string
+	Limit      int
+	Offset     int
+}
+
+// List returns the promotions matching f, newest first, with the total.
+func List(ctx context.Context, f Filter) ([]*models.Promotion, int, error) {
+	if f.Limit <= 0 || f.Limit > 100 {
+		f.Limit = 50
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	where := " WHERE TRUE"
+	args := []interface{}{}
+	argIdx := 1
+	if f.ProviderID != "" {
+		where += fmt.Sprintf(" AND pr.provider_id = $%d", argIdx)
+		args = append(args, f.ProviderID)
+		argIdx++
+	}
+	if f.Status != "" {
+		where += fmt.Sprintf(" AND pr.status = $%d", argIdx)
+		args = append(args, f.Status)
+		argIdx++
+	}
+	if f.CitySlug != "" {
+		where += fmt.Sprintf(" AND pr.city_slug = $%d", argIdx)
+		args = append(args, f.CitySlug)
+		argIdx++
+	}
+
+	var total int
+	if err := db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM promotions pr`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + promotionColumns + where +
+		fmt.Sprintf(" ORDER BY pr.created_at DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	rows, err := db.Pool.Query(ctx, query, append(args, f.Limit, f.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []*models.Promotion{}
+	for rows.Next() {
+		pr, err := scanPromotion(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, pr)
+	}
+	return list, total, rows.Err()
+}
+
+// Stats returns a promotion with its impressions and clicks per day.
+func Stats(ctx context.Context, id string) (*models.PromotionStats, error) {
+	pr, err := Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Pool.Query(ctx, `
+		SELECT day, impressions, clicks FROM promotion_daily_stats
+		WHERE promotion_id = $1 ORDER BY day
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	st := &models.PromotionStats{Promotion: pr, Days: []models.PromotionDay{}, CTR: CTR(pr.Clicks, pr.Impressions)}
+	for rows.Next() {
+		var day time.Time
+		var d models.PromotionDay
+		if err := rows.Scan(&day, &d.Impressions, &d.Clicks); err != nil {
+			return nil, err
+		}
+		d.Day = day.Format(time.DateOnly)
+		d.CTR = CTR(d.Clicks, d.Impressions)
+		st.Days = append(st.Days, d)
+	}
+	return st, rows.Err()
+}
+
+// RecordImpressions counts one impression on day for each promotion about
+// to be shown that is still under its daily cap, and returns those: only
+// they may be served. Concurrent requests can't take a promotion past its
+// cap, as each increment checks the cap on the locked row.
+func RecordImpressions(ctx context.Context, day time.Time, ids []string) (map[string]bool, error) {
+	served := map[string]bool{}
+	if len(ids) == 0 {
+		return served, nil
+	}
+	rows, err := db.Pool.Query(ctx, `
+		INSERT INTO promotion_daily_stats AS s (promotion_id, day, impressions)
+		SELECT id, $2, 1 FROM unnest($1::uuid[]) AS id
+		ON CONFLICT (promotion_id, day) DO UPDATE
+		SET impressions = s.impressions + 1
+		WHERE s.impressions < (SELECT daily_cap FROM promotions WHERE id = s.promotion_id)
+		RETURNING promotion_id::text
+	`, ids, day)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		served[id] = true
+	}
+	return served, rows.Err()
+}
+
+// RecordClick counts a click on day for a running promotion, or returns
+// db.ErrNotFound if it isn't running.
+func RecordClick(ctx context.Context, day time.Time, id string) error {
+	tag, err := db.Pool.Exec(ctx, `
+		INSERT INTO promotion_daily_stats (promotion_id, day, clicks)
+		SELECT id, $2, 1 FROM promotions
+		WHERE id = $1 AND status = 'active' AND starts_at <= now() AND ends_at > now()
+		ON CONFLICT (promotion_id, day) DO UPDATE
+		SET clicks = promotion_daily_stats.clicks + 1
+	`, id, day)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return db.ErrNotFound
+	}
+	return nil
+}
+
+// Expire ends active promotions past their end and cancels promotions left
+// unpaid for longer than PaymentWindow.
+func Expire(ctx context.Context) (expired, unpaid int, err error) {
+	tag, err := db.Pool.Exec(ctx, `
+		UPDATE promotions SET status = 'expired', updated_at = now()
+		WHERE status = 'active' AND ends_at <= now()
+	`)
+	if err != nil {
+		return 0, 0, err
+	}
+	expired = int(tag.RowsAffected())
+
+	tag, err = db.Pool.Exec(ctx, `
+		UPDATE promotions SET status = 'cancelled', updated_at = now()
+		WHERE status = 'pending_payment' AND created_at <= $1
+	`, time.Now().Add(-PaymentWindow))
+	if err != nil {
+		return expired, 0, err
+	}
+	return expired, int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,79 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+	"jainfood/internal/translit"
+)
+
+// Filter narrows the admin provider search. Zero fields don't filter.
+type Filter struct {
+	Query    string // Business name (transliterated) or owner phone
+	Verified *bool
+	Blocked  *bool
+	Category string // Provider category or a food category
+	City     string // City slug or name
+	Limit    int
+	Offset   int
+}
+
+// SearchProviders finds providers matching f for the admin console, newest
+// first, and returns the page with the total number of matches.
+func SearchProviders(ctx context.Context, f Filter) ([]*models.Provider, int, error) {
+	where := " WHERE TRUE"
+	args := []interface{}{}
+	argIdx := 1
+
+	if f.Query != "" {
+		where += fmt.Sprintf(` AND (search_key LIKE '%%' || $%d || '%%'
+			OR user_id IN (SELECT id FROM users WHERE phone LIKE '%%' || $%d || '%%'))`, argIdx, argIdx+1)
+		args = append(args, translit.Key(f.Query), f.Query)
+		argIdx += 2
+	}
+	if f.Verified != nil {
+		where += fmt.Sprintf(" AND COALESCE(verified, FALSE) = $%d", argIdx)
+		args = append(args, *f.Verified)
+		argIdx++
+	}
+	if f.Blocked != nil {
+		where += fmt.Sprintf(" AND COALESCE(blocked, FALSE) = $%d", argIdx)
+		args = append(args, *f.Blocked)
+		argIdx++
+	}
+	if f.Category != "" {
+		where += fmt.Sprintf(" AND (provider_category = $%d OR $%d = ANY(food_categories))", argIdx, argIdx)
+		args = append(args, f.Category)
+		argIdx++
+	}
+	if f.City != "" {
+		where += fmt.Sprintf(" AND pin_code IN (SELECT pin FROM pin_codes WHERE city_slug = $%d OR city ILIKE $%d)", argIdx, argIdx)
+		args = append(args, f.City)
+		argIdx++
+	}
+
+	var total int
+	if err := db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM providers`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Pool.Query(ctx, `SELECT `+providerColumns+` FROM providers`+where+
+		fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1),
+		append(args, f.Limit, f.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []*models.Provider{}
+	for rows.Next() {
+		p, err := scanProvider(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, p)
+	}
+	return list, total, rows.Err()
+}
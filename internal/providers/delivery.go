@@ -0,0 +1,128 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"jainfood/internal/db"
+	"jainfood/internal/delivery"
+	"jainfood/internal/models"
+)
+
+// GetDeliveryZone retrieves a provider's delivery zone, or nil when it has
+// none.
+func GetDeliveryZone(ctx context.Context, providerID string) (*models.DeliveryZone, error) {
+	z := &models.DeliveryZone{ProviderID: providerID}
+	var radius *float64
+	var area *string
+	var slabs []byte
+	err := db.Pool.QueryRow(ctx, `
+		SELECT radius_km::float8, ST_AsGeoJSON(area::geometry), fee_slabs
+		FROM provider_delivery_zones WHERE provider_id = $1
+	`, providerID).Scan(&radius, &area, &slabs)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if radius != nil {
+		z.RadiusKm = *radius
+	}
+	if area != nil {
+		var polygon struct {
+			Coordinates [][][2]float64 `json:"coordinates"`
+		}
+		if err := json.Unmarshal([]byte(*area), &polygon); err == nil && len(polygon.Coordinates) > 0 {
+			z.Area = polygon.Coordinates[0]
+		}
+	}
+	if err := json.Unmarshal(slabs, &z.FeeSlabs); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+// SetDeliveryZone creates or replaces a provider's delivery zone.
+func SetDeliveryZone(ctx context.Context, z *models.DeliveryZone) error {
+	var radius *float64
+	var area *string
+	if len(z.Area) > 0 {
+		geo := delivery.GeoJSON(z.Area)
+		area = &geo
+	} else {
+		radius = &z.RadiusKm
+	}
+	if z.FeeSlabs == nil {
+		z.FeeSlabs = []models.FeeSlab{}
+	}
+	slabs, err := json.Marshal(z.FeeSlabs)
+	if err != nil {
+		return err
+	}
+	_, err = db.Pool.Exec(ctx, `
+		INSERT INTO provider_delivery_zones (provider_id, radius_km, area, fee_slabs, updated_at)
+		VALUES ($1, $2, ST_GeomFromGeoJSON($3::text)::geography, $4, now())
+		ON CONFLICT (provider_id) DO UPDATE
+		SET radius_km = EXCLUDED.radius_km, area = EXCLUDED.area,
+		    fee_slabs = EXCLUDED.fee_slabs, updated_at = now()
+	`, z.ProviderID, radius, area, slabs)
+	return err
+}
+
+// DeleteDeliveryZone removes a provider's zone; it then delivers anywhere
+// without a slab fee.
+func DeleteDeliveryZone(ctx context.Context, providerID string) error {
+	ct, err := db.Pool.Exec(ctx, `DELETE FROM provider_delivery_zones WHERE provider_id = $1`, providerID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return fmt.Errorf("delivery zone not found")
+	}
+	return nil
+}
+
+// ErrInvalidLocation is returned when quoting delivery to coordinates that
+// aren't on the globe.
+var ErrInvalidLocation = errors.New("lat must be within ±90 and lng within ±180")
+
+// QuoteDelivery prices delivery of an order worth subtotal from a provider
+// to lat/lng. The quote is not deliverable when the location is outside the
+// provider's zone. It returns db.ErrNotFound for an unknown provider and
+// ErrInvalidLocation for impossible coordinates.
+func QuoteDelivery(ctx context.Context, providerID string, lat, lng, subtotal float64) (*models.DeliveryQuote, error) {
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, ErrInvalidLocation
+	}
+	if _, err := uuid.Parse(providerID); err != nil {
+		return nil, db.ErrNotFound
+	}
+	p := &models.Provider{ID: providerID}
+	var distanceKm float64
+	var inZone bool
+	err := db.Pool.QueryRow(ctx, `
+		SELECT COALESCE(p.free_delivery_min_price, 0)::float8, COALESCE(p.free_delivery_max_km, 0)::float8,
+		       ST_Distance(p.geo, ST_SetSRID(ST_MakePoint($2, $3), 4326)::geography) / 1000,
+		       `+delivery.DeliversToSQL("p.", "ST_SetSRID(ST_MakePoint($2, $3), 4326)::geography")+`
+		FROM providers p WHERE p.id = $1
+	`, providerID, lng, lat).Scan(&p.FreeDeliveryMinPrice, &p.FreeDeliveryMaxKm, &distanceKm, &inZone)
+	if err == pgx.ErrNoRows {
+		return nil, db.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !inZone {
+		return &models.DeliveryQuote{DistanceKm: distanceKm}, nil
+	}
+	zone, err := GetDeliveryZone(ctx, providerID)
+	if err != nil {
+		return nil, err
+	}
+	return delivery.Quote(p, zone, distanceKm, subtotal), nil
+}
@@ -0,0 +1,21 @@
+package providers
+
+import (
+	"context"
+	"testing"
+
+	"jainfood/internal/db"
+)
+
+func TestQuoteDeliveryRejectsBadInput(t *testing.T) {
+	ctx := context.Background()
+	id := "6f1c2d3e-4b5a-4c6d-8e7f-9a0b1c2d3e4f"
+	for _, c := range []struct{ lat, lng float64 }{{91, 72}, {-91, 72}, {23, 181}, {23, -181}} {
+		if _, err := QuoteDelivery(ctx, id, c.lat, c.lng, 500); err != ErrInvalidLocation {
+			t.Errorf("QuoteDelivery(%v, %v) error = %v, want ErrInvalidLocation", c.lat, c.lng, err)
+		}
+	}
+	if _, err := QuoteDelivery(ctx, "not-a-uuid", 23, 72, 500); err != db.ErrNotFound {
+		t.Errorf("QuoteDelivery with a malformed ID error = %v, want db.ErrNotFound", err)
+	}
+}
@@ -0,0 +1,184 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+)
+
+// GetSchedule retrieves a provider's timezone, weekly slots and current or
+// upcoming holidays.
+func GetSchedule(ctx context.Context, providerID string) (*models.ProviderSchedule, error) {
+	s := &models.ProviderSchedule{ProviderID: providerID, Slots: []models.OpeningSlot{}, Holidays: []models.Holiday{}}
+	err := db.Pool.QueryRow(ctx, `
+		SELECT timezone, COALESCE(available_today, TRUE) FROM providers WHERE id = $1
+	`, providerID).Scan(&s.Timezone, &s.AvailableToday)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT weekday, to_char(opens, 'HH24:MI'), to_char(closes, 'HH24:MI'),
+		       COALESCE(to_char(order_cutoff, 'HH24:MI'), '')
+		FROM provider_hours WHERE provider_id = $1
+		ORDER BY weekday, opens
+	`, providerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var slot models.OpeningSlot
+		if err := rows.Scan(&slot.Weekday, &slot.Opens, &slot.Closes, &slot.OrderCutoff); err != nil {
+			return nil, err
+		}
+		s.Slots = append(s.Slots, slot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Yesterday's holiday still closes a slot running past midnight
+	rows, err = db.Pool.Query(ctx, `
+		SELECT id, starts_on::text, ends_on::text, COALESCE(reason, '')
+		FROM provider_holidays
+		WHERE provider_id = $1 AND ends_on >= (now() AT TIME ZONE $2)::date - 1
+		ORDER BY starts_on
+	`, providerID, s.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var h models.Holiday
+		if err := rows.Scan(&h.ID, &h.From, &h.To, &h.Reason); err != nil {
+			return nil, err
+		}
+		s.Holidays = append(s.Holidays, h)
+	}
+	return s, rows.Err()
+}
+
+// SetHours replaces a provider's timezone and weekly slots.
+func SetHours(ctx context.Context, providerID, timezone string, slots []models.OpeningSlot) error {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	ct, err := tx.Exec(ctx, `UPDATE providers SET timezone = $2 WHERE id = $1`, providerID, timezone)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return fmt.Errorf("provider not found")
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM provider_hours WHERE provider_id = $1`, providerID); err != nil {
+		return err
+	}
+	for _, slot := range slots {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO provider_hours (provider_id, weekday, opens, closes, order_cutoff)
+			VALUES ($1, $2, $3::time, $4::time, NULLIF($5, '')::time)
+		`, providerID, slot.Weekday, slot.Opens, slot.Closes, slot.OrderCutoff)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// AddHoliday records a closure from h.From to h.To inclusive.
+func AddHoliday(ctx context.Context, providerID string, h *models.Holiday) error {
+	return db.Pool.QueryRow(ctx, `
+		INSERT INTO provider_holidays (provider_id, starts_on, ends_on, reason)
+		VALUES ($1, $2::date, $3::date, NULLIF($4, ''))
+		RETURNING id
+	`, providerID, h.From, h.To, h.Reason).Scan(&h.ID)
+}
+
+// DeleteHoliday removes one of a provider's holidays.
+func DeleteHoliday(ctx context.Context, providerID, holidayID string) error {
+	ct, err := db.Pool.Exec(ctx, `
+		DELETE FROM provider_holidays WHERE id = $1 AND provider_id = $2
+	`, holidayID, providerID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return fmt.Errorf("holiday not found")
+	}
+	return nil
+}
+
+// GetSchedules loads the schedules of several providers at once, keyed by
+// provider ID.
+func GetSchedules(ctx context.Context, providerIDs []string) (map[string]*models.ProviderSchedule, error) {
+	schedules := make(map[string]*models.ProviderSchedule, len(providerIDs))
+	rows, err := db.Pool.Query(ctx, `
+		SELECT id::text, timezone, COALESCE(available_today, TRUE) FROM providers WHERE id = ANY($1::uuid[])
+	`, providerIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		s := &models.ProviderSchedule{Slots: []models.OpeningSlot{}, Holidays: []models.Holiday{}}
+		if err := rows.Scan(&s.ProviderID, &s.Timezone, &s.AvailableToday); err != nil {
+			return nil, err
+		}
+		schedules[s.ProviderID] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Pool.Query(ctx, `
+		SELECT provider_id::text, weekday, to_char(opens, 'HH24:MI'), to_char(closes, 'HH24:MI'),
+		       COALESCE(to_char(order_cutoff, 'HH24:MI'), '')
+		FROM provider_hours WHERE provider_id = ANY($1::uuid[])
+		ORDER BY provider_id, weekday, opens
+	`, providerIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var slot models.OpeningSlot
+		if err := rows.Scan(&id, &slot.Weekday, &slot.Opens, &slot.Closes, &slot.OrderCutoff); err != nil {
+			return nil, err
+		}
+		if s, ok := schedules[id]; ok {
+			s.Slots = append(s.Slots, slot)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Pool.Query(ctx, `
+		SELECT provider_id::text, id, starts_on::text, ends_on::text, COALESCE(reason, '')
+		FROM provider_holidays
+		WHERE provider_id = ANY($1::uuid[]) AND ends_on >= current_date - 1
+		ORDER BY starts_on
+	`, providerIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var h models.Holiday
+		if err := rows.Scan(&id, &h.ID, &h.From, &h.To, &h.Reason); err != nil {
+			return nil, err
+		}
+		if s, ok := schedules[id]; ok {
+			s.Holidays = append(s.Holidays, h)
+		}
+	}
+	return schedules, rows.Err()
+}
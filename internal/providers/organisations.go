@@ -0,0 +1,379 @@
+package providers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+)
+
+// StaffRoles lists the roles a staff member can hold, most senior first.
+var StaffRoles = []string{models.StaffRoleOwner, models.StaffRoleManager, models.StaffRoleKitchenStaff}
+
+var (
+	// ErrOrganisationExists is returned when the user already belongs to an
+	// organisation.
+	ErrOrganisationExists = errors.New("you already belong to an organisation")
+	// ErrNotRegistered is returned when a staff member's phone has no account.
+	ErrNotRegistered = errors.New("no user is registered with this phone number")
+	// ErrStaffExists is returned when the user already has the role's scope.
+	ErrStaffExists = errors.New("this user already has a role for this outlet")
+	// ErrNotOutlet is returned for an outlet outside the organisation.
+	ErrNotOutlet = errors.New("outlet doesn't belong to this organisation")
+	// ErrOutletRequired is returned when a user who works at several outlets
+	// doesn't say which one a request is for.
+	ErrOutletRequired = errors.New("outlet_id is required when you work at more than one outlet")
+	// ErrOutletForbidden is returned when the user's role at the outlet
+	// doesn't allow the request.
+	ErrOutletForbidden = errors.New("your role doesn't allow this at this outlet")
+)
+
+// staffRank is an SQL expression ordering the role in column by seniority;
+// lower is more senior.
+func staffRank(column string) string {
+	return "CASE " + column + " WHEN 'owner' THEN 0 WHEN 'manager' THEN 1 ELSE 2 END"
+}
+
+// CanManageOutlet reports whether role may edit an outlet's profile, hours
+// and menus.
+func CanManageOutlet(role string) bool {
+	return role == models.StaffRoleOwner || role == models.StaffRoleManager
+}
+
+// CanHandleOrders reports whether role may see and update an outlet's orders.
+func CanHandleOrders(role string) bool {
+	return CanManageOutlet(role) || role == models.StaffRoleKitchenStaff
+}
+
+// CanAssign reports whether a staff member with role actor may add or remove
+// a staff member with role target. Owners assign managers and kitchen
+// staff; managers assign kitchen staff. Nobody assigns owners.
+func CanAssign(actor, target string) bool {
+	switch actor {
+	case models.StaffRoleOwner:
+		return target == models.StaffRoleManager || target == models.StaffRoleKitchenStaff
+	case models.StaffRoleManager:
+		return target == models.StaffRoleKitchenStaff
+	}
+	return false
+}
+
+// CreateOrganisation creates an organisation owned by ownerID. Outlets the
+// owner already runs join it.
+func CreateOrganisation(ctx context.Context, ownerID, name string) (*models.Organisation, error) {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM provider_staff WHERE user_id = $1)`, ownerID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrOrganisationExists
+	}
+
+	var orgID string
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO provider_organisations (name, owner_id) VALUES ($1, $2) RETURNING id
+	`, name, ownerID).Scan(&orgID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE providers SET organisation_id = $1 WHERE user_id = $2 AND organisation_id IS NULL
+	`, orgID, ownerID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO provider_staff (organisation_id, user_id, role) VALUES ($1, $2, 'owner')
+	`, orgID, ownerID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return GetOrganisation(ctx, orgID)
+}
+
+// GetOrganisation retrieves an organisation and its outlets, or db.ErrNotFound.
+func GetOrganisation(ctx context.Context, orgID string) (*models.Organisation, error) {
+	org := &models.Organisation{}
+	err := db.Pool.QueryRow(ctx, `
+		SELECT id, name, owner_id, created_at FROM provider_organisations WHERE id = $1
+	`, orgID).Scan(&org.ID, &org.Name, &org.OwnerID, &org.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, db.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT `+providerColumns+` FROM providers WHERE organisation_id = $1 ORDER BY created_at
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	org.Outlets = []*models.Provider{}
+	for rows.Next() {
+		p, err := scanProvider(rows)
+		if err != nil {
+			return nil, err
+		}
+		org.Outlets = append(org.Outlets, p)
+	}
+	return org, rows.Err()
+}
+
+// GetOrganisationForUser returns the organisation the user works for, or
+// db.ErrNotFound.
+func GetOrganisationForUser(ctx context.Context, userID string) (*models.Organisation, error) {
+	var orgID string
+	err := db.Pool.QueryRow(ctx, `
+		SELECT organisation_id FROM provider_staff WHERE user_id = $1
+		ORDER BY `+staffRank("role")+`, created_at LIMIT 1
+	`, userID).Scan(&orgID)
+	if err == pgx.ErrNoRows {
+		return nil, db.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return GetOrganisation(ctx, orgID)
+}
+
+// OrganisationRole returns the user's organisation-wide role, or "" if they
+// have none (they may still have roles at single outlets).
+func OrganisationRole(ctx context.Context, userID, orgID string) (string, error) {
+	var role string
+	err := db.Pool.QueryRow(ctx, `
+		SELECT role FROM provider_staff
+		WHERE user_id = $1 AND organisation_id = $2 AND provider_id IS NULL
+		ORDER BY `+staffRank("role")+` LIMIT 1
+	`, userID, orgID).Scan(&role)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+// outletAccessSQL lists (provider_id, role, rank) for every outlet user $1
+// works at. The user an outlet was created by counts as its owner, so
+// providers outside any organisation keep working.
+var outletAccessSQL = `
+	SELECT p.id AS provider_id, 'owner' AS role, 0 AS rank
+	FROM providers p WHERE p.user_id = $1
+	UNION ALL
+	SELECT p.id, s.role, ` + staffRank("s.role") + `
+	FROM provider_staff s
+	JOIN providers p ON p.organisation_id = s.organisation_id
+	WHERE s.user_id = $1 AND (s.provider_id IS NULL OR s.provider_id = p.id)`
+
+// OutletRole returns the user's role at an outlet, or "" if they don't work
+// there.
+func OutletRole(ctx context.Context, userID, providerID string) (string, error) {
+	var role string
+	err := db.Pool.QueryRow(ctx, `
+		SELECT role FROM (`+outletAccessSQL+`) a WHERE provider_id = $2 ORDER BY rank LIMIT 1
+	`, userID, providerID).Scan(&role)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+// AccessibleOutlets lists the outlets the user works at with their most
+// senior role at each.
+func AccessibleOutlets(ctx context.Context, userID string) ([]models.OutletAccess, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT DISTINCT ON (a.provider_id) a.provider_id, COALESCE(p.organisation_id::text, ''),
+		       COALESCE(p.business_name, ''), a.role
+		FROM (`+outletAccessSQL+`) a
+		JOIN providers p ON p.id = a.provider_id
+		ORDER BY a.provider_id, a.rank
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	outlets := []models.OutletAccess{}
+	for rows.Next() {
+		var o models.OutletAccess
+		if err := rows.Scan(&o.ProviderID, &o.OrganisationID, &o.BusinessName, &o.Role); err != nil {
+			return nil, err
+		}
+		outlets = append(outlets, o)
+	}
+	return outlets, rows.Err()
+}
+
+// ResolveOutlet returns the outlet a request by the user is for: outletID
+// if their role there satisfies allowed (else ErrOutletForbidden), or with
+// no outletID the only outlet where it does. It returns ErrOutletRequired
+// when several outlets qualify, and db.ErrNotFound when none does.
+func ResolveOutlet(ctx context.Context, userID, outletID string, allowed func(role string) bool) (string, error) {
+	if outletID != "" {
+		role, err := OutletRole(ctx, userID, outletID)
+		if err != nil {
+			return "", err
+		}
+		if !allowed(role) {
+			return "", ErrOutletForbidden
+		}
+		return outletID, nil
+	}
+	access, err := AccessibleOutlets(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return onlyOutlet(access, allowed)
+}
+
+// onlyOutlet returns the one outlet in access where allowed holds.
+func onlyOutlet(access []models.OutletAccess, allowed func(role string) bool) (string, error) {
+	id := ""
+	for _, a := range access {
+		if !allowed(a.Role) {
+			continue
+		}
+		if id != "" {
+			return "", ErrOutletRequired
+		}
+		id = a.ProviderID
+	}
+	if id == "" {
+		return "", db.ErrNotFound
+	}
+	return id, nil
+}
+
+// AddOutlet creates a new outlet for an organisation. Like the organisation's
+// other outlets it is owned by the organisation's owner.
+func AddOutlet(ctx context.Context, org *models.Organisation, businessName, address string, lat, lng float64, tags []string) (*models.Provider, error) {
+	return createProvider(ctx, org.OwnerID, org.ID, businessName, address, lat, lng, tags)
+}
+
+// staffColumns selects a models.StaffMember, in the order scanStaff reads it.
+const staffColumns = `s.id, s.organisation_id, s.user_id, COALESCE(u.name, ''), u.phone,
+	       COALESCE(s.provider_id::text, ''), s.role, s.created_at`
+
+func scanStaff(row pgx.Row) (*models.StaffMember, error) {
+	m := &models.StaffMember{}
+	err := row.Scan(&m.ID, &m.OrganisationID, &m.UserID, &m.Name, &m.Phone, &m.ProviderID, &m.Role, &m.CreatedAt)
+	return m, err
+}
+
+// ListStaff lists an organisation's staff, most senior first.
+func ListStaff(ctx context.Context, orgID string) ([]*models.StaffMember, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT `+staffColumns+`
+		FROM provider_staff s JOIN users u ON u.id = s.user_id
+		WHERE s.organisation_id = $1
+		ORDER BY `+staffRank("s.role")+`, s.created_at
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	staff := []*models.StaffMember{}
+	for rows.Next() {
+		m, err := scanStaff(rows)
+		if err != nil {
+			return nil, err
+		}
+		staff = append(staff, m)
+	}
+	return staff, rows.Err()
+}
+
+// GetStaff retrieves one of an organisation's staff, or db.ErrNotFound.
+func GetStaff(ctx context.Context, orgID, staffID string) (*models.StaffMember, error) {
+	m, err := scanStaff(db.Pool.QueryRow(ctx, `
+		SELECT `+staffColumns+`
+		FROM provider_staff s JOIN users u ON u.id = s.user_id
+		WHERE s.organisation_id = $1 AND s.id = $2
+	`, orgID, staffID))
+	if err == pgx.ErrNoRows {
+		return nil, db.ErrNotFound
+	}
+	return m, err
+}
+
+// AddStaff gives the user registered with phone a role in the organisation,
+// at one outlet or (providerID "") all of them. Buyers become providers so
+// they can use the provider app; promoted reports whether that happened.
+func AddStaff(ctx context.Context, orgID, phone, providerID, role string) (member *models.StaffMember, promoted bool, err error) {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var userID string
+	err = tx.QueryRow(ctx, `SELECT id FROM users WHERE phone = $1`, phone).Scan(&userID)
+	if err == pgx.ErrNoRows {
+		return nil, false, ErrNotRegistered
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var outlet *string
+	if providerID != "" {
+		var ok bool
+		if err := tx.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM providers WHERE id = $1 AND organisation_id = $2)
+		`, providerID, orgID).Scan(&ok); err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			return nil, false, ErrNotOutlet
+		}
+		outlet = &providerID
+	}
+
+	var staffID string
+	err = tx.QueryRow(ctx, `
+		INSERT INTO provider_staff (organisation_id, user_id, provider_id, role)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+		RETURNING id
+	`, orgID, userID, outlet, role).Scan(&staffID)
+	if err == pgx.ErrNoRows {
+		return nil, false, ErrStaffExists
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	ct, err := tx.Exec(ctx, `UPDATE users SET role = 'provider' WHERE id = $1 AND role = 'buyer'`, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, err
+	}
+	member, err = GetStaff(ctx, orgID, staffID)
+	return member, ct.RowsAffected() > 0, err
+}
+
+// RemoveStaff removes a staff member's role, or returns db.ErrNotFound.
+func RemoveStaff(ctx context.Context, orgID, staffID string) error {
+	ct, err := db.Pool.Exec(ctx, `
+		DELETE FROM provider_staff WHERE organisation_id = $1 AND id = $2 AND role <> 'owner'
+	`, orgID, staffID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return db.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package providers
+
+import (
+	"testing"
+
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+)
+
+func TestStaffPermissions(t *testing.T) {
+	owner, manager, kitchen := models.StaffRoleOwner, models.StaffRoleManager, models.StaffRoleKitchenStaff
+	for _, c := range []struct {
+		role           string
+		manage, orders bool
+	}{
+		{owner, true, true},
+		{manager, true, true},
+		{kitchen, false, true},
+		{"", false, false},
+		{"admin", false, false},
+	} {
+		if got := CanManageOutlet(c.role); got != c.manage {
+			t.Errorf("CanManageOutlet(%q) = %v", c.role, got)
+		}
+		if got := CanHandleOrders(c.role); got != c.orders {
+			t.Errorf("CanHandleOrders(%q) = %v", c.role, got)
+		}
+	}
+}
+
+func TestCanAssign(t *testing.T) {
+	allowed := map[[2]string]bool{
+		{models.StaffRoleOwner, models.StaffRoleManager}:        true,
+		{models.StaffRoleOwner, models.StaffRoleKitchenStaff}:   true,
+		{models.StaffRoleManager, models.StaffRoleKitchenStaff}: true,
+	}
+	for _, actor := range append(StaffRoles, "") {
+		for _, target := range append(StaffRoles, "") {
+			if got := CanAssign(actor, target); got != allowed[[2]string{actor, target}] {
+				t.Errorf("CanAssign(%q, %q) = %v", actor, target, got)
+			}
+		}
+	}
+}
+
+func TestOnlyOutlet(t *testing.T) {
+	owned := models.OutletAccess{ProviderID: "a", Role: models.StaffRoleOwner}
+	managed := models.OutletAccess{ProviderID: "b", Role: models.StaffRoleManager}
+	kitchen := models.OutletAccess{ProviderID: "c", Role: models.StaffRoleKitchenStaff}
+
+	if id, err := onlyOutlet([]models.OutletAccess{owned}, CanManageOutlet); err != nil || id != "a" {
+		t.Errorf("one outlet = %q, %v", id, err)
+	}
+	if id, err := onlyOutlet([]models.OutletAccess{kitchen, managed}, CanManageOutlet); err != nil || id != "b" {
+		t.Errorf("one managed outlet = %q, %v", id, err)
+	}
+	if _, err := onlyOutlet([]models.OutletAccess{owned, managed}, CanManageOutlet); err != ErrOutletRequired {
+		t.Errorf("two managed outlets: err = %v", err)
+	}
+	if _, err := onlyOutlet([]models.OutletAccess{kitchen}, CanManageOutlet); err != db.ErrNotFound {
+		t.Errorf("kitchen staff only: err = %v", err)
+	}
+	if _, err := onlyOutlet(nil, CanHandleOrders); err != db.ErrNotFound {
+		t.Errorf("no outlets: err = %v", err)
+	}
+}
@@ -0,0 +1,315 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+	"jainfood/internal/places"
+	"jainfood/internal/translit"
+)
+
+// ExternalPlatforms are the other ordering channels a provider can list.
+var ExternalPlatforms = []string{"swiggy", "zomato", "own-app"}
+
+// Limits on profile fields.
+const (
+	MaxFoodCategories   = 10
+	MaxTags             = 20
+	MaxMinOrderQuantity = 10000
+	MaxFreeDeliveryKm   = 100
+)
+
+// ProfilePatch is a validated JSON merge patch (RFC 7396) of a provider
+// profile: fields left out are unchanged, null resets a field to its
+// default and arrays are replaced whole.
+type ProfilePatch struct {
+	set      map[string]interface{} // Column -> new value; nil writes NULL
+	lat, lng *float64
+	pinCode  *string // "" recomputes the nearest pin code
+}
+
+// patchField parses one non-null field value into its column value.
+type patchField struct {
+	column string
+	parse  func(raw json.RawMessage) (interface{}, error)
+	reset  interface{} // Value for null
+	clear  bool        // Whether null is allowed
+}
+
+var patchFields = map[string]patchField{
+	"business_name":           {column: "business_name", parse: parseText(200)},
+	"address":                 {column: "address", parse: parseText(500)},
+	"tags":                    {column: "tags", parse: parseTags, clear: true},
+	"provider_category":       {column: "provider_category", parse: parseOneOf(models.ProviderCategories), clear: true},
+	"food_categories":         {column: "food_categories", parse: parseSetOf(models.FoodCategories, MaxFoodCategories), clear: true},
+	"external_platforms":      {column: "external_platforms", parse: parseSetOf(ExternalPlatforms, len(ExternalPlatforms)), clear: true},
+	"external_app_link":       {column: "external_app_link", parse: parseLink, clear: true},
+	"min_order_quantity":      {column: "min_order_quantity", parse: parseInt(1, MaxMinOrderQuantity), reset: 1, clear: true},
+	"bulk_order_enabled":      {column: "bulk_order_enabled", parse: parseBool, reset: false, clear: true},
+	"available_today":         {column: "available_today", parse: parseBool, reset: true, clear: true},
+	"free_delivery_min_price": {column: "free_delivery_min_price", parse: parseFloat(0, 1e6), reset: 0.0, clear: true},
+	"free_delivery_max_km":    {column: "free_delivery_max_km", parse: parseFloat(0, MaxFreeDeliveryKm), reset: 0.0, clear: true},
+}
+
+// ParseProfilePatch validates a merge patch body. Unknown fields are
+// rejected, as are fields the provider can't set (verified, rating, ...).
+func ParseProfilePatch(body []byte) (*ProfilePatch, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("body must be a JSON object")
+	}
+
+	p := &ProfilePatch{set: map[string]interface{}{}}
+	for name, raw := range fields {
+		null := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+		switch name {
+		case "lat", "lng":
+			if null {
+				return nil, fmt.Errorf("%s can't be removed", name)
+			}
+			var v float64
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return nil, fmt.Errorf("%s must be a number", name)
+			}
+			if name == "lat" {
+				p.lat = &v
+			} else {
+				p.lng = &v
+			}
+			continue
+		case "pin_code":
+			pin := ""
+			if !null {
+				if err := json.Unmarshal(raw, &pin); err != nil || !places.ValidPin(strings.TrimSpace(pin)) {
+					return nil, fmt.Errorf("pin_code must be 6 digits")
+				}
+			}
+			pin = strings.TrimSpace(pin)
+			p.pinCode = &pin
+			continue
+		}
+
+		f, ok := patchFields[name]
+		if !ok {
+			return nil, fmt.Errorf("%s can't be changed", name)
+		}
+		if null {
+			if !f.clear {
+				return nil, fmt.Errorf("%s can't be removed", name)
+			}
+			p.set[f.column] = f.reset
+			continue
+		}
+		v, err := f.parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s %v", name, err)
+		}
+		p.set[f.column] = v
+	}
+
+	if (p.lat == nil) != (p.lng == nil) {
+		return nil, fmt.Errorf("lat and lng must be changed together")
+	}
+	if p.lat != nil && (*p.lat < -90 || *p.lat > 90 || *p.lng < -180 || *p.lng > 180) {
+		return nil, fmt.Errorf("lat/lng out of range")
+	}
+	return p, nil
+}
+
+// Empty reports whether the patch changes nothing.
+func (p *ProfilePatch) Empty() bool {
+	return len(p.set) == 0 && p.lat == nil && p.pinCode == nil
+}
+
+// Fields lists the columns the patch changes, for the audit log.
+func (p *ProfilePatch) Fields() []string {
+	var fields []string
+	for column := range p.set {
+		fields = append(fields, column)
+	}
+	if p.lat != nil {
+		fields = append(fields, "geo")
+	}
+	if p.pinCode != nil {
+		fields = append(fields, "pin_code")
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// PatchProvider applies a profile patch and returns the updated provider,
+// or db.ErrNotFound. Moving the provider recomputes its pin code unless the
+// patch sets one.
+func PatchProvider(ctx context.Context, providerID string, p *ProfilePatch) (*models.Provider, error) {
+	if p.Empty() {
+		provider, err := GetProvider(ctx, providerID)
+		if err == pgx.ErrNoRows {
+			return nil, db.ErrNotFound
+		}
+		return provider, err
+	}
+
+	query, args := p.updateSQL(providerID)
+	ct, err := db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if ct.RowsAffected() == 0 {
+		return nil, db.ErrNotFound
+	}
+	return GetProvider(ctx, providerID)
+}
+
+// updateSQL builds the UPDATE applying a non-empty patch to the provider.
+func (p *ProfilePatch) updateSQL(providerID string) (string, []interface{}) {
+	args := []interface{}{providerID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	var sets []string
+	for _, column := range p.Fields() {
+		if v, ok := p.set[column]; ok {
+			sets = append(sets, column+" = "+arg(v))
+		}
+	}
+	if name, ok := p.set["business_name"]; ok {
+		sets = append(sets, "search_key = "+arg(translit.Key(name.(string))))
+	}
+	// Qualified: inside the pin code subquery a bare geo is pin_codes.geo
+	point := "providers.geo"
+	if p.lat != nil {
+		point = fmt.Sprintf("ST_SetSRID(ST_MakePoint(%s, %s), 4326)::geography", arg(*p.lng), arg(*p.lat))
+		sets = append(sets, "geo = "+point)
+	}
+	switch {
+	case p.pinCode != nil && *p.pinCode != "":
+		sets = append(sets, "pin_code = "+arg(*p.pinCode))
+	case p.pinCode != nil || p.lat != nil:
+		sets = append(sets, "pin_code = "+places.NearestPinSQL(point))
+	}
+	return `UPDATE providers SET ` + strings.Join(sets, ", ") + ` WHERE id = $1`, args
+}
+
+func parseText(max int) func(json.RawMessage) (interface{}, error) {
+	return func(raw json.RawMessage) (interface{}, error) {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, fmt.Errorf("must be a string")
+		}
+		s = strings.TrimSpace(s)
+		if s == "" || len(s) > max {
+			return nil, fmt.Errorf("must be 1-%d characters", max)
+		}
+		return s, nil
+	}
+}
+
+func parseOneOf(valid []string) func(json.RawMessage) (interface{}, error) {
+	return func(raw json.RawMessage) (interface{}, error) {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil || !contains(valid, s) {
+			return nil, fmt.Errorf("must be one of %s", strings.Join(valid, ", "))
+		}
+		return s, nil
+	}
+}
+
+// parseSetOf parses a list of values from valid, dropping duplicates.
+func parseSetOf(valid []string, max int) func(json.RawMessage) (interface{}, error) {
+	return func(raw json.RawMessage) (interface{}, error) {
+		var list []string
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return nil, fmt.Errorf("must be a list of strings")
+		}
+		out := []string{}
+		for _, s := range list {
+			if !contains(valid, s) {
+				return nil, fmt.Errorf("must only contain %s", strings.Join(valid, ", "))
+			}
+			if !contains(out, s) {
+				out = append(out, s)
+			}
+		}
+		if len(out) > max {
+			return nil, fmt.Errorf("can have at most %d entries", max)
+		}
+		return out, nil
+	}
+}
+
+func parseTags(raw json.RawMessage) (interface{}, error) {
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, fmt.Errorf("must be a list of strings")
+	}
+	out := []string{}
+	for _, tag := range list {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	if len(out) > MaxTags {
+		return nil, fmt.Errorf("can have at most %d entries", MaxTags)
+	}
+	return out, nil
+}
+
+func parseLink(raw json.RawMessage) (interface{}, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("must be a string")
+	}
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || len(s) > 500 {
+		return nil, fmt.Errorf("must be an http(s) URL")
+	}
+	return u.String(), nil
+}
+
+func parseInt(min, max int) func(json.RawMessage) (interface{}, error) {
+	return func(raw json.RawMessage) (interface{}, error) {
+		var n int
+		if err := json.Unmarshal(raw, &n); err != nil || n < min || n > max {
+			return nil, fmt.Errorf("must be a whole number from %d to %d", min, max)
+		}
+		return n, nil
+	}
+}
+
+func parseFloat(min, max float64) func(json.RawMessage) (interface{}, error) {
+	return func(raw json.RawMessage) (interface{}, error) {
+		var f float64
+		if err := json.Unmarshal(raw, &f); err != nil || f < min || f > max {
+			return nil, fmt.Errorf("must be a number from %g to %g", min, max)
+		}
+		return f, nil
+	}
+}
+
+func parseBool(raw json.RawMessage) (interface{}, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return nil, fmt.Errorf("must be true or false")
+	}
+	return b, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package providers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"jainfood/internal/models"
+	"jainfood/internal/places"
+)
+
+func TestParseProfilePatch(t *testing.T) {
+	p, err := ParseProfilePatch([]byte(`{
+		"provider_category": "caterer",
+		"food_categories": ["sweets", "namkeen", "sweets"],
+		"external_platforms": ["swiggy"],
+		"external_app_link": "https://example.com/menu",
+		"min_order_quantity": 10,
+		"bulk_order_enabled": true,
+		"free_delivery_min_price": 500,
+		"free_delivery_max_km": 3.5,
+		"available_today": false,
+		"tags": [" Sattvic ", "sattvic", ""]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseProfilePatch: %v", err)
+	}
+	want := map[string]interface{}{
+		"provider_category":       models.ProviderCategoryCaterer,
+		"food_categories":         []string{models.FoodCategorySweets, models.FoodCategoryNamkeen},
+		"external_platforms":      []string{"swiggy"},
+		"external_app_link":       "https://example.com/menu",
+		"min_order_quantity":      10,
+		"bulk_order_enabled":      true,
+		"free_delivery_min_price": 500.0,
+		"free_delivery_max_km":    3.5,
+		"available_today":         false,
+		"tags":                    []string{"sattvic"},
+	}
+	if !reflect.DeepEqual(p.set, want) {
+		t.Errorf("set = %#v\nwant %#v", p.set, want)
+	}
+	if p.Empty() {
+		t.Error("Empty() = true")
+	}
+}
+
+func TestParseProfilePatchNull(t *testing.T) {
+	p, err := ParseProfilePatch([]byte(`{"provider_category": null, "min_order_quantity": null, "available_today": null, "pin_code": null}`))
+	if err != nil {
+		t.Fatalf("ParseProfilePatch: %v", err)
+	}
+	want := map[string]interface{}{"provider_category": nil, "min_order_quantity": 1, "available_today": true}
+	if !reflect.DeepEqual(p.set, want) {
+		t.Errorf("set = %#v, want %#v", p.set, want)
+	}
+	if p.pinCode == nil || *p.pinCode != "" {
+		t.Error("null pin_code doesn't recompute the pin")
+	}
+	if got := p.Fields(); !reflect.DeepEqual(got, []string{"available_today", "min_order_quantity", "pin_code", "provider_category"}) {
+		t.Errorf("Fields = %v", got)
+	}
+
+	empty, err := ParseProfilePatch([]byte(`{}`))
+	if err != nil || !empty.Empty() {
+		t.Errorf("empty patch: %v, %v", empty, err)
+	}
+}
+
+func TestParseProfilePatchInvalid(t *testing.T) {
+	bad := []string{
+		`[]`,
+		`null`,
+		`{"verified": true}`,
+		`{"rating": 5}`,
+		`{"provider_category": "dhaba"}`,
+		`{"food_categories": ["sweets", "pizza"]}`,
+		`{"food_categories": "sweets"}`,
+		`{"external_platforms": ["ubereats"]}`,
+		`{"external_app_link": "javascript:alert(1)"}`,
+		`{"min_order_quantity": 0}`,
+		`{"min_order_quantity": 1.5}`,
+		`{"free_delivery_max_km": -1}`,
+		`{"bulk_order_enabled": "yes"}`,
+		`{"business_name": null}`,
+		`{"business_name": "  "}`,
+		`{"lat": 23.02}`,
+		`{"lat": 123, "lng": 72.57}`,
+		`{"lat": null, "lng": null}`,
+		`{"pin_code": "38000"}`,
+	}
+	for _, body := range bad {
+		if _, err := ParseProfilePatch([]byte(body)); err == nil {
+			t.Errorf("ParseProfilePatch(%s) succeeded", body)
+		}
+	}
+}
+
+func TestUpdateSQLNullPin(t *testing.T) {
+	p, err := ParseProfilePatch([]byte(`{"pin_code": null}`))
+	if err != nil {
+		t.Fatalf("ParseProfilePatch: %v", err)
+	}
+	query, args := p.updateSQL("prov-1")
+	// The nearest pin is looked up from the provider's stored location, not
+	// from pin_codes.geo inside the subquery
+	want := "pin_code = " + places.NearestPinSQL("providers.geo")
+	if !strings.Contains(query, want) {
+		t.Errorf("query = %s, want it to contain %s", query, want)
+	}
+	if strings.Contains(query, "geo = ") {
+		t.Errorf("query moves the provider: %s", query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"prov-1"}) {
+		t.Errorf("args = %v", args)
+	}
+}
+
+func TestUpdateSQLLocation(t *testing.T) {
+	p, err := ParseProfilePatch([]byte(`{"lat": 23.02, "lng": 72.57}`))
+	if err != nil {
+		t.Fatalf("ParseProfilePatch: %v", err)
+	}
+	query, args := p.updateSQL("prov-1")
+	point := "ST_SetSRID(ST_MakePoint($2, $3), 4326)::geography"
+	if !strings.Contains(query, "geo = "+point) || !strings.Contains(query, "pin_code = "+places.NearestPinSQL(point)) {
+		t.Errorf("query = %s", query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"prov-1", 72.57, 23.02}) {
+		t.Errorf("args = %v", args)
+	}
+}
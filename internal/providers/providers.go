@@ -5,68 +5,91 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"jainfood/internal/db"
 	"jainfood/internal/models"
+	"jainfood/internal/pii"
+	"jainfood/internal/places"
+	"jainfood/internal/promotions"
+	"jainfood/internal/translit"
 )
 
 // CreateProvider creates a new provider profile for a user.
 func CreateProvider(ctx context.Context, userID, businessName, address string, lat, lng float64, tags []string) (*models.Provider, error) {
+	return createProvider(ctx, userID, "", businessName, address, lat, lng, tags)
+}
+
+// createProvider inserts a provider, in organisation orgID unless it is "".
+func createProvider(ctx context.Context, userID, orgID, businessName, address string, lat, lng float64, tags []string) (*models.Provider, error) {
 	id := uuid.New().String()
 
 	_, err := db.Pool.Exec(ctx, `
-		INSERT INTO providers (id, user_id, business_name, address, geo, tags, verified)
-		VALUES ($1, $2, $3, $4, ST_SetSRID(ST_MakePoint($5, $6), 4326)::geography, $7, FALSE)
-	`, id, userID, businessName, address, lng, lat, tags)
+		INSERT INTO providers (id, user_id, organisation_id, business_name, address, geo, tags, verified, search_key, pin_code)
+		VALUES ($1, $2, NULLIF($9, '')::uuid, $3, $4, ST_SetSRID(ST_MakePoint($5, $6), 4326)::geography, $7, FALSE, $8,
+		        `+places.NearestPinSQL("ST_SetSRID(ST_MakePoint($5, $6), 4326)::geography")+`)
+	`, id, userID, businessName, address, lng, lat, tags, translit.Key(businessName), orgID)
 	if err != nil {
 		return nil, err
 	}
 
 	return &models.Provider{
-		ID:           id,
-		UserID:       userID,
-		BusinessName: businessName,
-		Address:      address,
-		Lat:          lat,
-		Lng:          lng,
-		Tags:         tags,
-		Verified:     false,
+		ID:             id,
+		UserID:         userID,
+		OrganisationID: orgID,
+		BusinessName:   businessName,
+		Address:        address,
+		Lat:            lat,
+		Lng:            lng,
+		Tags:           tags,
+		Verified:       false,
 	}, nil
 }
 
-// GetProvider retrieves a provider by ID.
-func GetProvider(ctx context.Context, providerID string) (*models.Provider, error) {
-	p := &models.Provider{}
-	err := db.Pool.QueryRow(ctx, `
-		SELECT id, user_id, business_name, address, 
+// providerColumns selects every column of models.Provider, in the order
+// scanProvider reads them.
+var providerColumns = `id, user_id, COALESCE(organisation_id::text, ''), COALESCE(business_name, ''), COALESCE(address, ''), COALESCE(pin_code, ''),
 		       ST_Y(geo::geometry) as lat, ST_X(geo::geometry) as lng,
-		       verified, tags, rating, created_at
-		FROM providers WHERE id = $1
-	`, providerID).Scan(
-		&p.ID, &p.UserID, &p.BusinessName, &p.Address,
-		&p.Lat, &p.Lng, &p.Verified, &p.Tags, &p.Rating, &p.CreatedAt,
+		       COALESCE(verified, FALSE), COALESCE(aadhar_verified, FALSE), COALESCE(aadhar_last4, ''),
+		       COALESCE(tags, '{}'), COALESCE(provider_category, ''), COALESCE(food_categories, '{}'),
+		       COALESCE(rating, 0), COALESCE(total_ratings, 0), COALESCE(total_orders, 0),
+		       COALESCE(available_today, TRUE), COALESCE(external_platforms, '{}'), COALESCE(external_app_link, ''),
+		       COALESCE(min_order_quantity, 1), COALESCE(bulk_order_enabled, FALSE),
+		       COALESCE(free_delivery_min_price, 0)::float8, COALESCE(free_delivery_max_km, 0)::float8,
+		       ` + promotions.RunningSQL("providers.id") + `, COALESCE(blocked, FALSE), COALESCE(blocked_reason, ''),
+		       terms_accepted_at, created_at`
+
+func scanProvider(row pgx.Row) (*models.Provider, error) {
+	p := &models.Provider{}
+	var aadharLast4 string
+	err := row.Scan(
+		&p.ID, &p.UserID, &p.OrganisationID, &p.BusinessName, &p.Address, &p.PinCode,
+		&p.Lat, &p.Lng, &p.Verified, &p.AadharVerified, &aadharLast4,
+		&p.Tags, &p.ProviderCategory, &p.FoodCategories,
+		&p.Rating, &p.TotalRatings, &p.TotalOrders,
+		&p.AvailableToday, &p.ExternalPlatforms, &p.ExternalAppLink,
+		&p.MinOrderQuantity, &p.BulkOrderEnabled,
+		&p.FreeDeliveryMinPrice, &p.FreeDeliveryMaxKm,
+		&p.IsPromoted, &p.Blocked, &p.BlockedReason,
+		&p.TermsAcceptedAt, &p.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if aadharLast4 != "" {
+		p.AadharNumber = pii.MaskAadhaar(aadharLast4)
+	}
 	return p, nil
 }
 
-// GetProviderByUserID retrieves a provider by the owning user ID.
+// GetProvider retrieves a provider by ID.
+func GetProvider(ctx context.Context, providerID string) (*models.Provider, error) {
+	return scanProvider(db.Pool.QueryRow(ctx, `SELECT `+providerColumns+` FROM providers WHERE id = $1`, providerID))
+}
+
+// GetProviderByUserID retrieves a provider by the owning user ID. A user who
+// owns several outlets gets the first one they created.
 func GetProviderByUserID(ctx context.Context, userID string) (*models.Provider, error) {
-	p := &models.Provider{}
-	err := db.Pool.QueryRow(ctx, `
-		SELECT id, user_id, business_name, address, 
-		       ST_Y(geo::geometry) as lat, ST_X(geo::geometry) as lng,
-		       verified, tags, rating, created_at
-		FROM providers WHERE user_id = $1
-	`, userID).Scan(
-		&p.ID, &p.UserID, &p.BusinessName, &p.Address,
-		&p.Lat, &p.Lng, &p.Verified, &p.Tags, &p.Rating, &p.CreatedAt,
-	)
-	if err != nil {
-		return nil, err
-	}
-	return p, nil
+	return scanProvider(db.Pool.QueryRow(ctx, `SELECT `+providerColumns+` FROM providers WHERE user_id = $1 ORDER BY created_at LIMIT 1`, userID))
 }
 
 // UpdateProvider updates provider details.
@@ -75,9 +98,10 @@ func UpdateProvider(ctx context.Context, providerID, businessName, address strin
 		UPDATE providers
 		SET business_name = $2, address = $3, 
 		    geo = ST_SetSRID(ST_MakePoint($4, $5), 4326)::geography,
-		    tags = $6
+		    tags = $6, search_key = $7,
+		    pin_code = `+places.NearestPinSQL("ST_SetSRID(ST_MakePoint($4, $5), 4326)::geography")+`
 		WHERE id = $1
-	`, providerID, businessName, address, lng, lat, tags)
+	`, providerID, businessName, address, lng, lat, tags, translit.Key(businessName))
 	if err != nil {
 		return err
 	}
@@ -102,9 +126,7 @@ func VerifyProvider(ctx context.Context, providerID string, verified bool) error
 // ListProviders retrieves all providers (with pagination).
 func ListProviders(ctx context.Context, limit, offset int) ([]*models.Provider, error) {
 	rows, err := db.Pool.Query(ctx, `
-		SELECT id, user_id, business_name, address, 
-		       ST_Y(geo::geometry) as lat, ST_X(geo::geometry) as lng,
-		       verified, tags, rating, created_at
+		SELECT `+providerColumns+`
 		FROM providers
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
@@ -116,11 +138,8 @@ func ListProviders(ctx context.Context, limit, offset int) ([]*models.Provider,
 
 	var providers []*models.Provider
 	for rows.Next() {
-		p := &models.Provider{}
-		if err := rows.Scan(
-			&p.ID, &p.UserID, &p.BusinessName, &p.Address,
-			&p.Lat, &p.Lng, &p.Verified, &p.Tags, &p.Rating, &p.CreatedAt,
-		); err != nil {
+		p, err := scanProvider(rows)
+		if err != nil {
 			return nil, err
 		}
 		providers = append(providers, p)
@@ -155,4 +174,3 @@ func UnblockProvider(ctx context.Context, providerID string) error {
 	}
 	return nil
 }
-
@@ -0,0 +1,291 @@
+package push
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeGoogle serves both the OAuth2 token endpoint and the FCM v1 send endpoint.
+type fakeGoogle struct {
+	t          *testing.T
+	key        *rsa.PrivateKey
+	server     *httptest.Server
+	tokenCalls int32
+	rejectNext int32 // Number of sends to reject with 401
+	busyNext   int32 // Number of sends to reject with 503
+	sends      int32
+	lastSend   map[string]interface{}
+}
+
+func newFakeGoogle(t *testing.T) *fakeGoogle {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &fakeGoogle{t: t, key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", g.handleToken)
+	mux.HandleFunc("/v1/projects/jainfood-test/messages:send", g.handleSend)
+	g.server = httptest.NewServer(mux)
+	t.Cleanup(g.server.Close)
+	return g
+}
+
+func (g *fakeGoogle) credentials() []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(g.key)
+	if err != nil {
+		g.t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	data, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "jainfood-test",
+		"private_key_id": "key-1",
+		"private_key":    string(keyPEM),
+		"client_email":   "push@jainfood-test.iam.gserviceaccount.com",
+		"token_uri":      g.server.URL + "/token",
+	})
+	return data
+}
+
+func (g *fakeGoogle) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if r.Form.Get("grant_type") != jwtBearerGrant {
+		http.Error(w, `{"error":"unsupported_grant_type"}`, 400)
+		return
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(r.Form.Get("assertion"), claims, func(tok *jwt.Token) (interface{}, error) {
+		if tok.Header["kid"] != "key-1" {
+			return nil, fmt.Errorf("unexpected kid %v", tok.Header["kid"])
+		}
+		return &g.key.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithAudience(g.server.URL+"/token"))
+	if err != nil {
+		http.Error(w, `{"error":"invalid_grant"}`, 400)
+		return
+	}
+	if claims["scope"] != fcmScope {
+		http.Error(w, `{"error":"invalid_scope"}`, 400)
+		return
+	}
+
+	n := atomic.AddInt32(&g.tokenCalls, 1)
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"access_token":"access-%d","expires_in":3600,"token_type":"Bearer"}`, n)
+}
+
+func (g *fakeGoogle) handleSend(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&g.sends, 1)
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer access-") || atomic.AddInt32(&g.rejectNext, -1) >= 0 {
+		w.WriteHeader(401)
+		fmt.Fprint(w, `{"error":{"code":401,"message":"Request had invalid authentication credentials.","status":"UNAUTHENTICATED"}}`)
+		return
+	}
+
+	var body struct {
+		Message map[string]interface{} `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	g.lastSend = body.Message
+
+	busy := atomic.AddInt32(&g.busyNext, -1) >= 0
+	switch {
+	case body.Message["token"] == "stale-token":
+		w.WriteHeader(404)
+		fmt.Fprint(w, `{"error":{"code":404,"message":"Requested entity was not found.","status":"NOT_FOUND",
+			"details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"UNREGISTERED"}]}}`)
+		return
+	case body.Message["token"] == "busy-token" || busy:
+		w.WriteHeader(503)
+		fmt.Fprint(w, `{"error":{"code":503,"message":"The service is currently unavailable.","status":"UNAVAILABLE",
+			"details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"UNAVAILABLE"}]}}`)
+		return
+	}
+	fmt.Fprint(w, `{"name":"projects/jainfood-test/messages/0:1500415314455276%31bd1c9631bd1c96"}`)
+}
+
+func (g *fakeGoogle) service(t *testing.T) *FCMService {
+	f, err := NewFCMService(g.credentials(), "")
+	if err != nil {
+		t.Fatalf("NewFCMService: %v", err)
+	}
+	f.BaseURL = g.server.URL + "/v1"
+	return f
+}
+
+func TestFCMSendCachesAccessToken(t *testing.T) {
+	g := newFakeGoogle(t)
+	f := g.service(t)
+
+	for i := 0; i < 3; i++ {
+		if err := f.SendToDevice("device-token", "Order Ready", "Your thali is ready", map[string]string{"order_id": "o1"}); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+	}
+	if got := atomic.LoadInt32(&g.tokenCalls); got != 1 {
+		t.Errorf("expected 1 token request, got %d", got)
+	}
+
+	android, _ := g.lastSend["android"].(map[string]interface{})
+	if android["priority"] != "high" {
+		t.Errorf("expected default android priority high, got %v", g.lastSend["android"])
+	}
+	if _, ok := g.lastSend["apns"]; !ok {
+		t.Error("expected default apns config to be sent")
+	}
+	if data, _ := g.lastSend["data"].(map[string]interface{}); data["order_id"] != "o1" {
+		t.Errorf("expected data to be forwarded, got %v", g.lastSend["data"])
+	}
+}
+
+func TestFCMRefreshesExpiredToken(t *testing.T) {
+	g := newFakeGoogle(t)
+	f := g.service(t)
+
+	now := time.Now()
+	f.tokens.now = func() time.Time { return now }
+
+	if err := f.SendToDevice("device-token", "t", "b", nil); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(59*time.Minute + time.Second) // Within the expiry skew
+	if err := f.SendToDevice("device-token", "t", "b", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&g.tokenCalls); got != 2 {
+		t.Errorf("expected token refresh near expiry, got %d token requests", got)
+	}
+}
+
+func TestFCMRetriesOnceOnUnauthorized(t *testing.T) {
+	g := newFakeGoogle(t)
+	f := g.service(t)
+	g.rejectNext = 1
+
+	if err := f.SendToTopic("city-indore", "t", "b", nil); err != nil {
+		t.Fatalf("expected retry with fresh token to succeed: %v", err)
+	}
+	if got := atomic.LoadInt32(&g.tokenCalls); got != 2 {
+		t.Errorf("expected token to be re-minted after 401, got %d token requests", got)
+	}
+	if g.lastSend["topic"] != "city-indore" {
+		t.Errorf("expected topic message, got %v", g.lastSend)
+	}
+}
+
+func TestFCMParsesPerTokenErrors(t *testing.T) {
+	g := newFakeGoogle(t)
+	f := g.service(t)
+	fastRetries(t)
+
+	err := f.SendToDevice("stale-token", "t", "b", nil)
+	if !errors.Is(err, ErrUnregistered) {
+		t.Fatalf("expected ErrUnregistered, got %v", err)
+	}
+	var fcmErr *FCMError
+	if !errors.As(err, &fcmErr) || fcmErr.Code != "UNREGISTERED" || fcmErr.Status != "NOT_FOUND" {
+		t.Errorf("unexpected error details: %+v", fcmErr)
+	}
+
+	err = f.SendToDevice("busy-token", "t", "b", nil)
+	if !errors.As(err, &fcmErr) || !fcmErr.Retryable() {
+		t.Errorf("expected retryable UNAVAILABLE error, got %v", err)
+	}
+	if errors.Is(err, ErrUnregistered) {
+		t.Error("UNAVAILABLE must not be treated as an unregistered token")
+	}
+}
+
+func TestFCMRetriesRetryableErrors(t *testing.T) {
+	g := newFakeGoogle(t)
+	f := g.service(t)
+	fastRetries(t)
+	g.busyNext = 1
+
+	if err := f.SendToDevice("device-token", "t", "b", nil); err != nil {
+		t.Fatalf("expected retry after UNAVAILABLE to succeed: %v", err)
+	}
+	if got := atomic.LoadInt32(&g.sends); got != 2 {
+		t.Errorf("expected 2 send attempts, got %d", got)
+	}
+
+	atomic.StoreInt32(&g.sends, 0)
+	if err := f.SendToDevice("stale-token", "t", "b", nil); !errors.Is(err, ErrUnregistered) {
+		t.Fatalf("expected ErrUnregistered, got %v", err)
+	}
+	if got := atomic.LoadInt32(&g.sends); got != 1 {
+		t.Errorf("unregistered tokens must not be retried, got %d send attempts", got)
+	}
+
+	atomic.StoreInt32(&g.sends, 0)
+	if err := f.SendToDevice("busy-token", "t", "b", nil); err == nil {
+		t.Fatal("expected persistent UNAVAILABLE to fail")
+	}
+	if got := atomic.LoadInt32(&g.sends); got != 1+fcmRetries {
+		t.Errorf("expected %d send attempts, got %d", 1+fcmRetries, got)
+	}
+}
+
+func fastRetries(t *testing.T) {
+	prev := fcmRetryDelay
+	fcmRetryDelay = time.Millisecond
+	t.Cleanup(func() { fcmRetryDelay = prev })
+}
+
+func TestFCMMessageOverridesDefaults(t *testing.T) {
+	g := newFakeGoogle(t)
+	f := g.service(t)
+
+	_, err := f.Send(context.Background(), &FCMMessage{
+		Token:        "device-token",
+		Notification: &FCMNotification{Title: "t", Body: "b"},
+		Android:      &AndroidConfig{Priority: "normal", Notification: &AndroidNotification{ChannelID: "promotions"}},
+		Webpush:      &WebpushConfig{FCMOptions: &WebpushFCMOptions{Link: "https://jainfood.app/orders"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	android, _ := g.lastSend["android"].(map[string]interface{})
+	if android["priority"] != "normal" {
+		t.Errorf("expected android override, got %v", android)
+	}
+	if _, ok := g.lastSend["webpush"]; !ok {
+		t.Error("expected webpush override to be sent")
+	}
+	if _, ok := g.lastSend["apns"]; !ok {
+		t.Error("expected apns default to still apply")
+	}
+}
+
+func TestParseServiceAccountRejectsOtherCredentials(t *testing.T) {
+	if _, err := ParseServiceAccount([]byte(`{"type":"authorized_user","client_email":"a","private_key":"b"}`)); err == nil {
+		t.Error("expected error for non service-account credentials")
+	}
+	if _, err := ParseServiceAccount([]byte(`{"type":"service_account"}`)); err == nil {
+		t.Error("expected error for missing key material")
+	}
+}
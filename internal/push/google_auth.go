@@ -0,0 +1,167 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ============================================
+// GOOGLE SERVICE ACCOUNT AUTH
+// OAuth2 JWT-bearer flow (RFC 7523) used by the FCM HTTP v1 API
+// https://developers.google.com/identity/protocols/oauth2/service-account
+// ============================================
+
+const (
+	fcmScope        = "https://www.googleapis.com/auth/firebase.messaging"
+	googleTokenURL  = "https://oauth2.googleapis.com/token"
+	jwtBearerGrant  = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	assertionTTL    = time.Hour
+	tokenExpirySkew = time.Minute // Refresh a little before Google expires the token
+)
+
+// ServiceAccount holds the fields of a Google service-account JSON key file
+// that are needed to mint access tokens.
+type ServiceAccount struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// ParseServiceAccount parses a service-account JSON key.
+func ParseServiceAccount(data []byte) (*ServiceAccount, error) {
+	var sa ServiceAccount
+	if err := json.Unmarshal(data, &sa); err != nil {
+		return nil, fmt.Errorf("invalid service account JSON: %v", err)
+	}
+	if sa.Type != "" && sa.Type != "service_account" {
+		return nil, fmt.Errorf("credentials type %q is not a service account", sa.Type)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return nil, errors.New("service account is missing client_email or private_key")
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = googleTokenURL
+	}
+	return &sa, nil
+}
+
+// tokenSource mints OAuth2 access tokens from a service account and caches
+// them until shortly before they expire.
+type tokenSource struct {
+	account *ServiceAccount
+	signKey interface{}
+	scope   string
+	client  *http.Client
+	now     func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func newTokenSource(sa *ServiceAccount, scope string, client *http.Client) (*tokenSource, error) {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(sa.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid service account private key: %v", err)
+	}
+	return &tokenSource{
+		account: sa,
+		signKey: key,
+		scope:   scope,
+		client:  client,
+		now:     time.Now,
+	}, nil
+}
+
+// Token returns a cached access token, fetching a new one if needed.
+func (ts *tokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.token != "" && ts.now().Before(ts.expires) {
+		return ts.token, nil
+	}
+
+	token, expiresIn, err := ts.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	ts.token = token
+	ts.expires = ts.now().Add(expiresIn - tokenExpirySkew)
+	return ts.token, nil
+}
+
+// Invalidate drops the cached token, e.g. after the API rejected it.
+func (ts *tokenSource) Invalidate() {
+	ts.mu.Lock()
+	ts.token = ""
+	ts.mu.Unlock()
+}
+
+func (ts *tokenSource) fetch(ctx context.Context) (string, time.Duration, error) {
+	now := ts.now()
+	assertion := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   ts.account.ClientEmail,
+		"scope": ts.scope,
+		"aud":   ts.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(assertionTTL).Unix(),
+	})
+	if ts.account.PrivateKeyID != "" {
+		assertion.Header["kid"] = ts.account.PrivateKeyID
+	}
+	signed, err := assertion.SignedString(ts.signKey)
+	if err != nil {
+		return "", 0, fmt.Errorf("signing token assertion: %v", err)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", jwtBearerGrant)
+	form.Set("assertion", signed)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", ts.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := ts.client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("token request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return "", 0, fmt.Errorf("token endpoint error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		TokenType   string `json:"token_type"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", 0, fmt.Errorf("token response decode error: %v", err)
+	}
+	if result.AccessToken == "" {
+		return "", 0, errors.New("token endpoint returned no access_token")
+	}
+	if result.ExpiresIn <= 0 {
+		result.ExpiresIn = 3600
+	}
+	return result.AccessToken, time.Duration(result.ExpiresIn) * time.Second, nil
+}
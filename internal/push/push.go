@@ -2,11 +2,14 @@ package push
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -20,6 +23,10 @@ type PushService interface {
 	SendToTopic(topic string, title, body string, data map[string]string) error
 }
 
+// ErrUnregistered is returned (wrapped) when the provider reports that a device
+// token is no longer valid. Callers should stop sending to that token.
+var ErrUnregistered = errors.New("push: device token is no longer registered")
+
 // PushMessage represents a push notification message
 type PushMessage struct {
 	Title string            `json:"title"`
@@ -28,115 +35,276 @@ type PushMessage struct {
 }
 
 // ============================================
-// FIREBASE CLOUD MESSAGING (FCM) SERVICE
-// https://firebase.google.com/docs/cloud-messaging
+// FIREBASE CLOUD MESSAGING (FCM) HTTP v1 SERVICE
+// https://firebase.google.com/docs/reference/fcm/rest/v1/projects.messages
 // Free tier: Unlimited notifications
 // ============================================
 
-// FCMService implements PushService for Firebase Cloud Messaging
+// FCMService implements PushService using the FCM HTTP v1 API with
+// service-account OAuth2 credentials.
 type FCMService struct {
-	ServerKey  string // Legacy server key (deprecated but still works)
-	ProjectID  string
-	BaseURL    string
+	ProjectID string
+	BaseURL   string
+	Client    *http.Client
+
+	// Platform defaults, applied to messages that don't set their own
+	Android *AndroidConfig
+	APNS    *APNSConfig
+	Webpush *WebpushConfig
+
+	tokens *tokenSource
 }
 
-func NewFCMService(serverKey, projectID string) *FCMService {
+// NewFCMService creates an FCM v1 client from a service-account JSON key.
+// projectID overrides the project in the key when non-empty.
+func NewFCMService(credentialsJSON []byte, projectID string) (*FCMService, error) {
+	sa, err := ParseServiceAccount(credentialsJSON)
+	if err != nil {
+		return nil, err
+	}
+	if projectID == "" {
+		projectID = sa.ProjectID
+	}
+	if projectID == "" {
+		return nil, errors.New("FCM project ID not set")
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	ts, err := newTokenSource(sa, fcmScope, client)
+	if err != nil {
+		return nil, err
+	}
+
 	return &FCMService{
-		ServerKey: serverKey,
 		ProjectID: projectID,
-		BaseURL:   "https://fcm.googleapis.com/fcm/send",
-	}
+		BaseURL:   "https://fcm.googleapis.com/v1",
+		Client:    client,
+		Android: &AndroidConfig{
+			Priority:     "high",
+			Notification: &AndroidNotification{Icon: "notification_icon", Sound: "default"},
+		},
+		APNS: &APNSConfig{
+			Headers: map[string]string{"apns-priority": "10"},
+			Payload: map[string]interface{}{"aps": map[string]interface{}{"sound": "default"}},
+		},
+		tokens: ts,
+	}, nil
 }
 
-// FCM request payload structure
-type fcmRequest struct {
-	To           string            `json:"to,omitempty"`
+// FCMMessage is an FCM v1 message. Exactly one of Token, Topic or Condition must be set.
+type FCMMessage struct {
+	Token        string            `json:"token,omitempty"`
 	Topic        string            `json:"topic,omitempty"`
-	Notification *fcmNotification  `json:"notification"`
+	Condition    string            `json:"condition,omitempty"`
+	Notification *FCMNotification  `json:"notification,omitempty"`
 	Data         map[string]string `json:"data,omitempty"`
-	Priority     string            `json:"priority"`
+	Android      *AndroidConfig    `json:"android,omitempty"`
+	APNS         *APNSConfig       `json:"apns,omitempty"`
+	Webpush      *WebpushConfig    `json:"webpush,omitempty"`
 }
 
-type fcmNotification struct {
-	Title string `json:"title"`
-	Body  string `json:"body"`
-	Icon  string `json:"icon,omitempty"`
-	Sound string `json:"sound,omitempty"`
+type FCMNotification struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+	Image string `json:"image,omitempty"`
 }
 
-// SendToDevice sends a push notification to a specific device
-func (f *FCMService) SendToDevice(token string, title, body string, data map[string]string) error {
-	payload := fcmRequest{
-		To: token,
-		Notification: &fcmNotification{
-			Title: title,
-			Body:  body,
-			Icon:  "notification_icon",
-			Sound: "default",
-		},
-		Data:     data,
-		Priority: "high",
+// AndroidConfig holds Android-specific overrides.
+type AndroidConfig struct {
+	Priority     string               `json:"priority,omitempty"` // normal, high
+	TTL          string               `json:"ttl,omitempty"`      // Duration in seconds, e.g. "3600s"
+	CollapseKey  string               `json:"collapse_key,omitempty"`
+	Notification *AndroidNotification `json:"notification,omitempty"`
+}
+
+type AndroidNotification struct {
+	Icon        string `json:"icon,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Sound       string `json:"sound,omitempty"`
+	ChannelID   string `json:"channel_id,omitempty"`
+	ClickAction string `json:"click_action,omitempty"`
+}
+
+// APNSConfig holds Apple Push Notification service overrides. Payload is
+// sent as-is and usually contains an "aps" dictionary.
+type APNSConfig struct {
+	Headers map[string]string      `json:"headers,omitempty"`
+	Payload map[string]interface{} `json:"payload,omitempty"`
+}
+
+// WebpushConfig holds Web Push overrides for browsers using the FCM JS SDK.
+type WebpushConfig struct {
+	Headers      map[string]string      `json:"headers,omitempty"`
+	Data         map[string]string      `json:"data,omitempty"`
+	Notification map[string]interface{} `json:"notification,omitempty"`
+	FCMOptions   *WebpushFCMOptions     `json:"fcm_options,omitempty"`
+}
+
+type WebpushFCMOptions struct {
+	Link string `json:"link,omitempty"`
+}
+
+// Retryable FCM errors are retried fcmRetries times, doubling the delay each time.
+const fcmRetries = 2
+
+var fcmRetryDelay = 500 * time.Millisecond
+
+// FCMError is a structured error returned by the FCM v1 API.
+// https://firebase.google.com/docs/reference/fcm/rest/v1/ErrorCode
+type FCMError struct {
+	HTTPStatus int
+	Status     string // Google API status, e.g. NOT_FOUND
+	Code       string // FCM error code, e.g. UNREGISTERED
+	Message    string
+}
+
+func (e *FCMError) Error() string {
+	code := e.Code
+	if code == "" {
+		code = e.Status
+	}
+	return fmt.Sprintf("FCM API error: %s (%s, status %d)", e.Message, code, e.HTTPStatus)
+}
+
+// Unwrap maps token-level failures to ErrUnregistered.
+func (e *FCMError) Unwrap() error {
+	if e.Code == "UNREGISTERED" {
+		return ErrUnregistered
+	}
+	return nil
+}
+
+// Retryable reports whether the send may succeed if retried later.
+func (e *FCMError) Retryable() bool {
+	switch e.Code {
+	case "UNAVAILABLE", "INTERNAL", "QUOTA_EXCEEDED":
+		return true
 	}
+	return e.HTTPStatus == 429 || e.HTTPStatus >= 500
+}
 
-	return f.send(payload)
+// SendToDevice sends a push notification to a specific device
+func (f *FCMService) SendToDevice(token string, title, body string, data map[string]string) error {
+	_, err := f.Send(context.Background(), &FCMMessage{
+		Token:        token,
+		Notification: &FCMNotification{Title: title, Body: body},
+		Data:         data,
+	})
+	return err
 }
 
 // SendToTopic sends a push notification to all subscribers of a topic
 func (f *FCMService) SendToTopic(topic string, title, body string, data map[string]string) error {
-	payload := fcmRequest{
-		To: "/topics/" + topic,
-		Notification: &fcmNotification{
-			Title: title,
-			Body:  body,
-			Icon:  "notification_icon",
-			Sound: "default",
-		},
-		Data:     data,
-		Priority: "high",
+	_, err := f.Send(context.Background(), &FCMMessage{
+		Topic:        topic,
+		Notification: &FCMNotification{Title: title, Body: body},
+		Data:         data,
+	})
+	return err
+}
+
+// Send delivers a message, filling in the platform defaults, and returns the
+// message name assigned by FCM.
+func (f *FCMService) Send(ctx context.Context, msg *FCMMessage) (string, error) {
+	m := *msg
+	if m.Android == nil {
+		m.Android = f.Android
+	}
+	if m.APNS == nil {
+		m.APNS = f.APNS
+	}
+	if m.Webpush == nil {
+		m.Webpush = f.Webpush
+	}
+
+	jsonData, err := json.Marshal(struct {
+		Message *FCMMessage `json:"message"`
+	}{&m})
+	if err != nil {
+		return "", err
 	}
 
-	return f.send(payload)
+	name, err := f.post(ctx, jsonData)
+	var fcmErr *FCMError
+	if errors.As(err, &fcmErr) && fcmErr.HTTPStatus == http.StatusUnauthorized {
+		// The cached token may have been revoked; mint a new one and retry once
+		f.tokens.Invalidate()
+		name, err = f.post(ctx, jsonData)
+	}
+	// Ride out brief FCM outages and rate limiting before giving up
+	for attempt := 1; attempt <= fcmRetries && errors.As(err, &fcmErr) && fcmErr.Retryable(); attempt++ {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(fcmRetryDelay << (attempt - 1)):
+		}
+		name, err = f.post(ctx, jsonData)
+	}
+	return name, err
 }
 
-func (f *FCMService) send(payload fcmRequest) error {
-	jsonData, err := json.Marshal(payload)
+func (f *FCMService) post(ctx context.Context, jsonData []byte) (string, error) {
+	accessToken, err := f.tokens.Token(ctx)
 	if err != nil {
-		return err
+		return "", fmt.Errorf("FCM auth failed: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", f.BaseURL, bytes.NewBuffer(jsonData))
+	url := fmt.Sprintf("%s/projects/%s/messages:send", f.BaseURL, f.ProjectID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return err
+		return "", err
 	}
-
-	req.Header.Set("Authorization", "key="+f.ServerKey)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := f.Client.Do(req)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("FCM API error: %s", string(body))
+		return "", parseFCMError(resp)
 	}
 
 	var result struct {
-		Success int `json:"success"`
-		Failure int `json:"failure"`
+		Name string `json:"name"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return err
+		return "", err
 	}
+	return result.Name, nil
+}
 
-	if result.Failure > 0 {
-		return fmt.Errorf("FCM: %d messages failed", result.Failure)
+// parseFCMError extracts the FCM error code from a google.rpc.Status body.
+func parseFCMError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+			Details []struct {
+				Type      string `json:"@type"`
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	fcmErr := &FCMError{HTTPStatus: resp.StatusCode}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		fcmErr.Message = string(body)
+		return fcmErr
 	}
 
-	return nil
+	fcmErr.Status = payload.Error.Status
+	fcmErr.Message = payload.Error.Message
+	for _, d := range payload.Error.Details {
+		if strings.HasSuffix(d.Type, "google.firebase.fcm.v1.FcmError") && d.ErrorCode != "" {
+			fcmErr.Code = d.ErrorCode
+			break
+		}
+	}
+	return fcmErr
 }
 
 // ============================================
@@ -178,19 +346,26 @@ func (m *MockPushService) SendToTopic(topic string, title, body string, data map
 // NOTIFICATION HELPERS
 // ============================================
 
-// Common notification types for JainFood app
+// TokenStore looks up and prunes the device tokens registered by users.
+type TokenStore interface {
+	TokensForUser(ctx context.Context, userID string) ([]string, error)
+	RemoveTokens(ctx context.Context, tokens []string) error
+}
+
+// NotificationHelper sends common JainFood notifications to every device of
+// the recipient user, pruning tokens the provider reports as unregistered.
 type NotificationHelper struct {
-	push PushService
+	push   PushService
+	tokens TokenStore
 }
 
-func NewNotificationHelper(push PushService) *NotificationHelper {
-	return &NotificationHelper{push: push}
+func NewNotificationHelper(push PushService, tokens TokenStore) *NotificationHelper {
+	return &NotificationHelper{push: push, tokens: tokens}
 }
 
-// NotifyNewOrder sends notification when a new order is placed
-func (n *NotificationHelper) NotifyNewOrder(providerToken string, orderID, buyerName string, total float64) error {
-	return n.push.SendToDevice(
-		providerToken,
+// NotifyNewOrder sends notification to the provider when a new order is placed
+func (n *NotificationHelper) NotifyNewOrder(ctx context.Context, providerUserID string, orderID, buyerName string, total float64) error {
+	return n.sendToUser(ctx, providerUserID,
 		"🆕 New Order Received!",
 		fmt.Sprintf("Order from %s - ₹%.2f", buyerName, total),
 		map[string]string{
@@ -200,10 +375,9 @@ func (n *NotificationHelper) NotifyNewOrder(providerToken string, orderID, buyer
 	)
 }
 
-// NotifyOrderConfirmed sends notification when order is confirmed
-func (n *NotificationHelper) NotifyOrderConfirmed(buyerToken string, orderID, providerName string) error {
-	return n.push.SendToDevice(
-		buyerToken,
+// NotifyOrderConfirmed sends notification to the buyer when order is confirmed
+func (n *NotificationHelper) NotifyOrderConfirmed(ctx context.Context, buyerUserID string, orderID, providerName string) error {
+	return n.sendToUser(ctx, buyerUserID,
 		"✅ Order Confirmed!",
 		fmt.Sprintf("Your order from %s has been confirmed", providerName),
 		map[string]string{
@@ -213,10 +387,9 @@ func (n *NotificationHelper) NotifyOrderConfirmed(buyerToken string, orderID, pr
 	)
 }
 
-// NotifyOrderReady sends notification when order is ready
-func (n *NotificationHelper) NotifyOrderReady(buyerToken string, orderID, providerName string) error {
-	return n.push.SendToDevice(
-		buyerToken,
+// NotifyOrderReady sends notification to the buyer when order is ready
+func (n *NotificationHelper) NotifyOrderReady(ctx context.Context, buyerUserID string, orderID, providerName string) error {
+	return n.sendToUser(ctx, buyerUserID,
 		"🍽️ Order Ready!",
 		fmt.Sprintf("Your order from %s is ready for pickup/delivery", providerName),
 		map[string]string{
@@ -227,9 +400,8 @@ func (n *NotificationHelper) NotifyOrderReady(buyerToken string, orderID, provid
 }
 
 // NotifyNewMessage sends notification for chat messages
-func (n *NotificationHelper) NotifyNewMessage(token string, senderName, message string, chatID string) error {
-	return n.push.SendToDevice(
-		token,
+func (n *NotificationHelper) NotifyNewMessage(ctx context.Context, recipientUserID string, senderName, message string, chatID string) error {
+	return n.sendToUser(ctx, recipientUserID,
 		fmt.Sprintf("💬 Message from %s", senderName),
 		message,
 		map[string]string{
@@ -239,24 +411,188 @@ func (n *NotificationHelper) NotifyNewMessage(token string, senderName, message
 	)
 }
 
+// sendToUser delivers to all of the user's devices. Unregistered tokens are
+// removed; other failures are returned once every device has been tried.
+func (n *NotificationHelper) sendToUser(ctx context.Context, userID, title, body string, data map[string]string) error {
+	tokens, err := n.tokens.TokensForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var stale []string
+	var errs []error
+	for _, token := range tokens {
+		err := n.push.SendToDevice(token, title, body, data)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrUnregistered):
+			stale = append(stale, token)
+		default:
+			errs = append(errs, err)
+		}
+	}
+
+	if len(stale) > 0 {
+		if err := n.tokens.RemoveTokens(ctx, stale); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ============================================
+// TOPIC FAN-OUT
+// ============================================
+
+// TopicStore resolves the users subscribed to a topic.
+type TopicStore interface {
+	TopicSubscribers(ctx context.Context, topic string) ([]string, error)
+}
+
+// BroadcastResult counts the outcome of a topic broadcast.
+type BroadcastResult struct {
+	Recipients int `json:"recipients"` // Users with at least one device attempted
+	Skipped    int `json:"skipped"`    // Users filtered out by the allow check
+	Failed     int `json:"failed"`     // Users whose delivery returned an error
+}
+
+// TopicFanout implements topics on top of per-device delivery. Subscribers
+// are resolved from our own store so per-user rules (frequency caps, blocks)
+// can be applied, which native FCM topics cannot do, and so Web Push
+// subscribers receive topic messages too.
+type TopicFanout struct {
+	Helper *NotificationHelper
+	Topics TopicStore
+	Allow  func(ctx context.Context, userID string) bool // Optional per-user gate for SendToTopic
+}
+
+func (f *TopicFanout) SendToDevice(token string, title, body string, data map[string]string) error {
+	return f.Helper.push.SendToDevice(token, title, body, data)
+}
+
+func (f *TopicFanout) SendToTopic(topic string, title, body string, data map[string]string) error {
+	_, err := f.Broadcast(context.Background(), topic, title, body, data, f.Allow)
+	return err
+}
+
+// Broadcast delivers to every subscriber of topic that allow accepts (all
+// subscribers if allow is nil).
+func (f *TopicFanout) Broadcast(ctx context.Context, topic, title, body string, data map[string]string,
+	allow func(ctx context.Context, userID string) bool) (*BroadcastResult, error) {
+	userIDs, err := f.Topics.TopicSubscribers(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BroadcastResult{}
+	var errs []error
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		if allow != nil && !allow(ctx, userID) {
+			result.Skipped++
+			continue
+		}
+		result.Recipients++
+		if err := f.Helper.sendToUser(ctx, userID, title, body, data); err != nil {
+			result.Failed++
+			errs = append(errs, err)
+		}
+	}
+
+	// Individual device failures don't fail the broadcast unless nobody got it
+	if result.Recipients > 0 && result.Failed == result.Recipients {
+		return result, errors.Join(errs...)
+	}
+	return result, nil
+}
+
 // ============================================
 // FACTORY FUNCTION
 // ============================================
 
-// NewPushService creates the appropriate push service based on environment
-func NewPushService() PushService {
-	serverKey := os.Getenv("FCM_SERVER_KEY")
-	projectID := os.Getenv("FCM_PROJECT_ID")
+// NewPushService creates the appropriate push service based on environment.
+//
+// PUSH_PROVIDER selects the backend: "fcm", "webpush", "mock", or "auto"
+// (default) which uses every configured backend, routing Web Push endpoints
+// to Web Push and native tokens to FCM.
+//
+// FCM is configured with service-account credentials either inline
+// (FCM_CREDENTIALS_JSON) or as a file (FCM_CREDENTIALS_FILE, falling back to
+// GOOGLE_APPLICATION_CREDENTIALS). Web Push needs VAPID_PUBLIC_KEY,
+// VAPID_PRIVATE_KEY and VAPID_SUBJECT; subscriptions are looked up in subs.
+func NewPushService(subs SubscriptionStore) PushService {
+	provider := strings.ToLower(os.Getenv("PUSH_PROVIDER"))
+
+	var fcm, web PushService
+	if provider != "mock" && provider != "webpush" {
+		fcm = newFCMFromEnv()
+	}
+	if provider != "mock" && provider != "fcm" {
+		web = newWebPushFromEnv(subs)
+	}
 
-	if serverKey != "" {
-		return NewFCMService(serverKey, projectID)
+	switch {
+	case fcm != nil && web != nil:
+		return &PlatformRouter{Native: fcm, Web: web}
+	case fcm != nil:
+		return fcm
+	case web != nil:
+		// Native tokens still go to the mock so nothing fails in development
+		return &PlatformRouter{Native: NewMockPushService(), Web: web}
 	}
 
 	// Default to mock for development
-	fmt.Println("⚠️  Using mock push service (FCM not configured)")
+	fmt.Println("⚠️  Using mock push service (FCM/Web Push not configured)")
 	return NewMockPushService()
 }
 
+func newFCMFromEnv() PushService {
+	credentials, err := loadFCMCredentials()
+	if err != nil {
+		fmt.Printf("⚠️  FCM credentials unreadable: %v\n", err)
+		return nil
+	}
+	if len(credentials) == 0 {
+		return nil
+	}
+	fcm, err := NewFCMService(credentials, os.Getenv("FCM_PROJECT_ID"))
+	if err != nil {
+		fmt.Printf("⚠️  FCM not available: %v\n", err)
+		return nil
+	}
+	return fcm
+}
+
+func newWebPushFromEnv(subs SubscriptionStore) PushService {
+	publicKey := os.Getenv("VAPID_PUBLIC_KEY")
+	privateKey := os.Getenv("VAPID_PRIVATE_KEY")
+	if publicKey == "" || privateKey == "" || subs == nil {
+		return nil
+	}
+	web, err := NewWebPushService(publicKey, privateKey, os.Getenv("VAPID_SUBJECT"), subs)
+	if err != nil {
+		fmt.Printf("⚠️  Web Push not available: %v\n", err)
+		return nil
+	}
+	return web
+}
+
+func loadFCMCredentials() ([]byte, error) {
+	if inline := os.Getenv("FCM_CREDENTIALS_JSON"); inline != "" {
+		return []byte(inline), nil
+	}
+	path := os.Getenv("FCM_CREDENTIALS_FILE")
+	if path == "" {
+		path = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if path == "" {
+		return nil, nil
+	}
+	return os.ReadFile(path)
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
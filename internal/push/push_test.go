@@ -0,0 +1,123 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type fakePush struct {
+	sent   []string
+	failed map[string]error
+}
+
+func (f *fakePush) SendToDevice(token string, title, body string, data map[string]string) error {
+	if err, ok := f.failed[token]; ok {
+		return err
+	}
+	f.sent = append(f.sent, token)
+	return nil
+}
+
+func (f *fakePush) SendToTopic(topic string, title, body string, data map[string]string) error {
+	return nil
+}
+
+type fakeTokens struct {
+	byUser  map[string][]string
+	removed []string
+}
+
+func (f *fakeTokens) TokensForUser(ctx context.Context, userID string) ([]string, error) {
+	return f.byUser[userID], nil
+}
+
+func (f *fakeTokens) RemoveTokens(ctx context.Context, tokens []string) error {
+	f.removed = append(f.removed, tokens...)
+	return nil
+}
+
+func TestSendToUserFansOut(t *testing.T) {
+	p := &fakePush{}
+	store := &fakeTokens{byUser: map[string][]string{"u1": {"a", "b", "c"}}}
+	h := NewNotificationHelper(p, store)
+
+	if err := h.NotifyOrderReady(context.Background(), "u1", "order-1", "Shanti Bhojanalay"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(p.sent) != 3 {
+		t.Errorf("expected 3 sends, got %d", len(p.sent))
+	}
+	if len(store.removed) != 0 {
+		t.Errorf("expected no tokens removed, got %v", store.removed)
+	}
+}
+
+func TestSendToUserPrunesUnregistered(t *testing.T) {
+	p := &fakePush{failed: map[string]error{
+		"stale": fmt.Errorf("FCM: NotRegistered: %w", ErrUnregistered),
+	}}
+	store := &fakeTokens{byUser: map[string][]string{"u1": {"good", "stale"}}}
+	h := NewNotificationHelper(p, store)
+
+	if err := h.NotifyNewMessage(context.Background(), "u1", "Asha", "hello", "chat-1"); err != nil {
+		t.Fatalf("unregistered tokens should not be reported as errors: %v", err)
+	}
+	if len(store.removed) != 1 || store.removed[0] != "stale" {
+		t.Errorf("expected stale token removed, got %v", store.removed)
+	}
+	if len(p.sent) != 1 || p.sent[0] != "good" {
+		t.Errorf("expected delivery to good token, got %v", p.sent)
+	}
+}
+
+func TestSendToUserReturnsOtherErrors(t *testing.T) {
+	boom := errors.New("boom")
+	p := &fakePush{failed: map[string]error{"a": boom}}
+	store := &fakeTokens{byUser: map[string][]string{"u1": {"a", "b"}}}
+	h := NewNotificationHelper(p, store)
+
+	err := h.NotifyNewOrder(context.Background(), "u1", "order-1", "Asha", 120)
+	if !errors.Is(err, boom) {
+		t.Errorf("expected boom error, got %v", err)
+	}
+	if len(p.sent) != 1 {
+		t.Errorf("remaining devices should still be tried, got %v", p.sent)
+	}
+}
+
+type fakeTopics map[string][]string
+
+func (f fakeTopics) TopicSubscribers(ctx context.Context, topic string) ([]string, error) {
+	return f[topic], nil
+}
+
+func TestTopicFanoutBroadcast(t *testing.T) {
+	p := &fakePush{}
+	store := &fakeTokens{byUser: map[string][]string{
+		"u1": {"u1-phone", "u1-web"},
+		"u2": {"u2-phone"},
+		"u3": {"u3-phone"},
+	}}
+	fanout := &TopicFanout{
+		Helper: NewNotificationHelper(p, store),
+		Topics: fakeTopics{"city-indore": {"u1", "u2", "u3"}},
+	}
+
+	capped := func(ctx context.Context, userID string) bool { return userID != "u2" }
+	result, err := fanout.Broadcast(context.Background(), "city-indore", "New tiffin center", "Verified pure Jain", nil, capped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Recipients != 2 || result.Skipped != 1 || result.Failed != 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if len(p.sent) != 3 {
+		t.Errorf("expected 3 device sends, got %v", p.sent)
+	}
+
+	if err := fanout.SendToTopic("city-pune", "t", "b", nil); err != nil {
+		t.Errorf("empty topic should not fail: %v", err)
+	}
+}
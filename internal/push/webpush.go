@@ -0,0 +1,391 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ============================================
+// WEB PUSH (RFC 8030) WITH VAPID (RFC 8292)
+// Payloads are encrypted with aes128gcm (RFC 8291), so browsers can receive
+// notifications without going through FCM.
+// ============================================
+
+const (
+	webPushRecordSize = 4096
+	webPushMaxBody    = 4096 // Push services must accept at least this much
+	vapidTokenTTL     = 12 * time.Hour
+)
+
+// ErrTopicsUnsupported is returned by services that cannot broadcast to topics.
+var ErrTopicsUnsupported = errors.New("push: topics are not supported by this service")
+
+// WebPushSubscription is a browser PushSubscription as returned by
+// PushSubscription.toJSON().
+type WebPushSubscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// SubscriptionStore looks up the encryption keys for a Web Push endpoint.
+// It returns (nil, nil) if the endpoint is unknown.
+type SubscriptionStore interface {
+	WebPushSubscription(ctx context.Context, endpoint string) (*WebPushSubscription, error)
+}
+
+// WebPushService implements PushService for browser Web Push. Device tokens
+// are subscription endpoint URLs.
+type WebPushService struct {
+	Subject       string // mailto: or https: contact for the push service operator
+	TTL           int    // Seconds the push service should keep undelivered messages
+	Subscriptions SubscriptionStore
+	Client        *http.Client
+
+	publicKey  []byte // Uncompressed P-256 point
+	privateKey *ecdsa.PrivateKey
+}
+
+// NewWebPushService creates a Web Push sender from base64url-encoded VAPID keys.
+func NewWebPushService(publicKey, privateKey, subject string, subs SubscriptionStore) (*WebPushService, error) {
+	pub, err := decodeBase64URL(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID public key: %v", err)
+	}
+	priv, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %v", err)
+	}
+	key, err := vapidSigningKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(pub, vapidPublicBytes(key)) {
+		return nil, errors.New("VAPID public key does not match private key")
+	}
+	if subject == "" {
+		return nil, errors.New("VAPID subject (mailto: or https: URL) is required")
+	}
+
+	return &WebPushService{
+		Subject:       subject,
+		TTL:           24 * 60 * 60,
+		Subscriptions: subs,
+		Client:        &http.Client{Timeout: 30 * time.Second},
+		publicKey:     pub,
+		privateKey:    key,
+	}, nil
+}
+
+// PublicKey returns the base64url application server key browsers pass to
+// pushManager.subscribe().
+func (w *WebPushService) PublicKey() string {
+	return base64.RawURLEncoding.EncodeToString(w.publicKey)
+}
+
+// GenerateVAPIDKeys creates a new base64url-encoded VAPID key pair.
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// SendToDevice encrypts the notification for the subscription registered
+// under token (its endpoint) and delivers it to the push service.
+func (w *WebPushService) SendToDevice(token string, title, body string, data map[string]string) error {
+	ctx := context.Background()
+	sub, err := w.Subscriptions.WebPushSubscription(ctx, token)
+	if err != nil {
+		return err
+	}
+	if sub == nil {
+		return fmt.Errorf("web push: no subscription for endpoint: %w", ErrUnregistered)
+	}
+
+	payload, err := json.Marshal(PushMessage{Title: title, Body: body, Data: data})
+	if err != nil {
+		return err
+	}
+	return w.Send(ctx, sub, payload)
+}
+
+// SendToTopic is not supported; Web Push has no server-side topics.
+func (w *WebPushService) SendToTopic(topic string, title, body string, data map[string]string) error {
+	return ErrTopicsUnsupported
+}
+
+// Send delivers an encrypted payload to a single subscription.
+func (w *WebPushService) Send(ctx context.Context, sub *WebPushSubscription, payload []byte) error {
+	uaPublic, err := decodeBase64URL(sub.Keys.P256dh)
+	if err != nil {
+		return fmt.Errorf("web push: invalid p256dh key: %v", err)
+	}
+	authSecret, err := decodeBase64URL(sub.Keys.Auth)
+	if err != nil {
+		return fmt.Errorf("web push: invalid auth secret: %v", err)
+	}
+
+	body, err := EncryptWebPush(payload, uaPublic, authSecret)
+	if err != nil {
+		return err
+	}
+
+	authorization, err := w.vapidAuthorization(sub.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(w.TTL))
+	req.Header.Set("Urgency", "high")
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("web push request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return fmt.Errorf("web push: subscription expired (status %d): %w", resp.StatusCode, ErrUnregistered)
+	case resp.StatusCode >= 400:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("web push error (status %d): %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// vapidAuthorization builds the RFC 8292 "vapid" Authorization header for
+// the push service that owns endpoint.
+func (w *WebPushService) vapidAuthorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("web push: invalid endpoint %q", endpoint)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(vapidTokenTTL).Unix(),
+		"sub": w.Subject,
+	})
+	signed, err := token.SignedString(w.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("web push: signing VAPID token: %v", err)
+	}
+	return fmt.Sprintf("vapid t=%s, k=%s", signed, w.PublicKey()), nil
+}
+
+// ============================================
+// RFC 8291 MESSAGE ENCRYPTION
+// ============================================
+
+// EncryptWebPush encrypts plaintext for a user agent's public key and auth
+// secret using a fresh ephemeral key and salt.
+func EncryptWebPush(plaintext, uaPublic, authSecret []byte) ([]byte, error) {
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encryptWebPush(plaintext, uaPublic, authSecret, asPrivate, salt)
+}
+
+// encryptWebPush produces an aes128gcm body (RFC 8188) holding a single
+// record, with keys derived as in RFC 8291 section 3.4.
+func encryptWebPush(plaintext, uaPublic, authSecret []byte, asPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	uaKey, err := ecdh.P256().NewPublicKey(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("web push: invalid user agent public key: %v", err)
+	}
+	ecdhSecret, err := asPrivate.ECDH(uaKey)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	cek, nonce, err := deriveWebPushKeys(ecdhSecret, authSecret, salt, uaPublic, asPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: salt (16) | record size (4) | key id length (1) | key id (sender public key)
+	header := make([]byte, 0, 21+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	// A single, final record: plaintext followed by the 0x02 delimiter
+	record := append(append([]byte{}, plaintext...), 0x02)
+	if len(header)+len(record)+gcm.Overhead() > webPushMaxBody {
+		return nil, fmt.Errorf("web push: payload too large (%d bytes)", len(plaintext))
+	}
+	return gcm.Seal(header, nonce, record, nil), nil
+}
+
+func deriveWebPushKeys(ecdhSecret, authSecret, salt, uaPublic, asPublic []byte) (cek, nonce []byte, err error) {
+	prkKey, err := hkdf.Extract(sha256.New, ecdhSecret, authSecret)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyInfo := "WebPush: info\x00" + string(uaPublic) + string(asPublic)
+	ikm, err := hkdf.Expand(sha256.New, prkKey, keyInfo, 32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	cek, err = hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce, err = hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, nonce, nil
+}
+
+// ============================================
+// KEY HELPERS
+// ============================================
+
+// vapidSigningKey converts a raw 32-byte P-256 scalar into an ECDSA key.
+func vapidSigningKey(raw []byte) (*ecdsa.PrivateKey, error) {
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %v", err)
+	}
+	pub := key.PublicKey().Bytes() // 0x04 | X | Y
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(pub[1:33]),
+			Y:     new(big.Int).SetBytes(pub[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}, nil
+}
+
+func vapidPublicBytes(key *ecdsa.PrivateKey) []byte {
+	pub := make([]byte, 65)
+	pub[0] = 0x04
+	key.X.FillBytes(pub[1:33])
+	key.Y.FillBytes(pub[33:])
+	return pub
+}
+
+// decodeBase64URL accepts base64url with or without padding, as browsers and
+// key generators differ.
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// ============================================
+// PLATFORM ROUTER
+// ============================================
+
+// IsWebPushEndpoint reports whether a device token is a Web Push endpoint
+// URL rather than a native FCM registration token.
+func IsWebPushEndpoint(token string) bool {
+	return strings.HasPrefix(token, "https://")
+}
+
+// webPushHosts are the push services browsers issue subscription endpoints
+// on: FCM (Chrome), Mozilla autopush, Apple and WNS (Edge). Subdomains match.
+var webPushHosts = []string{
+	"fcm.googleapis.com",
+	"push.services.mozilla.com",
+	"push.apple.com",
+	"notify.windows.com",
+}
+
+// ValidWebPushEndpoint reports whether endpoint is an https URL on a known
+// push service. The server POSTs to stored endpoints, so IP literals and any
+// other host (internal or not) are refused.
+func ValidWebPushEndpoint(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return false
+	}
+	if port := u.Port(); port != "" && port != "443" {
+		return false
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if net.ParseIP(host) != nil {
+		return false
+	}
+	for _, h := range webPushHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// PlatformRouter sends Web Push endpoints through Web and every other token
+// through Native. Topics are always handled by Native.
+type PlatformRouter struct {
+	Native PushService
+	Web    PushService
+}
+
+func (r *PlatformRouter) SendToDevice(token string, title, body string, data map[string]string) error {
+	if IsWebPushEndpoint(token) {
+		return r.Web.SendToDevice(token, title, body, data)
+	}
+	return r.Native.SendToDevice(token, title, body, data)
+}
+
+func (r *PlatformRouter) SendToTopic(topic string, title, body string, data map[string]string) error {
+	return r.Native.SendToTopic(topic, title, body, data)
+}
@@ -0,0 +1,222 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdh"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Test vectors from RFC 8291 Appendix A.
+const (
+	rfcPlaintext  = "When I grow up, I want to be a watermelon"
+	rfcASPrivate  = "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"
+	rfcASPublic   = "BP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A8"
+	rfcUAPublic   = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	rfcAuthSecret = "BTBZMqHH6r4Tts7J_aSIgg"
+	rfcSalt       = "DGv6ra1nlYgDCS1FRnbzlw"
+	rfcECDH       = "kyrL1jIIOHEzg3sM2ZWRHDRB62YACZhhSlknJ672kSs"
+	rfcCEK        = "oIhVW04MRdy2XN9CiKLxTg"
+	rfcNonce      = "4h_95klXJ5E_qnoN"
+	rfcBody       = "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+)
+
+func mustB64(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := decodeBase64URL(s)
+	if err != nil {
+		t.Fatalf("decode %q: %v", s, err)
+	}
+	return b
+}
+
+func TestWebPushKeyDerivationRFC8291(t *testing.T) {
+	asPrivate, err := ecdh.P256().NewPrivateKey(mustB64(t, rfcASPrivate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uaKey, err := ecdh.P256().NewPublicKey(mustB64(t, rfcUAPublic))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := asPrivate.ECDH(uaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := base64.RawURLEncoding.EncodeToString(secret); got != rfcECDH {
+		t.Fatalf("ecdh_secret = %s, want %s", got, rfcECDH)
+	}
+
+	cek, nonce, err := deriveWebPushKeys(secret, mustB64(t, rfcAuthSecret), mustB64(t, rfcSalt),
+		mustB64(t, rfcUAPublic), mustB64(t, rfcASPublic))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := base64.RawURLEncoding.EncodeToString(cek); got != rfcCEK {
+		t.Errorf("CEK = %s, want %s", got, rfcCEK)
+	}
+	if got := base64.RawURLEncoding.EncodeToString(nonce); got != rfcNonce {
+		t.Errorf("NONCE = %s, want %s", got, rfcNonce)
+	}
+}
+
+func TestWebPushEncryptRFC8291(t *testing.T) {
+	asPrivate, err := ecdh.P256().NewPrivateKey(mustB64(t, rfcASPrivate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := encryptWebPush([]byte(rfcPlaintext), mustB64(t, rfcUAPublic), mustB64(t, rfcAuthSecret),
+		asPrivate, mustB64(t, rfcSalt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := base64.RawURLEncoding.EncodeToString(body); got != rfcBody {
+		t.Errorf("encrypted body mismatch\n got: %s\nwant: %s", got, rfcBody)
+	}
+}
+
+func TestWebPushRejectsOversizedPayload(t *testing.T) {
+	_, err := EncryptWebPush(make([]byte, 4096), mustB64(t, rfcUAPublic), mustB64(t, rfcAuthSecret))
+	if err == nil {
+		t.Error("expected error for payload larger than one record")
+	}
+}
+
+type fakeSubscriptions map[string]*WebPushSubscription
+
+func (f fakeSubscriptions) WebPushSubscription(ctx context.Context, endpoint string) (*WebPushSubscription, error) {
+	return f[endpoint], nil
+}
+
+func newTestWebPush(t *testing.T, subs SubscriptionStore) *WebPushService {
+	pub, priv, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWebPushService(pub, priv, "mailto:ops@jainfood.app", subs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestWebPushSendToDevice(t *testing.T) {
+	var gotAuth, gotEncoding, gotTTL string
+	var gotLen int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotEncoding = r.Header.Get("Content-Encoding")
+		gotTTL = r.Header.Get("TTL")
+		buf := make([]byte, 8192)
+		n, _ := r.Body.Read(buf)
+		gotLen = n
+		if strings.HasSuffix(r.URL.Path, "/gone") {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	sub := &WebPushSubscription{Endpoint: server.URL + "/push/abc"}
+	sub.Keys.P256dh = rfcUAPublic
+	sub.Keys.Auth = rfcAuthSecret
+	gone := &WebPushSubscription{Endpoint: server.URL + "/push/gone", Keys: sub.Keys}
+
+	w := newTestWebPush(t, fakeSubscriptions{sub.Endpoint: sub, gone.Endpoint: gone})
+	w.Client = server.Client()
+
+	if err := w.SendToDevice(sub.Endpoint, "Paryushan menu", "New items", nil); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if gotEncoding != "aes128gcm" || gotTTL == "" || gotLen == 0 {
+		t.Errorf("unexpected request: encoding=%q ttl=%q len=%d", gotEncoding, gotTTL, gotLen)
+	}
+
+	// The VAPID JWT must be signed by our key and scoped to the push service origin
+	if !strings.HasPrefix(gotAuth, "vapid t=") || !strings.Contains(gotAuth, ", k="+w.PublicKey()) {
+		t.Fatalf("unexpected Authorization header: %q", gotAuth)
+	}
+	tokenStr := strings.TrimSuffix(strings.TrimPrefix(gotAuth, "vapid t="), ", k="+w.PublicKey())
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenStr, claims, func(*jwt.Token) (interface{}, error) {
+		return &w.privateKey.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience(server.URL))
+	if err != nil {
+		t.Fatalf("VAPID token invalid: %v", err)
+	}
+	if claims["sub"] != "mailto:ops@jainfood.app" {
+		t.Errorf("unexpected sub claim %v", claims["sub"])
+	}
+
+	if err := w.SendToDevice(gone.Endpoint, "t", "b", nil); !errors.Is(err, ErrUnregistered) {
+		t.Errorf("expected ErrUnregistered for 410, got %v", err)
+	}
+	if err := w.SendToDevice("https://push.example.com/unknown", "t", "b", nil); !errors.Is(err, ErrUnregistered) {
+		t.Errorf("expected ErrUnregistered for unknown subscription, got %v", err)
+	}
+}
+
+func TestNewWebPushServiceRejectsMismatchedKeys(t *testing.T) {
+	pub, _, _ := GenerateVAPIDKeys()
+	_, priv, _ := GenerateVAPIDKeys()
+	if _, err := NewWebPushService(pub, priv, "mailto:ops@jainfood.app", fakeSubscriptions{}); err == nil {
+		t.Error("expected error for mismatched VAPID keys")
+	}
+}
+
+type recordingPush struct{ tokens []string }
+
+func (r *recordingPush) SendToDevice(token string, title, body string, data map[string]string) error {
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *recordingPush) SendToTopic(topic string, title, body string, data map[string]string) error {
+	r.tokens = append(r.tokens, "topic:"+topic)
+	return nil
+}
+
+func TestPlatformRouter(t *testing.T) {
+	native, web := &recordingPush{}, &recordingPush{}
+	r := &PlatformRouter{Native: native, Web: web}
+
+	_ = r.SendToDevice("https://fcm.googleapis.com/fcm/send/abc", "t", "b", nil)
+	_ = r.SendToDevice("dGVzdC1mY20tdG9rZW4", "t", "b", nil)
+	_ = r.SendToTopic("city-indore", "t", "b", nil)
+
+	if len(web.tokens) != 1 || len(native.tokens) != 2 {
+		t.Errorf("unexpected routing: web=%v native=%v", web.tokens, native.tokens)
+	}
+}
+
+func TestValidWebPushEndpoint(t *testing.T) {
+	for endpoint, want := range map[string]bool{
+		"https://fcm.googleapis.com/fcm/send/abc":                true,
+		"https://updates.push.services.mozilla.com/wpush/v2/abc": true,
+		"https://web.push.apple.com/QGuQyavXutnMH":               true,
+		"https://wns2-par02p.notify.windows.com/w/?token=abc":    true,
+		"https://FCM.googleapis.com./fcm/send/abc":               true,
+		"http://fcm.googleapis.com/fcm/send/abc":                 false,
+		"https://fcm.googleapis.com:8443/fcm/send/abc":           false,
+		"https://user@fcm.googleapis.com/fcm/send/abc":           false,
+		"https://evilfcm.googleapis.com.attacker.net/push":       false,
+		"https://notfcm.googleapis.com.evil/push":                false,
+		"https://127.0.0.1/push":                                 false,
+		"https://[::1]/push":                                     false,
+		"https://169.254.169.254/latest/meta-data":               false,
+		"https://localhost/push":                                 false,
+		"https://example.com/push":                               false,
+		"not a url":                                              false,
+	} {
+		if got := ValidWebPushEndpoint(endpoint); got != want {
+			t.Errorf("ValidWebPushEndpoint(%q) = %v, want %v", endpoint, got, want)
+		}
+	}
+}
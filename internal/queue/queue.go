@@ -0,0 +1,378 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"jainfood/internal/redisclient"
+)
+
+// Priority constants. High priority jobs are always dequeued first.
+const (
+	PriorityHigh    = "high"
+	PriorityDefault = "default"
+)
+
+const (
+	defaultMaxAttempts = 5
+	baseBackoff        = 2 * time.Second
+	maxBackoff         = 5 * time.Minute
+	deadLetterMaxLen   = 1000
+)
+
+// Job is a unit of work stored in Redis.
+type Job struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Priority    string          `json:"priority"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	EnqueuedAt  time.Time       `json:"enqueued_at"`
+	ExpiresAt   *time.Time      `json:"expires_at,omitempty"` // Jobs past this time are dropped instead of run
+	Redact      []string        `json:"redact,omitempty"`     // Payload fields blanked before the job is dead-lettered
+
+	raw string // Encoded form as stored in Redis, used to ack/fail
+}
+
+// NewJob builds a job of the given type with a JSON-encoded payload.
+func NewJob(jobType string, payload interface{}) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Job{
+		ID:          uuid.New().String(),
+		Type:        jobType,
+		Payload:     data,
+		Priority:    PriorityDefault,
+		MaxAttempts: defaultMaxAttempts,
+	}, nil
+}
+
+// Decode unmarshals the job payload into v.
+func (j *Job) Decode(v interface{}) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// Expired reports whether the job is past its expiry time.
+func (j *Job) Expired(now time.Time) bool {
+	return j.ExpiresAt != nil && now.After(*j.ExpiresAt)
+}
+
+// Backoff returns the delay before retry number attempt (1-based).
+// Delays double from 2s and are capped at 5 minutes, with up to 20% jitter.
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := baseBackoff
+	for i := 1; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(d) / 5))
+	return d + jitter
+}
+
+// redactedValue replaces redacted payload fields in dead letters.
+const redactedValue = "[redacted]"
+
+// redactPayload blanks fields of a JSON object payload. Payloads that
+// aren't objects are dropped entirely rather than kept in the clear.
+func redactPayload(payload json.RawMessage, fields []string) json.RawMessage {
+	if len(fields) == 0 {
+		return payload
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &obj); err != nil {
+		return json.RawMessage("null")
+	}
+	for _, f := range fields {
+		if _, ok := obj[f]; ok {
+			obj[f] = json.RawMessage(strconv.Quote(redactedValue))
+		}
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return data
+}
+
+// permanentError marks an error that should not be retried.
+type permanentError struct{ err error }
+
+func (p *permanentError) Error() string { return p.err.Error() }
+func (p *permanentError) Unwrap() error { return p.err }
+
+// Permanent wraps err so the job is moved to the dead-letter list without retrying.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// ============================================
+// REDIS QUEUE
+// ============================================
+
+// Queue is a Redis-backed job queue with visibility timeouts, delayed
+// retries and a dead-letter list.
+//
+// Keys used (for a queue named "notify"):
+//
+//	queue:notify:high        ready list, high priority
+//	queue:notify:default     ready list, default priority
+//	queue:notify:processing  zset of in-flight jobs scored by visibility deadline
+//	queue:notify:delayed     zset of jobs waiting for retry scored by run-at time
+//	queue:notify:dead        dead-letter list (capped)
+type Queue struct {
+	Name       string
+	Visibility time.Duration
+	rdb        *redis.Client
+}
+
+// New creates a queue using the shared Redis client.
+func New(name string, visibility time.Duration) *Queue {
+	if visibility <= 0 {
+		visibility = 30 * time.Second
+	}
+	return &Queue{
+		Name:       name,
+		Visibility: visibility,
+		rdb:        redisclient.Rdb,
+	}
+}
+
+// HandlerTimeout is how long a handler may run: a fifth shorter than the
+// visibility timeout, so a slow job is cancelled and failed before it can
+// be reclaimed and run again alongside itself.
+func (q *Queue) HandlerTimeout() time.Duration {
+	return q.Visibility - q.Visibility/5
+}
+
+func (q *Queue) key(suffix string) string {
+	return "queue:" + q.Name + ":" + suffix
+}
+
+func (q *Queue) readyKey(priority string) string {
+	if priority == PriorityHigh {
+		return q.key(PriorityHigh)
+	}
+	return q.key(PriorityDefault)
+}
+
+// dequeueScript pops the first available job across the ready lists (in
+// priority order) and records it in the processing set atomically.
+var dequeueScript = redis.NewScript(`
+for i = 1, #KEYS - 1 do
+  local job = redis.call('RPOP', KEYS[i])
+  if job then
+    redis.call('ZADD', KEYS[#KEYS], ARGV[1], job)
+    return job
+  end
+end
+return false
+`)
+
+// promoteScript moves due jobs from the delayed set back onto their ready list.
+var promoteScript = redis.NewScript(`
+local jobs = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, job in ipairs(jobs) do
+  redis.call('ZREM', KEYS[1], job)
+  local ok, decoded = pcall(cjson.decode, job)
+  if ok and decoded.priority == 'high' then
+    redis.call('LPUSH', KEYS[2], job)
+  else
+    redis.call('LPUSH', KEYS[3], job)
+  end
+end
+return #jobs
+`)
+
+// reclaimScript removes jobs whose visibility deadline has passed from the
+// processing set and returns them so they can be retried.
+var reclaimScript = redis.NewScript(`
+local jobs = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, job in ipairs(jobs) do
+  redis.call('ZREM', KEYS[1], job)
+end
+return jobs
+`)
+
+// Enqueue adds a job to its ready list.
+func (q *Queue) Enqueue(ctx context.Context, job *Job) error {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+	if job.Priority == "" {
+		job.Priority = PriorityDefault
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = defaultMaxAttempts
+	}
+	if job.EnqueuedAt.IsZero() {
+		job.EnqueuedAt = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return q.rdb.LPush(ctx, q.readyKey(job.Priority), data).Err()
+}
+
+// Dequeue claims the next ready job, or returns (nil, nil) if none is available.
+// The job stays invisible to other workers until Ack, Fail or the visibility timeout.
+func (q *Queue) Dequeue(ctx context.Context) (*Job, error) {
+	deadline := time.Now().Add(q.Visibility).UnixMilli()
+	keys := []string{q.key(PriorityHigh), q.key(PriorityDefault), q.key("processing")}
+
+	raw, err := dequeueScript.Run(ctx, q.rdb, keys, deadline).Text()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job := &Job{}
+	if err := json.Unmarshal([]byte(raw), job); err != nil {
+		// Unparseable jobs go straight to the dead-letter list.
+		pipe := q.rdb.TxPipeline()
+		pipe.ZRem(ctx, q.key("processing"), raw)
+		pipe.LPush(ctx, q.key("dead"), raw)
+		pipe.LTrim(ctx, q.key("dead"), 0, deadLetterMaxLen-1)
+		_, _ = pipe.Exec(ctx)
+		return nil, fmt.Errorf("queue %s: malformed job: %w", q.Name, err)
+	}
+	job.raw = raw
+	return job, nil
+}
+
+// Ack marks a job as done.
+func (q *Queue) Ack(ctx context.Context, job *Job) error {
+	return q.rdb.ZRem(ctx, q.key("processing"), job.raw).Err()
+}
+
+// Fail records a failed attempt. The job is scheduled for retry with
+// exponential backoff, or moved to the dead-letter list once it has used
+// all its attempts or the error is permanent. Dead letters keep the
+// payload with its Redact fields blanked. It reports whether the job was
+// dead-lettered.
+func (q *Queue) Fail(ctx context.Context, job *Job, cause error) (bool, error) {
+	job.Attempts++
+	if cause != nil {
+		job.LastError = cause.Error()
+	}
+	dead := job.Attempts >= job.MaxAttempts || IsPermanent(cause)
+	if dead {
+		job.Payload = redactPayload(job.Payload, job.Redact)
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return false, err
+	}
+
+	pipe := q.rdb.TxPipeline()
+	if job.raw != "" {
+		pipe.ZRem(ctx, q.key("processing"), job.raw)
+	}
+	if dead {
+		pipe.LPush(ctx, q.key("dead"), data)
+		pipe.LTrim(ctx, q.key("dead"), 0, deadLetterMaxLen-1)
+	} else {
+		runAt := time.Now().Add(Backoff(job.Attempts)).UnixMilli()
+		pipe.ZAdd(ctx, q.key("delayed"), redis.Z{Score: float64(runAt), Member: data})
+	}
+	_, err = pipe.Exec(ctx)
+	return dead, err
+}
+
+// PromoteDue moves delayed jobs whose retry time has arrived back to the ready lists.
+func (q *Queue) PromoteDue(ctx context.Context, limit int) (int, error) {
+	keys := []string{q.key("delayed"), q.key(PriorityHigh), q.key(PriorityDefault)}
+	return promoteScript.Run(ctx, q.rdb, keys, time.Now().UnixMilli(), limit).Int()
+}
+
+// ReclaimExpired returns in-flight jobs whose visibility timeout has passed.
+// Callers should pass them to Fail so they are retried or dead-lettered.
+func (q *Queue) ReclaimExpired(ctx context.Context, limit int) ([]*Job, error) {
+	raws, err := reclaimScript.Run(ctx, q.rdb, []string{q.key("processing")}, time.Now().UnixMilli(), limit).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, 0, len(raws))
+	for _, raw := range raws {
+		job := &Job{}
+		if err := json.Unmarshal([]byte(raw), job); err != nil {
+			_ = q.rdb.LPush(ctx, q.key("dead"), raw).Err()
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// Stats holds queue depth counters.
+type Stats struct {
+	High       int64 `json:"high"`
+	Default    int64 `json:"default"`
+	Processing int64 `json:"processing"`
+	Delayed    int64 `json:"delayed"`
+	Dead       int64 `json:"dead"`
+}
+
+// Stats returns the current depth of each queue list.
+func (q *Queue) Stats(ctx context.Context) (*Stats, error) {
+	pipe := q.rdb.Pipeline()
+	high := pipe.LLen(ctx, q.key(PriorityHigh))
+	def := pipe.LLen(ctx, q.key(PriorityDefault))
+	processing := pipe.ZCard(ctx, q.key("processing"))
+	delayed := pipe.ZCard(ctx, q.key("delayed"))
+	dead := pipe.LLen(ctx, q.key("dead"))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return &Stats{
+		High:       high.Val(),
+		Default:    def.Val(),
+		Processing: processing.Val(),
+		Delayed:    delayed.Val(),
+		Dead:       dead.Val(),
+	}, nil
+}
+
+// DeadLetters returns the most recent dead-lettered jobs.
+func (q *Queue) DeadLetters(ctx context.Context, limit int) ([]*Job, error) {
+	raws, err := q.rdb.LRange(ctx, q.key("dead"), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*Job, 0, len(raws))
+	for _, raw := range raws {
+		job := &Job{}
+		if err := json.Unmarshal([]byte(raw), job); err != nil {
+			job = &Job{ID: "malformed", LastError: strconv.Quote(raw)}
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,104 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	prev := time.Duration(0)
+	for attempt := 1; attempt <= 5; attempt++ {
+		d := Backoff(attempt)
+		min := baseBackoff << (attempt - 1)
+		if d < min || d > min+min/5 {
+			t.Errorf("Backoff(%d) = %v, want between %v and %v", attempt, d, min, min+min/5)
+		}
+		if d <= prev {
+			t.Errorf("Backoff(%d) = %v, should grow over previous %v", attempt, d, prev)
+		}
+		prev = d
+	}
+}
+
+func TestBackoff_Capped(t *testing.T) {
+	d := Backoff(50)
+	if d < maxBackoff || d > maxBackoff+maxBackoff/5 {
+		t.Errorf("Backoff(50) = %v, want capped near %v", d, maxBackoff)
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	base := errors.New("invalid phone")
+	err := Permanent(base)
+
+	if !IsPermanent(err) {
+		t.Error("IsPermanent() should be true for wrapped error")
+	}
+	if !errors.Is(err, base) {
+		t.Error("Permanent() should unwrap to the original error")
+	}
+	if IsPermanent(base) {
+		t.Error("IsPermanent() should be false for plain error")
+	}
+}
+
+func TestNewJob(t *testing.T) {
+	job, err := NewJob("test.job", map[string]string{"phone": "9999999999"})
+	if err != nil {
+		t.Fatalf("NewJob() error = %v", err)
+	}
+	if job.ID == "" || job.Type != "test.job" {
+		t.Errorf("NewJob() = %+v, want ID and type set", job)
+	}
+	if job.Priority != PriorityDefault || job.MaxAttempts != defaultMaxAttempts {
+		t.Errorf("NewJob() priority/attempts = %s/%d, want defaults", job.Priority, job.MaxAttempts)
+	}
+
+	var payload map[string]string
+	if err := job.Decode(&payload); err != nil || payload["phone"] != "9999999999" {
+		t.Errorf("Decode() = %v, %v", payload, err)
+	}
+}
+
+func TestJobExpired(t *testing.T) {
+	now := time.Now()
+	job := &Job{}
+	if job.Expired(now) {
+		t.Error("job without expiry should never expire")
+	}
+
+	past := now.Add(-time.Minute)
+	job.ExpiresAt = &past
+	if !job.Expired(now) {
+		t.Error("job past expiry should be expired")
+	}
+}
+
+func TestHandlerTimeout(t *testing.T) {
+	for _, v := range []time.Duration{time.Second, 30 * time.Second, 10 * time.Minute} {
+		q := &Queue{Visibility: v}
+		if got := q.HandlerTimeout(); got <= 0 || got >= v {
+			t.Errorf("HandlerTimeout with visibility %v = %v, want strictly between 0 and it", v, got)
+		}
+	}
+}
+
+func TestRedactPayload(t *testing.T) {
+	got := redactPayload(json.RawMessage(`{"phone":"+919800000000","otp":"123456"}`), []string{"otp", "missing"})
+	var p map[string]string
+	if err := json.Unmarshal(got, &p); err != nil {
+		t.Fatalf("redacted payload %s: %v", got, err)
+	}
+	if p["otp"] != redactedValue || p["phone"] != "+919800000000" || len(p) != 2 {
+		t.Errorf("redactPayload = %s", got)
+	}
+
+	if got := redactPayload(json.RawMessage(`"123456"`), []string{"otp"}); string(got) != "null" {
+		t.Errorf("non-object payload kept as %s", got)
+	}
+	if got := redactPayload(json.RawMessage(`{"a":1}`), nil); string(got) != `{"a":1}` {
+		t.Errorf("payload without redact fields changed to %s", got)
+	}
+}
@@ -0,0 +1,223 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// testQueue returns a queue with a unique name on the Redis server at
+// REDIS_TEST_ADDR, deleting its keys when the test ends. Tests using it are
+// skipped when no server is configured.
+func testQueue(t *testing.T, visibility time.Duration) *Queue {
+	t.Helper()
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR not set")
+	}
+	rdb := redis.NewClient(&redis.Options{Addr: addr})
+	ctx := context.Background()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		t.Skipf("redis at %s unavailable: %v", addr, err)
+	}
+	q := &Queue{Name: "test-" + uuid.New().String(), Visibility: visibility, rdb: rdb}
+	t.Cleanup(func() {
+		for _, s := range []string{PriorityHigh, PriorityDefault, "processing", "delayed", "dead"} {
+			rdb.Del(ctx, q.key(s))
+		}
+		_ = rdb.Close()
+	})
+	return q
+}
+
+func enqueue(t *testing.T, q *Queue, jobType, priority string) *Job {
+	t.Helper()
+	job, err := NewJob(jobType, map[string]string{"otp": "123456"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job.Priority = priority
+	if err := q.Enqueue(context.Background(), job); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	return job
+}
+
+func TestQueue_PriorityAndAck(t *testing.T) {
+	q := testQueue(t, time.Minute)
+	ctx := context.Background()
+
+	first := enqueue(t, q, "a", PriorityDefault)
+	second := enqueue(t, q, "b", PriorityDefault)
+	urgent := enqueue(t, q, "c", PriorityHigh)
+
+	for _, want := range []*Job{urgent, first, second} {
+		job, err := q.Dequeue(ctx)
+		if err != nil || job == nil {
+			t.Fatalf("Dequeue = %v, %v", job, err)
+		}
+		if job.ID != want.ID {
+			t.Errorf("dequeued %s, want %s", job.Type, want.Type)
+		}
+		if err := q.Ack(ctx, job); err != nil {
+			t.Fatalf("Ack: %v", err)
+		}
+	}
+	if job, err := q.Dequeue(ctx); job != nil || err != nil {
+		t.Errorf("Dequeue on empty queue = %v, %v", job, err)
+	}
+	stats, err := q.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *stats != (Stats{}) {
+		t.Errorf("Stats after acking everything = %+v", stats)
+	}
+}
+
+func TestQueue_RetryThenDeadLetter(t *testing.T) {
+	q := testQueue(t, time.Minute)
+	ctx := context.Background()
+
+	job, err := NewJob("otp", map[string]string{"phone": "+919800000000", "otp": "123456"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job.MaxAttempts = 2
+	job.Redact = []string{"otp"}
+	if err := q.Enqueue(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+
+	claimed, err := q.Dequeue(ctx)
+	if err != nil || claimed == nil {
+		t.Fatalf("Dequeue = %v, %v", claimed, err)
+	}
+	if dead, err := q.Fail(ctx, claimed, errors.New("gateway down")); err != nil || dead {
+		t.Fatalf("first Fail = %v, %v; want a retry", dead, err)
+	}
+	if stats, _ := q.Stats(ctx); stats.Processing != 0 || stats.Delayed != 1 {
+		t.Fatalf("after a retryable failure: %+v", stats)
+	}
+
+	// Make the retry due now rather than waiting out the backoff
+	delayed, err := q.rdb.ZRange(ctx, q.key("delayed"), 0, -1).Result()
+	if err != nil || len(delayed) != 1 {
+		t.Fatalf("delayed = %v, %v", delayed, err)
+	}
+	q.rdb.ZAdd(ctx, q.key("delayed"), redis.Z{Score: 0, Member: delayed[0]})
+	if n, err := q.PromoteDue(ctx, 10); err != nil || n != 1 {
+		t.Fatalf("PromoteDue = %d, %v", n, err)
+	}
+
+	retry, err := q.Dequeue(ctx)
+	if err != nil || retry == nil {
+		t.Fatalf("Dequeue retry = %v, %v", retry, err)
+	}
+	if retry.Attempts != 1 || retry.LastError != "gateway down" {
+		t.Errorf("retry = %+v", retry)
+	}
+	if dead, err := q.Fail(ctx, retry, errors.New("gateway down")); err != nil || !dead {
+		t.Fatalf("last Fail = %v, %v; want dead-lettered", dead, err)
+	}
+
+	letters, err := q.DeadLetters(ctx, 10)
+	if err != nil || len(letters) != 1 {
+		t.Fatalf("DeadLetters = %v, %v", letters, err)
+	}
+	var p map[string]string
+	if err := json.Unmarshal(letters[0].Payload, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p["otp"] != redactedValue || p["phone"] != "+919800000000" {
+		t.Errorf("dead letter payload = %s", letters[0].Payload)
+	}
+}
+
+func TestQueue_PermanentFailure(t *testing.T) {
+	q := testQueue(t, time.Minute)
+	ctx := context.Background()
+
+	enqueue(t, q, "otp", PriorityHigh)
+	job, err := q.Dequeue(ctx)
+	if err != nil || job == nil {
+		t.Fatalf("Dequeue = %v, %v", job, err)
+	}
+	if dead, err := q.Fail(ctx, job, Permanent(errors.New("bad phone"))); err != nil || !dead {
+		t.Fatalf("Fail = %v, %v; want dead-lettered at once", dead, err)
+	}
+	if stats, _ := q.Stats(ctx); stats.Dead != 1 || stats.Delayed != 0 || stats.Processing != 0 {
+		t.Errorf("Stats = %+v", stats)
+	}
+}
+
+func TestQueue_ReclaimExpired(t *testing.T) {
+	q := testQueue(t, 50*time.Millisecond)
+	ctx := context.Background()
+
+	sent := enqueue(t, q, "otp", PriorityDefault)
+	if job, err := q.Dequeue(ctx); err != nil || job == nil {
+		t.Fatalf("Dequeue = %v, %v", job, err)
+	}
+	if jobs, err := q.ReclaimExpired(ctx, 10); err != nil || len(jobs) != 0 {
+		t.Fatalf("ReclaimExpired before the deadline = %v, %v", jobs, err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	jobs, err := q.ReclaimExpired(ctx, 10)
+	if err != nil || len(jobs) != 1 || jobs[0].ID != sent.ID {
+		t.Fatalf("ReclaimExpired = %v, %v", jobs, err)
+	}
+	if stats, _ := q.Stats(ctx); stats.Processing != 0 {
+		t.Errorf("reclaimed job still processing: %+v", stats)
+	}
+}
+
+func TestWorker_ProcessesJobs(t *testing.T) {
+	q := testQueue(t, time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	enqueue(t, q, "ok", PriorityDefault)
+	enqueue(t, q, "broken", PriorityDefault)
+
+	done := make(chan string, 2)
+	w := NewWorker(q, 2, zap.NewNop())
+	w.pollInterval = 10 * time.Millisecond
+	w.Handle("ok", func(ctx context.Context, job *Job) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("handler context has no deadline")
+		}
+		done <- job.Type
+		return nil
+	})
+	w.Handle("broken", func(ctx context.Context, job *Job) error {
+		defer func() { done <- job.Type }()
+		return Permanent(errors.New("broken"))
+	})
+	go w.Run(ctx)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			t.Fatal("jobs were not processed")
+		}
+	}
+	// Give the worker a moment to finish its bookkeeping
+	for ctx.Err() == nil {
+		stats, err := q.Stats(ctx)
+		if err == nil && stats.Processing == 0 && stats.Dead == 1 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("worker did not ack and dead-letter its jobs")
+}
@@ -0,0 +1,181 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"jainfood/internal/monitoring"
+)
+
+// HandlerFunc processes a single job. Returning an error schedules a retry
+// unless the error is wrapped with Permanent.
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+// Worker runs a pool of goroutines that process jobs from a Queue.
+type Worker struct {
+	queue        *Queue
+	handlers     map[string]HandlerFunc
+	concurrency  int
+	pollInterval time.Duration
+	logger       *zap.Logger
+}
+
+// NewWorker creates a worker pool for the queue.
+func NewWorker(q *Queue, concurrency int, logger *zap.Logger) *Worker {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &Worker{
+		queue:        q,
+		handlers:     make(map[string]HandlerFunc),
+		concurrency:  concurrency,
+		pollInterval: 500 * time.Millisecond,
+		logger:       logger,
+	}
+}
+
+// Handle registers the handler for a job type. Must be called before Run.
+func (w *Worker) Handle(jobType string, h HandlerFunc) {
+	w.handlers[jobType] = h
+}
+
+// Run starts the worker pool and the maintenance loop. It blocks until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < w.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.maintain(ctx)
+	}()
+
+	w.logger.Info("queue worker started",
+		zap.String("queue", w.queue.Name),
+		zap.Int("concurrency", w.concurrency))
+	wg.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		job, err := w.queue.Dequeue(ctx)
+		if err != nil {
+			w.logger.Error("queue dequeue failed", zap.String("queue", w.queue.Name), zap.Error(err))
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.pollInterval):
+			}
+			continue
+		}
+
+		w.process(ctx, job)
+	}
+}
+
+func (w *Worker) process(ctx context.Context, job *Job) {
+	m := monitoring.GetMetrics()
+	prefix := "queue_" + w.queue.Name + "_"
+
+	if job.Expired(time.Now()) {
+		_ = w.queue.Ack(ctx, job)
+		m.IncrCustom(prefix + "expired")
+		w.logger.Warn("queue job expired before processing",
+			zap.String("job_id", job.ID), zap.String("type", job.Type))
+		return
+	}
+
+	handler, ok := w.handlers[job.Type]
+	if !ok {
+		_, _ = w.queue.Fail(ctx, job, Permanent(fmt.Errorf("no handler for job type %q", job.Type)))
+		m.IncrCustom(prefix + "dead_lettered")
+		return
+	}
+
+	jobCtx, cancel := context.WithTimeout(ctx, w.queue.HandlerTimeout())
+	err := handler(jobCtx, job)
+	cancel()
+
+	if err == nil {
+		if ackErr := w.queue.Ack(ctx, job); ackErr != nil {
+			w.logger.Error("queue ack failed", zap.String("job_id", job.ID), zap.Error(ackErr))
+		}
+		m.IncrCustom(prefix + "processed")
+		return
+	}
+
+	dead, failErr := w.queue.Fail(ctx, job, err)
+	if failErr != nil {
+		w.logger.Error("queue fail bookkeeping failed", zap.String("job_id", job.ID), zap.Error(failErr))
+		return
+	}
+	m.IncrCustom(prefix + "failed")
+	if dead {
+		m.IncrCustom(prefix + "dead_lettered")
+		w.logger.Error("queue job dead-lettered",
+			zap.String("job_id", job.ID),
+			zap.String("type", job.Type),
+			zap.Int("attempts", job.Attempts),
+			zap.Error(err))
+		return
+	}
+	w.logger.Warn("queue job failed, will retry",
+		zap.String("job_id", job.ID),
+		zap.String("type", job.Type),
+		zap.Int("attempts", job.Attempts),
+		zap.Error(err))
+}
+
+// maintain promotes delayed retries, reclaims jobs whose visibility timeout
+// expired and publishes queue depth gauges.
+func (w *Worker) maintain(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := w.queue.PromoteDue(ctx, 100); err != nil {
+			w.logger.Error("queue promote failed", zap.String("queue", w.queue.Name), zap.Error(err))
+		}
+
+		expired, err := w.queue.ReclaimExpired(ctx, 100)
+		if err != nil {
+			w.logger.Error("queue reclaim failed", zap.String("queue", w.queue.Name), zap.Error(err))
+		}
+		for _, job := range expired {
+			if _, err := w.queue.Fail(ctx, job, fmt.Errorf("visibility timeout exceeded")); err != nil {
+				w.logger.Error("queue reclaim fail bookkeeping failed", zap.String("job_id", job.ID), zap.Error(err))
+			}
+		}
+
+		if stats, err := w.queue.Stats(ctx); err == nil {
+			m := monitoring.GetMetrics()
+			prefix := "queue_" + w.queue.Name + "_depth_"
+			m.SetCustom(prefix+"high", float64(stats.High))
+			m.SetCustom(prefix+"default", float64(stats.Default))
+			m.SetCustom(prefix+"processing", float64(stats.Processing))
+			m.SetCustom(prefix+"delayed", float64(stats.Delayed))
+			m.SetCustom(prefix+"dead", float64(stats.Dead))
+		}
+	}
+}
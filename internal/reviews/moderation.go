@@ -0,0 +1,153 @@
+package reviews
+
+import (
+	"context"
+	"fmt"
+
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/lib/pq"
+)
+
+// Moderation actions.
+const (
+	ActionApprove = "approve"
+	ActionHide    = "hide"
+)
+
+// ModerationTransition returns the state a review moves to when a moderator
+// applies action. Either action can be applied from any state, so a hidden
+// review can be restored by approving it.
+func ModerationTransition(action string) (string, error) {
+	switch action {
+	case ActionApprove:
+		return models.ReviewApproved, nil
+	case ActionHide:
+		return models.ReviewHidden, nil
+	}
+	return "", fmt.Errorf("action must be %s or %s", ActionApprove, ActionHide)
+}
+
+// ReportReview records a user's report against a review and flags it for
+// moderation unless a moderator has already decided on it. Reporting the
+// same review twice counts once.
+func ReportReview(ctx context.Context, reviewID, reporterID, reason string) error {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM reviews WHERE id = $1)`, reviewID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return db.ErrNotFound
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO review_reports (review_id, reporter_id, reason)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (review_id, reporter_id) DO NOTHING
+	`, reviewID, reporterID, reason)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return tx.Commit(ctx)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE reviews
+		SET report_count = report_count + 1,
+		    moderation_state = CASE WHEN moderation_state = 'published' THEN 'flagged' ELSE moderation_state END
+		WHERE id = $1
+	`, reviewID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ModerationQueue lists reviews in state (flagged by default), most reported
+// first, with reviewer and provider names and the reasons given in reports.
+func ModerationQueue(ctx context.Context, state string, limit, offset int) ([]models.Review, int, error) {
+	if state == "" {
+		state = models.ReviewFlagged
+	}
+
+	var total int
+	if err := db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM reviews WHERE moderation_state = $1`, state).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT r.id, r.provider_id, r.user_id, COALESCE(r.order_id::text, ''),
+		       r.rating, r.comment, r.photo_urls, r.created_at,
+		       r.moderation_state, r.report_count,
+		       COALESCE(u.name, ''), COALESCE(p.business_name, ''),
+		       COALESCE((SELECT array_agg(rr.reason ORDER BY rr.created_at) FROM review_reports rr
+		                 WHERE rr.review_id = r.id AND rr.reason <> ''), '{}')
+		FROM reviews r
+		LEFT JOIN users u ON u.id = r.user_id
+		LEFT JOIN providers p ON p.id = r.provider_id
+		WHERE r.moderation_state = $1
+		ORDER BY r.report_count DESC, r.created_at DESC
+		LIMIT $2 OFFSET $3
+	`, state, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	reviews := []models.Review{}
+	for rows.Next() {
+		var r models.Review
+		if err := rows.Scan(
+			&r.ID, &r.ProviderID, &r.UserID, &r.OrderID,
+			&r.Rating, &r.Comment, pq.Array(&r.PhotoURLs), &r.CreatedAt,
+			&r.ModerationState, &r.ReportCount,
+			&r.UserName, &r.ProviderName, pq.Array(&r.ReportReasons),
+		); err != nil {
+			return nil, 0, err
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, total, rows.Err()
+}
+
+// ModerateReview applies a moderator's action to a review, records who did
+// it and why, and recomputes the provider's rating. It returns the updated
+// review, or db.ErrNotFound.
+func ModerateReview(ctx context.Context, reviewID, adminID, action, note string) (*models.Review, error) {
+	state, err := ModerationTransition(action)
+	if err != nil {
+		return nil, err
+	}
+
+	var r models.Review
+	err = db.Pool.QueryRow(ctx, `
+		UPDATE reviews
+		SET moderation_state = $2, moderated_by = $3, moderated_at = now(), moderation_note = $4
+		WHERE id = $1
+		RETURNING id, provider_id, user_id, COALESCE(order_id::text, ''),
+		          rating, comment, photo_urls, created_at, moderation_state, report_count
+	`, reviewID, state, adminID, note).Scan(
+		&r.ID, &r.ProviderID, &r.UserID, &r.OrderID,
+		&r.Rating, &r.Comment, pq.Array(&r.PhotoURLs), &r.CreatedAt,
+		&r.ModerationState, &r.ReportCount,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, db.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := updateProviderRating(ctx, r.ProviderID); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
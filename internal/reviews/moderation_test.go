@@ -0,0 +1,19 @@
+package reviews
+
+import (
+	"testing"
+
+	"jainfood/internal/models"
+)
+
+func TestModerationTransition(t *testing.T) {
+	if s, err := ModerationTransition(ActionApprove); err != nil || s != models.ReviewApproved {
+		t.Errorf("approve = %q, %v", s, err)
+	}
+	if s, err := ModerationTransition(ActionHide); err != nil || s != models.ReviewHidden {
+		t.Errorf("hide = %q, %v", s, err)
+	}
+	if _, err := ModerationTransition("delete"); err == nil {
+		t.Error("unknown action succeeded")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"jainfood/internal/db"
 	"jainfood/internal/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/lib/pq"
 )
 
@@ -30,16 +31,26 @@ func CreateReview(ctx context.Context, providerID, userID, orderID string, ratin
 	}
 
 	// Update provider's average rating
-	_, _ = db.Pool.Exec(ctx, `
+	_ = updateProviderRating(ctx, providerID)
+
+	return &review, nil
+}
+
+// updateProviderRating recomputes a provider's average rating and count
+// from its reviews, leaving out hidden ones.
+func updateProviderRating(ctx context.Context, providerID string) error {
+	_, err := db.Pool.Exec(ctx, `
 		UPDATE providers
-		SET rating = (SELECT AVG(rating)::numeric(2,1) FROM reviews WHERE provider_id = $1),
-		    total_ratings = (SELECT COUNT(*) FROM reviews WHERE provider_id = $1)
+		SET rating = COALESCE((SELECT AVG(rating)::numeric(2,1) FROM reviews WHERE provider_id = $1 AND `+visible+`), 0),
+		    total_ratings = (SELECT COUNT(*) FROM reviews WHERE provider_id = $1 AND `+visible+`)
 		WHERE id = $1
 	`, providerID)
-
-	return &review, nil
+	return err
 }
 
+// visible filters out reviews hidden by a moderator.
+const visible = `moderation_state <> 'hidden'`
+
 // GetReviewsByProvider returns all reviews for a provider
 func GetReviewsByProvider(ctx context.Context, providerID string, limit, offset int) ([]models.Review, error) {
 	rows, err := db.Pool.Query(ctx, `
@@ -48,7 +59,7 @@ func GetReviewsByProvider(ctx context.Context, providerID string, limit, offset
 		       COALESCE(u.name, 'Anonymous') as user_name
 		FROM reviews r
 		LEFT JOIN users u ON r.user_id = u.id
-		WHERE r.provider_id = $1
+		WHERE r.provider_id = $1 AND r.`+visible+`
 		ORDER BY r.created_at DESC
 		LIMIT $2 OFFSET $3
 	`, providerID, limit, offset)
@@ -60,11 +71,10 @@ func GetReviewsByProvider(ctx context.Context, providerID string, limit, offset
 	var reviews []models.Review
 	for rows.Next() {
 		var r models.Review
-		var userName string
 		if err := rows.Scan(
 			&r.ID, &r.ProviderID, &r.UserID, &r.OrderID,
 			&r.Rating, &r.Comment, pq.Array(&r.PhotoURLs), &r.CreatedAt,
-			&userName,
+			&r.UserName,
 		); err != nil {
 			return nil, err
 		}
@@ -119,11 +129,12 @@ func GetReview(ctx context.Context, reviewID string) (*models.Review, error) {
 	var review models.Review
 	err := db.Pool.QueryRow(ctx, `
 		SELECT id, provider_id, user_id, COALESCE(order_id::text, ''),
-		       rating, comment, photo_urls, created_at
+		       rating, comment, photo_urls, created_at, moderation_state, report_count
 		FROM reviews WHERE id = $1
 	`, reviewID).Scan(
 		&review.ID, &review.ProviderID, &review.UserID, &review.OrderID,
 		&review.Rating, &review.Comment, pq.Array(&review.PhotoURLs), &review.CreatedAt,
+		&review.ModerationState, &review.ReportCount,
 	)
 	if err != nil {
 		return nil, err
@@ -133,22 +144,31 @@ func GetReview(ctx context.Context, reviewID string) (*models.Review, error) {
 
 // DeleteReview deletes a review
 func DeleteReview(ctx context.Context, reviewID, userID string) error {
-	result, err := db.Pool.Exec(ctx, `
-		DELETE FROM reviews WHERE id = $1 AND user_id = $2
-	`, reviewID, userID)
+	var providerID string
+	err := db.Pool.QueryRow(ctx, `
+		DELETE FROM reviews WHERE id = $1 AND user_id = $2 RETURNING provider_id
+	`, reviewID, userID).Scan(&providerID)
+	if err == pgx.ErrNoRows {
+		return db.ErrNotFound
+	}
 	if err != nil {
 		return err
 	}
-	if result.RowsAffected() == 0 {
-		return db.ErrNotFound
-	}
-	return nil
+	return updateProviderRating(ctx, providerID)
 }
 
-// DeleteReviewAdmin deletes a review (admin only - no user check)
+// DeleteReviewAdmin deletes a review (admin only - no user check), or
+// returns db.ErrNotFound.
 func DeleteReviewAdmin(ctx context.Context, reviewID string) error {
-	_, err := db.Pool.Exec(ctx, `DELETE FROM reviews WHERE id = $1`, reviewID)
-	return err
+	var providerID string
+	err := db.Pool.QueryRow(ctx, `DELETE FROM reviews WHERE id = $1 RETURNING provider_id`, reviewID).Scan(&providerID)
+	if err == pgx.ErrNoRows {
+		return db.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return updateProviderRating(ctx, providerID)
 }
 
 // GetProviderReviewStats returns review statistics for a provider
@@ -159,7 +179,7 @@ func GetProviderReviewStats(ctx context.Context, providerID string) (map[string]
 
 	err := db.Pool.QueryRow(ctx, `
 		SELECT COALESCE(AVG(rating), 0), COUNT(*)
-		FROM reviews WHERE provider_id = $1
+		FROM reviews WHERE provider_id = $1 AND `+visible+`
 	`, providerID).Scan(&avgRating, &totalReviews)
 	if err != nil {
 		return nil, err
@@ -168,7 +188,7 @@ func GetProviderReviewStats(ctx context.Context, providerID string) (map[string]
 	rows, err := db.Pool.Query(ctx, `
 		SELECT rating, COUNT(*)
 		FROM reviews
-		WHERE provider_id = $1
+		WHERE provider_id = $1 AND `+visible+`
 		GROUP BY rating
 		ORDER BY rating
 	`, providerID)
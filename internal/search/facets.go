@@ -0,0 +1,177 @@
+package search
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"jainfood/internal/db"
+)
+
+// ErrInvalidCursor is returned for a next_cursor that can't be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// FacetRange is a numeric bucket. A zero Min or Max leaves that side open,
+// so rating and distance bands are cumulative ("4+", "within 2 km") and
+// match the min_rating and radius filters.
+type FacetRange struct {
+	Label string
+	Min   float64
+	Max   float64
+}
+
+// Numeric facet buckets.
+var (
+	PriceRanges = []FacetRange{
+		{Label: "0-100", Max: 100},
+		{Label: "100-250", Min: 100, Max: 250},
+		{Label: "250-500", Min: 250, Max: 500},
+		{Label: "500+", Min: 500},
+	}
+	RatingBands = []FacetRange{
+		{Label: "4.5+", Min: 4.5},
+		{Label: "4+", Min: 4},
+		{Label: "3.5+", Min: 3.5},
+		{Label: "3+", Min: 3},
+	}
+	DistanceBands = []FacetRange{
+		{Label: "1km", Max: 1000},
+		{Label: "2km", Max: 2000},
+		{Label: "5km", Max: 5000},
+		{Label: "10km", Max: 10000},
+	}
+)
+
+// FacetBucket is one facet value and how many results carry it.
+type FacetBucket struct {
+	Value string   `json:"value"`
+	Count int      `json:"count"`
+	Min   *float64 `json:"min,omitempty"` // Numeric buckets: filter bounds
+	Max   *float64 `json:"max,omitempty"`
+}
+
+// Facets maps a facet name (provider_category, food_category, tags, price,
+// rating, distance) to its buckets.
+type Facets map[string][]FacetBucket
+
+// Page is the search response envelope. Total and Facets cover every match,
+// not just this page; pass NextCursor back as cursor for the next page.
+type Page struct {
+	Total      int    `json:"total"`
+	Facets     Facets `json:"facets"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ProviderPage is a page of provider results.
+type ProviderPage struct {
+	Results  []*ProviderSearchResult `json:"results"`
+	Promoted []*ProviderSearchResult `json:"promoted,omitempty"` // Promoted slots, first page only
+	Page
+}
+
+// ItemPage is a page of item results.
+type ItemPage struct {
+	Results []*ItemSearchResult `json:"results"`
+	Page
+}
+
+// termsFacet counts values of a column of the matched CTE; array columns
+// count each element.
+func termsFacet(col string, array bool) string {
+	from := fmt.Sprintf("SELECT %s AS v, COUNT(*) AS n FROM matched WHERE COALESCE(%s, '') <> '' GROUP BY 1", col, col)
+	if array {
+		from = fmt.Sprintf("SELECT t AS v, COUNT(*) AS n FROM matched, unnest(%s) AS t GROUP BY 1", col)
+	}
+	return `(SELECT COALESCE(jsonb_agg(jsonb_build_object('value', v, 'count', n) ORDER BY n DESC, v), '[]'::jsonb) FROM (` + from + `) f)`
+}
+
+// rangeFacet counts a numeric column of the matched CTE into buckets in a
+// single scan.
+func rangeFacet(col string, ranges []FacetRange) string {
+	buckets := make([]string, len(ranges))
+	for i, r := range ranges {
+		var conds []string
+		bounds := []string{fmt.Sprintf("'value', '%s'", r.Label)}
+		if r.Min > 0 {
+			conds = append(conds, fmt.Sprintf("%s >= %g", col, r.Min))
+			bounds = append(bounds, fmt.Sprintf("'min', %g", r.Min))
+		}
+		if r.Max > 0 {
+			conds = append(conds, fmt.Sprintf("%s < %g", col, r.Max))
+			bounds = append(bounds, fmt.Sprintf("'max', %g", r.Max))
+		}
+		if len(conds) == 0 {
+			conds = append(conds, "TRUE")
+		}
+		buckets[i] = fmt.Sprintf("jsonb_build_object(%s, 'count', COUNT(*) FILTER (WHERE %s))",
+			strings.Join(bounds, ", "), strings.Join(conds, " AND "))
+	}
+	return "(SELECT jsonb_build_array(" + strings.Join(buckets, ", ") + ") FROM matched)"
+}
+
+// facetsSQL combines named facet expressions into one jsonb object.
+func facetsSQL(facets map[string]string) string {
+	parts := make([]string, 0, len(facets))
+	for _, name := range []string{"provider_category", "food_category", "tags", "price", "rating", "distance"} {
+		if expr, ok := facets[name]; ok {
+			parts = append(parts, fmt.Sprintf("'%s', %s", name, expr))
+		}
+	}
+	return "jsonb_build_object(" + strings.Join(parts, ", ") + ")"
+}
+
+// cursor is a keyset position: results are ordered by (sort_key, distance, id).
+type cursor struct {
+	Key      float64 `json:"k"`
+	Distance float64 `json:"d"`
+	ID       string  `json:"id"`
+}
+
+func (c cursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := &cursor{}
+	if err := json.Unmarshal(b, c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// pageSQL wraps a matched query (which must select id, distance and
+// sort_key) so one statement returns the page after the cursor together
+// with the total and facets of the whole match set. It fetches limit+1 rows
+// so the caller can tell whether there is a next page.
+func pageSQL(matched, facets string, after *cursor, limit int, args []interface{}) (string, []interface{}) {
+	query := "WITH matched AS (" + matched + ")\n" +
+		"SELECT m.*, (SELECT COUNT(*) FROM matched), " + facets + " FROM matched m"
+	if after != nil {
+		n := len(args)
+		query += fmt.Sprintf(" WHERE (m.sort_key, m.distance, m.id) > ($%d, $%d, $%d::uuid)", n+1, n+2, n+3)
+		args = append(args, after.Key, after.Distance, after.ID)
+	}
+	query += fmt.Sprintf(" ORDER BY m.sort_key, m.distance, m.id LIMIT $%d", len(args)+1)
+	return query, append(args, limit+1)
+}
+
+// totalsSQL returns only the total and facets, when the page is empty.
+func totalsSQL(matched, facets string) string {
+	return "WITH matched AS (" + matched + ")\nSELECT (SELECT COUNT(*) FROM matched), " + facets
+}
+
+// scanTotals fills the total and facets when the page itself is empty.
+func scanTotals(ctx context.Context, query string, args []interface{}, p *Page) error {
+	return db.Pool.QueryRow(ctx, query, args...).Scan(&p.Total, &p.Facets)
+}
@@ -0,0 +1,51 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	in := cursor{Key: -0.7312345678901234, Distance: 1234.5678, ID: "8c1d3f0e-0000-4000-8000-000000000001"}
+	out, err := decodeCursor(in.encode())
+	if err != nil || *out != in {
+		t.Fatalf("round trip: %+v, %v", out, err)
+	}
+	if c, err := decodeCursor(""); c != nil || err != nil {
+		t.Error("empty cursor should mean the first page")
+	}
+	for _, bad := range []string{"!!", "e30"} { // not base64; "{}" without id
+		if _, err := decodeCursor(bad); err != ErrInvalidCursor {
+			t.Errorf("decodeCursor(%q) = %v, want ErrInvalidCursor", bad, err)
+		}
+	}
+}
+
+func TestRangeFacet(t *testing.T) {
+	got := rangeFacet("price", PriceRanges)
+	for _, part := range []string{
+		"'value', '0-100', 'max', 100, 'count', COUNT(*) FILTER (WHERE price < 100)",
+		"FILTER (WHERE price >= 100 AND price < 250)",
+		"'value', '500+', 'min', 500, 'count', COUNT(*) FILTER (WHERE price >= 500)",
+		"FROM matched",
+	} {
+		if !strings.Contains(got, part) {
+			t.Errorf("missing %q in %s", part, got)
+		}
+	}
+}
+
+func TestPageSQL(t *testing.T) {
+	args := []interface{}{72.5, 23.0, 5000.0}
+	query, pageArgs := pageSQL("SELECT 1", "'{}'::jsonb", &cursor{Key: 1, Distance: 2, ID: "x"}, 20, args)
+	if !strings.Contains(query, "(m.sort_key, m.distance, m.id) > ($4, $5, $6::uuid)") ||
+		!strings.Contains(query, "ORDER BY m.sort_key, m.distance, m.id LIMIT $7") {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if len(pageArgs) != 7 || pageArgs[6] != 21 {
+		t.Errorf("expected limit+1 as the last arg, got %v", pageArgs)
+	}
+	if first, _ := pageSQL("SELECT 1", "'{}'::jsonb", nil, 20, args); strings.Contains(first, "WHERE") {
+		t.Errorf("first page should not filter: %s", first)
+	}
+}
@@ -0,0 +1,119 @@
+package search
+
+import (
+	"fmt"
+	"math"
+
+	"jainfood/internal/promotions"
+)
+
+// Sort orders accepted by the search endpoints.
+const (
+	SortRelevance = "relevance" // Blended score (default)
+	SortDistance  = "distance"
+	SortRating    = "rating" // Bayesian-averaged rating
+	SortPrice     = "price"  // Items only, cheapest first
+)
+
+// PromotedLabel marks results in promoted slots. Promotions don't change
+// the order of the organic results.
+const PromotedLabel = promotions.Label
+
+// RankWeights configure the blended relevance score. Each component is
+// scaled to 0..1 before weighting.
+type RankWeights struct {
+	Relevance  float64 // ts_rank text relevance (only when there is a query)
+	Rating     float64 // Bayesian-averaged rating
+	Distance   float64 // Exponential distance decay
+	Popularity float64 // Log-scaled order count
+
+	DistanceHalfLife float64 // Meters at which the distance component halves
+	RatingPrior      float64 // Rating assumed for providers with few reviews
+	RatingMinVotes   float64 // Reviews needed before a provider's own rating dominates
+	PopularityCap    float64 // Order count that scores 1
+}
+
+// DefaultRankWeights are used when SearchFilters.Weights is nil.
+var DefaultRankWeights = RankWeights{
+	Relevance:        0.35,
+	Rating:           0.25,
+	Distance:         0.25,
+	Popularity:       0.10,
+	DistanceHalfLife: 2000,
+	RatingPrior:      3.5,
+	RatingMinVotes:   10,
+	PopularityCap:    500,
+}
+
+// ValidSort reports whether sort is a known order for the result kind.
+func ValidSort(sort string, items bool) bool {
+	switch sort {
+	case "", SortRelevance, SortDistance, SortRating:
+		return true
+	case SortPrice:
+		return items
+	}
+	return false
+}
+
+// RankExplain breaks down a result's score for debugging.
+type RankExplain struct {
+	Score      float64 `json:"score"`
+	Relevance  float64 `json:"relevance"`
+	Rating     float64 `json:"rating"`
+	Distance   float64 `json:"distance"`
+	Popularity float64 `json:"popularity"`
+}
+
+// BayesianRating shrinks a rating towards the prior until it has enough votes.
+func (w RankWeights) BayesianRating(rating float64, votes int) float64 {
+	v := float64(votes)
+	return (w.RatingMinVotes*w.RatingPrior + rating*v) / (w.RatingMinVotes + v)
+}
+
+// Explain computes the score components the SQL ordering uses.
+func (w RankWeights) Explain(relevance, rating float64, votes, orders int, distance float64) *RankExplain {
+	e := &RankExplain{
+		Relevance:  relevance,
+		Rating:     w.BayesianRating(rating, votes) / 5,
+		Distance:   math.Exp(-math.Ln2 * distance / w.DistanceHalfLife),
+		Popularity: math.Min(1, math.Log1p(float64(orders))/math.Log1p(w.PopularityCap)),
+	}
+	e.Score = w.Relevance*e.Relevance + w.Rating*e.Rating + w.Distance*e.Distance +
+		w.Popularity*e.Popularity
+	return e
+}
+
+// scoreSQL is the SQL form of Explain's Score. p is the providers table
+// prefix; relevance and distance are SQL expressions.
+func (w RankWeights) scoreSQL(p, relevance, distance string) string {
+	return fmt.Sprintf(`(%g * (%s)
+		+ %g * ((%g * %g + COALESCE(%srating, 0) * COALESCE(%stotal_ratings, 0)) / (%g + COALESCE(%stotal_ratings, 0)) / 5)
+		+ %g * EXP(-0.6931471805599453 * (%s) / %g)
+		+ %g * LEAST(1, LN(1 + COALESCE(%stotal_orders, 0)) / LN(1 + %g)))`,
+		w.Relevance, relevance,
+		w.Rating, w.RatingMinVotes, w.RatingPrior, p, p, w.RatingMinVotes, p,
+		w.Distance, distance, w.DistanceHalfLife,
+		w.Popularity, p, w.PopularityCap)
+}
+
+// bayesSQL is the Bayesian rating used by sort=rating.
+func (w RankWeights) bayesSQL(p string) string {
+	return fmt.Sprintf("((%g * %g + COALESCE(%srating, 0) * COALESCE(%stotal_ratings, 0)) / (%g + COALESCE(%stotal_ratings, 0)))",
+		w.RatingMinVotes, w.RatingPrior, p, p, w.RatingMinVotes, p)
+}
+
+// sortKey returns the SQL expression results are ordered by, ascending
+// (descending scores are negated) so keyset cursors compare one direction.
+// Ties fall back to distance, then id.
+func (w RankWeights) sortKey(sort, p, relevance, distance string) string {
+	switch sort {
+	case SortDistance:
+		return distance
+	case SortRating:
+		return "-" + w.bayesSQL(p)
+	case SortPrice:
+		return "mi.price::float8"
+	}
+	return "-" + w.scoreSQL(p, relevance, distance)
+}
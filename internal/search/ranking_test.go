@@ -0,0 +1,62 @@
+package search
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestBayesianRatingShrinksFewVotes(t *testing.T) {
+	w := DefaultRankWeights
+	oneReview := w.BayesianRating(5.0, 1)
+	established := w.BayesianRating(4.7, 200)
+	if oneReview >= established {
+		t.Errorf("a single 5-star review (%.2f) should not outrank 200 reviews at 4.7 (%.2f)", oneReview, established)
+	}
+	if got := w.BayesianRating(0, 0); got != w.RatingPrior {
+		t.Errorf("no reviews should score the prior, got %.2f", got)
+	}
+}
+
+func TestExplainComponents(t *testing.T) {
+	w := DefaultRankWeights
+	e := w.Explain(0.5, 4.5, 40, 500, w.DistanceHalfLife)
+
+	if math.Abs(e.Distance-0.5) > 1e-9 {
+		t.Errorf("distance score at half-life = %v, want 0.5", e.Distance)
+	}
+	if e.Popularity != 1 {
+		t.Errorf("unexpected components %+v", e)
+	}
+	want := w.Relevance*0.5 + w.Rating*e.Rating + w.Distance*0.5 + w.Popularity
+	if math.Abs(e.Score-want) > 1e-9 {
+		t.Errorf("score = %v, want %v", e.Score, want)
+	}
+}
+
+func TestSortKey(t *testing.T) {
+	w := DefaultRankWeights
+	if got := w.sortKey(SortDistance, "p.", "0", itemDistanceSQL); got != itemDistanceSQL {
+		t.Errorf("distance sort: %s", got)
+	}
+	if got := w.sortKey(SortPrice, "p.", "0", itemDistanceSQL); !strings.Contains(got, "mi.price") {
+		t.Errorf("price sort: %s", got)
+	}
+	if got := w.sortKey(SortRating, "p.", "0", itemDistanceSQL); !strings.HasPrefix(got, "-((") {
+		t.Errorf("rating sort should be negated: %s", got)
+	}
+	got := w.sortKey(SortRelevance, "p.", "ts_rank(x, y)", itemDistanceSQL)
+	for _, part := range []string{"-(", "ts_rank(x, y)", "p.total_ratings", "EXP("} {
+		if !strings.Contains(got, part) {
+			t.Errorf("relevance sort missing %q: %s", part, got)
+		}
+	}
+	// Promotions are shown in their own slots, not ranked up
+	if strings.Contains(got, "is_promoted") {
+		t.Errorf("relevance sort boosts promoted providers: %s", got)
+	}
+
+	if ValidSort(SortPrice, false) || !ValidSort(SortPrice, true) || ValidSort("newest", true) {
+		t.Error("unexpected ValidSort result")
+	}
+}
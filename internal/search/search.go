@@ -3,225 +3,507 @@ package search
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"jainfood/internal/db"
+	"jainfood/internal/delivery"
+	"jainfood/internal/dietary"
+	"jainfood/internal/hours"
+	"jainfood/internal/jainrules"
 	"jainfood/internal/models"
+	"jainfood/internal/places"
+	"jainfood/internal/promotions"
 )
 
 // SearchFilters holds filter criteria for provider/item search.
 type SearchFilters struct {
-	Lat           float64  // User's latitude
-	Lng           float64  // User's longitude
-	RadiusMeters  float64  // Search radius in meters
-	JainOnly      bool     // Only show Jain-compliant items
-	Tags          []string // Provider tags to filter by (e.g., "sattvic", "no-root-veggies")
-	MinRating     float64  // Minimum provider rating
-	PriceMax      float64  // Maximum item price (future)
-	Query         string   // Full-text search query
-	AvailableOnly bool     // Only show available items
+	Lat              float64             // User's latitude
+	Lng              float64             // User's longitude
+	RadiusMeters     float64             // Search radius in meters
+	JainOnly         bool                // Only show Jain-compliant items
+	Tags             []string            // Provider tags to filter by (e.g., "sattvic", "no-root-veggies")
+	ItemTags         []string            // Item dietary tags that must all be present (e.g., "tithi-safe")
+	MinRating        float64             // Minimum provider rating
+	PriceMin         float64             // Minimum item price
+	PriceMax         float64             // Maximum item price
+	Query            string              // Free-text query (items, menus, providers; Hindi or Latin)
+	AvailableOnly    bool                // Only show available items
+	ProviderCategory string              // e.g. tiffin-center
+	FoodCategory     string              // Provider food category, or the item's for item search
+	DeliverAt        time.Time           // Only providers that can deliver at this time; zero for any
+	Strictness       string              // Dietary profile strictness: relaxed, standard, strict
+	Exclusions       []dietary.Exclusion // Ingredient terms to exclude (profile, allergens, request)
+	Sort             string              // relevance (default), distance, rating, price
+	Weights          *RankWeights        // Ranking weights; nil uses DefaultRankWeights
+	ExplainRank      bool                // Attach a score breakdown to each result
+	PromotedSlots    int                 // Promoted slots to fill on the first page; 0 for none
+	PromotionDay     time.Time           // Local day promotion caps are counted on, as a UTC midnight
+}
+
+func (f SearchFilters) weights() RankWeights {
+	if f.Weights == nil {
+		return DefaultRankWeights
+	}
+	return *f.Weights
 }
 
 // ProviderSearchResult represents a provider in search results.
 type ProviderSearchResult struct {
 	models.Provider
-	Distance float64 `json:"distance_meters"`
+	Distance float64      `json:"distance_meters"`
+	Label    string       `json:"label,omitempty"`   // "Sponsored" in promoted slots
+	Ranking  *RankExplain `json:"ranking,omitempty"` // Score breakdown when requested
+
+	PromotionID string `json:"promotion_id,omitempty"` // Set in promoted slots, for click tracking
 }
 
-// SearchNearbyProviders finds providers within a radius using PostGIS.
-func SearchNearbyProviders(ctx context.Context, filters SearchFilters, limit, offset int) ([]*ProviderSearchResult, error) {
-	query := `
+// SearchNearbyProviders finds providers within a radius using PostGIS,
+// optionally matching a free-text query against name and categories. The
+// page after the cursor comes back with the total and facets of all matches,
+// and the first page with the matches in promoted slots. Callers record the
+// promoted impressions.
+func SearchNearbyProviders(ctx context.Context, filters SearchFilters, limit int, cursorToken string) (*ProviderPage, error) {
+	after, err := decodeCursor(cursorToken)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []interface{}{filters.Lng, filters.Lat, filters.RadiusMeters}
+	argIdx := 4
+	relevance, where := "0", ""
+	if filters.Query != "" {
+		cond, rel, textArgs := textMatch(filters.Query, argIdx, "search_vector", "search_key")
+		relevance, where = rel, " AND "+cond
+		args = append(args, textArgs...)
+		argIdx += len(textArgs)
+	}
+
+	w := filters.weights()
+	matched := `
 		SELECT id, user_id, business_name, address, 
 		       ST_Y(geo::geometry) as lat, ST_X(geo::geometry) as lng,
-		       verified, tags, rating, created_at,
-		       ST_Distance(geo, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography) as distance
+		       verified, tags, rating, COALESCE(total_ratings, 0), COALESCE(total_orders, 0),
+		       ` + promotions.RunningSQL("providers.id") + ` as is_promoted, created_at,
+		       COALESCE(provider_category, '') as provider_category,
+		       COALESCE(food_categories, '{}') as food_categories,
+		       ` + relevance + ` as relevance,
+		       ST_Distance(geo, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography) as distance,
+		       (` + w.sortKey(filters.Sort, "", relevance, providerDistanceSQL) + `)::float8 as sort_key
 		FROM providers
 		WHERE ST_DWithin(geo, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, $3)
-	`
-	args := []interface{}{filters.Lng, filters.Lat, filters.RadiusMeters}
-	argIdx := 4
+	` + where
 
 	// Add tag filtering
 	if len(filters.Tags) > 0 {
-		query += fmt.Sprintf(" AND tags && $%d", argIdx)
+		matched += fmt.Sprintf(" AND tags && $%d", argIdx)
 		args = append(args, filters.Tags)
 		argIdx++
 	}
 
 	// Add rating filter
 	if filters.MinRating > 0 {
-		query += fmt.Sprintf(" AND rating >= $%d", argIdx)
+		matched += fmt.Sprintf(" AND rating >= $%d", argIdx)
 		args = append(args, filters.MinRating)
 		argIdx++
 	}
 
-	// Only verified providers
-	query += " AND verified = TRUE"
+	// Category filters
+	if filters.ProviderCategory != "" {
+		matched += fmt.Sprintf(" AND provider_category = $%d", argIdx)
+		args = append(args, filters.ProviderCategory)
+		argIdx++
+	}
+	if filters.FoodCategory != "" {
+		matched += fmt.Sprintf(" AND $%d = ANY(food_categories)", argIdx)
+		args = append(args, filters.FoodCategory)
+		argIdx++
+	}
+
+	// Opening hours, holidays and order cut-offs
+	if !filters.DeliverAt.IsZero() {
+		matched += " AND " + hours.DeliverableSQL("providers.", fmt.Sprintf("$%d::timestamptz", argIdx))
+		args = append(args, filters.DeliverAt)
+		argIdx++
+	}
 
-	query += " ORDER BY distance ASC"
-	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
-	args = append(args, limit, offset)
+	// Only verified, unblocked providers that deliver to the buyer
+	matched += " AND verified = TRUE AND COALESCE(blocked, FALSE) = FALSE AND " + delivery.DeliversToSQL("providers.", searchPointSQL)
 
-	rows, err := db.Pool.Query(ctx, query, args...)
+	facets := facetsSQL(map[string]string{
+		"provider_category": termsFacet("provider_category", false),
+		"food_category":     termsFacet("food_categories", true),
+		"tags":              termsFacet("tags", true),
+		"rating":            rangeFacet("rating", RatingBands),
+		"distance":          rangeFacet("distance", DistanceBands),
+	})
+	query, pageArgs := pageSQL(matched, facets, after, limit, args)
+
+	rows, err := db.Pool.Query(ctx, query, pageArgs...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var results []*ProviderSearchResult
+	page := &ProviderPage{Results: []*ProviderSearchResult{}}
+	var last cursor
 	for rows.Next() {
 		r := &ProviderSearchResult{}
+		var rel, key float64
+		if err := rows.Scan(
+			&r.ID, &r.UserID, &r.BusinessName, &r.Address,
+			&r.Lat, &r.Lng, &r.Verified, &r.Tags, &r.Rating, &r.TotalRatings, &r.TotalOrders,
+			&r.IsPromoted, &r.CreatedAt, &r.ProviderCategory, &r.FoodCategories,
+			&rel, &r.Distance, &key,
+			&page.Total, &page.Facets,
+		); err != nil {
+			return nil, err
+		}
+		if len(page.Results) == limit {
+			page.NextCursor = last.encode()
+			break
+		}
+		last = cursor{Key: key, Distance: r.Distance, ID: r.ID}
+		if filters.ExplainRank {
+			r.Ranking = w.Explain(rel, r.Rating, r.TotalRatings, r.TotalOrders, r.Distance)
+		}
+		page.Results = append(page.Results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if after == nil && filters.PromotedSlots > 0 {
+		if page.Promoted, err = promotedProviders(ctx, matched, args, filters); err != nil {
+			return nil, err
+		}
+	}
+	if len(page.Results) == 0 {
+		return page, scanTotals(ctx, totalsSQL(matched, facets), args, &page.Page)
+	}
+	return page, nil
+}
+
+// promotedProviders fills the promoted slots from the matched providers
+// with a promotion running in a city around the search point, for every
+// category or the one filtered on. The least delivered promotions come
+// first.
+func promotedProviders(ctx context.Context, matched string, args []interface{}, filters SearchFilters) ([]*ProviderSearchResult, error) {
+	n := len(args)
+	city := fmt.Sprintf("pr.city_slug IN (SELECT slug FROM cities WHERE ST_DWithin(geo, %s, %d))", searchPointSQL, places.CityRadiusMeters)
+	query := `SELECT m.*, promo.id FROM (` + matched + `) m
+		JOIN LATERAL ` + promotions.PickSQL("m.id", city, fmt.Sprintf("$%d", n+1), fmt.Sprintf("$%d", n+2)) + ` ON TRUE
+		ORDER BY promo.delivered, random()
+		LIMIT ` + fmt.Sprintf("$%d", n+3)
+	rows, err := db.Pool.Query(ctx, query, append(args, filters.ProviderCategory, filters.PromotionDay, filters.PromotedSlots)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*ProviderSearchResult{}
+	for rows.Next() {
+		r := &ProviderSearchResult{Label: PromotedLabel}
+		var running bool
+		var rel, key float64
 		if err := rows.Scan(
 			&r.ID, &r.UserID, &r.BusinessName, &r.Address,
-			&r.Lat, &r.Lng, &r.Verified, &r.Tags, &r.Rating, &r.CreatedAt,
-			&r.Distance,
+			&r.Lat, &r.Lng, &r.Verified, &r.Tags, &r.Rating, &r.TotalRatings, &r.TotalOrders,
+			&running, &r.CreatedAt, &r.ProviderCategory, &r.FoodCategories,
+			&rel, &r.Distance, &key, &r.PromotionID,
 		); err != nil {
 			return nil, err
 		}
-		results = append(results, r)
+		r.IsPromoted = true
+		list = append(list, r)
 	}
-	return results, nil
+	return list, rows.Err()
 }
 
+const (
+	searchPointSQL      = "ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography"
+	providerDistanceSQL = "ST_Distance(geo, " + searchPointSQL + ")"
+	itemDistanceSQL     = "ST_Distance(p.geo, " + searchPointSQL + ")"
+)
+
 // ItemSearchResult represents an item in search results with provider info.
 type ItemSearchResult struct {
 	models.MenuItem
-	ProviderID       string  `json:"provider_id"`
-	ProviderName     string  `json:"provider_name"`
-	ProviderDistance float64 `json:"provider_distance_meters"`
+	ProviderID           string       `json:"provider_id"`
+	ProviderName         string       `json:"provider_name"`
+	ProviderDistance     float64      `json:"provider_distance_meters"`
+	ProviderRating       float64      `json:"provider_rating"`
+	ProviderTotalRatings int          `json:"provider_total_ratings"`
+	ProviderCategory     string       `json:"provider_category,omitempty"`
+	ProviderTags         []string     `json:"provider_tags,omitempty"`
+	Ranking              *RankExplain `json:"ranking,omitempty"` // Score breakdown when requested
+
+	providerTotalOrders int
+	relevance           float64
+}
+
+// ExcludedItem is a search hit removed by dietary exclusions.
+type ExcludedItem struct {
+	*ItemSearchResult
+	Reasons []string `json:"excluded_because"`
 }
 
 // SearchMenuItems searches for menu items with full-text search and filters.
-func SearchMenuItems(ctx context.Context, filters SearchFilters, limit, offset int) ([]*ItemSearchResult, error) {
-	query := `
-		SELECT mi.id, mi.menu_id, mi.name, mi.price, mi.ingredients, 
-		       mi.is_jain, mi.availability, mi.image_url, mi.created_at,
-		       p.id as provider_id, p.business_name,
-		       ST_Distance(p.geo, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography) as distance
+// The page after the cursor comes back with the total and facets of all matches.
+func SearchMenuItems(ctx context.Context, filters SearchFilters, limit int, cursorToken string) (*ItemPage, error) {
+	after, err := decodeCursor(cursorToken)
+	if err != nil {
+		return nil, err
+	}
+
+	base, exclusion, relevance, args := itemConditions(filters)
+	w := filters.weights()
+	matched := itemSelect(relevance, w.sortKey(filters.Sort, "p.", relevance, itemDistanceSQL)) + base
+	if exclusion != "" {
+		matched += " AND " + exclusion
+	}
+
+	facets := facetsSQL(map[string]string{
+		"provider_category": termsFacet("provider_category", false),
+		"food_category":     termsFacet("food_category", false),
+		"tags":              termsFacet("provider_tags", true),
+		"price":             rangeFacet("price", PriceRanges),
+		"rating":            rangeFacet("rating", RatingBands),
+		"distance":          rangeFacet("distance", DistanceBands),
+	})
+	query, pageArgs := pageSQL(matched, facets, after, limit, args)
+
+	rows, err := db.Pool.Query(ctx, query, pageArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &ItemPage{Results: []*ItemSearchResult{}}
+	var last cursor
+	for rows.Next() {
+		var key float64
+		r, err := scanItem(rows, &key, &page.Total, &page.Facets)
+		if err != nil {
+			return nil, err
+		}
+		if len(page.Results) == limit {
+			page.NextCursor = last.encode()
+			break
+		}
+		last = cursor{Key: key, Distance: r.ProviderDistance, ID: r.ID}
+		if filters.ExplainRank {
+			r.Ranking = w.Explain(r.relevance, r.ProviderRating, r.ProviderTotalRatings, r.providerTotalOrders, r.ProviderDistance)
+		}
+		page.Results = append(page.Results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(page.Results) == 0 {
+		return page, scanTotals(ctx, totalsSQL(matched, facets), args, &page.Page)
+	}
+	return page, nil
+}
+
+// SearchExcludedItems returns items that match the search but were removed
+// by the dietary exclusions, with the reasons each was excluded.
+func SearchExcludedItems(ctx context.Context, filters SearchFilters, limit int) ([]*ExcludedItem, error) {
+	base, exclusion, relevance, args := itemConditions(filters)
+	if exclusion == "" {
+		return []*ExcludedItem{}, nil
+	}
+	query := itemSelect(relevance, "0") + base + " AND NOT (" + exclusion + ")"
+	query += " ORDER BY distance ASC, mi.name ASC"
+	query += fmt.Sprintf(" LIMIT $%d", len(args)+1)
+	args = append(args, limit)
+
+	rows, err := db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	excluded := []*ExcludedItem{}
+	for rows.Next() {
+		var key float64
+		item, err := scanItem(rows, &key)
+		if err != nil {
+			return nil, err
+		}
+		excluded = append(excluded, &ExcludedItem{ItemSearchResult: item, Reasons: explain(filters, item)})
+	}
+	return excluded, rows.Err()
+}
+
+// SearchByIngredients searches items excluding certain ingredients (useful for Jain dietary filters).
+func SearchByIngredients(ctx context.Context, filters SearchFilters, excludeIngredients []string, limit int, cursorToken string) (*ItemPage, error) {
+	filters.AvailableOnly = true
+	for _, ing := range excludeIngredients {
+		filters.Exclusions = append(filters.Exclusions, dietary.Expand(ing)...)
+	}
+	return SearchMenuItems(ctx, filters, limit, cursorToken)
+}
+
+// itemSelect selects item rows with the provider columns the facets and
+// ranking need. sortKey is the ordering expression (see RankWeights.sortKey).
+func itemSelect(relevance, sortKey string) string {
+	return `
+		SELECT mi.id, mi.menu_id, mi.name, mi.price, COALESCE(mi.ingredients, '{}'),
+		       mi.is_jain, COALESCE(mi.jain_status, ''), COALESCE(mi.jain_reasons, '{}'), COALESCE(mi.dietary_tags, '{}'),
+		       COALESCE(mi.food_category, '') as food_category,
+		       mi.availability, mi.image_url, mi.created_at,
+		       p.id as provider_id, p.business_name, COALESCE(p.rating, 0) as rating, COALESCE(p.total_ratings, 0),
+		       COALESCE(p.total_orders, 0),
+		       COALESCE(p.provider_category, '') as provider_category, COALESCE(p.tags, '{}') as provider_tags,
+		       ` + relevance + ` as relevance,
+		       ST_Distance(p.geo, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography) as distance,
+		       (` + sortKey + `)::float8 as sort_key
 		FROM menu_items mi
 		JOIN menus m ON mi.menu_id = m.id
 		JOIN providers p ON m.provider_id = p.id
-		WHERE p.verified = TRUE
+		WHERE p.verified = TRUE AND COALESCE(p.blocked, FALSE) = FALSE
 		  AND ST_DWithin(p.geo, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, $3)
+		  AND ` + delivery.DeliversToSQL("p.", searchPointSQL) + `
 	`
-	args := []interface{}{filters.Lng, filters.Lat, filters.RadiusMeters}
+}
+
+// itemConditions builds the item search WHERE clause in two parts: the base
+// filters, and the dietary exclusions that SearchExcludedItems can invert.
+// relevance scores the text query ("0" without one).
+func itemConditions(filters SearchFilters) (base, exclusion, relevance string, args []interface{}) {
+	args = []interface{}{filters.Lng, filters.Lat, filters.RadiusMeters}
 	argIdx := 4
+	relevance = "0"
 
-	// Jain-only filter
+	// Jain-only filter (the provider's claim; the computed verdict is an exclusion)
 	if filters.JainOnly {
-		query += " AND mi.is_jain = TRUE"
+		base += " AND mi.is_jain = TRUE"
 	}
 
 	// Available items only
 	if filters.AvailableOnly {
-		query += " AND mi.availability = TRUE"
+		base += " AND mi.availability = TRUE"
 	}
 
-	// Full-text search on item name
+	// Text search over item, menu and provider text, plus fuzzy item and
+	// provider name keys
 	if filters.Query != "" {
-		query += fmt.Sprintf(" AND to_tsvector('english', mi.name) @@ plainto_tsquery('english', $%d)", argIdx)
-		args = append(args, filters.Query)
-		argIdx++
+		cond, rel, textArgs := textMatch(filters.Query, argIdx, "mi.search_vector", "mi.search_key", "p.search_key")
+		base += " AND " + cond
+		relevance = rel
+		args = append(args, textArgs...)
+		argIdx += len(textArgs)
 	}
 
 	// Provider tags filter
 	if len(filters.Tags) > 0 {
-		query += fmt.Sprintf(" AND p.tags && $%d", argIdx)
+		base += fmt.Sprintf(" AND p.tags && $%d", argIdx)
 		args = append(args, filters.Tags)
 		argIdx++
 	}
 
+	// Item dietary tags filter (observance days)
+	if len(filters.ItemTags) > 0 {
+		base += fmt.Sprintf(" AND mi.dietary_tags @> $%d", argIdx)
+		args = append(args, filters.ItemTags)
+		argIdx++
+	}
+
 	// Min rating filter
 	if filters.MinRating > 0 {
-		query += fmt.Sprintf(" AND p.rating >= $%d", argIdx)
+		base += fmt.Sprintf(" AND p.rating >= $%d", argIdx)
 		args = append(args, filters.MinRating)
 		argIdx++
 	}
 
-	// Price filter
+	// Category filters
+	if filters.ProviderCategory != "" {
+		base += fmt.Sprintf(" AND p.provider_category = $%d", argIdx)
+		args = append(args, filters.ProviderCategory)
+		argIdx++
+	}
+	if filters.FoodCategory != "" {
+		base += fmt.Sprintf(" AND mi.food_category = $%d", argIdx)
+		args = append(args, filters.FoodCategory)
+		argIdx++
+	}
+
+	// Price filters
+	if filters.PriceMin > 0 {
+		base += fmt.Sprintf(" AND mi.price >= $%d", argIdx)
+		args = append(args, filters.PriceMin)
+		argIdx++
+	}
 	if filters.PriceMax > 0 {
-		query += fmt.Sprintf(" AND mi.price <= $%d", argIdx)
+		base += fmt.Sprintf(" AND mi.price <= $%d", argIdx)
 		args = append(args, filters.PriceMax)
 		argIdx++
 	}
 
-	query += " ORDER BY distance ASC, mi.name ASC"
-	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
-	args = append(args, limit, offset)
-
-	rows, err := db.Pool.Query(ctx, query, args...)
-	if err != nil {
-		return nil, err
+	// Opening hours, holidays and order cut-offs
+	if !filters.DeliverAt.IsZero() {
+		base += " AND " + hours.DeliverableSQL("p.", fmt.Sprintf("$%d::timestamptz", argIdx))
+		args = append(args, filters.DeliverAt)
+		argIdx++
 	}
-	defer rows.Close()
 
-	var results []*ItemSearchResult
-	for rows.Next() {
-		r := &ItemSearchResult{}
-		if err := rows.Scan(
-			&r.ID, &r.MenuID, &r.Name, &r.Price, &r.Ingredients,
-			&r.IsJain, &r.Availability, &r.ImageURL, &r.CreatedAt,
-			&r.ProviderID, &r.ProviderName, &r.ProviderDistance,
-		); err != nil {
-			return nil, err
-		}
-		results = append(results, r)
-	}
-	return results, nil
-}
+	var excl []string
 
-// SearchByIngredients searches items excluding certain ingredients (useful for Jain dietary filters).
-func SearchByIngredients(ctx context.Context, filters SearchFilters, excludeIngredients []string, limit, offset int) ([]*ItemSearchResult, error) {
-	query := `
-		SELECT mi.id, mi.menu_id, mi.name, mi.price, mi.ingredients, 
-		       mi.is_jain, mi.availability, mi.image_url, mi.created_at,
-		       p.id as provider_id, p.business_name,
-		       ST_Distance(p.geo, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography) as distance
-		FROM menu_items mi
-		JOIN menus m ON mi.menu_id = m.id
-		JOIN providers p ON m.provider_id = p.id
-		WHERE p.verified = TRUE
-		  AND mi.availability = TRUE
-		  AND ST_DWithin(p.geo, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, $3)
-	`
-	args := []interface{}{filters.Lng, filters.Lat, filters.RadiusMeters}
-	argIdx := 4
+	// Computed Jain verdict: items flagged by the ingredient checker don't
+	// count even if claimed; strict also drops "caution" items
+	switch {
+	case filters.Strictness == models.StrictnessStrict:
+		excl = append(excl, "COALESCE(mi.jain_status, 'jain') = 'jain'")
+	case filters.JainOnly || filters.Strictness == models.StrictnessStandard:
+		excl = append(excl, "COALESCE(mi.jain_status, 'jain') <> 'non-jain'")
+	}
 
-	// Exclude ingredients (for Jain: no onion, garlic, root vegetables, etc.)
-	if len(excludeIngredients) > 0 {
-		// Use NOT (ingredients && ARRAY[...]) to exclude items containing these ingredients
-		placeholders := make([]string, len(excludeIngredients))
-		for i := range excludeIngredients {
-			placeholders[i] = fmt.Sprintf("$%d", argIdx+i)
+	// Ingredient exclusions, matched as whole words in the name or any ingredient
+	if len(filters.Exclusions) > 0 {
+		patterns := make([]string, len(filters.Exclusions))
+		for i, ex := range filters.Exclusions {
+			patterns[i] = `\m` + regexp.QuoteMeta(strings.ToLower(ex.Term)) + `\M`
 		}
-		query += fmt.Sprintf(" AND NOT (mi.ingredients && ARRAY[%s]::text[])", strings.Join(placeholders, ","))
-		for _, ing := range excludeIngredients {
-			args = append(args, ing)
-		}
-		argIdx += len(excludeIngredients)
+		excl = append(excl, fmt.Sprintf(
+			"NOT (mi.name ~* ANY($%d) OR EXISTS (SELECT 1 FROM unnest(mi.ingredients) AS ing WHERE ing ~* ANY($%d)))",
+			argIdx, argIdx))
+		args = append(args, patterns)
+		argIdx++
 	}
 
-	query += " ORDER BY distance ASC, mi.name ASC"
-	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
-	args = append(args, limit, offset)
+	return base, strings.Join(excl, " AND "), relevance, args
+}
 
-	rows, err := db.Pool.Query(ctx, query, args...)
-	if err != nil {
+// scanItem scans an itemSelect row into key (the sort key), followed by any
+// extra columns.
+func scanItem(rows pgx.Rows, key *float64, extra ...interface{}) (*ItemSearchResult, error) {
+	r := &ItemSearchResult{}
+	dest := []interface{}{
+		&r.ID, &r.MenuID, &r.Name, &r.Price, &r.Ingredients,
+		&r.IsJain, &r.JainStatus, &r.JainReasons, &r.DietaryTags, &r.FoodCategory,
+		&r.Availability, &r.ImageURL, &r.CreatedAt,
+		&r.ProviderID, &r.ProviderName, &r.ProviderRating, &r.ProviderTotalRatings,
+		&r.providerTotalOrders, &r.ProviderCategory, &r.ProviderTags,
+		&r.relevance, &r.ProviderDistance, key,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return r, nil
+}
 
-	var results []*ItemSearchResult
-	for rows.Next() {
-		r := &ItemSearchResult{}
-		if err := rows.Scan(
-			&r.ID, &r.MenuID, &r.Name, &r.Price, &r.Ingredients,
-			&r.IsJain, &r.Availability, &r.ImageURL, &r.CreatedAt,
-			&r.ProviderID, &r.ProviderName, &r.ProviderDistance,
-		); err != nil {
-			return nil, err
+// explain lists why an item failed the dietary exclusions.
+func explain(filters SearchFilters, item *ItemSearchResult) []string {
+	var reasons []string
+	switch {
+	case item.JainStatus == jainrules.VerdictNonJain && (filters.JainOnly || filters.Strictness != models.StrictnessRelaxed && filters.Strictness != ""):
+		reasons = append(reasons, item.JainReasons...)
+	case item.JainStatus == jainrules.VerdictCaution && filters.Strictness == models.StrictnessStrict:
+		for _, r := range item.JainReasons {
+			reasons = append(reasons, r+" (strict profile)")
 		}
-		results = append(results, r)
 	}
-	return results, nil
+	return append(reasons, dietary.Explain(filters.Exclusions, item.Name, item.Ingredients)...)
 }
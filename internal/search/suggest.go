@@ -0,0 +1,200 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+	"jainfood/internal/redisclient"
+	"jainfood/internal/translit"
+)
+
+// Suggestion types returned by Suggest.
+const (
+	SuggestDish     = "dish"
+	SuggestProvider = "provider"
+	SuggestCategory = "category"
+	SuggestCity     = "city"
+)
+
+// SuggestRadiusMeters bounds dish and provider suggestions around the caller.
+const SuggestRadiusMeters = 15000
+
+// Suggestion is one typeahead entry.
+type Suggestion struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	ID    string `json:"id,omitempty"`    // Provider ID, city slug or category value
+	Group string `json:"group,omitempty"` // provider_category or food_category, for categories
+
+	key        string
+	popularity float64
+}
+
+// suggestCategories are matched in Go; their popularity comes from nearby providers.
+var suggestCategories = func() []*Suggestion {
+	var out []*Suggestion
+	add := func(group string, values ...string) {
+		for _, v := range values {
+			label := strings.ReplaceAll(v, "-", " ")
+			out = append(out, &Suggestion{Type: SuggestCategory, Text: strings.ToUpper(label[:1]) + label[1:], ID: v, Group: group, key: translit.Key(label)})
+		}
+	}
+	add("provider_category",
+		models.ProviderCategoryTiffinCenter, models.ProviderCategoryCaterer, models.ProviderCategoryBhojnalaya,
+		models.ProviderCategoryRestaurant, models.ProviderCategoryBaker, models.ProviderCategoryRawMaterial,
+		models.ProviderCategorySodhKhana, models.ProviderCategoryHomeChef, models.ProviderCategoryChaukaBai)
+	add("food_category",
+		models.FoodCategoryRawMaterials, models.FoodCategoryBakery, models.FoodCategorySweets,
+		models.FoodCategoryIcecream, models.FoodCategoryNamkeen, models.FoodCategoryDryFruits,
+		models.FoodCategoryTiffinThali, models.FoodCategorySodhKhana, models.FoodCategorySodhSamgri,
+		models.FoodCategoryNirvaanLaddu)
+	return out
+}()
+
+// prefixMatch scores how well key matches the typed prefix: 1 for a prefix
+// of the whole key, 0.8 for a prefix of a later word, 0 otherwise.
+func prefixMatch(key, prefix string) float64 {
+	switch {
+	case strings.HasPrefix(key, prefix):
+		return 1
+	case strings.Contains(key, " "+prefix):
+		return 0.8
+	}
+	return 0
+}
+
+// suggestScore blends prefix quality with log-scaled popularity.
+func suggestScore(s *Suggestion, prefix string) float64 {
+	return 0.6*prefixMatch(s.key, prefix) + 0.4*math.Min(1, math.Log1p(s.popularity)/math.Log1p(1000))
+}
+
+// Suggest returns mixed dish, provider, category and city suggestions for a
+// partially typed query, ranked by match quality and popularity near
+// lat/lng (anywhere when both are zero).
+func Suggest(ctx context.Context, q string, lat, lng float64, limit int) ([]*Suggestion, error) {
+	prefix := translit.Key(q)
+	if prefix == "" {
+		return []*Suggestion{}, nil
+	}
+
+	var categories []*Suggestion
+	var categoryIDs []string
+	for _, c := range suggestCategories {
+		if prefixMatch(c.key, prefix) > 0 {
+			categories = append(categories, c)
+			categoryIDs = append(categoryIDs, c.ID)
+		}
+	}
+
+	// Word prefixes need the trigram index, which only helps from 3 characters
+	match := func(col string) string {
+		if len(prefix) < 3 {
+			return fmt.Sprintf("%s LIKE $1 || '%%'", col)
+		}
+		return fmt.Sprintf("(%s LIKE $1 || '%%' OR %s LIKE '%% ' || $1 || '%%')", col, col)
+	}
+	if categoryIDs == nil {
+		categoryIDs = []string{}
+	}
+	args := []interface{}{prefix, limit, categoryIDs}
+	near := "TRUE"
+	if lat != 0 || lng != 0 {
+		near = "ST_DWithin(p.geo, ST_SetSRID(ST_MakePoint($4, $5), 4326)::geography, $6)"
+		args = append(args, lng, lat, float64(SuggestRadiusMeters))
+	}
+
+	query := `
+		(SELECT 'dish', MIN(mi.name), '', mi.search_key, (SUM(COALESCE(p.total_orders, 0)) + COUNT(*))::float8
+		 FROM menu_items mi
+		 JOIN menus m ON mi.menu_id = m.id
+		 JOIN providers p ON m.provider_id = p.id
+		 WHERE p.verified = TRUE AND COALESCE(p.blocked, FALSE) = FALSE AND mi.availability = TRUE AND ` + near + ` AND ` + match("mi.search_key") + `
+		 GROUP BY mi.search_key ORDER BY 5 DESC LIMIT $2)
+		UNION ALL
+		(SELECT 'provider', p.business_name, p.id::text, p.search_key, (COALESCE(p.total_orders, 0) + COALESCE(p.total_ratings, 0))::float8
+		 FROM providers p
+		 WHERE p.verified = TRUE AND COALESCE(p.blocked, FALSE) = FALSE AND ` + near + ` AND ` + match("p.search_key") + `
+		 ORDER BY 5 DESC LIMIT $2)
+		UNION ALL
+		(SELECT 'city', c.name, c.slug, c.search_key,
+		        (SELECT COUNT(*) FROM providers p WHERE p.verified = TRUE AND COALESCE(p.blocked, FALSE) = FALSE AND ST_DWithin(p.geo, c.geo, 25000))::float8
+		 FROM cities c WHERE ` + match("c.search_key") + `
+		 ORDER BY 5 DESC LIMIT $2)
+		UNION ALL
+		(SELECT 'category', cat, cat, '', COUNT(p.id)::float8
+		 FROM unnest($3::text[]) AS cat
+		 LEFT JOIN providers p ON p.verified = TRUE AND COALESCE(p.blocked, FALSE) = FALSE AND ` + near + `
+		      AND (p.provider_category = cat OR cat = ANY(p.food_categories))
+		 GROUP BY cat)
+	`
+
+	rows, err := db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	popularity := map[string]float64{}
+	var results []*Suggestion
+	for rows.Next() {
+		s := &Suggestion{}
+		if err := rows.Scan(&s.Type, &s.Text, &s.ID, &s.key, &s.popularity); err != nil {
+			return nil, err
+		}
+		if s.Type == SuggestCategory {
+			popularity[s.ID] = s.popularity
+			continue
+		}
+		results = append(results, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, c := range categories {
+		s := *c
+		s.popularity = popularity[c.ID]
+		results = append(results, &s)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return suggestScore(results[i], prefix) > suggestScore(results[j], prefix)
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// CachedSuggest wraps Suggest with a short-lived Redis cache keyed by the
+// normalised prefix and the location rounded to ~1 km. Cache failures fall
+// through to the database. The second return reports a cache hit.
+func CachedSuggest(ctx context.Context, q string, lat, lng float64, limit int, ttl time.Duration) ([]*Suggestion, bool, error) {
+	lat, lng = math.Round(lat*100)/100, math.Round(lng*100)/100
+	key := fmt.Sprintf("suggest:%s:%.2f:%.2f:%d", translit.Key(q), lat, lng, limit)
+	if redisclient.Rdb != nil && ttl > 0 {
+		if cached, err := redisclient.Rdb.Get(ctx, key).Bytes(); err == nil {
+			var results []*Suggestion
+			if json.Unmarshal(cached, &results) == nil {
+				return results, true, nil
+			}
+		}
+	}
+
+	results, err := Suggest(ctx, q, lat, lng, limit)
+	if err != nil {
+		return nil, false, err
+	}
+	if redisclient.Rdb != nil && ttl > 0 {
+		if data, err := json.Marshal(results); err == nil {
+			_ = redisclient.Rdb.Set(ctx, key, data, ttl).Err()
+		}
+	}
+	return results, false, nil
+}
@@ -0,0 +1,33 @@
+package search
+
+import "testing"
+
+func TestPrefixMatch(t *testing.T) {
+	if prefixMatch("paner tika", "pan") != 1 || prefixMatch("paner tika", "tik") != 0.8 || prefixMatch("paner tika", "ika") != 0 {
+		t.Error("unexpected prefix match scores")
+	}
+}
+
+func TestSuggestScorePrefersPopularWholePrefix(t *testing.T) {
+	whole := &Suggestion{key: "khakhra", popularity: 40}
+	word := &Suggestion{key: "masala khakhra", popularity: 40}
+	popular := &Suggestion{key: "methi khakhra", popularity: 900}
+	if suggestScore(whole, "khak") <= suggestScore(word, "khak") {
+		t.Error("whole-key prefix should outrank word prefix at equal popularity")
+	}
+	if suggestScore(popular, "khak") <= suggestScore(word, "khak") {
+		t.Error("popularity should break ties between word-prefix matches")
+	}
+}
+
+func TestSuggestCategories(t *testing.T) {
+	found := map[string]string{}
+	for _, c := range suggestCategories {
+		if prefixMatch(c.key, "tifin") > 0 {
+			found[c.ID] = c.Group
+		}
+	}
+	if found["tiffin-center"] != "provider_category" || found["tiffin-thali"] != "food_category" {
+		t.Errorf("unexpected category matches %v", found)
+	}
+}
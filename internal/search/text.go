@@ -0,0 +1,93 @@
+package search
+
+import (
+	"context"
+	"fmt"
+
+	"jainfood/internal/db"
+	"jainfood/internal/translit"
+)
+
+// textMatch builds the condition and relevance for a free-text query. The
+// weighted search_vector is matched with both the raw query (Devanagari or
+// exact spelling) and its phonetic key; keys are trigram columns matched
+// fuzzily against the key, so "khakra" still finds "खाखरा".
+func textMatch(query string, argIdx int, vector string, keys ...string) (cond, relevance string, args []interface{}) {
+	q, k := argIdx, argIdx+1
+	tsq := fmt.Sprintf("(plainto_tsquery('simple', $%d) || plainto_tsquery('simple', $%d))", q, k)
+
+	cond = fmt.Sprintf("(%s @@ %s", vector, tsq)
+	relevance = fmt.Sprintf("GREATEST(ts_rank(%s, %s, 32)", vector, tsq)
+	for _, key := range keys {
+		cond += fmt.Sprintf(" OR $%d <%% %s", k, key)
+		relevance += fmt.Sprintf(", word_similarity($%d, %s)", k, key)
+	}
+	return cond + ")", relevance + ")", []interface{}{query, translit.Key(query)}
+}
+
+// ReindexResult counts rows whose search key changed.
+type ReindexResult struct {
+	Providers int `json:"providers"`
+	MenuItems int `json:"menu_items"`
+}
+
+// Reindex recomputes the phonetic search keys of all providers and items,
+// e.g. after the transliteration rules change. The search_vector triggers
+// pick up the new keys.
+func Reindex(ctx context.Context) (*ReindexResult, error) {
+	return reindex(ctx, false)
+}
+
+// BackfillKeys computes the phonetic keys of rows still carrying the
+// lowercased-name placeholder that migration 0014 backfilled, so
+// Devanagari names become searchable without a manual reindex. The API runs
+// it at startup; rows already keyed are only re-read.
+func BackfillKeys(ctx context.Context) (*ReindexResult, error) {
+	return reindex(ctx, true)
+}
+
+func reindex(ctx context.Context, placeholdersOnly bool) (*ReindexResult, error) {
+	res := &ReindexResult{}
+	var err error
+	if res.Providers, err = reindexTable(ctx, "providers", "business_name", placeholdersOnly); err != nil {
+		return nil, err
+	}
+	if res.MenuItems, err = reindexTable(ctx, "menu_items", "name", placeholdersOnly); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func reindexTable(ctx context.Context, table, column string, placeholdersOnly bool) (int, error) {
+	query := fmt.Sprintf("SELECT id, COALESCE(%s, ''), COALESCE(search_key, '') FROM %s", column, table)
+	if placeholdersOnly {
+		query += fmt.Sprintf(" WHERE search_key IS NULL OR search_key = lower(%s)", column)
+	}
+	rows, err := db.Pool.Query(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	keys := map[string]string{}
+	for rows.Next() {
+		var id, text, current string
+		if err := rows.Scan(&id, &text, &current); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if key := translit.Key(text); key != current {
+			keys[id] = key
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	update := fmt.Sprintf("UPDATE %s SET search_key = $2 WHERE id = $1", table)
+	for id, key := range keys {
+		if _, err := db.Pool.Exec(ctx, update, id, key); err != nil {
+			return 0, err
+		}
+	}
+	return len(keys), nil
+}
@@ -0,0 +1,21 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTextMatch(t *testing.T) {
+	cond, rel, args := textMatch("खाखरा", 4, "mi.search_vector", "mi.search_key", "p.search_key")
+	if len(args) != 2 || args[0] != "खाखरा" || args[1] != "khakhra" {
+		t.Fatalf("unexpected args %v", args)
+	}
+	for _, part := range []string{"mi.search_vector @@", "plainto_tsquery('simple', $4)", "$5 <% mi.search_key", "$5 <% p.search_key"} {
+		if !strings.Contains(cond, part) {
+			t.Errorf("condition missing %q: %s", part, cond)
+		}
+	}
+	if !strings.HasPrefix(rel, "GREATEST(ts_rank(") || !strings.Contains(rel, "word_similarity($5, p.search_key)") {
+		t.Errorf("unexpected relevance %s", rel)
+	}
+}
@@ -0,0 +1,278 @@
+package topics
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+	"jainfood/internal/places"
+	"jainfood/internal/users"
+)
+
+// Topic name prefixes. Names only use characters FCM accepts in topic names.
+const (
+	PrefixCity     = "city-"
+	PrefixPin      = "pin-"
+	PrefixCategory = "category-"
+	PrefixFood     = "food-"
+)
+
+var (
+	pinPattern   = regexp.MustCompile(`^[1-9][0-9]{5}$`)
+	topicPattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+	slugStrip    = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+var providerCategories = map[string]bool{
+	models.ProviderCategoryTiffinCenter: true,
+	models.ProviderCategoryCaterer:      true,
+	models.ProviderCategoryBhojnalaya:   true,
+	models.ProviderCategoryRestaurant:   true,
+	models.ProviderCategoryBaker:        true,
+	models.ProviderCategoryRawMaterial:  true,
+	models.ProviderCategorySodhKhana:    true,
+	models.ProviderCategoryHomeChef:     true,
+	models.ProviderCategoryChaukaBai:    true,
+}
+
+var foodCategories = map[string]bool{
+	models.FoodCategoryRawMaterials: true,
+	models.FoodCategoryBakery:       true,
+	models.FoodCategorySweets:       true,
+	models.FoodCategoryIcecream:     true,
+	models.FoodCategoryNamkeen:      true,
+	models.FoodCategoryDryFruits:    true,
+	models.FoodCategoryTiffinThali:  true,
+	models.FoodCategorySodhKhana:    true,
+	models.FoodCategorySodhSamgri:   true,
+	models.FoodCategoryNirvaanLaddu: true,
+}
+
+// Slug lowercases s and collapses anything that isn't a letter or digit into "-".
+func Slug(s string) string {
+	return strings.Trim(slugStrip.ReplaceAllString(strings.ToLower(strings.TrimSpace(s)), "-"), "-")
+}
+
+// CityTopic returns the topic for a city name, e.g. "Indore" -> "city-indore".
+func CityTopic(city string) (string, error) {
+	slug := Slug(city)
+	if slug == "" {
+		return "", fmt.Errorf("invalid city %q", city)
+	}
+	return PrefixCity + slug, nil
+}
+
+// PinTopic returns the topic for an Indian pin code.
+func PinTopic(pin string) (string, error) {
+	pin = strings.TrimSpace(pin)
+	if !pinPattern.MatchString(pin) {
+		return "", fmt.Errorf("invalid pin code %q", pin)
+	}
+	return PrefixPin + pin, nil
+}
+
+// CategoryTopic returns the topic for a provider category.
+func CategoryTopic(category string) (string, error) {
+	if !providerCategories[category] {
+		return "", fmt.Errorf("unknown provider category %q", category)
+	}
+	return PrefixCategory + category, nil
+}
+
+// FoodTopic returns the topic for a food category.
+func FoodTopic(category string) (string, error) {
+	if !foodCategories[category] {
+		return "", fmt.Errorf("unknown food category %q", category)
+	}
+	return PrefixFood + category, nil
+}
+
+// Valid reports whether topic is a well-formed topic name with a known prefix.
+func Valid(topic string) bool {
+	if !topicPattern.MatchString(topic) {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(topic, PrefixCity):
+		return len(topic) > len(PrefixCity)
+	case strings.HasPrefix(topic, PrefixPin):
+		return pinPattern.MatchString(strings.TrimPrefix(topic, PrefixPin))
+	case strings.HasPrefix(topic, PrefixCategory):
+		return providerCategories[strings.TrimPrefix(topic, PrefixCategory)]
+	case strings.HasPrefix(topic, PrefixFood):
+		return foodCategories[strings.TrimPrefix(topic, PrefixFood)]
+	}
+	return false
+}
+
+// Interests is what a user chooses to hear about; each field maps to topics.
+type Interests struct {
+	Cities             []string `json:"cities"`
+	PinCodes           []string `json:"pin_codes"`
+	ProviderCategories []string `json:"provider_categories"`
+	FoodCategories     []string `json:"food_categories"`
+}
+
+// FromInterests derives the de-duplicated topic list for a set of interests.
+func FromInterests(in Interests) ([]string, error) {
+	seen := map[string]bool{}
+	var out []string
+	add := func(topic string, err error) error {
+		if err != nil {
+			return err
+		}
+		if !seen[topic] {
+			seen[topic] = true
+			out = append(out, topic)
+		}
+		return nil
+	}
+
+	for _, c := range in.Cities {
+		if err := add(CityTopic(c)); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range in.PinCodes {
+		if err := add(PinTopic(p)); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range in.ProviderCategories {
+		if err := add(CategoryTopic(c)); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range in.FoodCategories {
+		if err := add(FoodTopic(f)); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// ForProvider returns the topics a provider's own profile falls under. Invalid
+// or unset profile fields are skipped.
+func ForProvider(city, pinCode, category string, foods []string) []string {
+	var out []string
+	if t, err := CityTopic(city); err == nil {
+		out = append(out, t)
+	}
+	if t, err := PinTopic(pinCode); err == nil {
+		out = append(out, t)
+	}
+	if t, err := CategoryTopic(category); err == nil {
+		out = append(out, t)
+	}
+	for _, f := range foods {
+		if t, err := FoodTopic(f); err == nil {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// ============================================
+// SUBSCRIPTION STORAGE
+// ============================================
+
+// SetSubscriptions replaces all of a user's topic subscriptions.
+func SetSubscriptions(ctx context.Context, userID string, topics []string) error {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM topic_subscriptions WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, t := range topics {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO topic_subscriptions (user_id, topic) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, userID, t); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// Subscribe adds a single topic subscription.
+func Subscribe(ctx context.Context, userID, topic string) error {
+	_, err := db.Pool.Exec(ctx, `
+		INSERT INTO topic_subscriptions (user_id, topic) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, userID, topic)
+	return err
+}
+
+// Unsubscribe removes a single topic subscription.
+func Unsubscribe(ctx context.Context, userID, topic string) error {
+	ct, err := db.Pool.Exec(ctx, `DELETE FROM topic_subscriptions WHERE user_id = $1 AND topic = $2`, userID, topic)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return db.ErrNotFound
+	}
+	return nil
+}
+
+// GetSubscriptions lists a user's topics.
+func GetSubscriptions(ctx context.Context, userID string) ([]string, error) {
+	return queryStrings(ctx, `SELECT topic FROM topic_subscriptions WHERE user_id = $1 ORDER BY topic`, userID)
+}
+
+// GetProviderTopics returns the topics matching a provider's profile. The
+// provider's city is its pin code's, or else the directory city it is in.
+func GetProviderTopics(ctx context.Context, providerID string) ([]string, error) {
+	var city, pin, category string
+	var foods []string
+	err := db.Pool.QueryRow(ctx, `
+		SELECT COALESCE(
+		         (SELECT pc.city FROM pin_codes pc WHERE pc.pin = p.pin_code),
+		         (SELECT c.name FROM cities c WHERE ST_DWithin(c.geo, p.geo, $2)
+		          ORDER BY c.geo <-> p.geo LIMIT 1),
+		         ''),
+		       COALESCE(p.pin_code, ''), COALESCE(p.provider_category, ''), COALESCE(p.food_categories, '{}')
+		FROM providers p WHERE p.id = $1
+	`, providerID, float64(places.CityRadiusMeters)).Scan(&city, &pin, &category, &foods)
+	if err != nil {
+		return nil, err
+	}
+	return ForProvider(city, pin, category, foods), nil
+}
+
+// Store adapts topic subscriptions to push.TopicStore.
+type Store struct{}
+
+// TopicSubscribers returns the IDs of non-blocked users subscribed to topic.
+func (Store) TopicSubscribers(ctx context.Context, topic string) ([]string, error) {
+	return queryStrings(ctx, `
+		SELECT s.user_id FROM topic_subscriptions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.topic = $1 AND NOT `+users.BlockedSQL+`
+	`, topic)
+}
+
+func queryStrings(ctx context.Context, sql string, args ...interface{}) ([]string, error) {
+	rows, err := db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []string{}
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
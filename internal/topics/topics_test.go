@@ -0,0 +1,90 @@
+package topics
+
+import (
+	"testing"
+
+	"jainfood/internal/models"
+)
+
+func TestCityTopic(t *testing.T) {
+	tests := map[string]string{
+		"Indore":         "city-indore",
+		"  New Delhi ":   "city-new-delhi",
+		"Navi Mumbai!!":  "city-navi-mumbai",
+		"Sawai Madhopur": "city-sawai-madhopur",
+	}
+	for in, want := range tests {
+		got, err := CityTopic(in)
+		if err != nil || got != want {
+			t.Errorf("CityTopic(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := CityTopic("  !! "); err == nil {
+		t.Error("expected error for empty city slug")
+	}
+}
+
+func TestPinTopic(t *testing.T) {
+	if got, err := PinTopic("452001"); err != nil || got != "pin-452001" {
+		t.Errorf("PinTopic = %q, %v", got, err)
+	}
+	for _, bad := range []string{"052001", "45200", "4520011", "abcdef"} {
+		if _, err := PinTopic(bad); err == nil {
+			t.Errorf("expected error for pin %q", bad)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	valid := []string{"city-indore", "pin-452001", "category-tiffin-center", "food-sweets"}
+	invalid := []string{"", "city-", "pin-12", "category-unknown", "food-pizza", "Indore", "city-Indore", "other-x"}
+	for _, topic := range valid {
+		if !Valid(topic) {
+			t.Errorf("expected %q to be valid", topic)
+		}
+	}
+	for _, topic := range invalid {
+		if Valid(topic) {
+			t.Errorf("expected %q to be invalid", topic)
+		}
+	}
+}
+
+func TestFromInterests(t *testing.T) {
+	got, err := FromInterests(Interests{
+		Cities:             []string{"Indore", "indore"},
+		PinCodes:           []string{"452001"},
+		ProviderCategories: []string{models.ProviderCategoryTiffinCenter},
+		FoodCategories:     []string{models.FoodCategorySweets},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"city-indore", "pin-452001", "category-tiffin-center", "food-sweets"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("topic %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if _, err := FromInterests(Interests{FoodCategories: []string{"pizza"}}); err == nil {
+		t.Error("expected error for unknown food category")
+	}
+}
+
+func TestForProviderSkipsUnsetFields(t *testing.T) {
+	got := ForProvider("", "", models.ProviderCategoryCaterer, []string{models.FoodCategoryNamkeen, "unknown"})
+	if len(got) != 2 || got[0] != "category-caterer" || got[1] != "food-namkeen" {
+		t.Errorf("unexpected provider topics %v", got)
+	}
+}
+
+func TestForProviderIncludesCity(t *testing.T) {
+	got := ForProvider("Navi Mumbai", "400703", "", nil)
+	if len(got) != 2 || got[0] != "city-navi-mumbai" || got[1] != "pin-400703" {
+		t.Errorf("unexpected provider topics %v", got)
+	}
+}
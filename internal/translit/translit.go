@@ -0,0 +1,170 @@
+// Package translit romanises Devanagari and reduces Hindi, Hinglish and
+// English spellings to a common phonetic key, so "खाखरा", "khakhra" and
+// "khaakhra" search the same way.
+package translit
+
+import (
+	"strings"
+	"unicode"
+)
+
+var consonants = map[rune]string{
+	'क': "k", 'ख': "kh", 'ग': "g", 'घ': "gh", 'ङ': "n",
+	'च': "ch", 'छ': "chh", 'ज': "j", 'झ': "jh", 'ञ': "n",
+	'ट': "t", 'ठ': "th", 'ड': "d", 'ढ': "dh", 'ण': "n",
+	'त': "t", 'थ': "th", 'द': "d", 'ध': "dh", 'न': "n",
+	'प': "p", 'फ': "ph", 'ब': "b", 'भ': "bh", 'म': "m",
+	'य': "y", 'र': "r", 'ल': "l", 'व': "v", 'श': "sh",
+	'ष': "sh", 'स': "s", 'ह': "h", 'ळ': "l",
+	// Precomposed nukta forms (क़ ख़ ग़ ज़ ड़ ढ़ फ़ य़)
+	'क़': "q", 'ख़': "kh", 'ग़': "g", 'ज़': "z",
+	'ड़': "d", 'ढ़': "dh", 'फ़': "f", 'य़': "y",
+}
+
+// nukta changes the sound of the preceding consonant. ड़/ढ़ stay "d"/"dh",
+// as menus spell them (khichdi, papad, pakoda).
+var nuktaForms = map[string]string{"k": "q", "j": "z", "ph": "f"}
+
+var vowels = map[rune]string{
+	'अ': "a", 'आ': "aa", 'इ': "i", 'ई': "i", 'उ': "u", 'ऊ': "u", // mithai, not mithaee
+	'ऋ': "ri", 'ए': "e", 'ऐ': "ai", 'ओ': "o", 'औ': "au", 'ऑ': "o",
+}
+
+var matras = map[rune]string{
+	'ा': "aa", 'ि': "i", 'ी': "ee", 'ु': "u", 'ू': "oo", 'ृ': "ri",
+	'े': "e", 'ै': "ai", 'ो': "o", 'ौ': "au", 'ॉ': "o",
+}
+
+const (
+	virama     = '्'
+	nukta      = '़'
+	anusvara   = 'ं'
+	chandrabin = 'ँ'
+	visarga    = 'ः'
+)
+
+// syllable is a consonant with its vowel, or a standalone vowel.
+type syllable struct {
+	consonant string
+	vowel     string
+	inherent  bool   // vowel is the implicit "a", subject to schwa deletion
+	coda      string // anusvara/visarga after the vowel
+}
+
+// IsDevanagari reports whether s contains any Devanagari letters.
+func IsDevanagari(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Devanagari) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToLatin romanises Devanagari text in the informal Hinglish style people
+// type (long vowels doubled, no diacritics). Other text passes through.
+func ToLatin(s string) string {
+	var out strings.Builder
+	var word []syllable
+	flush := func() {
+		out.WriteString(renderWord(word))
+		word = word[:0]
+	}
+
+	for _, r := range s {
+		switch {
+		case consonants[r] != "":
+			word = append(word, syllable{consonant: consonants[r], vowel: "a", inherent: true})
+		case vowels[r] != "":
+			word = append(word, syllable{vowel: vowels[r]})
+		case matras[r] != "" && len(word) > 0:
+			last := &word[len(word)-1]
+			last.vowel, last.inherent = matras[r], false
+		case r == virama && len(word) > 0:
+			last := &word[len(word)-1]
+			last.vowel, last.inherent = "", false
+		case r == nukta && len(word) > 0:
+			last := &word[len(word)-1]
+			if f, ok := nuktaForms[last.consonant]; ok {
+				last.consonant = f
+			}
+		case (r == anusvara || r == chandrabin) && len(word) > 0:
+			word[len(word)-1].coda = "n"
+			word[len(word)-1].inherent = false
+		case r == visarga && len(word) > 0:
+			word[len(word)-1].coda = "h"
+			word[len(word)-1].inherent = false
+		case r >= '०' && r <= '९':
+			flush()
+			out.WriteRune('0' + (r - '०'))
+		default:
+			flush()
+			out.WriteRune(r)
+		}
+	}
+	flush()
+	return out.String()
+}
+
+// renderWord applies Hindi schwa deletion (right to left): the implicit "a"
+// is dropped at the end of a word, and between a vowel and a following
+// consonant-vowel ("khaakhraa", not "khaakharaa").
+func renderWord(word []syllable) string {
+	n := len(word)
+	if n > 1 && word[n-1].inherent {
+		word[n-1].vowel = ""
+	}
+	for i := n - 2; i > 0; i-- {
+		s := &word[i]
+		if !s.inherent || s.consonant == "" {
+			continue
+		}
+		prevVowel := word[i-1].vowel != ""
+		next := word[i+1]
+		if prevVowel && next.consonant != "" && next.vowel != "" {
+			s.vowel = ""
+		}
+	}
+
+	var b strings.Builder
+	for i, s := range word {
+		b.WriteString(s.consonant)
+		b.WriteString(s.vowel)
+		// Anusvara assimilates to a following labial: "mumbai", "sambhar"
+		if s.coda == "n" && i+1 < n && word[i+1].consonant != "" && strings.ContainsAny(word[i+1].consonant[:1], "pbm") {
+			s.coda = "m"
+		}
+		b.WriteString(s.coda)
+	}
+	return b.String()
+}
+
+// spelling variants that Hinglish writers use interchangeably.
+var keyReplacer = strings.NewReplacer(
+	"ee", "i", "ii", "i", "oo", "u", "uu", "u",
+	"w", "v", "z", "j", "q", "k", "ph", "f",
+)
+
+// Key reduces text to a phonetic search key: romanised, lowercased,
+// punctuation-free, with long vowels and doubled letters collapsed.
+func Key(s string) string {
+	if IsDevanagari(s) {
+		s = ToLatin(s)
+	}
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, f := range fields {
+		f = keyReplacer.Replace(f)
+		var b strings.Builder
+		var prev rune
+		for _, r := range f {
+			if r != prev {
+				b.WriteRune(r)
+			}
+			prev = r
+		}
+		fields[i] = b.String()
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,56 @@
+package translit
+
+import "testing"
+
+func TestToLatin(t *testing.T) {
+	cases := map[string]string{
+		"खाखरा":       "khaakhraa",
+		"कमल":         "kamal",
+		"समझना":       "samajhnaa",
+		"ढोकला":       "dhoklaa",
+		"पनीर टिक्का": "paneer tikkaa",
+		"ज़ीरा":       "zeeraa",
+		"दाल-बाटी":    "daal-baatee",
+		"न":           "na",
+		"ठंडाई":       "thandaai",
+		"मुंबई":       "mumbai",
+		"१२ पीस":      "12 pees",
+	}
+	for in, want := range cases {
+		if got := ToLatin(in); got != want {
+			t.Errorf("ToLatin(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestKeyMatchesAcrossScripts(t *testing.T) {
+	pairs := [][2]string{
+		{"खाखरा", "Khakhra"},
+		{"मसाला खिचड़ी", "Masala Khichdi"},
+		{"ज़ीरा", "zeera"},
+		{"पनीर टिक्का", "Paneer Tikka"},
+		{"दाल बाटी", "Daal Baati"},
+		{"ढोकला", "dhokla"},
+	}
+	for _, p := range pairs {
+		if a, b := Key(p[0]), Key(p[1]); a != b {
+			t.Errorf("Key(%q) = %q, Key(%q) = %q", p[0], a, p[1], b)
+		}
+	}
+}
+
+func TestKeyNormalizes(t *testing.T) {
+	if got := Key("  Shanti  Bhojanalay (Pure-Jain)! "); got != "shanti bhojanalay pure jain" {
+		t.Errorf("unexpected key %q", got)
+	}
+	if IsDevanagari("khakhra") || !IsDevanagari("khakhra खाखरा") {
+		t.Error("IsDevanagari mismatch")
+	}
+}
+
+func TestPrecomposedNukta(t *testing.T) {
+	// U+095C / U+095B are the precomposed forms of ड़ and ज़
+	if got := ToLatin("खिचड़ी ज़ीरा"); got != "khichdee zeeraa" {
+		t.Errorf("precomposed nukta: %q", got)
+	}
+}
@@ -0,0 +1,150 @@
+// Package travel plans multi-stop trips for Jain travellers: for each stop
+// (a city on a date) and each leg between stops, the providers that are
+// open that day.
+package travel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"jainfood/internal/hours"
+	"jainfood/internal/models"
+	"jainfood/internal/places"
+	"jainfood/internal/providers"
+)
+
+// MaxStops bounds a trip.
+const MaxStops = 10
+
+// CorridorMeters is how far from the straight line between two stops a
+// provider counts as on the way.
+const CorridorMeters = 10000
+
+// Stop is a city on a date (YYYY-MM-DD).
+type Stop struct {
+	City string `json:"city"` // City slug, see GET /v1/cities
+	Date string `json:"date"`
+}
+
+// TripProvider is a provider open on the stop's date, with that day's slots
+// (empty when the provider hasn't published hours).
+type TripProvider struct {
+	*places.CityProvider
+	Slots []models.OpeningSlot `json:"slots"`
+}
+
+// StopPlan lists the providers open in a city on the stop's date.
+type StopPlan struct {
+	City      *models.City    `json:"city"`
+	Date      string          `json:"date"`
+	Providers []*TripProvider `json:"providers"`
+}
+
+// LegPlan lists providers on the way between two stops, open on the day of
+// arrival.
+type LegPlan struct {
+	From      string          `json:"from"`
+	To        string          `json:"to"`
+	Date      string          `json:"date"`
+	Providers []*TripProvider `json:"providers"`
+}
+
+// Plan is a planned trip.
+type Plan struct {
+	Stops   []*StopPlan `json:"stops"`
+	EnRoute []*LegPlan  `json:"en_route"`
+}
+
+// Validate checks the stops: 1 to MaxStops, each with a city and a date
+// that isn't before today, in date order.
+func Validate(stops []Stop, today string) error {
+	if len(stops) == 0 || len(stops) > MaxStops {
+		return fmt.Errorf("a trip needs 1 to %d stops", MaxStops)
+	}
+	prev := today
+	for i, s := range stops {
+		if s.City == "" {
+			return fmt.Errorf("stop %d: city is required", i+1)
+		}
+		if _, err := time.Parse(time.DateOnly, s.Date); err != nil {
+			return fmt.Errorf("stop %d: date must be YYYY-MM-DD", i+1)
+		}
+		if s.Date < prev {
+			if i == 0 {
+				return fmt.Errorf("stop 1: date is in the past")
+			}
+			return fmt.Errorf("stop %d: dates must be in trip order", i+1)
+		}
+		prev = s.Date
+	}
+	return nil
+}
+
+// PlanTrip finds the providers open at each stop and on each leg,
+// optionally of one provider_category. Unknown cities return the
+// places.GetCity error, db.ErrNotFound.
+func PlanTrip(ctx context.Context, stops []Stop, category string, now time.Time) (*Plan, error) {
+	plan := &Plan{Stops: []*StopPlan{}, EnRoute: []*LegPlan{}}
+	var ids []string
+	collect := func(list []*places.CityProvider) {
+		for _, cp := range list {
+			ids = append(ids, cp.ID)
+		}
+	}
+
+	// Candidates first, then one schedule lookup for all of them
+	stopProviders := make([][]*places.CityProvider, len(stops))
+	legProviders := make([][]*places.CityProvider, len(stops))
+	for i, s := range stops {
+		dir, err := places.CityProviders(ctx, s.City, category)
+		if err != nil {
+			return nil, err
+		}
+		plan.Stops = append(plan.Stops, &StopPlan{City: dir.City, Date: s.Date})
+		for _, d := range dir.Districts {
+			stopProviders[i] = append(stopProviders[i], d.Providers...)
+		}
+		collect(stopProviders[i])
+
+		if i > 0 && stops[i-1].City != s.City {
+			along, err := places.ProvidersAlong(ctx, stops[i-1].City, s.City, CorridorMeters, category)
+			if err != nil {
+				return nil, err
+			}
+			legProviders[i] = along
+			collect(along)
+		}
+	}
+	schedules, err := providers.GetSchedules(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	openOn := func(list []*places.CityProvider, date string) []*TripProvider {
+		day, _ := time.Parse(time.DateOnly, date)
+		out := []*TripProvider{}
+		for _, cp := range list {
+			s, ok := schedules[cp.ID]
+			if !ok {
+				s = &models.ProviderSchedule{AvailableToday: true}
+			}
+			if hours.OpenOn(s, date, now) {
+				out = append(out, &TripProvider{CityProvider: cp, Slots: hours.SlotsOn(s, day.Weekday())})
+			}
+		}
+		return out
+	}
+
+	for i, s := range stops {
+		plan.Stops[i].Providers = openOn(stopProviders[i], s.Date)
+		if legProviders[i] != nil {
+			plan.EnRoute = append(plan.EnRoute, &LegPlan{
+				From:      stops[i-1].City,
+				To:        s.City,
+				Date:      s.Date,
+				Providers: openOn(legProviders[i], s.Date),
+			})
+		}
+	}
+	return plan, nil
+}
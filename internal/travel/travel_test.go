@@ -0,0 +1,34 @@
+package travel
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	today := "2025-03-07"
+	valid := [][]Stop{
+		{{City: "ahmedabad", Date: "2025-03-07"}},
+		{{City: "ahmedabad", Date: "2025-03-08"}, {City: "palitana", Date: "2025-03-08"}, {City: "bhavnagar", Date: "2025-03-10"}},
+	}
+	for _, stops := range valid {
+		if err := Validate(stops, today); err != nil {
+			t.Errorf("Validate(%v) = %v", stops, err)
+		}
+	}
+
+	tooMany := make([]Stop, MaxStops+1)
+	for i := range tooMany {
+		tooMany[i] = Stop{City: "surat", Date: "2025-03-08"}
+	}
+	invalid := map[string][]Stop{
+		"no stops":     nil,
+		"too many":     tooMany,
+		"no city":      {{Date: "2025-03-08"}},
+		"bad date":     {{City: "surat", Date: "08/03/2025"}},
+		"past":         {{City: "surat", Date: "2025-03-06"}},
+		"out of order": {{City: "surat", Date: "2025-03-09"}, {City: "mumbai", Date: "2025-03-08"}},
+	}
+	for name, stops := range invalid {
+		if err := Validate(stops, today); err == nil {
+			t.Errorf("%s: Validate succeeded, want error", name)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+	"jainfood/internal/pii"
+)
+
+// streetLines are the parts of an address that find a home; they are
+// stored encrypted, while pin code, city and location stay queryable.
+type streetLines struct {
+	Line1    string `json:"line1"`
+	Line2    string `json:"line2,omitempty"`
+	Landmark string `json:"landmark,omitempty"`
+}
+
+// SealStreet encrypts a's street lines. Without keys (allowed outside
+// production only) it returns "" and the lines are stored in the clear.
+func SealStreet(keys *pii.Keyring, a *models.Address) (string, error) {
+	if keys == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(streetLines{Line1: a.Line1, Line2: a.Line2, Landmark: a.Landmark})
+	if err != nil {
+		return "", err
+	}
+	return keys.Encrypt(string(data))
+}
+
+// OpenStreet fills a's street lines from a SealStreet ciphertext. An empty
+// ciphertext leaves the lines stored in the clear.
+func OpenStreet(keys *pii.Keyring, a *models.Address, sealed string) error {
+	if sealed == "" {
+		return nil
+	}
+	if keys == nil {
+		return pii.ErrNotConfigured
+	}
+	data, err := keys.Decrypt(sealed)
+	if err != nil {
+		return err
+	}
+	var s streetLines
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return err
+	}
+	a.Line1, a.Line2, a.Landmark = s.Line1, s.Line2, s.Landmark
+	return nil
+}
+
+const addressColumns = `id, label, COALESCE(line1, ''), COALESCE(line2, ''), COALESCE(landmark, ''),
+	COALESCE(street_enc, ''), pin_code, city, COALESCE(district, ''), state,
+	ST_Y(geo::geometry), ST_X(geo::geometry), is_default, created_at`
+
+func scanAddress(keys *pii.Keyring, row pgx.Row) (*models.Address, error) {
+	a := &models.Address{}
+	var sealed string
+	err := row.Scan(&a.ID, &a.Label, &a.Line1, &a.Line2, &a.Landmark, &sealed, &a.PinCode, &a.City,
+		&a.District, &a.State, &a.Lat, &a.Lng, &a.IsDefault, &a.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, db.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := OpenStreet(keys, a, sealed); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// ListAddresses returns a user's saved addresses, default first.
+func ListAddresses(ctx context.Context, keys *pii.Keyring, userID string) ([]*models.Address, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT `+addressColumns+`
+		FROM user_addresses WHERE user_id = $1
+		ORDER BY is_default DESC, created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*models.Address{}
+	for rows.Next() {
+		a, err := scanAddress(keys, rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// GetAddress returns one of a user's addresses, or db.ErrNotFound.
+func GetAddress(ctx context.Context, keys *pii.Keyring, userID, addressID string) (*models.Address, error) {
+	return scanAddress(keys, db.Pool.QueryRow(ctx, `
+		SELECT `+addressColumns+`
+		FROM user_addresses WHERE id = $1 AND user_id = $2
+	`, addressID, userID))
+}
+
+// GetDefaultAddress returns the user's default address, or db.ErrNotFound.
+func GetDefaultAddress(ctx context.Context, keys *pii.Keyring, userID string) (*models.Address, error) {
+	return scanAddress(keys, db.Pool.QueryRow(ctx, `
+		SELECT `+addressColumns+`
+		FROM user_addresses WHERE user_id = $1 AND is_default
+	`, userID))
+}
+
+// SaveAddress creates the address, or updates it when a.ID is set. The
+// user's first address becomes the default; making an address the default
+// clears the flag on the others. A user always keeps a default, so the
+// current default can only lose the flag to another address. Street lines
+// are stored encrypted when keys is set.
+func SaveAddress(ctx context.Context, keys *pii.Keyring, userID string, a *models.Address) error {
+	sealed, err := SealStreet(keys, a)
+	if err != nil {
+		return err
+	}
+	line1, line2, landmark := a.Line1, a.Line2, a.Landmark
+	if sealed != "" {
+		line1, line2, landmark = "", "", ""
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if !a.IsDefault {
+		// Stay (or become) the default unless another address is
+		var otherDefaults int
+		if err := tx.QueryRow(ctx, `
+			SELECT COUNT(*) FROM user_addresses WHERE user_id = $1 AND id::text <> $2 AND is_default
+		`, userID, a.ID).Scan(&otherDefaults); err != nil {
+			return err
+		}
+		a.IsDefault = otherDefaults == 0
+	}
+	if a.IsDefault {
+		if _, err := tx.Exec(ctx, `
+			UPDATE user_addresses SET is_default = FALSE WHERE user_id = $1 AND is_default
+		`, userID); err != nil {
+			return err
+		}
+	}
+
+	args := []interface{}{userID, a.Label, line1, line2, landmark, a.PinCode, a.City, a.District, a.State, a.Lng, a.Lat, a.IsDefault, sealed}
+	if a.ID == "" {
+		err = tx.QueryRow(ctx, `
+			INSERT INTO user_addresses (user_id, label, line1, line2, landmark, pin_code, city, district, state, geo, is_default, street_enc)
+			VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6, $7, NULLIF($8, ''), $9,
+			        ST_SetSRID(ST_MakePoint($10, $11), 4326)::geography, $12, NULLIF($13, ''))
+			RETURNING id, created_at
+		`, args...).Scan(&a.ID, &a.CreatedAt)
+	} else {
+		err = tx.QueryRow(ctx, `
+			UPDATE user_addresses
+			SET label = $2, line1 = NULLIF($3, ''), line2 = NULLIF($4, ''), landmark = NULLIF($5, ''), pin_code = $6,
+			    city = $7, district = NULLIF($8, ''), state = $9,
+			    geo = ST_SetSRID(ST_MakePoint($10, $11), 4326)::geography, is_default = $12,
+			    street_enc = NULLIF($13, ''), updated_at = now()
+			WHERE id = $14 AND user_id = $1
+			RETURNING created_at
+		`, append(args, a.ID)...).Scan(&a.CreatedAt)
+	}
+	if err == pgx.ErrNoRows {
+		return db.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DeleteAddress removes one of a user's addresses. If it was the default,
+// the most recent remaining address takes over.
+func DeleteAddress(ctx context.Context, userID, addressID string) error {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var wasDefault bool
+	err = tx.QueryRow(ctx, `
+		DELETE FROM user_addresses WHERE id = $1 AND user_id = $2 RETURNING is_default
+	`, addressID, userID).Scan(&wasDefault)
+	if err == pgx.ErrNoRows {
+		return db.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if wasDefault {
+		if _, err := tx.Exec(ctx, `
+			UPDATE user_addresses SET is_default = TRUE
+			WHERE id = (SELECT id FROM user_addresses WHERE user_id = $1 ORDER BY created_at DESC LIMIT 1)
+		`, userID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// EncryptStoredAddresses encrypts street lines saved in the clear, from
+// before encryption or while PII_KEYS was unset, and reports how many
+// addresses it encrypted.
+func EncryptStoredAddresses(ctx context.Context, keys *pii.Keyring) (int, error) {
+	done := 0
+	for {
+		rows, err := db.Pool.Query(ctx, `
+			SELECT id::text, line1, COALESCE(line2, ''), COALESCE(landmark, '')
+			FROM user_addresses WHERE street_enc IS NULL AND line1 IS NOT NULL
+			LIMIT 500
+		`)
+		if err != nil {
+			return done, err
+		}
+		batch := []*models.Address{}
+		for rows.Next() {
+			a := &models.Address{}
+			if err := rows.Scan(&a.ID, &a.Line1, &a.Line2, &a.Landmark); err != nil {
+				rows.Close()
+				return done, err
+			}
+			batch = append(batch, a)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return done, err
+		}
+		if len(batch) == 0 {
+			return done, nil
+		}
+
+		for _, a := range batch {
+			sealed, err := SealStreet(keys, a)
+			if err != nil {
+				return done, err
+			}
+			if _, err := db.Pool.Exec(ctx, `
+				UPDATE user_addresses SET street_enc = $2, line1 = NULL, line2 = NULL, landmark = NULL
+				WHERE id = $1 AND street_enc IS NULL
+			`, a.ID, sealed); err != nil {
+				return done, err
+			}
+			done++
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package users
+
+import (
+	"context"
+	"fmt"
+
+	"jainfood/internal/db"
+	"jainfood/internal/models"
+)
+
+// Filter narrows the admin user search. Zero fields don't filter.
+type Filter struct {
+	Query   string // Part of a name or phone number
+	Role    string
+	Blocked *bool // Under an active block
+	Limit   int
+	Offset  int
+}
+
+// SearchUsers finds users matching f for the admin console, newest first,
+// with their block status, and returns the page with the total number of
+// matches.
+func SearchUsers(ctx context.Context, f Filter) ([]*models.User, int, error) {
+	where := " WHERE TRUE"
+	args := []interface{}{}
+	argIdx := 1
+
+	if f.Query != "" {
+		where += fmt.Sprintf(` AND (u.name ILIKE '%%' || $%d || '%%' OR u.phone LIKE '%%' || $%d || '%%')`, argIdx, argIdx)
+		args = append(args, f.Query)
+		argIdx++
+	}
+	if f.Role != "" {
+		where += fmt.Sprintf(" AND u.role = $%d", argIdx)
+		args = append(args, f.Role)
+		argIdx++
+	}
+	if f.Blocked != nil {
+		where += fmt.Sprintf(" AND %s = $%d", BlockedSQL, argIdx)
+		args = append(args, *f.Blocked)
+		argIdx++
+	}
+
+	var total int
+	if err := db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM users u`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT u.id, u.phone, COALESCE(u.name, ''), COALESCE(u.email, ''), u.role, u.preferences,
+		       `+BlockedSQL+`, COALESCE(u.blocked_reason, ''), u.blocked_until, u.created_at
+		FROM users u`+where+
+		fmt.Sprintf(" ORDER BY u.created_at DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1),
+		append(args, f.Limit, f.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []*models.User{}
+	for rows.Next() {
+		u := &models.User{}
+		if err := rows.Scan(&u.ID, &u.Phone, &u.Name, &u.Email, &u.Role, &u.Preferences,
+			&u.Blocked, &u.BlockedReason, &u.BlockedUntil, &u.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, u)
+	}
+	return list, total, rows.Err()
+}
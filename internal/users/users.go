@@ -3,11 +3,13 @@ package users
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"jainfood/internal/db"
 	"jainfood/internal/models"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // CreateUser creates a new user with phone-based registration.
@@ -35,9 +37,10 @@ func CreateUser(ctx context.Context, phone, name, role string, preferences map[s
 func GetUserByID(ctx context.Context, userID string) (*models.User, error) {
 	u := &models.User{}
 	err := db.Pool.QueryRow(ctx, `
-		SELECT id, phone, name, email, role, preferences, created_at
+		SELECT id, phone, name, email, role, preferences,
+		       COALESCE(preferred_channel, 'sms'), created_at
 		FROM users WHERE id = $1
-	`, userID).Scan(&u.ID, &u.Phone, &u.Name, &u.Email, &u.Role, &u.Preferences, &u.CreatedAt)
+	`, userID).Scan(&u.ID, &u.Phone, &u.Name, &u.Email, &u.Role, &u.Preferences, &u.PreferredChannel, &u.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +90,37 @@ func UpdateUser(ctx context.Context, userID, name, email string, preferences map
 	return nil
 }
 
+// SetPreferredChannel sets the channel ("sms" or "whatsapp") used for OTPs and order updates.
+func SetPreferredChannel(ctx context.Context, userID, channel string) error {
+	if channel != models.ChannelSMS && channel != models.ChannelWhatsApp {
+		return fmt.Errorf("invalid channel %q", channel)
+	}
+	ct, err := db.Pool.Exec(ctx, `UPDATE users SET preferred_channel = $2 WHERE id = $1`, userID, channel)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// GetPreferredChannelByPhone returns the preferred notification channel for a
+// phone number, or "" if no user is registered with it.
+func GetPreferredChannelByPhone(ctx context.Context, phone string) (string, error) {
+	var channel string
+	err := db.Pool.QueryRow(ctx, `
+		SELECT COALESCE(preferred_channel, 'sms') FROM users WHERE phone = $1
+	`, phone).Scan(&channel)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return channel, nil
+}
+
 // DeleteUser removes a user (for GDPR/privacy compliance).
 func DeleteUser(ctx context.Context, userID string) error {
 	ct, err := db.Pool.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID)
@@ -123,16 +157,17 @@ func ListUsers(ctx context.Context, limit, offset int) ([]*models.User, error) {
 	return users, nil
 }
 
-// BlockUser blocks a user (admin only)
-func BlockUser(ctx context.Context, userID, reason string) error {
+// BlockUser blocks a user (admin only) until until, or indefinitely when
+// until is nil. It returns db.ErrNotFound for an unknown user.
+func BlockUser(ctx context.Context, userID, reason string, until *time.Time) error {
 	ct, err := db.Pool.Exec(ctx, `
-		UPDATE users SET blocked = true, blocked_reason = $2 WHERE id = $1
-	`, userID, reason)
+		UPDATE users SET blocked = true, blocked_reason = $2, blocked_until = $3, blocked_at = now() WHERE id = $1
+	`, userID, reason, until)
 	if err != nil {
 		return err
 	}
 	if ct.RowsAffected() == 0 {
-		return fmt.Errorf("user not found")
+		return db.ErrNotFound
 	}
 	return nil
 }
@@ -140,13 +175,13 @@ func BlockUser(ctx context.Context, userID, reason string) error {
 // UnblockUser unblocks a user (admin only)
 func UnblockUser(ctx context.Context, userID string) error {
 	ct, err := db.Pool.Exec(ctx, `
-		UPDATE users SET blocked = false, blocked_reason = NULL WHERE id = $1
+		UPDATE users SET blocked = false, blocked_reason = NULL, blocked_until = NULL WHERE id = $1
 	`, userID)
 	if err != nil {
 		return err
 	}
 	if ct.RowsAffected() == 0 {
-		return fmt.Errorf("user not found")
+		return db.ErrNotFound
 	}
 	return nil
 }
@@ -194,16 +229,92 @@ func RegisterUser(ctx context.Context, phone, name, email, role string, termsAcc
 	}, nil
 }
 
+// BlockedSQL is true for a users row (alias u) under an active block.
+const BlockedSQL = `(COALESCE(u.blocked, FALSE) AND (u.blocked_until IS NULL OR u.blocked_until > now()))`
+
+// IsBlocked reports whether the user is under an active block.
+func IsBlocked(ctx context.Context, userID string) (bool, error) {
+	var blocked bool
+	err := db.Pool.QueryRow(ctx, `SELECT `+BlockedSQL+` FROM users u WHERE u.id = $1`, userID).Scan(&blocked)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	return blocked, err
+}
+
+// ListActiveBlocks returns the users under an active block, with their
+// BlockedUntil, to reload the token denylist.
+func ListActiveBlocks(ctx context.Context) ([]*models.User, error) {
+	rows, err := db.Pool.Query(ctx, `SELECT u.id, u.blocked_until FROM users u WHERE `+BlockedSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.User
+	for rows.Next() {
+		u := &models.User{Blocked: true}
+		if err := rows.Scan(&u.ID, &u.BlockedUntil); err != nil {
+			return nil, err
+		}
+		list = append(list, u)
+	}
+	return list, rows.Err()
+}
+
 // GetUserByPhoneWithStatus retrieves user by phone with blocked status check
 func GetUserByPhoneWithStatus(ctx context.Context, phone string) (*models.User, bool, error) {
 	u := &models.User{}
 	var blocked bool
 	err := db.Pool.QueryRow(ctx, `
-		SELECT id, phone, name, email, role, preferences, blocked, created_at
-		FROM users WHERE phone = $1
+		SELECT id, phone, name, email, role, preferences, `+BlockedSQL+`, created_at
+		FROM users u WHERE phone = $1
 	`, phone).Scan(&u.ID, &u.Phone, &u.Name, &u.Email, &u.Role, &u.Preferences, &blocked, &u.CreatedAt)
 	if err != nil {
 		return nil, false, err
 	}
 	return u, blocked, nil
 }
+
+// GetDietaryProfile returns the user's dietary profile, or the standard
+// profile if they haven't saved one.
+func GetDietaryProfile(ctx context.Context, userID string) (*models.DietaryProfile, error) {
+	p := &models.DietaryProfile{UserID: userID}
+	err := db.Pool.QueryRow(ctx, `
+		SELECT strictness, exclude_ingredients, allergens, observe_tithi, updated_at
+		FROM dietary_profiles WHERE user_id = $1
+	`, userID).Scan(&p.Strictness, &p.ExcludeIngredients, &p.Allergens, &p.ObserveTithi, &p.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return &models.DietaryProfile{
+			UserID:             userID,
+			Strictness:         models.StrictnessStandard,
+			ExcludeIngredients: []string{},
+			Allergens:          []string{},
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetDietaryProfile creates or replaces the user's dietary profile.
+func SetDietaryProfile(ctx context.Context, p *models.DietaryProfile) error {
+	if p.ExcludeIngredients == nil {
+		p.ExcludeIngredients = []string{}
+	}
+	if p.Allergens == nil {
+		p.Allergens = []string{}
+	}
+	return db.Pool.QueryRow(ctx, `
+		INSERT INTO dietary_profiles (user_id, strictness, exclude_ingredients, allergens, observe_tithi, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET strictness = EXCLUDED.strictness,
+		    exclude_ingredients = EXCLUDED.exclude_ingredients,
+		    allergens = EXCLUDED.allergens,
+		    observe_tithi = EXCLUDED.observe_tithi,
+		    updated_at = NOW()
+		RETURNING updated_at
+	`, p.UserID, p.Strictness, p.ExcludeIngredients, p.Allergens, p.ObserveTithi).Scan(&p.UpdatedAt)
+}
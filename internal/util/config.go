@@ -3,7 +3,9 @@ package util
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Environment represents the deployment environment
@@ -36,6 +38,11 @@ type Config struct {
 	JwtSecret string
 	OtpSecret string
 
+	// PII encryption (Aadhaar numbers)
+	PIIKeys        string // Master keys: "id:base64key,id2:base64key"
+	PIIActiveKeyID string // Key new values are wrapped with
+	PIIHashKey     string // Base64 key for duplicate-detection hashes
+
 	// S3/Object Storage
 	S3Endpoint  string
 	S3Region    string
@@ -52,13 +59,65 @@ type Config struct {
 	SMSIndiaHubPEID     string
 	TextbeltAPIKey      string
 
+	// WhatsApp Cloud API
+	WhatsAppAccessToken   string
+	WhatsAppPhoneNumberID string
+	WhatsAppAppSecret     string // Verifies webhook signatures
+	WhatsAppVerifyToken   string // Echoed back during webhook subscription
+
+	// Background job queue
+	QueueWorkers    int           // In-process notification workers (0 leaves the queue to cmd/worker)
+	QueueVisibility time.Duration // How long a claimed job stays invisible before it is retried
+
 	// Payment Gateway
 	RazorpayKeyID     string
 	RazorpayKeySecret string
 
 	// Push Notifications
-	FCMServerKey string
-	FCMProjectID string
+	FCMCredentialsFile string // Service-account JSON key for the FCM HTTP v1 API
+	FCMProjectID       string // Overrides the project in the key
+	PushProvider       string // fcm, webpush, mock or auto
+	VAPIDPublicKey     string // Web Push application server key (base64url)
+	VAPIDPrivateKey    string
+	VAPIDSubject       string // mailto: or https: contact sent to push services
+
+	// Jain compliance
+	JainRulesMode string // reject: refuse is_jain items with forbidden ingredients; flag: save and flag them
+
+	// Jain calendar
+	JainCalendarLat          float64 // Reference location when a request has no lat/lng
+	JainCalendarLng          float64
+	JainCalendarTimezone     string
+	ChoviharDeliveryBuffer   int // Minutes before sunset the last chovihar delivery must arrive
+	ChoviharOrderLeadMinutes int // Minutes before the last delivery a chovihar order must be placed
+
+	// Search ranking
+	SearchWeightRelevance   float64
+	SearchWeightRating      float64
+	SearchWeightDistance    float64
+	SearchWeightPopularity  float64
+	SearchDistanceHalfLifeM float64 // Distance at which the distance score halves
+	SearchRatingPrior       float64 // Rating assumed until a provider has enough reviews
+	SearchRatingMinVotes    float64 // Reviews before a provider's own rating dominates
+	SearchSuggestCacheTTL   int     // Seconds typeahead suggestions are cached in Redis
+
+	// Broadcast campaigns
+	CampaignQuietStart          int    // Hour quiet hours begin (no campaign pushes)
+	CampaignQuietEnd            int    // Hour quiet hours end
+	CampaignTimezone            string // Timezone for quiet hours and daily caps
+	CampaignDailyCap            int    // Max campaign pushes per user per day
+	CampaignProviderWeeklyLimit int    // Max campaigns a provider may create per 7 days
+
+	// Provider analytics
+	AnalyticsTimezone       string // Timezone analytics days and hours are counted in
+	AnalyticsRollupInterval int    // Seconds between rollup runs; 0 leaves rollups to cmd/worker
+
+	// Promotions
+	PromotionSlots       int   // Promotions that may run at once per city and category
+	PromotionFeedSlots   int   // Promoted slots shown above search and city feed results
+	PromotionCPMPaise    int64 // Price per 1000 capped daily impressions, in paise
+	PromotionMaxDays     int   // Longest promotion that can be booked
+	PromotionExpiryCheck int   // Seconds between expiry runs; 0 disables the in-process job
 
 	// CDN
 	CDNType           string
@@ -90,6 +149,11 @@ func Load() *Config {
 		JwtSecret: getEnvRequired("JWT_SECRET"),
 		OtpSecret: getEnvRequired("OTP_SECRET"),
 
+		// PII encryption
+		PIIKeys:        getEnv("PII_KEYS", ""),
+		PIIActiveKeyID: getEnv("PII_ACTIVE_KEY_ID", ""),
+		PIIHashKey:     getEnv("PII_HASH_KEY", ""),
+
 		// S3/MinIO
 		S3Endpoint:  getEnv("S3_ENDPOINT", ""),
 		S3Region:    getEnv("S3_REGION", "us-east-1"),
@@ -106,13 +170,65 @@ func Load() *Config {
 		SMSIndiaHubPEID:     getEnv("SMSINDIAHUB_PEID", ""),
 		TextbeltAPIKey:      getEnv("TEXTBELT_API_KEY", "textbelt"),
 
+		// WhatsApp
+		WhatsAppAccessToken:   getEnv("WHATSAPP_ACCESS_TOKEN", ""),
+		WhatsAppPhoneNumberID: getEnv("WHATSAPP_PHONE_NUMBER_ID", ""),
+		WhatsAppAppSecret:     getEnv("WHATSAPP_APP_SECRET", ""),
+		WhatsAppVerifyToken:   getEnv("WHATSAPP_VERIFY_TOKEN", ""),
+
+		// Background job queue
+		QueueWorkers:    getEnvInt("QUEUE_WORKERS", 4),
+		QueueVisibility: time.Duration(getEnvInt("QUEUE_VISIBILITY_SECONDS", 30)) * time.Second,
+
 		// Payment
 		RazorpayKeyID:     getEnv("RAZORPAY_KEY_ID", ""),
 		RazorpayKeySecret: getEnv("RAZORPAY_KEY_SECRET", ""),
 
 		// Push Notifications
-		FCMServerKey: getEnv("FCM_SERVER_KEY", ""),
-		FCMProjectID: getEnv("FCM_PROJECT_ID", ""),
+		FCMCredentialsFile: getEnv("FCM_CREDENTIALS_FILE", ""),
+		FCMProjectID:       getEnv("FCM_PROJECT_ID", ""),
+		PushProvider:       getEnv("PUSH_PROVIDER", "auto"),
+		VAPIDPublicKey:     getEnv("VAPID_PUBLIC_KEY", ""),
+		VAPIDPrivateKey:    getEnv("VAPID_PRIVATE_KEY", ""),
+		VAPIDSubject:       getEnv("VAPID_SUBJECT", ""),
+
+		// Jain compliance
+		JainRulesMode: getEnv("JAIN_RULES_MODE", "reject"),
+
+		// Jain calendar (defaults to Ahmedabad)
+		JainCalendarLat:          getEnvFloat("JAIN_CALENDAR_LAT", 23.0225),
+		JainCalendarLng:          getEnvFloat("JAIN_CALENDAR_LNG", 72.5714),
+		JainCalendarTimezone:     getEnv("JAIN_CALENDAR_TIMEZONE", "Asia/Kolkata"),
+		ChoviharDeliveryBuffer:   getEnvInt("CHOVIHAR_DELIVERY_BUFFER_MINUTES", 30),
+		ChoviharOrderLeadMinutes: getEnvInt("CHOVIHAR_ORDER_LEAD_MINUTES", 45),
+
+		// Search ranking
+		SearchWeightRelevance:   getEnvFloat("SEARCH_WEIGHT_RELEVANCE", 0.35),
+		SearchWeightRating:      getEnvFloat("SEARCH_WEIGHT_RATING", 0.25),
+		SearchWeightDistance:    getEnvFloat("SEARCH_WEIGHT_DISTANCE", 0.25),
+		SearchWeightPopularity:  getEnvFloat("SEARCH_WEIGHT_POPULARITY", 0.10),
+		SearchDistanceHalfLifeM: getEnvFloat("SEARCH_DISTANCE_HALF_LIFE_M", 2000),
+		SearchRatingPrior:       getEnvFloat("SEARCH_RATING_PRIOR", 3.5),
+		SearchRatingMinVotes:    getEnvFloat("SEARCH_RATING_MIN_VOTES", 10),
+		SearchSuggestCacheTTL:   getEnvInt("SEARCH_SUGGEST_CACHE_TTL_SECONDS", 30),
+
+		// Campaigns
+		CampaignQuietStart:          getEnvInt("CAMPAIGN_QUIET_START", 22),
+		CampaignQuietEnd:            getEnvInt("CAMPAIGN_QUIET_END", 8),
+		CampaignTimezone:            getEnv("CAMPAIGN_TIMEZONE", "Asia/Kolkata"),
+		CampaignDailyCap:            getEnvInt("CAMPAIGN_DAILY_CAP", 2),
+		CampaignProviderWeeklyLimit: getEnvInt("CAMPAIGN_PROVIDER_WEEKLY_LIMIT", 2),
+
+		// Analytics
+		AnalyticsTimezone:       getEnv("ANALYTICS_TIMEZONE", "Asia/Kolkata"),
+		AnalyticsRollupInterval: getEnvInt("ANALYTICS_ROLLUP_INTERVAL_SECONDS", 300),
+
+		// Promotions
+		PromotionSlots:       getEnvInt("PROMOTION_SLOTS", 3),
+		PromotionFeedSlots:   getEnvInt("PROMOTION_FEED_SLOTS", 2),
+		PromotionCPMPaise:    int64(getEnvInt("PROMOTION_CPM_PAISE", 5000)),
+		PromotionMaxDays:     getEnvInt("PROMOTION_MAX_DAYS", 90),
+		PromotionExpiryCheck: getEnvInt("PROMOTION_EXPIRY_CHECK_SECONDS", 60),
 
 		// CDN
 		CDNType:          getEnv("CDN_TYPE", ""),
@@ -183,6 +299,24 @@ func getEnv(k, d string) string {
 	return d
 }
 
+func getEnvInt(k string, d int) int {
+	if v := os.Getenv(k); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return d
+}
+
+func getEnvFloat(k string, d float64) float64 {
+	if v := os.Getenv(k); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return d
+}
+
 func getEnvRequired(k string) string {
 	v := os.Getenv(k)
 	if v == "" {
@@ -0,0 +1,20 @@
+-- Migration: WhatsApp notification channel
+-- Adds a per-user preferred notification channel and a table tracking
+-- delivery status reported by provider webhooks.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS preferred_channel VARCHAR(16) DEFAULT 'sms'
+  CHECK (preferred_channel IN ('sms','whatsapp'));
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+  message_id TEXT PRIMARY KEY,            -- provider message id (e.g. wamid.*)
+  channel VARCHAR(16) NOT NULL,
+  recipient VARCHAR(32),
+  template TEXT,
+  status VARCHAR(16) NOT NULL,            -- sent, delivered, read, failed
+  error_code INT,
+  error_title TEXT,
+  created_at TIMESTAMPTZ DEFAULT now(),
+  updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_recipient ON notification_deliveries(recipient, created_at DESC);
@@ -0,0 +1,10 @@
+-- Migration: Web Push (VAPID) subscriptions
+-- The subscription endpoint is also registered in device_tokens (platform 'web')
+-- so it is included in per-user fan-out and pruned with the token.
+
+CREATE TABLE IF NOT EXISTS webpush_subscriptions (
+  endpoint TEXT PRIMARY KEY REFERENCES device_tokens(token) ON DELETE CASCADE,
+  p256dh TEXT NOT NULL,
+  auth TEXT NOT NULL,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
@@ -0,0 +1,33 @@
+-- Migration: topic subscriptions and broadcast campaigns
+-- Topics are derived names such as city-indore, pin-452001,
+-- category-tiffin-center and food-sweets.
+
+CREATE TABLE IF NOT EXISTS topic_subscriptions (
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  topic VARCHAR(64) NOT NULL,
+  created_at TIMESTAMPTZ DEFAULT now(),
+  PRIMARY KEY (user_id, topic)
+);
+
+CREATE INDEX IF NOT EXISTS idx_topic_subscriptions_topic ON topic_subscriptions(topic);
+
+CREATE TABLE IF NOT EXISTS campaigns (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  provider_id UUID REFERENCES providers(id) ON DELETE CASCADE, -- NULL for admin campaigns
+  topic VARCHAR(64) NOT NULL,
+  title TEXT NOT NULL,
+  body TEXT NOT NULL,
+  data JSONB DEFAULT '{}',
+  status VARCHAR(16) NOT NULL DEFAULT 'scheduled'
+    CHECK (status IN ('scheduled','sending','sent','cancelled','failed')),
+  send_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  sent_at TIMESTAMPTZ,
+  recipients INT DEFAULT 0,
+  skipped INT DEFAULT 0,
+  error TEXT,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_due ON campaigns(send_at) WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS idx_campaigns_provider ON campaigns(provider_id, created_at DESC);
@@ -0,0 +1,11 @@
+-- Migration: computed Jain compliance verdict for menu items
+-- is_jain remains the provider's claim; jain_status is computed from the
+-- ingredient taxonomy (internal/jainrules) at the recorded version.
+
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS jain_status VARCHAR(16)
+  CHECK (jain_status IN ('jain','caution','non-jain'));
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS jain_reasons TEXT[];
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS jain_rules_version VARCHAR(16);
+
+CREATE INDEX IF NOT EXISTS idx_menu_items_jain_flagged ON menu_items(id)
+  WHERE is_jain = TRUE AND jain_status = 'non-jain';
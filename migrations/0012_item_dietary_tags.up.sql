@@ -0,0 +1,6 @@
+-- Migration: provider-set dietary tags on menu items for observance days
+-- (tithi-safe for ashtami/chaudas/Paryushan, ayambil for Ayambil Oli).
+
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS dietary_tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_menu_items_dietary_tags ON menu_items USING GIN(dietary_tags);
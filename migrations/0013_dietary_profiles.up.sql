@@ -0,0 +1,11 @@
+-- Migration: typed dietary profiles, applied automatically to item search
+
+CREATE TABLE IF NOT EXISTS dietary_profiles (
+  user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  strictness VARCHAR(16) NOT NULL DEFAULT 'standard'
+    CHECK (strictness IN ('relaxed','standard','strict')),
+  exclude_ingredients TEXT[] NOT NULL DEFAULT '{}',
+  allergens TEXT[] NOT NULL DEFAULT '{}',
+  observe_tithi BOOLEAN NOT NULL DEFAULT FALSE,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,99 @@
+-- Migration: unified search across items, menus and providers
+-- search_key is a phonetic Latin key (internal/translit) written by the
+-- application, so Devanagari and Hinglish spellings meet in one form.
+-- search_vector is maintained by triggers with weights:
+--   A item name, B provider name, C menu name/description,
+--   D provider_category, food categories and ingredients
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE providers ADD COLUMN IF NOT EXISTS search_key TEXT;
+ALTER TABLE providers ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS search_key TEXT;
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+-- ====================
+-- PROVIDER VECTOR
+-- ====================
+CREATE OR REPLACE FUNCTION providers_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+  NEW.search_vector :=
+    setweight(to_tsvector('simple', COALESCE(NEW.business_name, '') || ' ' || COALESCE(NEW.search_key, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(NEW.provider_category, '') || ' ' ||
+      array_to_string(COALESCE(NEW.food_categories, '{}'), ' ')), 'B') ||
+    setweight(to_tsvector('simple', array_to_string(COALESCE(NEW.tags, '{}'), ' ')), 'C');
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_providers_search_vector ON providers;
+CREATE TRIGGER trigger_providers_search_vector
+BEFORE INSERT OR UPDATE OF business_name, search_key, provider_category, food_categories, tags ON providers
+FOR EACH ROW EXECUTE FUNCTION providers_search_vector();
+
+-- ====================
+-- MENU ITEM VECTOR
+-- ====================
+CREATE OR REPLACE FUNCTION menu_items_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+  SELECT
+    setweight(to_tsvector('simple', COALESCE(NEW.name, '') || ' ' || COALESCE(NEW.search_key, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(p.business_name, '') || ' ' || COALESCE(p.search_key, '')), 'B') ||
+    setweight(to_tsvector('simple', COALESCE(m.name, '') || ' ' || COALESCE(m.description, '')), 'C') ||
+    setweight(to_tsvector('simple', COALESCE(p.provider_category, '') || ' ' ||
+      array_to_string(COALESCE(p.food_categories, '{}'), ' ') || ' ' ||
+      COALESCE(NEW.food_category, '') || ' ' ||
+      array_to_string(COALESCE(NEW.ingredients, '{}'), ' ')), 'D')
+  INTO NEW.search_vector
+  FROM menus m
+  JOIN providers p ON p.id = m.provider_id
+  WHERE m.id = NEW.menu_id;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_menu_items_search_vector ON menu_items;
+CREATE TRIGGER trigger_menu_items_search_vector
+BEFORE INSERT OR UPDATE OF name, search_key, menu_id, food_category, ingredients ON menu_items
+FOR EACH ROW EXECUTE FUNCTION menu_items_search_vector();
+
+-- Item vectors embed menu and provider text, so refresh them when those change
+CREATE OR REPLACE FUNCTION refresh_menu_item_search_vectors()
+RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_TABLE_NAME = 'menus' THEN
+    UPDATE menu_items SET menu_id = menu_id WHERE menu_id = NEW.id;
+  ELSE
+    UPDATE menu_items mi SET menu_id = mi.menu_id
+    FROM menus m WHERE m.id = mi.menu_id AND m.provider_id = NEW.id;
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_menus_refresh_item_search ON menus;
+CREATE TRIGGER trigger_menus_refresh_item_search
+AFTER UPDATE OF name, description ON menus
+FOR EACH ROW EXECUTE FUNCTION refresh_menu_item_search_vectors();
+
+DROP TRIGGER IF EXISTS trigger_providers_refresh_item_search ON providers;
+CREATE TRIGGER trigger_providers_refresh_item_search
+AFTER UPDATE OF business_name, search_key, provider_category, food_categories ON providers
+FOR EACH ROW EXECUTE FUNCTION refresh_menu_item_search_vectors();
+
+-- ====================
+-- BACKFILL & INDEXES
+-- ====================
+-- Lowercased names are only a placeholder: Devanagari names get no
+-- phonetic key from them. The API replaces placeholders with the
+-- transliterated keys at startup (search.BackfillKeys); without it, run
+-- POST /v1/admin/search/reindex after applying this migration.
+UPDATE providers SET search_key = lower(business_name) WHERE search_key IS NULL;
+UPDATE menu_items SET search_key = lower(name) WHERE search_key IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_providers_search_vector ON providers USING GIN(search_vector);
+CREATE INDEX IF NOT EXISTS idx_providers_search_key_trgm ON providers USING GIN(search_key gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_menu_items_search_vector ON menu_items USING GIN(search_vector);
+CREATE INDEX IF NOT EXISTS idx_menu_items_search_key_trgm ON menu_items USING GIN(search_key gin_trgm_ops);
@@ -0,0 +1,46 @@
+-- Migration: typeahead suggestions
+-- Prefix indexes on the phonetic search keys (whole-key prefixes use the
+-- btree indexes; word prefixes use the trigram indexes from 0014), and a
+-- cities table so places can be suggested alongside dishes and providers.
+
+CREATE INDEX IF NOT EXISTS idx_menu_items_search_key_prefix ON menu_items(search_key text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_providers_search_key_prefix ON providers(search_key text_pattern_ops);
+
+CREATE TABLE IF NOT EXISTS cities (
+  slug TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  name_hi TEXT,
+  state TEXT NOT NULL,
+  geo GEOGRAPHY(POINT,4326) NOT NULL,
+  search_key TEXT NOT NULL -- phonetic keys of the English and Hindi names
+);
+CREATE INDEX IF NOT EXISTS idx_cities_geo ON cities USING GIST(geo);
+CREATE INDEX IF NOT EXISTS idx_cities_search_key_prefix ON cities(search_key text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_cities_search_key_trgm ON cities USING GIN(search_key gin_trgm_ops);
+
+INSERT INTO cities (slug, name, name_hi, state, geo, search_key) VALUES
+  ('ahmedabad', 'Ahmedabad', 'अहमदाबाद', 'Gujarat', ST_SetSRID(ST_MakePoint(72.5714, 23.0225), 4326)::geography, 'ahmedabad ahamdabad'),
+  ('surat', 'Surat', 'सूरत', 'Gujarat', ST_SetSRID(ST_MakePoint(72.8311, 21.1702), 4326)::geography, 'surat'),
+  ('vadodara', 'Vadodara', 'वडोदरा', 'Gujarat', ST_SetSRID(ST_MakePoint(73.1812, 22.3072), 4326)::geography, 'vadodara vadodra'),
+  ('rajkot', 'Rajkot', 'राजकोट', 'Gujarat', ST_SetSRID(ST_MakePoint(70.8022, 22.3039), 4326)::geography, 'rajkot'),
+  ('bhavnagar', 'Bhavnagar', 'भावनगर', 'Gujarat', ST_SetSRID(ST_MakePoint(72.1519, 21.7645), 4326)::geography, 'bhavnagar bhavangar'),
+  ('palitana', 'Palitana', 'पालीताणा', 'Gujarat', ST_SetSRID(ST_MakePoint(71.8236, 21.5253), 4326)::geography, 'palitana'),
+  ('mumbai', 'Mumbai', 'मुंबई', 'Maharashtra', ST_SetSRID(ST_MakePoint(72.8777, 19.0760), 4326)::geography, 'mumbai'),
+  ('pune', 'Pune', 'पुणे', 'Maharashtra', ST_SetSRID(ST_MakePoint(73.8567, 18.5204), 4326)::geography, 'pune'),
+  ('nagpur', 'Nagpur', 'नागपुर', 'Maharashtra', ST_SetSRID(ST_MakePoint(79.0882, 21.1458), 4326)::geography, 'nagpur'),
+  ('indore', 'Indore', 'इंदौर', 'Madhya Pradesh', ST_SetSRID(ST_MakePoint(75.8577, 22.7196), 4326)::geography, 'indore indaur'),
+  ('bhopal', 'Bhopal', 'भोपाल', 'Madhya Pradesh', ST_SetSRID(ST_MakePoint(77.4126, 23.2599), 4326)::geography, 'bhopal'),
+  ('ujjain', 'Ujjain', 'उज्जैन', 'Madhya Pradesh', ST_SetSRID(ST_MakePoint(75.7885, 23.1765), 4326)::geography, 'ujain'),
+  ('sagar', 'Sagar', 'सागर', 'Madhya Pradesh', ST_SetSRID(ST_MakePoint(78.7378, 23.8388), 4326)::geography, 'sagar'),
+  ('jabalpur', 'Jabalpur', 'जबलपुर', 'Madhya Pradesh', ST_SetSRID(ST_MakePoint(79.9864, 23.1815), 4326)::geography, 'jabalpur'),
+  ('jaipur', 'Jaipur', 'जयपुर', 'Rajasthan', ST_SetSRID(ST_MakePoint(75.7873, 26.9124), 4326)::geography, 'jaipur jaypur'),
+  ('udaipur', 'Udaipur', 'उदयपुर', 'Rajasthan', ST_SetSRID(ST_MakePoint(73.7125, 24.5854), 4326)::geography, 'udaipur udaypur'),
+  ('jodhpur', 'Jodhpur', 'जोधपुर', 'Rajasthan', ST_SetSRID(ST_MakePoint(73.0243, 26.2389), 4326)::geography, 'jodhpur'),
+  ('ajmer', 'Ajmer', 'अजमेर', 'Rajasthan', ST_SetSRID(ST_MakePoint(74.6399, 26.4499), 4326)::geography, 'ajmer'),
+  ('delhi', 'Delhi', 'दिल्ली', 'Delhi', ST_SetSRID(ST_MakePoint(77.2090, 28.6139), 4326)::geography, 'delhi dili'),
+  ('kolkata', 'Kolkata', 'कोलकाता', 'West Bengal', ST_SetSRID(ST_MakePoint(88.3639, 22.5726), 4326)::geography, 'kolkata'),
+  ('chennai', 'Chennai', 'चेन्नई', 'Tamil Nadu', ST_SetSRID(ST_MakePoint(80.2707, 13.0827), 4326)::geography, 'chenai'),
+  ('bengaluru', 'Bengaluru', 'बेंगलुरु', 'Karnataka', ST_SetSRID(ST_MakePoint(77.5946, 12.9716), 4326)::geography, 'bengaluru bengluru'),
+  ('hyderabad', 'Hyderabad', 'हैदराबाद', 'Telangana', ST_SetSRID(ST_MakePoint(78.4867, 17.3850), 4326)::geography, 'hyderabad haidrabad'),
+  ('shravanabelagola', 'Shravanabelagola', 'श्रवणबेलगोला', 'Karnataka', ST_SetSRID(ST_MakePoint(76.4884, 12.8590), 4326)::geography, 'shravanabelagola shravanbelgola')
+ON CONFLICT (slug) DO NOTHING;
@@ -0,0 +1,26 @@
+-- Migration: structured opening hours, holiday closures and order cut-offs
+
+ALTER TABLE providers ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'Asia/Kolkata';
+
+-- Weekly slots in the provider's timezone. A slot whose closes is not after
+-- opens runs past midnight into the next day. order_cutoff, when set, is the
+-- last time orders are accepted for that slot (usually before it opens).
+CREATE TABLE IF NOT EXISTS provider_hours (
+  provider_id UUID NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+  weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6), -- 0 = Sunday
+  opens TIME NOT NULL,
+  closes TIME NOT NULL,
+  order_cutoff TIME,
+  PRIMARY KEY (provider_id, weekday, opens)
+);
+
+CREATE TABLE IF NOT EXISTS provider_holidays (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  provider_id UUID NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+  starts_on DATE NOT NULL,
+  ends_on DATE NOT NULL CHECK (ends_on >= starts_on),
+  reason TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_provider_holidays_provider ON provider_holidays(provider_id, ends_on);
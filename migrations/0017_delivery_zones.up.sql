@@ -0,0 +1,19 @@
+-- Migration: provider delivery zones, distance fee slabs and order delivery fees
+
+-- One zone per provider: a radius around the provider or a polygon.
+-- fee_slabs is an ordered array of {up_to_km, fee}.
+CREATE TABLE IF NOT EXISTS provider_delivery_zones (
+  provider_id UUID PRIMARY KEY REFERENCES providers(id) ON DELETE CASCADE,
+  radius_km NUMERIC(6,2) CHECK (radius_km > 0),
+  area GEOGRAPHY(POLYGON, 4326),
+  fee_slabs JSONB NOT NULL DEFAULT '[]',
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK ((radius_km IS NULL) <> (area IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_provider_delivery_zones_area ON provider_delivery_zones USING GIST(area);
+
+-- See 0006: columns added on the partitioned parent propagate to partitions
+ALTER TABLE orders
+  ADD COLUMN IF NOT EXISTS delivery_fee NUMERIC(10,2) NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS delivery_distance_km NUMERIC(6,2);
@@ -0,0 +1,93 @@
+-- Migration: pin-code dataset, buyer address book and order address snapshots
+
+-- Offline pin-code lookup: pin -> city/district/state and a centroid.
+-- Seeded with head and delivery offices of the cities in 0015; load the full
+-- India Post directory into the same table with \copy for wider coverage.
+CREATE TABLE IF NOT EXISTS pin_codes (
+  pin CHAR(6) PRIMARY KEY CHECK (pin ~ '^[1-9][0-9]{5}$'),
+  city_slug TEXT REFERENCES cities(slug),
+  city TEXT NOT NULL,
+  district TEXT NOT NULL,
+  state TEXT NOT NULL,
+  geo GEOGRAPHY(POINT,4326) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_pin_codes_city ON pin_codes(city_slug);
+CREATE INDEX IF NOT EXISTS idx_pin_codes_geo ON pin_codes USING GIST(geo);
+
+INSERT INTO pin_codes (pin, city_slug, city, district, state, geo) VALUES
+  ('380001', 'ahmedabad', 'Ahmedabad', 'Ahmedabad', 'Gujarat', ST_SetSRID(ST_MakePoint(72.5878, 23.0258), 4326)::geography),
+  ('380006', 'ahmedabad', 'Ahmedabad', 'Ahmedabad', 'Gujarat', ST_SetSRID(ST_MakePoint(72.5626, 23.0216), 4326)::geography),
+  ('380009', 'ahmedabad', 'Ahmedabad', 'Ahmedabad', 'Gujarat', ST_SetSRID(ST_MakePoint(72.5532, 23.0365), 4326)::geography),
+  ('380015', 'ahmedabad', 'Ahmedabad', 'Ahmedabad', 'Gujarat', ST_SetSRID(ST_MakePoint(72.5263, 23.0300), 4326)::geography),
+  ('380054', 'ahmedabad', 'Ahmedabad', 'Ahmedabad', 'Gujarat', ST_SetSRID(ST_MakePoint(72.5079, 23.0396), 4326)::geography),
+  ('395001', 'surat', 'Surat', 'Surat', 'Gujarat', ST_SetSRID(ST_MakePoint(72.8311, 21.1959), 4326)::geography),
+  ('395007', 'surat', 'Surat', 'Surat', 'Gujarat', ST_SetSRID(ST_MakePoint(72.7933, 21.1588), 4326)::geography),
+  ('390001', 'vadodara', 'Vadodara', 'Vadodara', 'Gujarat', ST_SetSRID(ST_MakePoint(73.2081, 22.3008), 4326)::geography),
+  ('390007', 'vadodara', 'Vadodara', 'Vadodara', 'Gujarat', ST_SetSRID(ST_MakePoint(73.1662, 22.3124), 4326)::geography),
+  ('360001', 'rajkot', 'Rajkot', 'Rajkot', 'Gujarat', ST_SetSRID(ST_MakePoint(70.8022, 22.3039), 4326)::geography),
+  ('364001', 'bhavnagar', 'Bhavnagar', 'Bhavnagar', 'Gujarat', ST_SetSRID(ST_MakePoint(72.1519, 21.7645), 4326)::geography),
+  ('364270', 'palitana', 'Palitana', 'Bhavnagar', 'Gujarat', ST_SetSRID(ST_MakePoint(71.8236, 21.5253), 4326)::geography),
+  ('400001', 'mumbai', 'Mumbai', 'Mumbai', 'Maharashtra', ST_SetSRID(ST_MakePoint(72.8347, 18.9388), 4326)::geography),
+  ('400002', 'mumbai', 'Mumbai', 'Mumbai', 'Maharashtra', ST_SetSRID(ST_MakePoint(72.8296, 18.9490), 4326)::geography),
+  ('400004', 'mumbai', 'Mumbai', 'Mumbai', 'Maharashtra', ST_SetSRID(ST_MakePoint(72.8153, 18.9563), 4326)::geography),
+  ('400006', 'mumbai', 'Mumbai', 'Mumbai', 'Maharashtra', ST_SetSRID(ST_MakePoint(72.8051, 18.9548), 4326)::geography),
+  ('400050', 'mumbai', 'Mumbai', 'Mumbai Suburban', 'Maharashtra', ST_SetSRID(ST_MakePoint(72.8333, 19.0596), 4326)::geography),
+  ('400080', 'mumbai', 'Mumbai', 'Mumbai Suburban', 'Maharashtra', ST_SetSRID(ST_MakePoint(72.9565, 19.1726), 4326)::geography),
+  ('400092', 'mumbai', 'Mumbai', 'Mumbai Suburban', 'Maharashtra', ST_SetSRID(ST_MakePoint(72.8479, 19.2307), 4326)::geography),
+  ('411001', 'pune', 'Pune', 'Pune', 'Maharashtra', ST_SetSRID(ST_MakePoint(73.8743, 18.5196), 4326)::geography),
+  ('411002', 'pune', 'Pune', 'Pune', 'Maharashtra', ST_SetSRID(ST_MakePoint(73.8553, 18.5122), 4326)::geography),
+  ('440001', 'nagpur', 'Nagpur', 'Nagpur', 'Maharashtra', ST_SetSRID(ST_MakePoint(79.0882, 21.1458), 4326)::geography),
+  ('440002', 'nagpur', 'Nagpur', 'Nagpur', 'Maharashtra', ST_SetSRID(ST_MakePoint(79.1096, 21.1530), 4326)::geography),
+  ('452001', 'indore', 'Indore', 'Indore', 'Madhya Pradesh', ST_SetSRID(ST_MakePoint(75.8577, 22.7196), 4326)::geography),
+  ('452002', 'indore', 'Indore', 'Indore', 'Madhya Pradesh', ST_SetSRID(ST_MakePoint(75.8504, 22.7165), 4326)::geography),
+  ('462001', 'bhopal', 'Bhopal', 'Bhopal', 'Madhya Pradesh', ST_SetSRID(ST_MakePoint(77.4126, 23.2599), 4326)::geography),
+  ('456001', 'ujjain', 'Ujjain', 'Ujjain', 'Madhya Pradesh', ST_SetSRID(ST_MakePoint(75.7885, 23.1765), 4326)::geography),
+  ('470001', 'sagar', 'Sagar', 'Sagar', 'Madhya Pradesh', ST_SetSRID(ST_MakePoint(78.7378, 23.8388), 4326)::geography),
+  ('482001', 'jabalpur', 'Jabalpur', 'Jabalpur', 'Madhya Pradesh', ST_SetSRID(ST_MakePoint(79.9864, 23.1815), 4326)::geography),
+  ('302001', 'jaipur', 'Jaipur', 'Jaipur', 'Rajasthan', ST_SetSRID(ST_MakePoint(75.8235, 26.9196), 4326)::geography),
+  ('302003', 'jaipur', 'Jaipur', 'Jaipur', 'Rajasthan', ST_SetSRID(ST_MakePoint(75.8267, 26.9239), 4326)::geography),
+  ('313001', 'udaipur', 'Udaipur', 'Udaipur', 'Rajasthan', ST_SetSRID(ST_MakePoint(73.7125, 24.5854), 4326)::geography),
+  ('342001', 'jodhpur', 'Jodhpur', 'Jodhpur', 'Rajasthan', ST_SetSRID(ST_MakePoint(73.0243, 26.2389), 4326)::geography),
+  ('305001', 'ajmer', 'Ajmer', 'Ajmer', 'Rajasthan', ST_SetSRID(ST_MakePoint(74.6399, 26.4499), 4326)::geography),
+  ('110001', 'delhi', 'New Delhi', 'New Delhi', 'Delhi', ST_SetSRID(ST_MakePoint(77.2167, 28.6315), 4326)::geography),
+  ('110006', 'delhi', 'Delhi', 'Central Delhi', 'Delhi', ST_SetSRID(ST_MakePoint(77.2303, 28.6562), 4326)::geography),
+  ('110092', 'delhi', 'Delhi', 'East Delhi', 'Delhi', ST_SetSRID(ST_MakePoint(77.2944, 28.6328), 4326)::geography),
+  ('700001', 'kolkata', 'Kolkata', 'Kolkata', 'West Bengal', ST_SetSRID(ST_MakePoint(88.3494, 22.5726), 4326)::geography),
+  ('700007', 'kolkata', 'Kolkata', 'Kolkata', 'West Bengal', ST_SetSRID(ST_MakePoint(88.3563, 22.5838), 4326)::geography),
+  ('600001', 'chennai', 'Chennai', 'Chennai', 'Tamil Nadu', ST_SetSRID(ST_MakePoint(80.2838, 13.0878), 4326)::geography),
+  ('600079', 'chennai', 'Chennai', 'Chennai', 'Tamil Nadu', ST_SetSRID(ST_MakePoint(80.2785, 13.0947), 4326)::geography),
+  ('560001', 'bengaluru', 'Bengaluru', 'Bengaluru Urban', 'Karnataka', ST_SetSRID(ST_MakePoint(77.6033, 12.9762), 4326)::geography),
+  ('560053', 'bengaluru', 'Bengaluru', 'Bengaluru Urban', 'Karnataka', ST_SetSRID(ST_MakePoint(77.5777, 12.9667), 4326)::geography),
+  ('500001', 'hyderabad', 'Hyderabad', 'Hyderabad', 'Telangana', ST_SetSRID(ST_MakePoint(78.4747, 17.3887), 4326)::geography),
+  ('573135', 'shravanabelagola', 'Shravanabelagola', 'Hassan', 'Karnataka', ST_SetSRID(ST_MakePoint(76.4884, 12.8590), 4326)::geography)
+ON CONFLICT (pin) DO NOTHING;
+
+-- Providers' pin_code (0005) was never filled: use the nearest known pin
+-- within 5 km
+UPDATE providers p SET pin_code = (
+  SELECT pc.pin FROM pin_codes pc
+  WHERE ST_DWithin(pc.geo, p.geo, 5000)
+  ORDER BY pc.geo <-> p.geo LIMIT 1
+) WHERE p.pin_code IS NULL;
+
+CREATE TABLE IF NOT EXISTS user_addresses (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  label TEXT NOT NULL DEFAULT 'home',
+  line1 TEXT NOT NULL,
+  line2 TEXT,
+  landmark TEXT,
+  pin_code CHAR(6) NOT NULL,
+  city TEXT NOT NULL,
+  district TEXT,
+  state TEXT NOT NULL,
+  geo GEOGRAPHY(POINT,4326) NOT NULL,
+  is_default BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_user_addresses_user ON user_addresses(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_addresses_default ON user_addresses(user_id) WHERE is_default;
+
+-- Where an order was delivered, copied from the address when it was placed
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_address JSONB;
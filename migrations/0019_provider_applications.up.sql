@@ -0,0 +1,54 @@
+-- Migration: provider onboarding applications and the document review queue
+
+-- One application per provider. Business details live here until an admin
+-- approves it; approval copies the verified fields onto the provider.
+CREATE TABLE IF NOT EXISTS provider_applications (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  provider_id UUID NOT NULL UNIQUE REFERENCES providers(id) ON DELETE CASCADE,
+  state TEXT NOT NULL DEFAULT 'draft'
+    CHECK (state IN ('draft', 'submitted', 'under_review', 'changes_requested', 'approved', 'rejected')),
+  legal_name TEXT NOT NULL DEFAULT '',
+  business_type TEXT NOT NULL DEFAULT '',
+  gstin TEXT,
+  fssai_number TEXT NOT NULL DEFAULT '',
+  fssai_expires_on DATE,
+  id_proof_type TEXT NOT NULL DEFAULT '',
+  aadhar_number_hash TEXT,
+  aadhar_last4 CHAR(4),
+  submitted_at TIMESTAMPTZ,
+  reviewed_by UUID REFERENCES users(id),
+  reviewed_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- The review queue: open applications, oldest submission first
+CREATE INDEX IF NOT EXISTS idx_provider_applications_queue
+  ON provider_applications(state, submitted_at);
+
+-- object_key points into the provider-docs/ folder of the media bucket
+CREATE TABLE IF NOT EXISTS provider_application_documents (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  application_id UUID NOT NULL REFERENCES provider_applications(id) ON DELETE CASCADE,
+  kind TEXT NOT NULL CHECK (kind IN ('fssai_licence', 'id_proof', 'kitchen_photo')),
+  object_key TEXT NOT NULL,
+  uploaded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (application_id, object_key)
+);
+
+-- Every state change, with the reviewer's or provider's comment
+CREATE TABLE IF NOT EXISTS provider_application_comments (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  application_id UUID NOT NULL REFERENCES provider_applications(id) ON DELETE CASCADE,
+  author_id UUID NOT NULL REFERENCES users(id),
+  from_state TEXT NOT NULL,
+  to_state TEXT NOT NULL,
+  comment TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_provider_application_comments_app
+  ON provider_application_comments(application_id, created_at);
+
+-- Verified licence details, copied from the approved application
+ALTER TABLE providers ADD COLUMN IF NOT EXISTS fssai_number TEXT;
+ALTER TABLE providers ADD COLUMN IF NOT EXISTS fssai_expires_on DATE;
@@ -0,0 +1,18 @@
+-- Migration: envelope-encrypted Aadhaar numbers with keyed hashes
+
+-- "v1:<key id>:<wrapped data key>:<ciphertext>", see internal/pii
+ALTER TABLE provider_applications ADD COLUMN IF NOT EXISTS aadhar_number_enc TEXT;
+ALTER TABLE providers ADD COLUMN IF NOT EXISTS aadhar_number_enc TEXT;
+ALTER TABLE providers ADD COLUMN IF NOT EXISTS aadhar_last4 CHAR(4);
+
+-- Hashes written before this migration were unkeyed SHA-256 and can't be
+-- converted without the numbers; drop them so providers re-enter the number
+-- (the application reports it missing) and it is stored encrypted.
+UPDATE provider_applications SET aadhar_number_hash = NULL, aadhar_last4 = NULL
+WHERE aadhar_number_enc IS NULL AND aadhar_number_hash IS NOT NULL;
+UPDATE providers SET aadhar_number_hash = NULL
+WHERE aadhar_number_enc IS NULL AND aadhar_number_hash IS NOT NULL;
+
+-- An Aadhaar number can back only one provider
+CREATE UNIQUE INDEX IF NOT EXISTS idx_provider_applications_aadhar_hash
+  ON provider_applications(aadhar_number_hash) WHERE aadhar_number_hash IS NOT NULL;
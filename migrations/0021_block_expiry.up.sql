@@ -0,0 +1,7 @@
+-- Migration: user blocks with expiry
+
+-- NULL blocks indefinitely; the API's Redis denylist entry expires with it
+ALTER TABLE users ADD COLUMN IF NOT EXISTS blocked_until TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS blocked_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_users_blocked ON users(blocked_until) WHERE blocked = TRUE;
@@ -0,0 +1,28 @@
+-- Migration: provider organisations with multiple outlets and staff roles
+
+-- An organisation runs one or more outlets; each outlet is a providers row
+-- with its own location, hours, delivery zone and menus
+CREATE TABLE IF NOT EXISTS provider_organisations (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  name TEXT NOT NULL,
+  owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_provider_organisations_owner ON provider_organisations(owner_id);
+
+ALTER TABLE providers ADD COLUMN IF NOT EXISTS organisation_id UUID REFERENCES provider_organisations(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_providers_organisation ON providers(organisation_id);
+CREATE INDEX IF NOT EXISTS idx_providers_user ON providers(user_id);
+
+-- Staff roles, per outlet or (provider_id NULL) for every outlet
+CREATE TABLE IF NOT EXISTS provider_staff (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  organisation_id UUID NOT NULL REFERENCES provider_organisations(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  provider_id UUID REFERENCES providers(id) ON DELETE CASCADE,
+  role VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'manager', 'kitchen_staff')),
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_provider_staff_unique
+  ON provider_staff(organisation_id, user_id, COALESCE(provider_id, '00000000-0000-0000-0000-000000000000'::uuid));
+CREATE INDEX IF NOT EXISTS idx_provider_staff_user ON provider_staff(user_id);
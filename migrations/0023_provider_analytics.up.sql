@@ -0,0 +1,47 @@
+-- Migration: daily provider analytics rollups
+
+-- One row per provider per local day, rebuilt by the analytics roller
+-- whenever that day's orders or reviews change
+CREATE TABLE IF NOT EXISTS provider_daily_stats (
+  provider_id UUID NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+  day DATE NOT NULL,
+  orders INT NOT NULL DEFAULT 0,
+  orders_by_status JSONB NOT NULL DEFAULT '{}',
+  billable_orders INT NOT NULL DEFAULT 0, -- Confirmed, ready or completed
+  revenue NUMERIC(12,2) NOT NULL DEFAULT 0, -- Of billable orders
+  hourly_orders INT[] NOT NULL DEFAULT array_fill(0, ARRAY[24]),
+  ratings INT NOT NULL DEFAULT 0,
+  rating_sum INT NOT NULL DEFAULT 0,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (provider_id, day)
+);
+
+-- Items sold per day, for top items
+CREATE TABLE IF NOT EXISTS provider_daily_items (
+  provider_id UUID NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+  day DATE NOT NULL,
+  item_id TEXT NOT NULL,
+  name TEXT NOT NULL DEFAULT '',
+  quantity INT NOT NULL DEFAULT 0,
+  revenue NUMERIC(12,2) NOT NULL DEFAULT 0,
+  PRIMARY KEY (provider_id, day, item_id)
+);
+
+-- Orders per buyer per day, for repeat-customer rates over any range
+CREATE TABLE IF NOT EXISTS provider_daily_buyers (
+  provider_id UUID NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+  day DATE NOT NULL,
+  buyer_id UUID NOT NULL,
+  orders INT NOT NULL DEFAULT 0,
+  PRIMARY KEY (provider_id, day, buyer_id)
+);
+
+-- How far each rollup job has processed changes
+CREATE TABLE IF NOT EXISTS rollup_watermarks (
+  job TEXT PRIMARY KEY,
+  rolled_up_to TIMESTAMPTZ NOT NULL
+);
+
+-- The roller finds changed orders by updated_at
+CREATE INDEX IF NOT EXISTS idx_orders_updated_at ON orders(updated_at);
+CREATE INDEX IF NOT EXISTS idx_reviews_created_at ON reviews(created_at);
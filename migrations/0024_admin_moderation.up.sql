@@ -0,0 +1,29 @@
+-- Migration: review moderation and admin search
+
+-- Reviews start published; user reports flag them for the moderation
+-- queue, where an admin approves (keeps) or hides them
+ALTER TABLE reviews
+  ADD COLUMN IF NOT EXISTS moderation_state VARCHAR(16) NOT NULL DEFAULT 'published'
+    CHECK (moderation_state IN ('published', 'flagged', 'approved', 'hidden')),
+  ADD COLUMN IF NOT EXISTS report_count INT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS moderated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS moderation_note TEXT;
+CREATE INDEX IF NOT EXISTS idx_reviews_moderation ON reviews(moderation_state, created_at);
+
+CREATE TABLE IF NOT EXISTS review_reports (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  review_id UUID NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+  reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  reason TEXT NOT NULL,
+  created_at TIMESTAMPTZ DEFAULT now(),
+  UNIQUE (review_id, reporter_id)
+);
+
+-- Admin user search by name or phone, and signup counts
+CREATE INDEX IF NOT EXISTS idx_users_name_trgm ON users USING GIN(name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_phone_trgm ON users USING GIN(phone gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at);
+
+-- Admin audit trail lookups
+CREATE INDEX IF NOT EXISTS idx_events_type_created ON events(event_type, created_at DESC);
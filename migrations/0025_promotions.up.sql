@@ -0,0 +1,41 @@
+-- Migration: promoted listings
+
+-- A promotion books one of a city's promoted slots for a provider between
+-- starts_at and ends_at, optionally for one provider category (NULL for
+-- every category). Admins create them active; providers create them
+-- pending_payment and they become active once the Razorpay payment is
+-- verified. The expiry job ends them. Running promotions are shown in
+-- promoted slots only; providers.is_promoted doesn't follow them.
+CREATE TABLE IF NOT EXISTS promotions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  provider_id UUID NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+  city_slug TEXT NOT NULL REFERENCES cities(slug),
+  provider_category VARCHAR(32),
+  starts_at TIMESTAMPTZ NOT NULL,
+  ends_at TIMESTAMPTZ NOT NULL,
+  daily_cap INT NOT NULL CHECK (daily_cap > 0),
+  status VARCHAR(16) NOT NULL DEFAULT 'active'
+    CHECK (status IN ('pending_payment', 'active', 'cancelled', 'expired')),
+  source VARCHAR(16) NOT NULL CHECK (source IN ('admin', 'payment')),
+  amount_paise BIGINT NOT NULL DEFAULT 0,
+  payment_order_id TEXT UNIQUE,
+  payment_id TEXT,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ DEFAULT now(),
+  updated_at TIMESTAMPTZ DEFAULT now(),
+  CHECK (ends_at > starts_at)
+);
+CREATE INDEX IF NOT EXISTS idx_promotions_slot ON promotions(city_slug, provider_category, starts_at, ends_at)
+  WHERE status IN ('pending_payment', 'active');
+CREATE INDEX IF NOT EXISTS idx_promotions_provider ON promotions(provider_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_promotions_status ON promotions(status, ends_at);
+
+-- Impressions and clicks per promotion and local day, for daily caps and
+-- billing
+CREATE TABLE IF NOT EXISTS promotion_daily_stats (
+  promotion_id UUID NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+  day DATE NOT NULL,
+  impressions INT NOT NULL DEFAULT 0,
+  clicks INT NOT NULL DEFAULT 0,
+  PRIMARY KEY (promotion_id, day)
+);
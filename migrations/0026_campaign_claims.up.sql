@@ -0,0 +1,7 @@
+-- Migration: reclaim campaigns left sending
+
+-- When the dispatcher claimed a campaign; campaigns still sending long
+-- after it (the instance died mid-send) are claimed again
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_sending ON campaigns(claimed_at) WHERE status = 'sending';
@@ -0,0 +1,11 @@
+-- Migration: encrypted street lines for saved and order addresses
+
+-- Line 1, line 2 and landmark as one envelope-encrypted value (see
+-- internal/pii); pin code, city and location stay in the clear for
+-- delivery quotes. The API encrypts rows saved in the clear at startup.
+ALTER TABLE user_addresses ADD COLUMN IF NOT EXISTS street_enc TEXT;
+ALTER TABLE user_addresses ALTER COLUMN line1 DROP NOT NULL;
+
+-- The same for the address snapshot on an order; delivery_address keeps
+-- the rest of it
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_street_enc TEXT;
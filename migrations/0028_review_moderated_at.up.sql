@@ -0,0 +1,5 @@
+-- Migration: roll up moderated reviews
+
+-- The analytics roller rebuilds the day of every review moderated since its
+-- last run, as hiding or restoring one changes that day's ratings
+CREATE INDEX IF NOT EXISTS idx_reviews_moderated_at ON reviews(moderated_at) WHERE moderated_at IS NOT NULL;
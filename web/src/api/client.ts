@@ -4,6 +4,7 @@ import { logger } from '../utils/logger'
 import type {
   User,
   Provider,
+  ProviderProfilePatch,
   Menu,
   MenuItem,
   Order,
@@ -13,7 +14,34 @@ import type {
   OtpResponse,
   ProviderSearchResult,
   ItemSearchResult,
+  SearchSuggestion,
+  SearchPage,
   Review,
+  ProviderApplication,
+  ApplicationDetails,
+  ApplicationDocument,
+  DocumentKind,
+  Organisation,
+  OutletAccess,
+  StaffMember,
+  StaffRole,
+  OrderInbox,
+  OrderStatus,
+  ProviderAnalytics,
+  AnalyticsGranularity,
+  AdminKPIs,
+  AdminProviderFilters,
+  AdminUserFilters,
+  ProviderBulkAction,
+  UserBulkAction,
+  ReviewModerationAction,
+  ReviewModerationState,
+  BulkResult,
+  AuditEvent,
+  Promotion,
+  PromotionBooking,
+  PromotionStats,
+  PromotionStatus,
 } from '../types'
 
 // Import mock APIs for development mode
@@ -28,6 +56,9 @@ import {
   mockChatApi,
   mockMediaApi,
   mockReviewApi,
+  mockOrganisationApi,
+  mockAdminApi,
+  mockPromotionApi,
 } from './mockApi'
 
 // API URL configuration for different environments
@@ -256,15 +287,115 @@ export const providerApi = {
     return data
   },
 
-  update: async (id: string, provider: Partial<Provider>): Promise<void> => {
-    if (USE_MOCK_API) return mockProviderApi.update(id, provider)
-    await api.put(`/providers/${id}`, provider)
+  update: async (id: string, patch: ProviderProfilePatch): Promise<Provider> => {
+    if (USE_MOCK_API) return mockProviderApi.update(id, patch)
+    const { data } = await api.patch(`/providers/${id}`, patch)
+    return data
+  },
+
+  // The signed-in provider's own profile. Staff of several outlets pass
+  // the outlet they mean.
+  getMe: async (outletId?: string): Promise<Provider> => {
+    if (USE_MOCK_API) return mockProviderApi.getMe()
+    const { data } = await api.get('/providers/me', { params: { outlet_id: outletId } })
+    return data
+  },
+
+  updateMe: async (patch: ProviderProfilePatch, outletId?: string): Promise<Provider> => {
+    if (USE_MOCK_API) return mockProviderApi.updateMe(patch)
+    const { data } = await api.patch('/providers/me', patch, { params: { outlet_id: outletId } })
+    return data
+  },
+
+  getAnalytics: async (
+    id: string,
+    params: { from?: string; to?: string; granularity?: AnalyticsGranularity } = {}
+  ): Promise<ProviderAnalytics> => {
+    if (USE_MOCK_API) return mockProviderApi.getAnalytics(id, params)
+    const { data } = await api.get(`/providers/${id}/analytics`, { params })
+    return data
   },
 
   verify: async (id: string, verified: boolean): Promise<void> => {
     if (USE_MOCK_API) return mockProviderApi.verify(id, verified)
     await api.post(`/providers/${id}/verify`, { verified })
   },
+
+  // Verification application for the signed-in provider
+  getApplication: async (): Promise<ProviderApplication> => {
+    if (USE_MOCK_API) return mockProviderApi.getApplication()
+    const { data } = await api.get('/providers/me/application')
+    return data
+  },
+
+  saveApplication: async (details: ApplicationDetails): Promise<ProviderApplication> => {
+    if (USE_MOCK_API) return mockProviderApi.saveApplication(details)
+    const { data } = await api.put('/providers/me/application', details)
+    return data
+  },
+
+  addDocument: async (kind: DocumentKind, objectKey: string): Promise<ApplicationDocument> => {
+    if (USE_MOCK_API) return mockProviderApi.addDocument(kind, objectKey)
+    const { data } = await api.post('/providers/me/application/documents', { kind, object_key: objectKey })
+    return data
+  },
+
+  deleteDocument: async (documentId: string): Promise<void> => {
+    if (USE_MOCK_API) return mockProviderApi.deleteDocument(documentId)
+    await api.delete(`/providers/me/application/documents/${documentId}`)
+  },
+
+  submitApplication: async (comment?: string): Promise<ProviderApplication> => {
+    if (USE_MOCK_API) return mockProviderApi.submitApplication(comment)
+    const { data } = await api.post('/providers/me/application/submit', { comment })
+    return data
+  },
+}
+
+// ==================== ORGANISATION API ====================
+export const organisationApi = {
+  create: async (name: string): Promise<Organisation> => {
+    if (USE_MOCK_API) return mockOrganisationApi.create(name)
+    const { data } = await api.post('/organisations', { name })
+    return data
+  },
+
+  // The signed-in user's organisation, their organisation-wide role and the outlets they work at
+  getMine: async (): Promise<{ organisation: Organisation; role: StaffRole | ''; access: OutletAccess[] }> => {
+    if (USE_MOCK_API) return mockOrganisationApi.getMine()
+    const { data } = await api.get('/organisations/me')
+    return data
+  },
+
+  addOutlet: async (outlet: {
+    business_name: string
+    address: string
+    lat: number
+    lng: number
+    tags?: string[]
+  }): Promise<Provider> => {
+    if (USE_MOCK_API) return mockOrganisationApi.addOutlet(outlet)
+    const { data } = await api.post('/organisations/me/outlets', outlet)
+    return data
+  },
+
+  listStaff: async (): Promise<StaffMember[]> => {
+    if (USE_MOCK_API) return mockOrganisationApi.listStaff()
+    const { data } = await api.get('/organisations/me/staff')
+    return data.staff || []
+  },
+
+  // Omit outlet_id to give the role at every outlet
+  addStaff: async (staff: { phone: string; role: Exclude<StaffRole, 'owner'>; outlet_id?: string }): Promise<StaffMember> => {
+    if (USE_MOCK_API) return mockOrganisationApi.addStaff(staff)
+    const { data } = await api.post('/organisations/me/staff', staff)
+    return data
+  },
+
+  removeStaff: async (staffId: string): Promise<void> => {
+    if (USE_MOCK_API) return mockOrganisationApi.removeStaff(staffId)
+    await api.delete(`/organisations/me/staff/${staffId}`)
+  },
 }
 
 // ==================== MENU API ====================
@@ -351,17 +482,40 @@ export const searchApi = {
     tags?: string[]
     min_rating?: number
     provider_category?: string
+    open_now?: boolean
+    deliver_at?: string
     limit?: number
-    offset?: number
+    cursor?: string
   }): Promise<ProviderSearchResult[]> => {
     if (USE_MOCK_API) return mockSearchApi.providers(params)
-    const { data } = await api.get('/search/providers', {
+    const { data } = await api.get<SearchPage<ProviderSearchResult>>('/search/providers', {
       params: {
         ...params,
         tags: params.tags?.join(','),
       },
     })
-    return data || []
+    return data?.results || []
+  },
+
+  // Provider search with its promoted slots, which come back on the first page only
+  providerPage: async (params: {
+    lat: number
+    lng: number
+    radius?: number
+    tags?: string[]
+    min_rating?: number
+    provider_category?: string
+    limit?: number
+    cursor?: string
+  }): Promise<SearchPage<ProviderSearchResult>> => {
+    if (USE_MOCK_API) return mockSearchApi.providerPage(params)
+    const { data } = await api.get<SearchPage<ProviderSearchResult>>('/search/providers', {
+      params: {
+        ...params,
+        tags: params.tags?.join(','),
+      },
+    })
+    return data
   },
 
   items: async (params: {
@@ -374,17 +528,25 @@ export const searchApi = {
     tags?: string[]
     min_rating?: number
     price_max?: number
+    open_now?: boolean
+    deliver_at?: string
     limit?: number
-    offset?: number
+    cursor?: string
   }): Promise<ItemSearchResult[]> => {
     if (USE_MOCK_API) return mockSearchApi.items(params)
-    const { data } = await api.get('/search/items', {
+    const { data } = await api.get<SearchPage<ItemSearchResult>>('/search/items', {
       params: {
         ...params,
         tags: params.tags?.join(','),
       },
     })
-    return data || []
+    return data?.results || []
+  },
+
+  suggest: async (params: { q: string; lat?: number; lng?: number; limit?: number }): Promise<SearchSuggestion[]> => {
+    if (USE_MOCK_API) return mockSearchApi.suggest(params)
+    const { data } = await api.get('/search/suggest', { params })
+    return data?.suggestions || []
   },
 }
 
@@ -394,7 +556,10 @@ export const orderApi = {
     provider_id: string
     items: Array<{ item_id: string; name: string; qty: number; price: number }>
     total: number
-  }): Promise<{ order_id: string; order_code: string; otp?: string }> => {
+    address_id?: string // Saved delivery address
+    lat?: number // Or a raw delivery location; omit both for pickup
+    lng?: number
+  }): Promise<{ order_id: string; order_code: string; otp?: string; delivery_fee?: number; total?: number }> => {
     if (USE_MOCK_API) return mockOrderApi.create(order)
     const { data } = await api.post('/orders', order)
     return data
@@ -418,6 +583,13 @@ export const orderApi = {
     return data || []
   },
 
+  // Orders for every outlet the signed-in user works at
+  getInbox: async (params: { outlet_id?: string; status?: OrderStatus } = {}): Promise<OrderInbox> => {
+    if (USE_MOCK_API) return mockOrderApi.getInbox(params)
+    const { data } = await api.get('/orders/inbox', { params })
+    return { orders: data.orders || [], outlets: data.outlets || [] }
+  },
+
   getProviderOrders: async (providerId: string): Promise<Order[]> => {
     if (USE_MOCK_API) return mockOrderApi.getProviderOrders(providerId)
     const { data } = await api.get(`/orders/provider/${providerId}`)
@@ -520,6 +692,129 @@ export const reviewApi = {
     if (USE_MOCK_API) return mockReviewApi.delete(reviewId)
     await api.delete(`/reviews/${reviewId}`)
   },
+
+  // Flag someone else's review for moderation
+  report: async (reviewId: string, reason: string): Promise<void> => {
+    if (USE_MOCK_API) return mockReviewApi.report(reviewId, reason)
+    await api.post(`/reviews/${reviewId}/report`, { reason })
+  },
+}
+
+// ==================== ADMIN API ====================
+export const adminApi = {
+  // Platform KPIs over from..to (YYYY-MM-DD, default the last 30 days)
+  getKpis: async (params: { from?: string; to?: string } = {}): Promise<AdminKPIs> => {
+    if (USE_MOCK_API) return mockAdminApi.getKpis(params)
+    const { data } = await api.get('/admin/kpis', { params })
+    return data
+  },
+
+  searchProviders: async (filters: AdminProviderFilters = {}): Promise<{ providers: Provider[]; total: number }> => {
+    if (USE_MOCK_API) return mockAdminApi.searchProviders(filters)
+    const { data } = await api.get('/admin/providers', { params: filters })
+    return data
+  },
+
+  bulkProviders: async (ids: string[], action: ProviderBulkAction, reason?: string): Promise<BulkResult> => {
+    if (USE_MOCK_API) return mockAdminApi.bulkProviders(ids, action, reason)
+    const { data } = await api.post('/admin/providers/bulk', { ids, action, reason })
+    return data
+  },
+
+  searchUsers: async (filters: AdminUserFilters = {}): Promise<{ users: User[]; total: number }> => {
+    if (USE_MOCK_API) return mockAdminApi.searchUsers(filters)
+    const { data } = await api.get('/admin/users', { params: filters })
+    return data
+  },
+
+  bulkUsers: async (ids: string[], action: UserBulkAction, reason?: string, until?: string): Promise<BulkResult> => {
+    if (USE_MOCK_API) return mockAdminApi.bulkUsers(ids, action, reason, until)
+    const { data } = await api.post('/admin/users/bulk', { ids, action, reason, until })
+    return data
+  },
+
+  // Moderation queue; flagged reviews, most reported first, by default
+  getReviews: async (state: ReviewModerationState = 'flagged', limit = 50, offset = 0): Promise<{ reviews: Review[]; total: number }> => {
+    if (USE_MOCK_API) return mockAdminApi.getReviews(state, limit, offset)
+    const { data } = await api.get('/admin/reviews', { params: { state, limit, offset } })
+    return data
+  },
+
+  moderateReview: async (reviewId: string, action: ReviewModerationAction, note?: string): Promise<void> => {
+    if (USE_MOCK_API) return mockAdminApi.moderateReview(reviewId, action, note)
+    await api.post(`/admin/reviews/${reviewId}/moderate`, { action, note })
+  },
+
+  bulkReviews: async (ids: string[], action: ReviewModerationAction, note?: string): Promise<BulkResult> => {
+    if (USE_MOCK_API) return mockAdminApi.bulkReviews(ids, action, note)
+    const { data } = await api.post('/admin/reviews/bulk', { ids, action, note })
+    return data
+  },
+
+  getAudit: async (adminId?: string, limit = 50, offset = 0): Promise<AuditEvent[]> => {
+    if (USE_MOCK_API) return mockAdminApi.getAudit(adminId, limit, offset)
+    const { data } = await api.get('/admin/audit', { params: { admin_id: adminId, limit, offset } })
+    return data || []
+  },
+
+  getPromotions: async (params: { status?: PromotionStatus; city?: string; provider_id?: string; limit?: number; offset?: number } = {}): Promise<{ promotions: Promotion[]; total: number }> => {
+    if (USE_MOCK_API) return mockPromotionApi.list(params)
+    const { data } = await api.get('/admin/promotions', { params })
+    return data
+  },
+
+  // Book a promoted slot without payment; amountPaise records offline billing
+  createPromotion: async (providerId: string, booking: PromotionBooking, amountPaise = 0): Promise<Promotion> => {
+    if (USE_MOCK_API) return mockPromotionApi.create(providerId, booking, 'admin', amountPaise)
+    const { data } = await api.post('/admin/promotions', { ...booking, provider_id: providerId, amount_paise: amountPaise })
+    return data
+  },
+
+  cancelPromotion: async (id: string): Promise<Promotion> => {
+    if (USE_MOCK_API) return mockPromotionApi.cancel(id)
+    const { data } = await api.post(`/admin/promotions/${id}/cancel`)
+    return data
+  },
+}
+
+// ==================== PROMOTION API ====================
+export const promotionApi = {
+  list: async (providerId: string, status?: PromotionStatus): Promise<{ promotions: Promotion[]; total: number }> => {
+    if (USE_MOCK_API) return mockPromotionApi.list({ provider_id: providerId, status })
+    const { data } = await api.get(`/providers/${providerId}/promotions`, { params: { status } })
+    return data
+  },
+
+  // Book a promoted slot; pay the returned Razorpay order, then call pay
+  book: async (providerId: string, booking: PromotionBooking): Promise<{ promotion: Promotion } & RazorpayOrderResponse> => {
+    if (USE_MOCK_API) {
+      const promotion = await mockPromotionApi.create(providerId, booking, 'payment', 0)
+      return { promotion, razorpay_order_id: promotion.payment_order_id || '', amount: promotion.amount_paise, currency: 'INR', key_id: 'rzp_test_mock' }
+    }
+    const { data } = await api.post(`/providers/${providerId}/promotions`, booking)
+    return data
+  },
+
+  pay: async (providerId: string, promotionId: string, razorpayPaymentId: string, razorpaySignature: string): Promise<Promotion> => {
+    if (USE_MOCK_API) return mockPromotionApi.activate(promotionId, razorpayPaymentId)
+    const { data } = await api.post(`/providers/${providerId}/promotions/${promotionId}/pay`, {
+      razorpay_payment_id: razorpayPaymentId,
+      razorpay_signature: razorpaySignature,
+    })
+    return data
+  },
+
+  stats: async (providerId: string, promotionId: string): Promise<PromotionStats> => {
+    if (USE_MOCK_API) return mockPromotionApi.stats(promotionId)
+    const { data } = await api.get(`/providers/${providerId}/promotions/${promotionId}/stats`)
+    return data
+  },
+
+  // Count a tap on a promoted slot; failures don't matter to the buyer
+  click: async (promotionId: string): Promise<void> => {
+    if (USE_MOCK_API) return mockPromotionApi.click(promotionId)
+    await api.post(`/promotions/${promotionId}/click`).catch(() => undefined)
+  },
 }
 
 // ==================== PAYMENT API (Razorpay) ====================
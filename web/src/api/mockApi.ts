@@ -3,6 +3,7 @@
 import type {
   User,
   Provider,
+  ProviderProfilePatch,
   Menu,
   MenuItem,
   Order,
@@ -11,9 +12,34 @@ import type {
   OtpResponse,
   ProviderSearchResult,
   ItemSearchResult,
+  SearchSuggestion,
   Chat,
   ChatMessage,
   Review,
+  ProviderApplication,
+  ApplicationDetails,
+  ApplicationDocument,
+  DocumentKind,
+  Organisation,
+  StaffMember,
+  StaffRole,
+  OrderInbox,
+  ProviderAnalytics,
+  AnalyticsGranularity,
+  AdminKPIs,
+  AdminProviderFilters,
+  AdminUserFilters,
+  ProviderBulkAction,
+  UserBulkAction,
+  ReviewModerationAction,
+  ReviewModerationState,
+  BulkResult,
+  AuditEvent,
+  SearchPage,
+  Promotion,
+  PromotionBooking,
+  PromotionStats,
+  PromotionStatus,
 } from '../types'
 
 import {
@@ -74,6 +100,9 @@ let menuItems: Record<string, MenuItem[]> = loadFromStorage<Record<string, MenuI
 let otpStore: Record<string, string> = {}
 let chats: Chat[] = loadFromStorage<Chat[]>(STORAGE_KEYS.CHATS, [])
 let messages: ChatMessage[] = loadFromStorage<ChatMessage[]>(STORAGE_KEYS.MESSAGES, [])
+let application: ProviderApplication | null = null // Signed-in provider's verification application
+let organisation: Organisation | null = null // Signed-in provider's organisation
+let staff: StaffMember[] = []
 
 // Persist changes helper
 function persistUser() {
@@ -162,16 +191,163 @@ export const mockProviderApi = {
     if (currentUser) currentUser = { ...currentUser, role: 'provider' }
     return newP
   },
-  update: async (id: string, updates: Partial<Provider>): Promise<void> => {
+  update: async (id: string, patch: ProviderProfilePatch): Promise<Provider> => {
     await delay(300)
     const i = providers.findIndex(p => p.id === id)
-    if (i !== -1) providers[i] = { ...providers[i], ...updates }
+    if (i === -1) throw new Error('Provider not found')
+    const updates = Object.fromEntries(Object.entries(patch).filter(([, v]) => v !== null))
+    providers[i] = { ...providers[i], ...updates }
+    return providers[i]
+  },
+  getMe: async (): Promise<Provider> => {
+    await delay(200)
+    const p = providers.find(p => p.user_id === currentUser?.id)
+    if (!p) throw new Error('Provider not found')
+    return p
+  },
+  updateMe: async (patch: ProviderProfilePatch): Promise<Provider> => {
+    const p = providers.find(p => p.user_id === currentUser?.id)
+    if (!p) throw new Error('Provider not found')
+    return mockProviderApi.update(p.id, patch)
+  },
+  getAnalytics: async (id: string, params: { from?: string; to?: string; granularity?: AnalyticsGranularity } = {}): Promise<ProviderAnalytics> => {
+    await delay(300)
+    const to = params.to || new Date().toISOString().slice(0, 10)
+    const from = params.from || new Date(Date.parse(to) - 29 * 86400000).toISOString().slice(0, 10)
+    const mine = orders.filter(o => o.provider_id === id && o.created_at.slice(0, 10) >= from && o.created_at.slice(0, 10) <= to)
+    const billable = mine.filter(o => ['CONFIRMED', 'READY', 'COMPLETED'].includes(o.status))
+    const revenue = billable.reduce((sum, o) => sum + o.total_estimate, 0)
+    const byStatus: Partial<Record<OrderStatus, number>> = {}
+    const hours: Record<number, number> = {}
+    const buyers: Record<string, number> = {}
+    const items: Record<string, { item_id: string; name: string; quantity: number; revenue: number }> = {}
+    mine.forEach(o => {
+      byStatus[o.status] = (byStatus[o.status] || 0) + 1
+      const h = new Date(o.created_at).getHours()
+      hours[h] = (hours[h] || 0) + 1
+    })
+    billable.forEach(o => {
+      buyers[o.buyer_id] = (buyers[o.buyer_id] || 0) + 1
+      o.items.forEach(it => {
+        const item = items[it.item_id] || (items[it.item_id] = { item_id: it.item_id, name: it.name, quantity: 0, revenue: 0 })
+        item.quantity += it.qty
+        item.revenue += it.qty * it.price
+      })
+    })
+    const customers = Object.keys(buyers).length
+    const repeat = Object.values(buyers).filter(n => n > 1).length
+    const p = providers.find(x => x.id === id)
+    return {
+      provider_id: id, from, to, granularity: params.granularity || 'day', timezone: 'Asia/Kolkata',
+      orders: mine.length, orders_by_status: byStatus, revenue,
+      average_order_value: billable.length ? revenue / billable.length : 0,
+      customers, repeat_customers: repeat, repeat_customer_rate: customers ? repeat / customers : 0,
+      ratings: p?.total_ratings || 0, average_rating: p?.rating || 0,
+      series: [],
+      top_items: Object.values(items).sort((a, b) => b.quantity - a.quantity).slice(0, 10),
+      peak_hours: Object.entries(hours).map(([hour, n]) => ({ hour: Number(hour), orders: n })).sort((a, b) => b.orders - a.orders),
+    }
   },
   verify: async (id: string, verified: boolean): Promise<void> => {
     await delay(300)
     const i = providers.findIndex(p => p.id === id)
     if (i !== -1) providers[i] = { ...providers[i], verified }
   },
+  getApplication: async (): Promise<ProviderApplication> => {
+    await delay(200)
+    if (!application) throw new Error('No application yet')
+    return application
+  },
+  saveApplication: async (details: ApplicationDetails): Promise<ProviderApplication> => {
+    await delay(300)
+    const { aadhar_number, ...rest } = details
+    const now = new Date().toISOString()
+    application = {
+      id: application?.id || 'app-' + Date.now(),
+      provider_id: providers[providers.length - 1]?.id || '',
+      state: 'draft',
+      documents: [],
+      history: [],
+      created_at: now,
+      ...(application ?? {}),
+      ...rest,
+      aadhar_number: aadhar_number ? 'XXXX-XXXX-' + aadhar_number.slice(-4) : application?.aadhar_number,
+      updated_at: now,
+    }
+    return application
+  },
+  addDocument: async (kind: DocumentKind, objectKey: string): Promise<ApplicationDocument> => {
+    await delay(200)
+    if (!application) throw new Error('No application yet')
+    const doc = { id: 'doc-' + Date.now(), kind, object_key: objectKey, uploaded_at: new Date().toISOString() }
+    application.documents.push(doc)
+    return doc
+  },
+  deleteDocument: async (documentId: string): Promise<void> => {
+    await delay(200)
+    if (application) application.documents = application.documents.filter(d => d.id !== documentId)
+  },
+  submitApplication: async (comment?: string): Promise<ProviderApplication> => {
+    await delay(300)
+    if (!application) throw new Error('No application yet')
+    const now = new Date().toISOString()
+    application.history.push({
+      id: 'c-' + Date.now(), author_id: currentUser?.id || '', from_state: application.state,
+      to_state: 'submitted', comment, created_at: now,
+    })
+    application = { ...application, state: 'submitted', submitted_at: now }
+    return application
+  },
+}
+
+export const mockOrganisationApi = {
+  create: async (name: string): Promise<Organisation> => {
+    await delay(400)
+    if (organisation) throw new Error('You already belong to an organisation')
+    const id = 'org-' + Date.now()
+    providers = providers.map(p => (p.user_id === currentUser?.id ? { ...p, organisation_id: id } : p))
+    organisation = {
+      id, name, owner_id: currentUser?.id || '', created_at: new Date().toISOString(),
+      outlets: providers.filter(p => p.organisation_id === id),
+    }
+    staff = [{ id: 'staff-' + Date.now(), organisation_id: id, user_id: currentUser?.id || '', name: currentUser?.name, role: 'owner', created_at: organisation.created_at }]
+    return organisation
+  },
+  getMine: async () => {
+    await delay(200)
+    if (!organisation) throw new Error("You don't belong to an organisation")
+    const outlets = providers.filter(p => p.organisation_id === organisation!.id)
+    return {
+      organisation: { ...organisation, outlets },
+      role: 'owner' as StaffRole,
+      access: outlets.map(p => ({ outlet_id: p.id, organisation_id: organisation!.id, business_name: p.business_name, role: 'owner' as StaffRole })),
+    }
+  },
+  addOutlet: async (outlet: { business_name: string; address: string; lat: number; lng: number; tags?: string[] }): Promise<Provider> => {
+    if (!organisation) throw new Error("You don't belong to an organisation")
+    const p = await mockProviderApi.create(outlet)
+    const i = providers.findIndex(x => x.id === p.id)
+    providers[i] = { ...providers[i], organisation_id: organisation.id }
+    return providers[i]
+  },
+  listStaff: async (): Promise<StaffMember[]> => {
+    await delay(200)
+    return staff
+  },
+  addStaff: async (member: { phone: string; role: Exclude<StaffRole, 'owner'>; outlet_id?: string }): Promise<StaffMember> => {
+    await delay(300)
+    if (!organisation) throw new Error("You don't belong to an organisation")
+    const m: StaffMember = {
+      id: 'staff-' + Date.now(), organisation_id: organisation.id, user_id: 'user-' + member.phone,
+      phone: member.phone, outlet_id: member.outlet_id, role: member.role, created_at: new Date().toISOString(),
+    }
+    staff.push(m)
+    return m
+  },
+  removeStaff: async (staffId: string): Promise<void> => {
+    await delay(200)
+    staff = staff.filter(m => m.id !== staffId || m.role === 'owner')
+  },
 }
 
 export const mockMenuApi = {
@@ -243,6 +419,21 @@ export const mockSearchApi = {
     results.sort((a, b) => (a.distance_meters || 0) - (b.distance_meters || 0))
     return results.slice(params.offset || 0, (params.offset || 0) + (params.limit || 20))
   },
+  providerPage: async (params: { lat: number; lng: number; radius?: number; tags?: string[]; min_rating?: number; provider_category?: string; limit?: number; cursor?: string }): Promise<SearchPage<ProviderSearchResult>> => {
+    const results = await mockSearchApi.providers({ ...params, limit: params.limit })
+    const now = new Date().toISOString()
+    const promoted = promotions
+      .filter(pr => pr.status === 'active' && pr.starts_at <= now && pr.ends_at > now)
+      .filter(pr => !pr.provider_category || pr.provider_category === params.provider_category)
+      .map(pr => {
+        const provider = results.find(p => p.id === pr.provider_id)
+        return provider && { ...provider, label: 'Sponsored', promotion_id: pr.id }
+      })
+      .filter((p): p is ProviderSearchResult => !!p)
+      .slice(0, 2)
+    if (!params.cursor) promoted.forEach(p => { countPromotion(p.promotion_id!, 'impressions') })
+    return { results, promoted: params.cursor ? undefined : promoted, total: results.length, facets: {} }
+  },
   items: async (params: { lat: number; lng: number; radius?: number; q?: string; jain_only?: boolean; available_only?: boolean; tags?: string[]; price_max?: number; food_category?: string; limit?: number; offset?: number }): Promise<ItemSearchResult[]> => {
     await delay(400)
     let allItems: ItemSearchResult[] = []
@@ -260,6 +451,18 @@ export const mockSearchApi = {
     if (params.food_category) allItems = allItems.filter(i => i.food_category === params.food_category)
     return allItems.slice(params.offset || 0, (params.offset || 0) + (params.limit || 20))
   },
+  suggest: async (params: { q: string; limit?: number }): Promise<SearchSuggestion[]> => {
+    await delay(100)
+    const q = params.q.trim().toLowerCase()
+    if (!q) return []
+    const dishes = Object.values(menuItems).flat()
+      .filter(i => i.name.toLowerCase().split(/\s+/).some(w => w.startsWith(q)))
+      .map(i => ({ type: 'dish' as const, text: i.name }))
+    const found = providers
+      .filter(p => p.business_name.toLowerCase().split(/\s+/).some(w => w.startsWith(q)))
+      .map(p => ({ type: 'provider' as const, text: p.business_name, id: p.id }))
+    return [...dishes, ...found].slice(0, params.limit || 8)
+  },
 }
 
 export const mockOrderApi = {
@@ -300,6 +503,19 @@ export const mockOrderApi = {
     await delay(300)
     return orders.filter(o => o.buyer_id === currentUser?.id).sort((a, b) => new Date(b.created_at).getTime() - new Date(a.created_at).getTime())
   },
+  getInbox: async (params: { outlet_id?: string; status?: OrderStatus } = {}): Promise<OrderInbox> => {
+    await delay(300)
+    const outlets = providers
+      .filter(p => p.user_id === currentUser?.id)
+      .map(p => ({ outlet_id: p.id, organisation_id: p.organisation_id, business_name: p.business_name, role: 'owner' as StaffRole }))
+    const ids = outlets.map(o => o.outlet_id).filter(id => !params.outlet_id || id === params.outlet_id)
+    return {
+      orders: orders
+        .filter(o => ids.includes(o.provider_id) && (!params.status || o.status === params.status))
+        .sort((a, b) => new Date(b.created_at).getTime() - new Date(a.created_at).getTime()),
+      outlets,
+    }
+  },
   getProviderOrders: async (providerId: string): Promise<Order[]> => {
     await delay(300)
     return orders.filter(o => o.provider_id === providerId).sort((a, b) => new Date(b.created_at).getTime() - new Date(a.created_at).getTime())
@@ -459,6 +675,226 @@ export const mockReviewApi = {
     reviews = reviews.filter(r => r.id !== reviewId)
     saveToStorage('jain-food-mock-reviews', reviews)
   },
+
+  report: async (reviewId: string, reason: string): Promise<void> => {
+    await delay(300)
+    const review = reviews.find(r => r.id === reviewId)
+    if (!review) throw new Error('review not found')
+    review.report_count = (review.report_count || 0) + 1
+    review.report_reasons = [...(review.report_reasons || []), reason]
+    if (!review.moderation_state || review.moderation_state === 'published') {
+      review.moderation_state = 'flagged'
+    }
+    saveToStorage('jain-food-mock-reviews', reviews)
+  },
+}
+
+// ==================== ADMIN ====================
+const adminUsers: User[] = [mockUser, mockProviderUser]
+
+// bulk applies fn to each id, collecting failures like the API does
+const bulk = (ids: string[], fn: (id: string) => void): BulkResult => {
+  const result: BulkResult = { succeeded: [], failed: [] }
+  for (const id of new Set(ids)) {
+    try {
+      fn(id)
+      result.succeeded.push(id)
+    } catch (err) {
+      result.failed.push({ id, error: (err as Error).message })
+    }
+  }
+  return result
+}
+
+const moderate = (reviewId: string, action: ReviewModerationAction) => {
+  const review = reviews.find(r => r.id === reviewId)
+  if (!review) throw new Error('review not found')
+  if (action === 'delete') {
+    reviews = reviews.filter(r => r.id !== reviewId)
+  } else {
+    review.moderation_state = action === 'approve' ? 'approved' : 'hidden'
+  }
+}
+
+export const mockAdminApi = {
+  getKpis: async (params: { from?: string; to?: string }): Promise<AdminKPIs> => {
+    await delay(300)
+    const today = new Date().toISOString().slice(0, 10)
+    const billable = orders.filter(o => ['CONFIRMED', 'READY', 'COMPLETED'].includes(o.status))
+    const active = new Set(billable.map(o => o.provider_id))
+    return {
+      from: params.from || new Date(Date.now() - 29 * 24 * 60 * 60 * 1000).toISOString().slice(0, 10),
+      to: params.to || today,
+      users: adminUsers.length,
+      signups: adminUsers.length,
+      signups_by_role: { buyer: 1, provider: 1 },
+      orders: orders.length,
+      gmv: billable.reduce((sum, o) => sum + (o.total_estimate || 0), 0),
+      active_providers: active.size,
+      active_providers_by_city: active.size > 0 ? [{ city: 'Mumbai', count: active.size }] : [],
+      pending_applications: 0,
+      flagged_reviews: reviews.filter(r => r.moderation_state === 'flagged').length,
+      blocked_users: adminUsers.filter(u => u.blocked).length,
+      updated_at: new Date().toISOString(),
+    }
+  },
+
+  searchProviders: async (filters: AdminProviderFilters): Promise<{ providers: Provider[]; total: number }> => {
+    await delay(300)
+    const q = (filters.q || '').toLowerCase()
+    const matched = providers.filter(p =>
+      (!q || p.business_name.toLowerCase().includes(q)) &&
+      (filters.verified === undefined || p.verified === filters.verified) &&
+      (filters.blocked === undefined || !!p.blocked === filters.blocked) &&
+      (!filters.category || p.provider_category === filters.category || (p.food_categories || []).some(c => c === filters.category))
+    )
+    const offset = filters.offset || 0
+    return { providers: matched.slice(offset, offset + (filters.limit || 50)), total: matched.length }
+  },
+
+  bulkProviders: async (ids: string[], action: ProviderBulkAction, reason?: string): Promise<BulkResult> => {
+    await delay(400)
+    const result = bulk(ids, id => {
+      const provider = providers.find(p => p.id === id)
+      if (!provider) throw new Error('provider not found')
+      if (action === 'verify' || action === 'unverify') provider.verified = action === 'verify'
+      else {
+        provider.blocked = action === 'block'
+        provider.blocked_reason = action === 'block' ? reason : undefined
+      }
+    })
+    saveToStorage(STORAGE_KEYS.PROVIDERS, providers)
+    return result
+  },
+
+  searchUsers: async (filters: AdminUserFilters): Promise<{ users: User[]; total: number }> => {
+    await delay(300)
+    const q = (filters.q || '').toLowerCase()
+    const matched = adminUsers.filter(u =>
+      (!q || (u.name || '').toLowerCase().includes(q) || u.phone.includes(q)) &&
+      (!filters.role || u.role === filters.role) &&
+      (filters.blocked === undefined || u.blocked === filters.blocked)
+    )
+    const offset = filters.offset || 0
+    return { users: matched.slice(offset, offset + (filters.limit || 50)), total: matched.length }
+  },
+
+  bulkUsers: async (ids: string[], action: UserBulkAction, reason?: string, until?: string): Promise<BulkResult> => {
+    await delay(400)
+    return bulk(ids, id => {
+      const user = adminUsers.find(u => u.id === id)
+      if (!user) throw new Error('user not found')
+      user.blocked = action === 'block'
+      user.blocked_reason = action === 'block' ? reason : undefined
+      user.blocked_until = action === 'block' ? until : undefined
+    })
+  },
+
+  getReviews: async (state: ReviewModerationState, limit: number, offset: number): Promise<{ reviews: Review[]; total: number }> => {
+    await delay(300)
+    const matched = reviews
+      .filter(r => (r.moderation_state || 'published') === state)
+      .sort((a, b) => (b.report_count || 0) - (a.report_count || 0))
+    return { reviews: matched.slice(offset, offset + limit), total: matched.length }
+  },
+
+  moderateReview: async (reviewId: string, action: ReviewModerationAction, _note?: string): Promise<void> => {
+    await delay(300)
+    moderate(reviewId, action)
+    saveToStorage('jain-food-mock-reviews', reviews)
+  },
+
+  bulkReviews: async (ids: string[], action: ReviewModerationAction, _note?: string): Promise<BulkResult> => {
+    await delay(400)
+    const result = bulk(ids, id => moderate(id, action))
+    saveToStorage('jain-food-mock-reviews', reviews)
+    return result
+  },
+
+  getAudit: async (_adminId?: string, _limit = 50, _offset = 0): Promise<AuditEvent[]> => {
+    await delay(200)
+    return []
+  },
+}
+
+// Promoted listings, kept in memory with per-day counts
+let promotions: Promotion[] = []
+const promotionDays: Record<string, Record<string, { impressions: number; clicks: number }>> = {}
+
+const countPromotion = (id: string, kind: 'impressions' | 'clicks') => {
+  const day = new Date().toISOString().slice(0, 10)
+  const days = (promotionDays[id] = promotionDays[id] || {})
+  days[day] = days[day] || { impressions: 0, clicks: 0 }
+  days[day][kind]++
+  const promotion = promotions.find(pr => pr.id === id)
+  if (promotion) promotion[kind]++
+}
+
+export const mockPromotionApi = {
+  list: async (params: { status?: PromotionStatus; city?: string; provider_id?: string; limit?: number; offset?: number }): Promise<{ promotions: Promotion[]; total: number }> => {
+    await delay(200)
+    const matched = promotions.filter(pr =>
+      (!params.status || pr.status === params.status) &&
+      (!params.city || pr.city === params.city) &&
+      (!params.provider_id || pr.provider_id === params.provider_id)
+    )
+    const offset = params.offset || 0
+    return { promotions: matched.slice(offset, offset + (params.limit || 50)), total: matched.length }
+  },
+
+  create: async (providerId: string, booking: PromotionBooking, source: 'admin' | 'payment', amountPaise: number): Promise<Promotion> => {
+    await delay(300)
+    const days = Math.max(1, Math.ceil((Date.parse(booking.ends_at) - Date.parse(booking.starts_at)) / (24 * 60 * 60 * 1000)))
+    const id = 'promo_' + Date.now()
+    const promotion: Promotion = {
+      id,
+      provider_id: providerId,
+      business_name: providers.find(p => p.id === providerId)?.business_name,
+      ...booking,
+      status: source === 'admin' ? 'active' : 'pending_payment',
+      source,
+      amount_paise: source === 'admin' ? amountPaise : Math.ceil((days * booking.daily_cap * 5000) / 1000 / 100) * 100,
+      payment_order_id: source === 'payment' ? 'order_mock_' + id : undefined,
+      impressions: 0,
+      clicks: 0,
+      created_at: new Date().toISOString(),
+    }
+    promotions = [promotion, ...promotions]
+    return promotion
+  },
+
+  activate: async (id: string, paymentId: string): Promise<Promotion> => {
+    await delay(300)
+    const promotion = promotions.find(pr => pr.id === id)
+    if (!promotion || promotion.status !== 'pending_payment') throw new Error('promotion is not awaiting this payment')
+    promotion.status = 'active'
+    promotion.payment_id = paymentId
+    return promotion
+  },
+
+  cancel: async (id: string): Promise<Promotion> => {
+    await delay(200)
+    const promotion = promotions.find(pr => pr.id === id)
+    if (!promotion) throw new Error('promotion not found')
+    if (promotion.status !== 'active' && promotion.status !== 'pending_payment') throw new Error('promotion has already ended or been cancelled')
+    promotion.status = 'cancelled'
+    return promotion
+  },
+
+  stats: async (id: string): Promise<PromotionStats> => {
+    await delay(200)
+    const promotion = promotions.find(pr => pr.id === id)
+    if (!promotion) throw new Error('promotion not found')
+    const ctr = (clicks: number, impressions: number) => (impressions ? Math.round((clicks / impressions) * 10000) / 10000 : 0)
+    const days = Object.entries(promotionDays[id] || {})
+      .sort(([a], [b]) => a.localeCompare(b))
+      .map(([day, d]) => ({ day, ...d, ctr: ctr(d.clicks, d.impressions) }))
+    return { promotion, days, ctr: ctr(promotion.clicks, promotion.impressions) }
+  },
+
+  click: async (id: string): Promise<void> => {
+    countPromotion(id, 'clicks')
+  },
 }
 
 // Reset all mock data (useful for testing)
@@ -472,6 +908,7 @@ export const resetMockData = () => {
   chats.length = 0
   messages.length = 0
   otpStore = {}
+  promotions = []
 
   // Clear localStorage
   Object.values(STORAGE_KEYS).forEach(key => localStorage.removeItem(key))
@@ -11,7 +11,7 @@ import {
   ClockIcon,
 } from '@heroicons/react/24/outline'
 import { StarIcon as StarSolid } from '@heroicons/react/24/solid'
-import { searchApi } from '../api/client'
+import { searchApi, promotionApi } from '../api/client'
 import { useLocationStore } from '../store/locationStore'
 import { JAIN_TAGS } from '../types'
 import type { ProviderSearchResult, ItemSearchResult } from '../types'
@@ -220,6 +220,7 @@ function ProviderCard({ provider }: { provider: ProviderSearchResult }) {
   return (
     <Link
       to={`/provider/${provider.id}`}
+      onClick={() => provider.promotion_id && promotionApi.click(provider.promotion_id)}
       className="flex bg-white rounded-xl overflow-hidden shadow-sm hover:shadow-md transition-shadow"
     >
       <div className="w-28 h-28 flex-shrink-0 bg-gradient-to-br from-primary-100 to-orange-100 flex items-center justify-center">
@@ -231,7 +232,14 @@ function ProviderCard({ provider }: { provider: ProviderSearchResult }) {
       </div>
       <div className="flex-1 p-3">
         <div className="flex items-start justify-between">
-          <h3 className="font-semibold text-gray-900 line-clamp-1">{provider.business_name}</h3>
+          <h3 className="font-semibold text-gray-900 line-clamp-1">
+            {provider.business_name}
+            {provider.label && (
+              <span className="ml-2 align-middle text-[10px] font-medium uppercase tracking-wide px-1.5 py-0.5 bg-amber-100 text-amber-700 rounded">
+                {provider.label}
+              </span>
+            )}
+          </h3>
           <div className="flex items-center gap-1 bg-green-100 px-1.5 py-0.5 rounded text-xs">
             <StarSolid className="w-3 h-3 text-green-600" />
             <span className="font-medium text-green-700">{provider.rating > 0 ? provider.rating.toFixed(1) : 'New'}</span>
@@ -323,10 +331,10 @@ export default function SearchPage() {
   }, [searchQuery, searchType, filters, providerCategory, foodCategory, setSearchParams])
 
   // Provider search query
-  const { data: providers, isLoading: providersLoading } = useQuery({
+  const { data: providerPage, isLoading: providersLoading } = useQuery({
     queryKey: ['search-providers', lat, lng, filters, providerCategory],
     queryFn: () =>
-      searchApi.providers({
+      searchApi.providerPage({
         lat: lat || 19.076,
         lng: lng || 72.8777,
         radius: 15000,
@@ -337,6 +345,8 @@ export default function SearchPage() {
       }),
     enabled: searchType === 'providers',
   })
+  const providers = providerPage?.results
+  const promoted = providerPage?.promoted || []
 
   // Item search query
   const { data: items, isLoading: itemsLoading } = useQuery({
@@ -459,6 +469,15 @@ export default function SearchPage() {
           providers && providers.length > 0 ? (
             <div className="space-y-4">
               <p className="text-sm text-gray-600">{providers.length} restaurants found</p>
+              {promoted.map((provider) => (
+                <motion.div
+                  key={`promoted-${provider.promotion_id}`}
+                  initial={{ opacity: 0, y: 10 }}
+                  animate={{ opacity: 1, y: 0 }}
+                >
+                  <ProviderCard provider={provider} />
+                </motion.div>
+              ))}
               {providers.map((provider) => (
                 <motion.div
                   key={provider.id}
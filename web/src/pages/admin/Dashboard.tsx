@@ -1,3 +1,4 @@
+import { useQuery } from '@tanstack/react-query'
 import { motion } from 'framer-motion'
 import {
   UsersIcon,
@@ -8,21 +9,11 @@ import {
   ArrowTrendingUpIcon,
   CheckBadgeIcon,
   ExclamationTriangleIcon,
+  NoSymbolIcon,
 } from '@heroicons/react/24/outline'
 import { Link } from 'react-router-dom'
 import { useLanguageStore } from '../../store/languageStore'
-
-// Mock stats - replace with actual API call
-const mockStats = {
-  totalUsers: 1250,
-  totalProviders: 85,
-  totalOrders: 4520,
-  totalReviews: 890,
-  pendingVerifications: 12,
-  reportedReviews: 5,
-  todayOrders: 45,
-  revenue: 125000,
-}
+import { adminApi } from '../../api/client'
 
 function StatCard({
   title,
@@ -62,8 +53,12 @@ function StatCard({
 }
 
 export default function AdminDashboard() {
-  // In production, fetch from API
-  const stats = mockStats
+  // Last 30 days
+  const { data: kpis } = useQuery({
+    queryKey: ['admin-kpis'],
+    queryFn: () => adminApi.getKpis(),
+  })
+  const count = (n: number | undefined) => (kpis ? (n ?? 0).toLocaleString() : '–')
 
   return (
     <div className="min-h-screen bg-gray-50 p-4 md:p-8">
@@ -78,57 +73,57 @@ export default function AdminDashboard() {
         <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-4 gap-6 mb-8">
           <StatCard
             title="Total Users"
-            value={stats.totalUsers.toLocaleString()}
+            value={count(kpis?.users)}
             icon={UsersIcon}
             color="bg-blue-500"
             link="/admin/users"
           />
           <StatCard
-            title="Total Providers"
-            value={stats.totalProviders.toLocaleString()}
+            title="Active Providers (30d)"
+            value={count(kpis?.active_providers)}
             icon={BuildingStorefrontIcon}
             color="bg-green-500"
             link="/admin/providers"
           />
           <StatCard
-            title="Total Orders"
-            value={stats.totalOrders.toLocaleString()}
+            title="Orders (30d)"
+            value={count(kpis?.orders)}
             icon={ShoppingBagIcon}
             color="bg-purple-500"
           />
           <StatCard
-            title="Reviews"
-            value={stats.totalReviews.toLocaleString()}
-            icon={ChatBubbleLeftRightIcon}
-            color="bg-orange-500"
-            link="/admin/reviews"
+            title="GMV (30d)"
+            value={kpis ? `₹${kpis.gmv.toLocaleString()}` : '–'}
+            icon={CurrencyRupeeIcon}
+            color="bg-emerald-500"
           />
         </div>
 
         {/* Secondary Stats */}
         <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-4 gap-6 mb-8">
           <StatCard
-            title="Today's Orders"
-            value={stats.todayOrders}
+            title="Signups (30d)"
+            value={count(kpis?.signups)}
             icon={ArrowTrendingUpIcon}
             color="bg-indigo-500"
           />
           <StatCard
-            title="Today's Revenue"
-            value={`₹${stats.revenue.toLocaleString()}`}
-            icon={CurrencyRupeeIcon}
-            color="bg-emerald-500"
+            title="Blocked Users"
+            value={count(kpis?.blocked_users)}
+            icon={NoSymbolIcon}
+            color="bg-gray-500"
+            link="/admin/users?filter=blocked"
           />
           <StatCard
             title="Pending Verifications"
-            value={stats.pendingVerifications}
+            value={count(kpis?.pending_applications)}
             icon={CheckBadgeIcon}
             color="bg-amber-500"
             link="/admin/providers?filter=pending"
           />
           <StatCard
             title="Reported Reviews"
-            value={stats.reportedReviews}
+            value={count(kpis?.flagged_reviews)}
             icon={ExclamationTriangleIcon}
             color="bg-red-500"
             link="/admin/reviews?filter=reported"
@@ -170,44 +165,32 @@ export default function AdminDashboard() {
           </div>
         </div>
 
-        {/* Recent Activity */}
+        {/* Active providers by city */}
         <div className="bg-white rounded-xl p-6 border">
-          <h2 className="text-xl font-bold text-gray-900 mb-4">Recent Activity</h2>
-          <div className="space-y-4">
-            <div className="flex items-center gap-4 p-3 bg-gray-50 rounded-lg">
-              <div className="p-2 bg-green-100 rounded-lg">
-                <BuildingStorefrontIcon className="w-5 h-5 text-green-600" />
-              </div>
-              <div className="flex-1">
-                <p className="text-sm font-medium text-gray-900">New provider registered</p>
-                <p className="text-xs text-gray-500">Shree Jain Bhojnalaya - 2 mins ago</p>
-              </div>
-              <span className="text-xs bg-yellow-100 text-yellow-700 px-2 py-1 rounded-full">
-                Pending Verification
-              </span>
-            </div>
-            <div className="flex items-center gap-4 p-3 bg-gray-50 rounded-lg">
-              <div className="p-2 bg-blue-100 rounded-lg">
-                <UsersIcon className="w-5 h-5 text-blue-600" />
-              </div>
-              <div className="flex-1">
-                <p className="text-sm font-medium text-gray-900">New user signup</p>
-                <p className="text-xs text-gray-500">+91 98xxx xxxxx - 5 mins ago</p>
-              </div>
+          <h2 className="text-xl font-bold text-gray-900 mb-4">Active Providers by City</h2>
+          {!kpis || kpis.active_providers_by_city.length === 0 ? (
+            <p className="text-sm text-gray-500">No orders in this period yet.</p>
+          ) : (
+            <div className="space-y-3">
+              {kpis.active_providers_by_city.map(c => (
+                <div key={c.city} className="flex items-center gap-4">
+                  <span className="w-32 text-sm font-medium text-gray-700">{c.city}</span>
+                  <div className="flex-1 h-2 bg-gray-100 rounded-full">
+                    <div
+                      className="h-2 bg-green-500 rounded-full"
+                      style={{ width: `${(c.count / kpis.active_providers) * 100}%` }}
+                    />
+                  </div>
+                  <span className="w-10 text-right text-sm text-gray-600">{c.count}</span>
+                </div>
+              ))}
             </div>
-            <div className="flex items-center gap-4 p-3 bg-gray-50 rounded-lg">
-              <div className="p-2 bg-orange-100 rounded-lg">
-                <ChatBubbleLeftRightIcon className="w-5 h-5 text-orange-600" />
-              </div>
-              <div className="flex-1">
-                <p className="text-sm font-medium text-gray-900">New review posted</p>
-                <p className="text-xs text-gray-500">Sattvic Kitchen - 10 mins ago</p>
-              </div>
-              <span className="text-xs bg-green-100 text-green-700 px-2 py-1 rounded-full">
-                ★ 5.0
-              </span>
-            </div>
-          </div>
+          )}
+          {kpis?.updated_at && (
+            <p className="text-xs text-gray-400 mt-4">
+              Orders up to {new Date(kpis.updated_at).toLocaleString()}
+            </p>
+          )}
         </div>
       </div>
     </div>
@@ -1,4 +1,5 @@
 import { useState } from 'react'
+import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query'
 import { motion } from 'framer-motion'
 import toast from 'react-hot-toast'
 import { Link, useSearchParams } from 'react-router-dom'
@@ -12,94 +13,29 @@ import {
 } from '@heroicons/react/24/outline'
 import { StarIcon as StarSolid } from '@heroicons/react/24/solid'
 import { useLanguageStore } from '../../store/languageStore'
-import type { Provider } from '../../types'
+import { adminApi } from '../../api/client'
+import { toastBulkResult } from '../../utils/bulk'
+import { PROVIDER_CATEGORIES } from '../../types'
+import type { Provider, ProviderBulkAction } from '../../types'
 
-// Mock providers data - replace with actual API
-const mockProviders: Provider[] = [
-  {
-    id: 'prov-1',
-    user_id: 'user-2',
-    business_name: 'Sattvic Kitchen',
-    address: '123 Main St, Mumbai',
-    pin_code: '400001',
-    lat: 19.0760,
-    lng: 72.8777,
-    verified: true,
-    aadhar_verified: true,
-    tags: ['sattvic', 'no-onion-garlic'],
-    provider_category: 'tiffin-center',
-    food_categories: ['tiffin-thali'],
-    rating: 4.5,
-    total_ratings: 120,
-    total_orders: 450,
-    available_today: true,
-    min_order_quantity: 1,
-    bulk_order_enabled: true,
-    free_delivery_min_price: 200,
-    free_delivery_max_km: 5,
-    is_promoted: false,
-    blocked: false,
-    created_at: '2025-10-01T10:00:00Z',
-  },
-  {
-    id: 'prov-2',
-    user_id: 'user-4',
-    business_name: 'Jain Bhojnalaya',
-    address: '456 Temple Rd, Ahmedabad',
-    pin_code: '380001',
-    lat: 23.0225,
-    lng: 72.5714,
-    verified: false,
-    aadhar_verified: true,
-    tags: ['pure-jain', 'no-root-veggies'],
-    provider_category: 'bhojnalaya',
-    food_categories: ['tiffin-thali', 'sweets'],
-    rating: 0,
-    total_ratings: 0,
-    total_orders: 0,
-    available_today: true,
-    min_order_quantity: 1,
-    bulk_order_enabled: false,
-    free_delivery_min_price: 0,
-    free_delivery_max_km: 0,
-    is_promoted: false,
-    blocked: false,
-    created_at: '2026-01-15T10:00:00Z',
-  },
-  {
-    id: 'prov-3',
-    user_id: 'user-5',
-    business_name: 'Shree Sweets',
-    address: '789 Sweet Lane, Delhi',
-    pin_code: '110001',
-    lat: 28.6139,
-    lng: 77.2090,
-    verified: true,
-    aadhar_verified: false,
-    tags: ['pure-jain'],
-    provider_category: 'baker',
-    food_categories: ['sweets', 'bakery'],
-    rating: 4.8,
-    total_ratings: 250,
-    total_orders: 890,
-    available_today: false,
-    min_order_quantity: 2,
-    bulk_order_enabled: true,
-    free_delivery_min_price: 500,
-    free_delivery_max_km: 10,
-    is_promoted: true,
-    blocked: false,
-    created_at: '2025-08-20T10:00:00Z',
-  },
-]
+const PAST_TENSE: Record<ProviderBulkAction, string> = {
+  verify: 'verified',
+  unverify: 'unverified',
+  block: 'blocked',
+  unblock: 'unblocked',
+}
 
 function ProviderRow({
   provider,
+  selected,
+  onSelect,
   onVerify,
   onBlock,
   onUnblock,
 }: {
   provider: Provider
+  selected: boolean
+  onSelect: (providerId: string) => void
   onVerify: (providerId: string) => void
   onBlock: (providerId: string, reason: string) => void
   onUnblock: (providerId: string) => void
@@ -122,6 +58,9 @@ function ProviderRow({
         animate={{ opacity: 1 }}
         className={`border-b ${provider.blocked ? 'bg-red-50' : ''}`}
       >
+        <td className="pl-6 py-4">
+          <input type="checkbox" checked={selected} onChange={() => onSelect(provider.id)} />
+        </td>
         <td className="px-6 py-4">
           <div className="flex items-center gap-3">
             <div className="w-12 h-12 rounded-xl bg-gradient-to-br from-primary-100 to-orange-100 flex items-center justify-center">
@@ -221,7 +160,7 @@ function ProviderRow({
       {/* Block Modal */}
       {showBlockModal && (
         <tr>
-          <td colSpan={7}>
+          <td colSpan={8}>
             <div className="fixed inset-0 bg-black/50 flex items-center justify-center z-50">
               <div className="bg-white rounded-xl p-6 max-w-md w-full mx-4">
                 <h3 className="text-lg font-bold text-gray-900 mb-4">Block Provider</h3>
@@ -268,41 +207,45 @@ function ProviderRow({
 
 export default function AdminProviders() {
   const [searchParams] = useSearchParams()
+  const queryClient = useQueryClient()
   const [search, setSearch] = useState('')
   const [statusFilter, setStatusFilter] = useState<string>(searchParams.get('filter') || 'all')
+  const [category, setCategory] = useState('')
+  const [selected, setSelected] = useState<string[]>([])
+  const [showBulkBlock, setShowBulkBlock] = useState(false)
+  const [bulkReason, setBulkReason] = useState('')
 
-  // In production, fetch from API
-  const providers = mockProviders
-
-  const filteredProviders = providers.filter((provider) => {
-    const matchesSearch =
-      provider.business_name.toLowerCase().includes(search.toLowerCase()) ||
-      provider.address.toLowerCase().includes(search.toLowerCase())
-    const matchesStatus =
-      statusFilter === 'all' ||
-      (statusFilter === 'pending' && !provider.verified) ||
-      (statusFilter === 'verified' && provider.verified) ||
-      (statusFilter === 'blocked' && provider.blocked)
-    return matchesSearch && matchesStatus
+  const { data, isLoading } = useQuery({
+    queryKey: ['admin-providers', search, statusFilter, category],
+    queryFn: () =>
+      adminApi.searchProviders({
+        q: search || undefined,
+        verified: statusFilter === 'pending' ? false : statusFilter === 'verified' ? true : undefined,
+        blocked: statusFilter === 'blocked' ? true : undefined,
+        category: category || undefined,
+      }),
   })
+  const filteredProviders = data?.providers || []
 
-  const handleVerify = (providerId: string) => {
-    // In production, call API
-    toast.success('Provider verified successfully')
-    console.log('Verifying provider', providerId)
-  }
+  const actionMutation = useMutation({
+    mutationFn: ({ ids, action, reason }: { ids: string[]; action: ProviderBulkAction; reason?: string }) =>
+      adminApi.bulkProviders(ids, action, reason),
+    onSuccess: (result, { action }) => {
+      toastBulkResult(result, 'provider', PAST_TENSE[action])
+      setSelected([])
+      queryClient.invalidateQueries({ queryKey: ['admin-providers'] })
+    },
+    onError: () => toast.error('Action failed'),
+  })
 
-  const handleBlock = (providerId: string, reason: string) => {
-    // In production, call API
-    toast.success('Provider blocked successfully')
-    console.log('Blocking provider', providerId, 'for', reason)
-  }
+  const handleVerify = (providerId: string) => actionMutation.mutate({ ids: [providerId], action: 'verify' })
+  const handleBlock = (providerId: string, reason: string) =>
+    actionMutation.mutate({ ids: [providerId], action: 'block', reason })
+  const handleUnblock = (providerId: string) => actionMutation.mutate({ ids: [providerId], action: 'unblock' })
 
-  const handleUnblock = (providerId: string) => {
-    // In production, call API
-    toast.success('Provider unblocked successfully')
-    console.log('Unblocking provider', providerId)
-  }
+  const toggleSelected = (providerId: string) =>
+    setSelected((ids) => (ids.includes(providerId) ? ids.filter((id) => id !== providerId) : [...ids, providerId]))
+  const allSelected = filteredProviders.length > 0 && selected.length === filteredProviders.length
 
   return (
     <div className="min-h-screen bg-gray-50 p-4 md:p-8">
@@ -322,7 +265,7 @@ export default function AdminProviders() {
                 type="text"
                 value={search}
                 onChange={(e) => setSearch(e.target.value)}
-                placeholder="Search by name or address..."
+                placeholder="Search by name or owner phone..."
                 className="w-full pl-10 pr-4 py-2 border rounded-lg focus:outline-none focus:ring-2 focus:ring-primary-500"
               />
             </div>
@@ -336,14 +279,101 @@ export default function AdminProviders() {
               <option value="verified">Verified</option>
               <option value="blocked">Blocked</option>
             </select>
+            <select
+              value={category}
+              onChange={(e) => setCategory(e.target.value)}
+              className="px-4 py-2 border rounded-lg focus:outline-none focus:ring-2 focus:ring-primary-500"
+            >
+              <option value="">All Categories</option>
+              {Object.entries(PROVIDER_CATEGORIES).map(([value, label]) => (
+                <option key={value} value={value}>
+                  {label.en}
+                </option>
+              ))}
+            </select>
           </div>
         </div>
 
+        {/* Bulk actions */}
+        {selected.length > 0 && (
+          <div className="bg-white rounded-xl p-4 border mb-6 flex flex-wrap items-center gap-3">
+            <span className="text-sm text-gray-600">{selected.length} selected</span>
+            <button
+              onClick={() => actionMutation.mutate({ ids: selected, action: 'verify' })}
+              className="px-3 py-1.5 text-sm bg-green-500 text-white rounded-lg hover:bg-green-600"
+            >
+              Verify
+            </button>
+            <button
+              onClick={() => actionMutation.mutate({ ids: selected, action: 'unverify' })}
+              className="px-3 py-1.5 text-sm border border-gray-300 text-gray-700 rounded-lg hover:bg-gray-50"
+            >
+              Unverify
+            </button>
+            <button
+              onClick={() => actionMutation.mutate({ ids: selected, action: 'unblock' })}
+              className="px-3 py-1.5 text-sm border border-gray-300 text-gray-700 rounded-lg hover:bg-gray-50"
+            >
+              Unblock
+            </button>
+            <button
+              onClick={() => setShowBulkBlock(true)}
+              className="px-3 py-1.5 text-sm bg-red-500 text-white rounded-lg hover:bg-red-600"
+            >
+              Block
+            </button>
+          </div>
+        )}
+
+        {showBulkBlock && (
+          <div className="fixed inset-0 bg-black/50 flex items-center justify-center z-50">
+            <div className="bg-white rounded-xl p-6 max-w-md w-full mx-4">
+              <h3 className="text-lg font-bold text-gray-900 mb-4">Block {selected.length} Providers</h3>
+              <textarea
+                value={bulkReason}
+                onChange={(e) => setBulkReason(e.target.value)}
+                placeholder="Reason for blocking (required)"
+                className="w-full px-4 py-3 border rounded-xl focus:outline-none focus:ring-2 focus:ring-primary-500 resize-none"
+                rows={3}
+              />
+              <div className="flex gap-3 mt-4">
+                <button
+                  onClick={() => setShowBulkBlock(false)}
+                  className="flex-1 px-4 py-2 border border-gray-300 text-gray-700 rounded-lg hover:bg-gray-50"
+                >
+                  Cancel
+                </button>
+                <button
+                  onClick={() => {
+                    if (!bulkReason.trim()) {
+                      toast.error('Please provide a reason for blocking')
+                      return
+                    }
+                    actionMutation.mutate({ ids: selected, action: 'block', reason: bulkReason })
+                    setShowBulkBlock(false)
+                    setBulkReason('')
+                  }}
+                  className="flex-1 px-4 py-2 bg-red-500 text-white rounded-lg hover:bg-red-600"
+                >
+                  Block Providers
+                </button>
+              </div>
+            </div>
+          </div>
+        )}
+
         {/* Providers Table */}
         <div className="bg-white rounded-xl border overflow-hidden overflow-x-auto">
           <table className="w-full min-w-[800px]">
             <thead className="bg-gray-50">
               <tr>
+                <th className="pl-6 py-3">
+                  <input
+                    type="checkbox"
+                    checked={allSelected}
+                    onChange={() => setSelected(allSelected ? [] : filteredProviders.map((p) => p.id))}
+                  />
+                </th>
                 <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
                   Provider
                 </th>
@@ -372,6 +402,8 @@ export default function AdminProviders() {
                 <ProviderRow
                   key={provider.id}
                   provider={provider}
+                  selected={selected.includes(provider.id)}
+                  onSelect={toggleSelected}
                   onVerify={handleVerify}
                   onBlock={handleBlock}
                   onUnblock={handleUnblock}
@@ -380,7 +412,7 @@ export default function AdminProviders() {
             </tbody>
           </table>
 
-          {filteredProviders.length === 0 && (
+          {!isLoading && filteredProviders.length === 0 && (
             <div className="p-8 text-center text-gray-500">
               <BuildingStorefrontIcon className="w-12 h-12 mx-auto mb-4 text-gray-300" />
               <p>No providers found matching your criteria</p>
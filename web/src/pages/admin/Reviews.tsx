@@ -1,92 +1,40 @@
 import { useState } from 'react'
+import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query'
 import { motion } from 'framer-motion'
 import toast from 'react-hot-toast'
-import { useSearchParams } from 'react-router-dom'
 import {
   MagnifyingGlassIcon,
   ChatBubbleLeftRightIcon,
   TrashIcon,
   FlagIcon,
   EyeIcon,
+  EyeSlashIcon,
   UserIcon,
 } from '@heroicons/react/24/outline'
 import { StarIcon as StarSolid } from '@heroicons/react/24/solid'
 import { useLanguageStore } from '../../store/languageStore'
-import type { Review } from '../../types'
+import { adminApi } from '../../api/client'
+import { toastBulkResult } from '../../utils/bulk'
+import type { Review, ReviewModerationAction, ReviewModerationState } from '../../types'
 
-// Mock reviews data - replace with actual API
-const mockReviews: (Review & { provider_name?: string; reported?: boolean })[] = [
-  {
-    id: 'rev-1',
-    provider_id: 'prov-1',
-    user_id: 'user-1',
-    rating: 5,
-    comment: 'Amazing authentic Jain food! The thali was delicious and perfectly sattvic.',
-    photo_urls: [],
-    created_at: new Date(Date.now() - 2 * 24 * 60 * 60 * 1000).toISOString(),
-    user_name: 'Rahul S.',
-    provider_name: 'Sattvic Kitchen',
-    reported: false,
-  },
-  {
-    id: 'rev-2',
-    provider_id: 'prov-1',
-    user_id: 'user-2',
-    rating: 4,
-    comment: 'Good quality food. Delivery was on time. Will order again!',
-    photo_urls: [],
-    created_at: new Date(Date.now() - 5 * 24 * 60 * 60 * 1000).toISOString(),
-    user_name: 'Priya M.',
-    provider_name: 'Sattvic Kitchen',
-    reported: false,
-  },
-  {
-    id: 'rev-3',
-    provider_id: 'prov-2',
-    user_id: 'user-3',
-    rating: 1,
-    comment: 'Very poor experience! Food was cold and taste was bad.',
-    photo_urls: [],
-    created_at: new Date(Date.now() - 7 * 24 * 60 * 60 * 1000).toISOString(),
-    user_name: 'Amit K.',
-    provider_name: 'Jain Bhojnalaya',
-    reported: true,
-  },
-  {
-    id: 'rev-4',
-    provider_id: 'prov-3',
-    user_id: 'user-4',
-    rating: 5,
-    comment: 'Best sweets in town! Pure Jain preparation with excellent taste.',
-    photo_urls: [],
-    created_at: new Date(Date.now() - 10 * 24 * 60 * 60 * 1000).toISOString(),
-    user_name: 'Sneha J.',
-    provider_name: 'Shree Sweets',
-    reported: false,
-  },
-  {
-    id: 'rev-5',
-    provider_id: 'prov-1',
-    user_id: 'user-5',
-    rating: 2,
-    comment: 'This restaurant is fraud! They use onion in cooking!!!',
-    photo_urls: [],
-    created_at: new Date(Date.now() - 1 * 24 * 60 * 60 * 1000).toISOString(),
-    user_name: 'Anonymous',
-    provider_name: 'Sattvic Kitchen',
-    reported: true,
-  },
-]
+const PAST_TENSE: Record<ReviewModerationAction, string> = {
+  approve: 'approved',
+  hide: 'hidden',
+  delete: 'deleted',
+}
 
 function ReviewCard({
   review,
-  onDelete,
-  onDismissReport,
+  selected,
+  onSelect,
+  onModerate,
 }: {
-  review: Review & { provider_name?: string; reported?: boolean }
-  onDelete: (reviewId: string) => void
-  onDismissReport: (reviewId: string) => void
+  review: Review
+  selected: boolean
+  onSelect: (reviewId: string) => void
+  onModerate: (reviewId: string, action: ReviewModerationAction) => void
 }) {
+  const reported = review.moderation_state === 'flagged'
   const [showDeleteModal, setShowDeleteModal] = useState(false)
 
   const formatDate = (dateString: string) => {
@@ -102,10 +50,11 @@ function ReviewCard({
       <motion.div
         initial={{ opacity: 0, y: 10 }}
         animate={{ opacity: 1, y: 0 }}
-        className={`bg-white rounded-xl p-4 border ${review.reported ? 'border-red-300 bg-red-50' : ''}`}
+        className={`bg-white rounded-xl p-4 border ${reported ? 'border-red-300 bg-red-50' : ''}`}
       >
         <div className="flex items-start justify-between mb-3">
           <div className="flex items-center gap-3">
+            <input type="checkbox" checked={selected} onChange={() => onSelect(review.id)} />
             <div className="w-10 h-10 rounded-full bg-primary-100 flex items-center justify-center">
               <UserIcon className="w-5 h-5 text-primary-600" />
             </div>
@@ -115,10 +64,10 @@ function ReviewCard({
             </div>
           </div>
           <div className="flex items-center gap-2">
-            {review.reported && (
+            {(review.report_count || 0) > 0 && (
               <span className="flex items-center gap-1 text-red-600 text-xs bg-red-100 px-2 py-1 rounded-full">
                 <FlagIcon className="w-3 h-3" />
-                Reported
+                Reported {review.report_count}×
               </span>
             )}
             <div className="flex items-center gap-1 bg-green-100 text-green-700 px-2 py-1 rounded-lg">
@@ -133,6 +82,13 @@ function ReviewCard({
             {review.provider_name}
           </p>
           <p className="text-gray-700">{review.comment}</p>
+          {review.report_reasons && review.report_reasons.length > 0 && (
+            <ul className="mt-2 text-sm text-red-700 list-disc list-inside">
+              {review.report_reasons.map((reason, idx) => (
+                <li key={idx}>{reason}</li>
+              ))}
+            </ul>
+          )}
         </div>
 
         {review.photo_urls && review.photo_urls.length > 0 && (
@@ -149,13 +105,22 @@ function ReviewCard({
         )}
 
         <div className="flex items-center justify-end gap-2 pt-3 border-t">
-          {review.reported && (
+          {review.moderation_state !== 'approved' && (
             <button
-              onClick={() => onDismissReport(review.id)}
+              onClick={() => onModerate(review.id, 'approve')}
               className="flex items-center gap-1 px-3 py-1.5 text-sm text-green-600 hover:bg-green-50 rounded-lg"
             >
               <EyeIcon className="w-4 h-4" />
-              Dismiss Report
+              {review.moderation_state === 'hidden' ? 'Restore' : 'Keep'}
+            </button>
+          )}
+          {review.moderation_state !== 'hidden' && (
+            <button
+              onClick={() => onModerate(review.id, 'hide')}
+              className="flex items-center gap-1 px-3 py-1.5 text-sm text-amber-600 hover:bg-amber-50 rounded-lg"
+            >
+              <EyeSlashIcon className="w-4 h-4" />
+              Hide
             </button>
           )}
           <button
@@ -186,7 +151,7 @@ function ReviewCard({
               </button>
               <button
                 onClick={() => {
-                  onDelete(review.id)
+                  onModerate(review.id, 'delete')
                   setShowDeleteModal(false)
                 }}
                 className="flex-1 px-4 py-2 bg-red-500 text-white rounded-lg hover:bg-red-600"
@@ -202,40 +167,48 @@ function ReviewCard({
 }
 
 export default function AdminReviews() {
-  const [searchParams] = useSearchParams()
+  const queryClient = useQueryClient()
   const [search, setSearch] = useState('')
-  const [filter, setFilter] = useState<string>(searchParams.get('filter') || 'all')
+  // Reported reviews first; the dashboard links here with ?filter=reported
+  const [filter, setFilter] = useState<ReviewModerationState>('flagged')
   const [ratingFilter, setRatingFilter] = useState<string>('all')
+  const [selected, setSelected] = useState<string[]>([])
 
-  // In production, fetch from API
-  const reviews = mockReviews
+  const { data, isLoading } = useQuery({
+    queryKey: ['admin-reviews', filter],
+    queryFn: () => adminApi.getReviews(filter),
+  })
+  const reviews = data?.reviews || []
 
   const filteredReviews = reviews.filter((review) => {
     const matchesSearch =
       review.comment?.toLowerCase().includes(search.toLowerCase()) ||
       review.user_name?.toLowerCase().includes(search.toLowerCase()) ||
       review.provider_name?.toLowerCase().includes(search.toLowerCase())
-    const matchesFilter =
-      filter === 'all' ||
-      (filter === 'reported' && review.reported)
     const matchesRating =
       ratingFilter === 'all' || review.rating === parseInt(ratingFilter)
-    return matchesSearch && matchesFilter && matchesRating
+    return matchesSearch && matchesRating
   })
 
-  const handleDelete = (reviewId: string) => {
-    // In production, call API
-    toast.success('Review deleted successfully')
-    console.log('Deleting review', reviewId)
-  }
+  const moderateMutation = useMutation({
+    mutationFn: ({ ids, action }: { ids: string[]; action: ReviewModerationAction }) =>
+      adminApi.bulkReviews(ids, action),
+    onSuccess: (result, { action }) => {
+      toastBulkResult(result, 'review', PAST_TENSE[action])
+      setSelected([])
+      queryClient.invalidateQueries({ queryKey: ['admin-reviews'] })
+      queryClient.invalidateQueries({ queryKey: ['admin-kpis'] })
+    },
+    onError: () => toast.error('Moderation failed'),
+  })
 
-  const handleDismissReport = (reviewId: string) => {
-    // In production, call API
-    toast.success('Report dismissed')
-    console.log('Dismissing report for review', reviewId)
-  }
+  const handleModerate = (reviewId: string, action: ReviewModerationAction) =>
+    moderateMutation.mutate({ ids: [reviewId], action })
+
+  const toggleSelected = (reviewId: string) =>
+    setSelected((ids) => (ids.includes(reviewId) ? ids.filter((id) => id !== reviewId) : [...ids, reviewId]))
 
-  const reportedCount = reviews.filter(r => r.reported).length
+  const reportedCount = filter === 'flagged' ? data?.total || 0 : 0
 
   return (
     <div className="min-h-screen bg-gray-50 p-4 md:p-8">
@@ -268,11 +241,16 @@ export default function AdminReviews() {
             </div>
             <select
               value={filter}
-              onChange={(e) => setFilter(e.target.value)}
+              onChange={(e) => {
+                setFilter(e.target.value as ReviewModerationState)
+                setSelected([])
+              }}
               className="px-4 py-2 border rounded-lg focus:outline-none focus:ring-2 focus:ring-primary-500"
             >
-              <option value="all">All Reviews</option>
-              <option value="reported">Reported Only</option>
+              <option value="flagged">Reported</option>
+              <option value="approved">Kept</option>
+              <option value="hidden">Hidden</option>
+              <option value="published">Published</option>
             </select>
             <select
               value={ratingFilter}
@@ -289,19 +267,45 @@ export default function AdminReviews() {
           </div>
         </div>
 
+        {/* Bulk actions */}
+        {selected.length > 0 && (
+          <div className="bg-white rounded-xl p-4 border mb-6 flex flex-wrap items-center gap-3">
+            <span className="text-sm text-gray-600">{selected.length} selected</span>
+            <button
+              onClick={() => moderateMutation.mutate({ ids: selected, action: 'approve' })}
+              className="px-3 py-1.5 text-sm bg-green-500 text-white rounded-lg hover:bg-green-600"
+            >
+              Keep
+            </button>
+            <button
+              onClick={() => moderateMutation.mutate({ ids: selected, action: 'hide' })}
+              className="px-3 py-1.5 text-sm bg-amber-500 text-white rounded-lg hover:bg-amber-600"
+            >
+              Hide
+            </button>
+            <button
+              onClick={() => moderateMutation.mutate({ ids: selected, action: 'delete' })}
+              className="px-3 py-1.5 text-sm bg-red-500 text-white rounded-lg hover:bg-red-600"
+            >
+              Delete
+            </button>
+          </div>
+        )}
+
         {/* Reviews List */}
         <div className="space-y-4">
           {filteredReviews.map((review) => (
             <ReviewCard
               key={review.id}
               review={review}
-              onDelete={handleDelete}
-              onDismissReport={handleDismissReport}
+              selected={selected.includes(review.id)}
+              onSelect={toggleSelected}
+              onModerate={handleModerate}
             />
           ))}
         </div>
 
-        {filteredReviews.length === 0 && (
+        {!isLoading && filteredReviews.length === 0 && (
           <div className="bg-white rounded-xl p-8 border text-center text-gray-500">
             <ChatBubbleLeftRightIcon className="w-12 h-12 mx-auto mb-4 text-gray-300" />
             <p>No reviews found matching your criteria</p>
@@ -1,6 +1,8 @@
 import { useState } from 'react'
+import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query'
 import { motion } from 'framer-motion'
 import toast from 'react-hot-toast'
+import { useSearchParams } from 'react-router-dom'
 import {
   MagnifyingGlassIcon,
   UserIcon,
@@ -10,52 +12,20 @@ import {
   XCircleIcon,
 } from '@heroicons/react/24/outline'
 import { useLanguageStore } from '../../store/languageStore'
-import type { User } from '../../types'
-
-// Mock users data - replace with actual API
-const mockUsers: User[] = [
-  {
-    id: 'user-1',
-    phone: '+919876543210',
-    name: 'Rahul Sharma',
-    email: 'rahul@example.com',
-    role: 'buyer',
-    preferences: null,
-    language: 'en',
-    blocked: false,
-    created_at: '2025-12-01T10:00:00Z',
-  },
-  {
-    id: 'user-2',
-    phone: '+919876543211',
-    name: 'Priya Jain',
-    email: 'priya@example.com',
-    role: 'provider',
-    preferences: null,
-    language: 'hi',
-    blocked: false,
-    created_at: '2025-12-05T10:00:00Z',
-  },
-  {
-    id: 'user-3',
-    phone: '+919876543212',
-    name: 'Amit Kumar',
-    email: 'amit@example.com',
-    role: 'buyer',
-    preferences: null,
-    language: 'en',
-    blocked: true,
-    blocked_reason: 'Spam reports',
-    created_at: '2025-11-15T10:00:00Z',
-  },
-]
+import { adminApi } from '../../api/client'
+import { toastBulkResult } from '../../utils/bulk'
+import type { User, UserBulkAction } from '../../types'
 
 function UserRow({
   user,
+  selected,
+  onSelect,
   onBlock,
   onUnblock,
 }: {
   user: User
+  selected: boolean
+  onSelect: (userId: string) => void
   onBlock: (userId: string, reason: string) => void
   onUnblock: (userId: string) => void
 }) {
@@ -77,6 +47,9 @@ function UserRow({
         animate={{ opacity: 1 }}
         className={`border-b ${user.blocked ? 'bg-red-50' : ''}`}
       >
+        <td className="pl-6 py-4">
+          <input type="checkbox" checked={selected} onChange={() => onSelect(user.id)} />
+        </td>
         <td className="px-6 py-4">
           <div className="flex items-center gap-3">
             <div className="w-10 h-10 rounded-full bg-primary-100 flex items-center justify-center">
@@ -151,7 +124,7 @@ function UserRow({
       {/* Block Modal */}
       {showBlockModal && (
         <tr>
-          <td colSpan={7}>
+          <td colSpan={8}>
             <div className="fixed inset-0 bg-black/50 flex items-center justify-center z-50">
               <div className="bg-white rounded-xl p-6 max-w-md w-full mx-4">
                 <h3 className="text-lg font-bold text-gray-900 mb-4">Block User</h3>
@@ -197,32 +170,44 @@ function UserRow({
 }
 
 export default function AdminUsers() {
+  const [searchParams] = useSearchParams()
+  const queryClient = useQueryClient()
   const [search, setSearch] = useState('')
   const [roleFilter, setRoleFilter] = useState<string>('all')
+  const [blockedOnly, setBlockedOnly] = useState(searchParams.get('filter') === 'blocked')
+  const [selected, setSelected] = useState<string[]>([])
+  const [showBulkBlock, setShowBulkBlock] = useState(false)
+  const [bulkReason, setBulkReason] = useState('')
 
-  // In production, fetch from API
-  const users = mockUsers
+  const { data, isLoading } = useQuery({
+    queryKey: ['admin-users', search, roleFilter, blockedOnly],
+    queryFn: () =>
+      adminApi.searchUsers({
+        q: search || undefined,
+        role: roleFilter === 'all' ? undefined : (roleFilter as User['role']),
+        blocked: blockedOnly ? true : undefined,
+      }),
+  })
+  const filteredUsers = data?.users || []
 
-  const filteredUsers = users.filter((user) => {
-    const matchesSearch =
-      user.name?.toLowerCase().includes(search.toLowerCase()) ||
-      user.phone.includes(search) ||
-      user.email?.toLowerCase().includes(search.toLowerCase())
-    const matchesRole = roleFilter === 'all' || user.role === roleFilter
-    return matchesSearch && matchesRole
+  const actionMutation = useMutation({
+    mutationFn: ({ ids, action, reason }: { ids: string[]; action: UserBulkAction; reason?: string }) =>
+      adminApi.bulkUsers(ids, action, reason),
+    onSuccess: (result, { action }) => {
+      toastBulkResult(result, 'user', action === 'block' ? 'blocked' : 'unblocked')
+      setSelected([])
+      queryClient.invalidateQueries({ queryKey: ['admin-users'] })
+    },
+    onError: () => toast.error('Action failed'),
   })
 
-  const handleBlock = (userId: string, reason: string) => {
-    // In production, call API
-    toast.success('User blocked successfully')
-    console.log('Blocking user', userId, 'for', reason)
-  }
+  const handleBlock = (userId: string, reason: string) =>
+    actionMutation.mutate({ ids: [userId], action: 'block', reason })
+  const handleUnblock = (userId: string) => actionMutation.mutate({ ids: [userId], action: 'unblock' })
 
-  const handleUnblock = (userId: string) => {
-    // In production, call API
-    toast.success('User unblocked successfully')
-    console.log('Unblocking user', userId)
-  }
+  const toggleSelected = (userId: string) =>
+    setSelected((ids) => (ids.includes(userId) ? ids.filter((id) => id !== userId) : [...ids, userId]))
+  const allSelected = filteredUsers.length > 0 && selected.length === filteredUsers.length
 
   return (
     <div className="min-h-screen bg-gray-50 p-4 md:p-8">
@@ -242,7 +227,7 @@ export default function AdminUsers() {
                 type="text"
                 value={search}
                 onChange={(e) => setSearch(e.target.value)}
-                placeholder="Search by name, phone, or email..."
+                placeholder="Search by name or phone..."
                 className="w-full pl-10 pr-4 py-2 border rounded-lg focus:outline-none focus:ring-2 focus:ring-primary-500"
               />
             </div>
@@ -256,14 +241,81 @@ export default function AdminUsers() {
               <option value="provider">Providers</option>
               <option value="admin">Admins</option>
             </select>
+            <label className="flex items-center gap-2 text-sm text-gray-700">
+              <input type="checkbox" checked={blockedOnly} onChange={(e) => setBlockedOnly(e.target.checked)} />
+              Blocked only
+            </label>
           </div>
         </div>
 
+        {/* Bulk actions */}
+        {selected.length > 0 && (
+          <div className="bg-white rounded-xl p-4 border mb-6 flex flex-wrap items-center gap-3">
+            <span className="text-sm text-gray-600">{selected.length} selected</span>
+            <button
+              onClick={() => actionMutation.mutate({ ids: selected, action: 'unblock' })}
+              className="px-3 py-1.5 text-sm border border-gray-300 text-gray-700 rounded-lg hover:bg-gray-50"
+            >
+              Unblock
+            </button>
+            <button
+              onClick={() => setShowBulkBlock(true)}
+              className="px-3 py-1.5 text-sm bg-red-500 text-white rounded-lg hover:bg-red-600"
+            >
+              Block
+            </button>
+          </div>
+        )}
+
+        {showBulkBlock && (
+          <div className="fixed inset-0 bg-black/50 flex items-center justify-center z-50">
+            <div className="bg-white rounded-xl p-6 max-w-md w-full mx-4">
+              <h3 className="text-lg font-bold text-gray-900 mb-4">Block {selected.length} Users</h3>
+              <textarea
+                value={bulkReason}
+                onChange={(e) => setBulkReason(e.target.value)}
+                placeholder="Reason for blocking (required)"
+                className="w-full px-4 py-3 border rounded-xl focus:outline-none focus:ring-2 focus:ring-primary-500 resize-none"
+                rows={3}
+              />
+              <div className="flex gap-3 mt-4">
+                <button
+                  onClick={() => setShowBulkBlock(false)}
+                  className="flex-1 px-4 py-2 border border-gray-300 text-gray-700 rounded-lg hover:bg-gray-50"
+                >
+                  Cancel
+                </button>
+                <button
+                  onClick={() => {
+                    if (!bulkReason.trim()) {
+                      toast.error('Please provide a reason for blocking')
+                      return
+                    }
+                    actionMutation.mutate({ ids: selected, action: 'block', reason: bulkReason })
+                    setShowBulkBlock(false)
+                    setBulkReason('')
+                  }}
+                  className="flex-1 px-4 py-2 bg-red-500 text-white rounded-lg hover:bg-red-600"
+                >
+                  Block Users
+                </button>
+              </div>
+            </div>
+          </div>
+        )}
+
         {/* Users Table */}
         <div className="bg-white rounded-xl border overflow-hidden">
           <table className="w-full">
             <thead className="bg-gray-50">
               <tr>
+                <th className="pl-6 py-3">
+                  <input
+                    type="checkbox"
+                    checked={allSelected}
+                    onChange={() => setSelected(allSelected ? [] : filteredUsers.map((u) => u.id))}
+                  />
+                </th>
                 <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
                   User
                 </th>
@@ -292,6 +344,8 @@ export default function AdminUsers() {
                 <UserRow
                   key={user.id}
                   user={user}
+                  selected={selected.includes(user.id)}
+                  onSelect={toggleSelected}
                   onBlock={handleBlock}
                   onUnblock={handleUnblock}
                 />
@@ -299,7 +353,7 @@ export default function AdminUsers() {
             </tbody>
           </table>
 
-          {filteredUsers.length === 0 && (
+          {!isLoading && filteredUsers.length === 0 && (
             <div className="p-8 text-center text-gray-500">
               <UserIcon className="w-12 h-12 mx-auto mb-4 text-gray-300" />
               <p>No users found matching your criteria</p>
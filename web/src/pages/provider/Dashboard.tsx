@@ -1,10 +1,26 @@
+import { useQuery } from '@tanstack/react-query'
 import { ClipboardDocumentListIcon, CurrencyRupeeIcon, StarIcon } from '@heroicons/react/24/outline'
+import { providerApi } from '../../api/client'
+
+const formatHour = (h: number) => `${h % 12 || 12}${h < 12 ? 'am' : 'pm'}`
 
 export default function ProviderDashboard() {
+  const { data: provider } = useQuery({
+    queryKey: ['my-provider-profile'],
+    queryFn: () => providerApi.getMe(),
+  })
+
+  // Last 30 days
+  const { data: analytics } = useQuery({
+    queryKey: ['provider-analytics', provider?.id],
+    queryFn: () => providerApi.getAnalytics(provider!.id),
+    enabled: !!provider?.id,
+  })
+
   const stats = [
-    { label: 'Total Orders', value: '0', icon: ClipboardDocumentListIcon, color: 'bg-blue-500' },
-    { label: 'Revenue', value: '₹0', icon: CurrencyRupeeIcon, color: 'bg-green-500' },
-    { label: 'Rating', value: '4.5', icon: StarIcon, color: 'bg-yellow-500' },
+    { label: 'Orders (30 days)', value: String(analytics?.orders ?? 0), icon: ClipboardDocumentListIcon, color: 'bg-blue-500' },
+    { label: 'Revenue (30 days)', value: `₹${Math.round(analytics?.revenue ?? 0).toLocaleString('en-IN')}`, icon: CurrencyRupeeIcon, color: 'bg-green-500' },
+    { label: 'Rating', value: provider?.total_ratings ? provider.rating.toFixed(1) : '–', icon: StarIcon, color: 'bg-yellow-500' },
   ]
 
   return (
@@ -28,6 +44,41 @@ export default function ProviderDashboard() {
         ))}
       </div>
 
+      {analytics && analytics.orders > 0 && (
+        <div className="grid grid-cols-1 sm:grid-cols-2 gap-4 mb-6">
+          <div className="bg-white rounded-xl p-6">
+            <h2 className="font-semibold text-gray-900 mb-4">Top Items</h2>
+            <ul className="space-y-2">
+              {analytics.top_items.slice(0, 5).map((item) => (
+                <li key={item.item_id} className="flex justify-between text-sm">
+                  <span className="text-gray-700">{item.name || item.item_id}</span>
+                  <span className="text-gray-500">{item.quantity} sold</span>
+                </li>
+              ))}
+            </ul>
+          </div>
+          <div className="bg-white rounded-xl p-6">
+            <h2 className="font-semibold text-gray-900 mb-4">Customers</h2>
+            <dl className="space-y-2 text-sm">
+              <div className="flex justify-between">
+                <dt className="text-gray-600">Average order</dt>
+                <dd className="text-gray-900">₹{Math.round(analytics.average_order_value)}</dd>
+              </div>
+              <div className="flex justify-between">
+                <dt className="text-gray-600">Repeat customers</dt>
+                <dd className="text-gray-900">{Math.round(analytics.repeat_customer_rate * 100)}%</dd>
+              </div>
+              {analytics.peak_hours.length > 0 && (
+                <div className="flex justify-between">
+                  <dt className="text-gray-600">Busiest hour</dt>
+                  <dd className="text-gray-900">{formatHour(analytics.peak_hours[0].hour)}</dd>
+                </div>
+              )}
+            </dl>
+          </div>
+        </div>
+      )}
+
       {/* Quick Actions */}
       <div className="bg-white rounded-xl p-6 mb-6">
         <h2 className="font-semibold text-gray-900 mb-4">Quick Actions</h2>
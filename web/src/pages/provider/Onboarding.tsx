@@ -3,7 +3,7 @@ import { useNavigate } from 'react-router-dom'
 import { motion } from 'framer-motion'
 import toast from 'react-hot-toast'
 import { MapPinIcon, CheckCircleIcon } from '@heroicons/react/24/outline'
-import { providerApi } from '../../api/client'
+import { providerApi, mediaApi } from '../../api/client'
 import { useLocationStore } from '../../store/locationStore'
 import { useLanguageStore } from '../../store/languageStore'
 import TermsModal from '../../components/TermsModal'
@@ -15,8 +15,34 @@ import {
   type ProviderCategory,
   type FoodCategory,
   type ExternalPlatform,
+  type BusinessType,
+  type IDProofType,
+  type DocumentKind,
 } from '../../types'
 
+const BUSINESS_TYPES: Record<BusinessType, { en: string; hi: string }> = {
+  individual: { en: 'Individual / Home Chef', hi: 'व्यक्तिगत / होम शेफ' },
+  proprietorship: { en: 'Proprietorship', hi: 'स्वामित्व' },
+  partnership: { en: 'Partnership', hi: 'साझेदारी' },
+  company: { en: 'Company', hi: 'कंपनी' },
+}
+
+const ID_PROOF_TYPES: Record<IDProofType, string> = {
+  aadhaar: 'Aadhaar',
+  pan: 'PAN',
+  passport: 'Passport',
+  voter_id: 'Voter ID',
+  driving_licence: 'Driving Licence',
+}
+
+const MIN_KITCHEN_PHOTOS = 2
+
+interface UploadedDoc {
+  kind: DocumentKind
+  object_key: string
+  name: string
+}
+
 export default function ProviderOnboarding() {
   const navigate = useNavigate()
   const { lat, lng, getCurrentLocation } = useLocationStore()
@@ -36,6 +62,11 @@ export default function ProviderOnboarding() {
     tags: [] as string[],
     provider_category: '' as ProviderCategory | '',
     food_categories: [] as FoodCategory[],
+    legal_name: '',
+    business_type: '' as BusinessType | '',
+    fssai_number: '',
+    fssai_expires_on: '',
+    id_proof_type: '' as IDProofType | '',
     aadhar_number: '',
     external_platforms: [] as ExternalPlatform[],
     external_app_link: '',
@@ -72,6 +103,45 @@ export default function ProviderOnboarding() {
     }))
   }
 
+  const [documents, setDocuments] = useState<UploadedDoc[]>([])
+  const [uploading, setUploading] = useState<DocumentKind | null>(null)
+
+  // Documents go straight to storage; they're attached to the application on submit
+  const handleUpload = async (kind: DocumentKind, files: FileList | null) => {
+    if (!files || files.length === 0) return
+    setUploading(kind)
+    try {
+      const uploaded: UploadedDoc[] = []
+      for (const file of Array.from(files)) {
+        const { upload_url, object_key } = await mediaApi.getUploadUrl('provider-docs', file.type, file.name)
+        const res = await fetch(upload_url, { method: 'PUT', body: file, headers: { 'Content-Type': file.type } })
+        if (!res.ok) throw new Error(`upload failed: ${res.status}`)
+        uploaded.push({ kind, object_key, name: file.name })
+      }
+      setDocuments((prev) => [...prev, ...uploaded])
+    } catch {
+      toast.error(language === 'hi' ? 'अपलोड विफल। कृपया पुनः प्रयास करें।' : 'Upload failed. Please try again.')
+    } finally {
+      setUploading(null)
+    }
+  }
+
+  const removeDocument = (objectKey: string) => {
+    setDocuments((prev) => prev.filter((d) => d.object_key !== objectKey))
+  }
+
+  const docsOf = (kind: DocumentKind) => documents.filter((d) => d.kind === kind)
+
+  const verificationComplete =
+    !!formData.legal_name &&
+    !!formData.business_type &&
+    /^[12]\d{13}$/.test(formData.fssai_number) &&
+    !!formData.id_proof_type &&
+    (formData.id_proof_type !== 'aadhaar' || formData.aadhar_number.replace(/\D/g, '').length === 12) &&
+    docsOf('fssai_licence').length > 0 &&
+    docsOf('id_proof').length > 0 &&
+    docsOf('kitchen_photo').length >= MIN_KITCHEN_PHOTOS
+
   const handleTermsAccept = () => {
     setTermsAccepted(true)
     setShowTerms(false)
@@ -103,7 +173,34 @@ export default function ProviderOnboarding() {
         lng: formData.lng || lng || 72.8777,
         tags: formData.tags,
       })
-      toast.success(language === 'hi' ? 'प्रदाता सफलतापूर्वक पंजीकृत!' : 'Provider registered successfully!')
+      await providerApi.updateMe({
+        pin_code: formData.pin_code,
+        provider_category: formData.provider_category || null,
+        food_categories: formData.food_categories,
+        external_platforms: formData.external_platforms,
+        external_app_link: formData.external_app_link || null,
+        min_order_quantity: formData.min_order_quantity,
+        bulk_order_enabled: formData.bulk_order_enabled,
+        free_delivery_min_price: formData.free_delivery_min_price,
+        free_delivery_max_km: formData.free_delivery_max_km,
+      })
+      await providerApi.saveApplication({
+        legal_name: formData.legal_name,
+        business_type: formData.business_type,
+        fssai_number: formData.fssai_number,
+        fssai_expires_on: formData.fssai_expires_on || undefined,
+        id_proof_type: formData.id_proof_type,
+        aadhar_number: formData.id_proof_type === 'aadhaar' ? formData.aadhar_number : undefined,
+      })
+      for (const doc of documents) {
+        await providerApi.addDocument(doc.kind, doc.object_key)
+      }
+      await providerApi.submitApplication()
+      toast.success(
+        language === 'hi'
+          ? 'पंजीकरण हो गया! सत्यापन के लिए आवेदन भेजा गया।'
+          : 'Registered! Your application has been sent for verification.'
+      )
       navigate('/provider/dashboard')
     } catch {
       toast.error(language === 'hi' ? 'पंजीकरण विफल। कृपया पुनः प्रयास करें।' : 'Registration failed. Please try again.')
@@ -112,7 +209,7 @@ export default function ProviderOnboarding() {
     }
   }
 
-  const totalSteps = 5
+  const totalSteps = 6
 
   return (
     <div className="min-h-screen bg-gray-50 py-12 px-4">
@@ -387,8 +484,128 @@ export default function ProviderOnboarding() {
             </div>
           )}
 
-          {/* Step 5: External Platforms & Terms */}
+          {/* Step 5: Verification (FSSAI licence, ID proof, kitchen photos) */}
           {step === 5 && (
+            <div className="space-y-4">
+              <h2 className="text-lg font-semibold">{t('onboarding.verification')}</h2>
+              <p className="text-sm text-gray-600">
+                {language === 'hi'
+                  ? 'हमारी टीम आपके दस्तावेज़ों की जाँच करके आपको सत्यापित करेगी।'
+                  : 'Our team reviews these documents before your kitchen is verified.'}
+              </p>
+              <div>
+                <label className="block text-sm font-medium text-gray-700 mb-1">{t('onboarding.legalName')} *</label>
+                <input
+                  type="text"
+                  value={formData.legal_name}
+                  onChange={(e) => setFormData({ ...formData, legal_name: e.target.value })}
+                  className="w-full px-4 py-3 border rounded-xl focus:ring-2 focus:ring-primary-500"
+                />
+              </div>
+              <div>
+                <label className="block text-sm font-medium text-gray-700 mb-1">{t('onboarding.businessType')} *</label>
+                <select
+                  value={formData.business_type}
+                  onChange={(e) => setFormData({ ...formData, business_type: e.target.value as BusinessType })}
+                  className="w-full px-4 py-3 border rounded-xl"
+                >
+                  <option value="">—</option>
+                  {(Object.keys(BUSINESS_TYPES) as BusinessType[]).map((key) => (
+                    <option key={key} value={key}>{BUSINESS_TYPES[key][language]}</option>
+                  ))}
+                </select>
+              </div>
+              <div className="grid grid-cols-2 gap-4">
+                <div>
+                  <label className="block text-sm font-medium text-gray-700 mb-1">{t('onboarding.fssaiNumber')} *</label>
+                  <input
+                    type="text"
+                    value={formData.fssai_number}
+                    onChange={(e) => setFormData({ ...formData, fssai_number: e.target.value.replace(/\D/g, '').slice(0, 14) })}
+                    className="w-full px-4 py-3 border rounded-xl"
+                    placeholder="14 digits"
+                    maxLength={14}
+                  />
+                </div>
+                <div>
+                  <label className="block text-sm font-medium text-gray-700 mb-1">{t('onboarding.fssaiExpiry')}</label>
+                  <input
+                    type="date"
+                    value={formData.fssai_expires_on}
+                    onChange={(e) => setFormData({ ...formData, fssai_expires_on: e.target.value })}
+                    className="w-full px-4 py-3 border rounded-xl"
+                  />
+                </div>
+              </div>
+              <DocumentInput
+                label={t('onboarding.fssaiLicence')}
+                kind="fssai_licence"
+                docs={docsOf('fssai_licence')}
+                uploading={uploading === 'fssai_licence'}
+                onUpload={handleUpload}
+                onRemove={removeDocument}
+              />
+              <div>
+                <label className="block text-sm font-medium text-gray-700 mb-1">{t('onboarding.idProof')} *</label>
+                <select
+                  value={formData.id_proof_type}
+                  onChange={(e) => setFormData({ ...formData, id_proof_type: e.target.value as IDProofType })}
+                  className="w-full px-4 py-3 border rounded-xl"
+                >
+                  <option value="">—</option>
+                  {(Object.keys(ID_PROOF_TYPES) as IDProofType[]).map((key) => (
+                    <option key={key} value={key}>{ID_PROOF_TYPES[key]}</option>
+                  ))}
+                </select>
+              </div>
+              {formData.id_proof_type === 'aadhaar' && (
+                <div>
+                  <label className="block text-sm font-medium text-gray-700 mb-1">{t('onboarding.aadharNumber')} *</label>
+                  <input
+                    type="text"
+                    inputMode="numeric"
+                    value={formData.aadhar_number}
+                    onChange={(e) => setFormData({ ...formData, aadhar_number: e.target.value.replace(/[^\d ]/g, '').slice(0, 14) })}
+                    className="w-full px-4 py-3 border rounded-xl"
+                    placeholder="XXXX XXXX XXXX"
+                  />
+                </div>
+              )}
+              <DocumentInput
+                label={t('onboarding.idProofUpload')}
+                kind="id_proof"
+                docs={docsOf('id_proof')}
+                uploading={uploading === 'id_proof'}
+                onUpload={handleUpload}
+                onRemove={removeDocument}
+              />
+              <DocumentInput
+                label={`${t('onboarding.kitchenPhotos')} (min ${MIN_KITCHEN_PHOTOS})`}
+                kind="kitchen_photo"
+                docs={docsOf('kitchen_photo')}
+                uploading={uploading === 'kitchen_photo'}
+                onUpload={handleUpload}
+                onRemove={removeDocument}
+                multiple
+                accept="image/*"
+              />
+              <div className="flex gap-3">
+                <button onClick={() => setStep(4)} className="flex-1 py-3 border border-gray-300 rounded-xl">
+                  {t('common.back')}
+                </button>
+                <button
+                  onClick={() => setStep(6)}
+                  disabled={!verificationComplete || uploading !== null}
+                  className="flex-1 py-3 bg-primary-500 text-white font-semibold rounded-xl disabled:opacity-50"
+                >
+                  {t('common.continue')}
+                </button>
+              </div>
+            </div>
+          )}
+
+          {/* Step 6: External Platforms & Terms */}
+          {step === 6 && (
             <div className="space-y-6">
               <div>
                 <h2 className="text-lg font-semibold mb-3">{t('onboarding.externalPlatforms')}</h2>
@@ -458,7 +675,7 @@ export default function ProviderOnboarding() {
               </div>
 
               <div className="flex gap-3">
-                <button onClick={() => setStep(4)} className="flex-1 py-3 border border-gray-300 rounded-xl">
+                <button onClick={() => setStep(5)} className="flex-1 py-3 border border-gray-300 rounded-xl">
                   {t('common.back')}
                 </button>
                 <button
@@ -485,3 +702,47 @@ export default function ProviderOnboarding() {
     </div>
   )
 }
+
+function DocumentInput({
+  label,
+  kind,
+  docs,
+  uploading,
+  onUpload,
+  onRemove,
+  multiple = false,
+  accept = 'image/*,application/pdf',
+}: {
+  label: string
+  kind: DocumentKind
+  docs: UploadedDoc[]
+  uploading: boolean
+  onUpload: (kind: DocumentKind, files: FileList | null) => void
+  onRemove: (objectKey: string) => void
+  multiple?: boolean
+  accept?: string
+}) {
+  return (
+    <div>
+      <label className="block text-sm font-medium text-gray-700 mb-1">{label} *</label>
+      <input
+        type="file"
+        accept={accept}
+        multiple={multiple}
+        disabled={uploading}
+        onChange={(e) => {
+          onUpload(kind, e.target.files)
+          e.target.value = ''
+        }}
+        className="w-full text-sm"
+      />
+      {uploading && <p className="text-xs text-gray-500 mt-1">Uploading…</p>}
+      {docs.map((d) => (
+        <div key={d.object_key} className="flex items-center justify-between text-sm bg-gray-50 rounded-lg px-3 py-2 mt-2">
+          <span className="truncate">{d.name}</span>
+          <button onClick={() => onRemove(d.object_key)} className="text-red-600 ml-2">✕</button>
+        </div>
+      ))}
+    </div>
+  )
+}
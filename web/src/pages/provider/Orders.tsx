@@ -8,7 +8,7 @@ import {
   PhoneIcon,
   ChatBubbleLeftIcon,
 } from '@heroicons/react/24/outline'
-import { orderApi, providerApi } from '../../api/client'
+import { orderApi } from '../../api/client'
 import { useAuthStore } from '../../store/authStore'
 import { ORDER_STATUS_LABELS, ORDER_STATUS_COLORS } from '../../types'
 import type { Order } from '../../types'
@@ -139,21 +139,11 @@ export default function ProviderOrders() {
   const { user } = useAuthStore()
   const queryClient = useQueryClient()
 
-  // Get provider
-  const { data: provider } = useQuery({
-    queryKey: ['my-provider', user?.id],
-    queryFn: async () => {
-      const providers = await providerApi.list(100, 0)
-      return providers.find((p) => p.user_id === user?.id)
-    },
-    enabled: !!user?.id,
-  })
-
-  // Get orders
+  // Get orders for every outlet the user works at
   const { data: orders, isLoading } = useQuery({
-    queryKey: ['provider-orders', provider?.id],
-    queryFn: () => orderApi.getProviderOrders(provider!.id),
-    enabled: !!provider?.id,
+    queryKey: ['provider-orders', user?.id],
+    queryFn: async () => (await orderApi.getInbox()).orders,
+    enabled: !!user?.id,
     refetchInterval: 30000, // Refresh every 30 seconds
   })
 
@@ -11,10 +11,7 @@ export default function ProviderProfile() {
 
   const { data: provider, isLoading } = useQuery({
     queryKey: ['my-provider', user?.id],
-    queryFn: async () => {
-      const providers = await providerApi.list(100, 0)
-      return providers.find((p) => p.user_id === user?.id)
-    },
+    queryFn: () => providerApi.getMe(),
     enabled: !!user?.id,
   })
 
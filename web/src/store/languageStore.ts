@@ -197,6 +197,15 @@ const translations: Record<Language, Record<string, string>> = {
     'onboarding.freeDeliveryKm': 'Free Delivery (Max Distance in KM)',
     'onboarding.externalPlatforms': 'External Platforms',
     'onboarding.externalAppLink': 'Your App Link',
+    'onboarding.verification': 'Verification Documents',
+    'onboarding.legalName': 'Legal Name (as on licence)',
+    'onboarding.businessType': 'Business Type',
+    'onboarding.fssaiNumber': 'FSSAI Number',
+    'onboarding.fssaiExpiry': 'FSSAI Valid Until',
+    'onboarding.fssaiLicence': 'FSSAI Licence / Registration',
+    'onboarding.idProof': 'ID Proof',
+    'onboarding.idProofUpload': 'ID Proof Document',
+    'onboarding.kitchenPhotos': 'Kitchen Photos',
 
     // FAQ
     'faq.title': 'Frequently Asked Questions',
@@ -395,6 +404,15 @@ const translations: Record<Language, Record<string, string>> = {
     'onboarding.freeDeliveryKm': 'मुफ्त डिलीवरी (अधिकतम दूरी किमी में)',
     'onboarding.externalPlatforms': 'बाहरी प्लेटफॉर्म',
     'onboarding.externalAppLink': 'आपका ऐप लिंक',
+    'onboarding.verification': 'सत्यापन दस्तावेज़',
+    'onboarding.legalName': 'कानूनी नाम (लाइसेंस के अनुसार)',
+    'onboarding.businessType': 'व्यवसाय का प्रकार',
+    'onboarding.fssaiNumber': 'FSSAI नंबर',
+    'onboarding.fssaiExpiry': 'FSSAI वैधता',
+    'onboarding.fssaiLicence': 'FSSAI लाइसेंस / पंजीकरण',
+    'onboarding.idProof': 'पहचान प्रमाण',
+    'onboarding.idProofUpload': 'पहचान प्रमाण दस्तावेज़',
+    'onboarding.kitchenPhotos': 'रसोई की फ़ोटो',
 
     // FAQ
     'faq.title': 'अक्सर पूछे जाने वाले प्रश्न',
@@ -81,6 +81,7 @@ export interface User {
   language: 'en' | 'hi'
   blocked: boolean
   blocked_reason?: string
+  blocked_until?: string // Omitted: blocked indefinitely
   terms_accepted_at?: string
   created_at: string
 }
@@ -96,6 +97,7 @@ export interface UserPreferences {
 export interface Provider {
   id: string
   user_id: string
+  organisation_id?: string // Set for outlets of a multi-outlet organisation
   business_name: string
   address: string
   pin_code?: string
@@ -121,6 +123,10 @@ export interface Provider {
   blocked_reason?: string
   terms_accepted_at?: string
   created_at: string
+  // Provider detail only
+  hours?: ProviderSchedule
+  open_now?: boolean
+  next_opening?: string
   // Extended fields from search
   distance_meters?: number
   image_url?: string
@@ -128,6 +134,277 @@ export interface Provider {
   price_range?: string
 }
 
+// Fields a provider can change with PATCH (JSON merge patch: omitted fields
+// are unchanged, null resets a field to its default)
+export type ProviderProfilePatch = {
+  [K in
+    | 'business_name'
+    | 'address'
+    | 'pin_code'
+    | 'lat'
+    | 'lng'
+    | 'tags'
+    | 'provider_category'
+    | 'food_categories'
+    | 'external_platforms'
+    | 'external_app_link'
+    | 'min_order_quantity'
+    | 'bulk_order_enabled'
+    | 'free_delivery_min_price'
+    | 'free_delivery_max_km'
+    | 'available_today']?: Provider[K] | null
+}
+
+// Opening hours, in the provider's timezone. A slot closing at or before it
+// opens runs past midnight.
+export interface OpeningSlot {
+  weekday: number // 0 = Sunday
+  opens: string // HH:MM
+  closes: string
+  order_cutoff?: string
+}
+
+export interface Holiday {
+  id: string
+  from: string // YYYY-MM-DD
+  to: string
+  reason?: string
+}
+
+export interface ProviderSchedule {
+  provider_id: string
+  timezone: string
+  available_today: boolean
+  slots: OpeningSlot[]
+  holidays: Holiday[]
+}
+
+// Provider analytics (GET /providers/:id/analytics)
+export type AnalyticsGranularity = 'day' | 'week' | 'month'
+
+export interface AnalyticsPeriod {
+  period: string // First day, YYYY-MM-DD
+  orders: number
+  revenue: number
+  average_order_value: number
+  ratings: number
+  average_rating: number
+}
+
+export interface ProviderAnalytics {
+  provider_id: string
+  from: string
+  to: string
+  granularity: AnalyticsGranularity
+  timezone: string
+  orders: number
+  orders_by_status: Partial<Record<OrderStatus, number>>
+  revenue: number // Confirmed, ready and completed orders
+  average_order_value: number
+  customers: number
+  repeat_customers: number
+  repeat_customer_rate: number // 0-1
+  ratings: number
+  average_rating: number
+  series: AnalyticsPeriod[]
+  top_items: Array<{ item_id: string; name: string; quantity: number; revenue: number }>
+  peak_hours: Array<{ hour: number; orders: number }> // Busiest first
+  updated_at?: string
+}
+
+// Multi-outlet provider organisations
+export type StaffRole = 'owner' | 'manager' | 'kitchen_staff'
+
+export interface Organisation {
+  id: string
+  name: string
+  owner_id: string
+  outlets: Provider[]
+  created_at: string
+}
+
+export interface StaffMember {
+  id: string
+  organisation_id: string
+  user_id: string
+  name?: string
+  phone?: string
+  outlet_id?: string // Omitted: every outlet
+  role: StaffRole
+  created_at: string
+}
+
+// An outlet the signed-in user works at
+export interface OutletAccess {
+  outlet_id: string
+  organisation_id?: string
+  business_name: string
+  role: StaffRole
+}
+
+export interface OrderInbox {
+  orders: Order[]
+  outlets: OutletAccess[]
+}
+
+// Admin console (/admin/*)
+export interface AdminKPIs {
+  from: string
+  to: string
+  users: number // All time
+  signups: number
+  signups_by_role: Record<string, number>
+  orders: number
+  gmv: number // Confirmed, ready and completed orders
+  active_providers: number
+  active_providers_by_city: Array<{ city: string; count: number }>
+  pending_applications: number
+  flagged_reviews: number
+  blocked_users: number
+  updated_at?: string
+}
+
+export interface AdminProviderFilters {
+  q?: string
+  verified?: boolean
+  blocked?: boolean
+  category?: string
+  city?: string
+  limit?: number
+  offset?: number
+}
+
+export interface AdminUserFilters {
+  q?: string
+  role?: User['role']
+  blocked?: boolean
+  limit?: number
+  offset?: number
+}
+
+export type ProviderBulkAction = 'verify' | 'unverify' | 'block' | 'unblock'
+export type UserBulkAction = 'block' | 'unblock'
+export type ReviewModerationAction = 'approve' | 'hide' | 'delete'
+
+export interface BulkResult {
+  succeeded: string[]
+  failed: Array<{ id: string; error: string }>
+}
+
+export interface AuditEvent {
+  id: string
+  entity_type: string
+  entity_id: string // The admin
+  event_type: string
+  payload: {
+    method: string
+    route: string
+    params: Record<string, string>
+    status: number
+    details?: Record<string, unknown>
+  }
+  created_at: string
+}
+
+// Promoted listings
+export type PromotionStatus = 'pending_payment' | 'active' | 'cancelled' | 'expired'
+
+export interface Promotion {
+  id: string
+  provider_id: string
+  business_name?: string
+  city: string
+  provider_category?: string // Omitted for every category
+  starts_at: string
+  ends_at: string
+  daily_cap: number
+  status: PromotionStatus
+  source: 'admin' | 'payment'
+  amount_paise: number
+  payment_order_id?: string
+  payment_id?: string
+  impressions: number
+  clicks: number
+  created_at: string
+  updated_at?: string
+}
+
+export interface PromotionBooking {
+  city: string
+  provider_category?: string
+  starts_at: string
+  ends_at: string
+  daily_cap: number
+}
+
+export interface PromotionStats {
+  promotion: Promotion
+  days: { day: string; impressions: number; clicks: number; ctr: number }[]
+  ctr: number
+}
+
+// Provider verification application
+export type ApplicationState =
+  | 'draft'
+  | 'submitted'
+  | 'under_review'
+  | 'changes_requested'
+  | 'approved'
+  | 'rejected'
+
+export type DocumentKind = 'fssai_licence' | 'id_proof' | 'kitchen_photo'
+
+export type BusinessType = 'individual' | 'proprietorship' | 'partnership' | 'company'
+
+export type IDProofType = 'aadhaar' | 'pan' | 'passport' | 'voter_id' | 'driving_licence'
+
+export interface ApplicationDocument {
+  id: string
+  kind: DocumentKind
+  object_key: string // From mediaApi.getUploadUrl('provider-docs', ...)
+  uploaded_at: string
+}
+
+export interface ApplicationComment {
+  id: string
+  author_id: string
+  from_state: ApplicationState
+  to_state: ApplicationState
+  comment?: string
+  created_at: string
+}
+
+export interface ProviderApplication {
+  id: string
+  provider_id: string
+  business_name?: string
+  state: ApplicationState
+  legal_name: string
+  business_type: BusinessType | ''
+  gstin?: string
+  fssai_number: string
+  fssai_expires_on?: string // YYYY-MM-DD
+  id_proof_type: IDProofType | ''
+  aadhar_number?: string // Masked: XXXX-XXXX-1234
+  documents: ApplicationDocument[]
+  history: ApplicationComment[]
+  submitted_at?: string
+  reviewed_by?: string
+  reviewed_at?: string
+  created_at: string
+  updated_at: string
+}
+
+export interface ApplicationDetails {
+  legal_name: string
+  business_type: BusinessType | ''
+  gstin?: string
+  fssai_number: string
+  fssai_expires_on?: string
+  id_proof_type: IDProofType | ''
+  aadhar_number?: string // Sent once; stored encrypted and returned masked
+}
+
 // Menu types
 export interface Menu {
   id: string
@@ -165,13 +442,29 @@ export interface Order {
   buyer_id: string
   provider_id: string
   items: OrderItem[]
-  total_estimate: number
+  total_estimate: number // Includes delivery_fee
+  delivery_fee?: number
   status: OrderStatus
   order_type: 'individual' | 'bulk'
   created_at: string
   // Extended fields
   provider?: Provider
-  delivery_address?: string
+  delivery_address?: Address // Snapshot taken when the order was placed
+}
+
+export interface Address {
+  id?: string
+  label: string // home, work, or custom
+  line1: string
+  line2?: string
+  landmark?: string
+  pin_code: string
+  city: string
+  district?: string
+  state: string
+  lat: number
+  lng: number
+  is_default: boolean
 }
 
 export interface OrderItem {
@@ -208,8 +501,15 @@ export interface Review {
   created_at: string
   // Extended
   user_name?: string
+  // Moderation, in admin listings
+  moderation_state?: ReviewModerationState
+  report_count?: number
+  report_reasons?: string[]
+  provider_name?: string
 }
 
+export type ReviewModerationState = 'published' | 'flagged' | 'approved' | 'hidden'
+
 // Offer types
 export interface Offer {
   id: string
@@ -282,6 +582,8 @@ export interface SearchFilters {
 export interface ProviderSearchResult extends Provider {
   distance_meters: number
   has_offers?: boolean
+  label?: string // "Sponsored" in promoted slots
+  promotion_id?: string // Set in promoted slots, for click tracking
 }
 
 export interface ItemSearchResult extends MenuItem {
@@ -290,6 +592,29 @@ export interface ItemSearchResult extends MenuItem {
   provider_distance_meters: number
 }
 
+export interface FacetBucket {
+  value: string
+  count: number
+  min?: number
+  max?: number
+}
+
+// Search response envelope; total and facets cover all matches, not just this page
+export interface SearchPage<T> {
+  results: T[]
+  promoted?: T[] // Promoted slots, first page of provider search only
+  total: number
+  facets: Partial<Record<'provider_category' | 'food_category' | 'tags' | 'price' | 'rating' | 'distance', FacetBucket[]>>
+  next_cursor?: string
+}
+
+export interface SearchSuggestion {
+  type: 'dish' | 'provider' | 'category' | 'city'
+  text: string
+  id?: string // provider id, city slug or category value
+  group?: 'provider_category' | 'food_category'
+}
+
 // API Response types
 export interface ApiResponse<T> {
   data?: T
@@ -0,0 +1,13 @@
+import toast from 'react-hot-toast'
+import type { BulkResult } from '../types'
+
+// Toasts the outcome of an admin bulk action, e.g. "3 providers verified"
+export function toastBulkResult(result: BulkResult, noun: string, verb: string) {
+  const done = result.succeeded.length
+  if (done > 0) {
+    toast.success(`${done} ${noun}${done === 1 ? '' : 's'} ${verb}`)
+  }
+  if (result.failed.length > 0) {
+    toast.error(`${result.failed.length} failed: ${result.failed[0].error}`)
+  }
+}